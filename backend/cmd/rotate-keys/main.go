@@ -0,0 +1,134 @@
+// rotate-keys 用当前主密钥（security.active-key）重新加密敏感字段，并重建手机号盲索引
+// 加密上线前写入的明文也会在此时加密；轮换完成前不要从配置中删除旧密钥
+//
+//	go run ./cmd/rotate-keys [-batch 500] [-dry-run]
+package main
+
+import (
+	"HealthCare/backend/config"
+	"HealthCare/backend/global"
+	"HealthCare/backend/security"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// rotateTable 描述一张需要轮换的表，Fields 为加密列，BlindIndex 为盲索引列到原字段的映射
+type rotateTable struct {
+	Name       string
+	Fields     []string
+	BlindIndex map[string]string
+}
+
+var tables = []rotateTable{
+	{Name: "users", Fields: []string{"phone", "address", "birthday"}, BlindIndex: map[string]string{"phone_bidx": "phone"}},
+	{Name: "user_health_items", Fields: []string{"item_value"}},
+}
+
+func main() {
+	batch := flag.Int("batch", 500, "每批处理的行数")
+	dryRun := flag.Bool("dry-run", false, "只统计需要轮换的行数，不写入")
+	flag.Parse()
+	if *batch <= 0 {
+		log.Fatal("batch must be positive")
+	}
+
+	config.InitConfig()
+	fmt.Printf("Active key: %s\n", security.ActiveKey())
+	for _, table := range tables {
+		scanned, updated, err := rotate(global.DB, table, *batch, *dryRun)
+		if err != nil {
+			log.Fatalf("Rotate %s failed after %d rows: %v", table.Name, scanned, err)
+		}
+		fmt.Printf("%s: scanned %d, updated %d\n", table.Name, scanned, updated)
+	}
+}
+
+// rotate 按主键分批读取原始列值，重新加密需要轮换的字段；每批在一个事务中写入
+func rotate(db *gorm.DB, table rotateTable, batch int, dryRun bool) (scanned, updated int, err error) {
+	columns := append([]string{"id"}, table.Fields...)
+	for column := range table.BlindIndex {
+		columns = append(columns, column)
+	}
+
+	var lastID uint64
+	for {
+		var rows []map[string]interface{}
+		if err := db.Table(table.Name).Select(columns).Where("id > ?", lastID).
+			Order("id").Limit(batch).Find(&rows).Error; err != nil {
+			return scanned, updated, err
+		}
+		if len(rows) == 0 {
+			return scanned, updated, nil
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				id, updates, err := rotateRow(table, row)
+				if err != nil {
+					return fmt.Errorf("row %d: %w", id, err)
+				}
+				lastID = id
+				scanned++
+				if len(updates) == 0 {
+					continue
+				}
+				updated++
+				if dryRun {
+					continue
+				}
+				if err := tx.Table(table.Name).Where("id = ?", id).UpdateColumns(updates).Error; err != nil {
+					return fmt.Errorf("row %d: %w", id, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return scanned, updated, err
+		}
+	}
+}
+
+// rotateRow 返回一行中需要更新的列，不需要轮换时返回空
+func rotateRow(table rotateTable, row map[string]interface{}) (uint64, map[string]interface{}, error) {
+	id, _ := strconv.ParseUint(toString(row["id"]), 10, 64)
+	updates := make(map[string]interface{})
+	plaintexts := make(map[string]string)
+	for _, field := range table.Fields {
+		value := toString(row[field])
+		plaintext, err := security.Decrypt(value)
+		if err != nil {
+			return id, nil, fmt.Errorf("%s: %w", field, err)
+		}
+		plaintexts[field] = plaintext
+		if !security.NeedsRotation(value) {
+			continue
+		}
+		sealed, err := security.Encrypt(plaintext)
+		if err != nil {
+			return id, nil, fmt.Errorf("%s: %w", field, err)
+		}
+		updates[field] = sealed
+	}
+	for column, field := range table.BlindIndex {
+		if index := security.BlindIndex(field, plaintexts[field]); index != toString(row[column]) {
+			updates[column] = index
+		}
+	}
+	return id, updates, nil
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
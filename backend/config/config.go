@@ -1,7 +1,9 @@
 package config
 
 import (
+	"HealthCare/backend/security"
 	"log"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -13,6 +15,7 @@ type Config struct {
 		Author       string `mapstructure:"author" yaml:"author"`
 		BackendPort  string `mapstructure:"backend-port" yaml:"backend-port"`
 		FrontendPort string `mapstructure:"frontend-port" yaml:"frontend-port"`
+		PublicURL    string `mapstructure:"public-url" yaml:"public-url"` // 对外访问地址，用于生成报告验证链接
 	} `mapstructure:"app" yaml:"app"`
 
 	Database struct {
@@ -21,7 +24,30 @@ type Config struct {
 		MaxOpenConns int    `mapstructure:"max-open-conns" yaml:"max-open-conns"`
 	} `mapstructure:"database" yaml:"database"`
 
-	Relations []string `mapstructure:"relations" yaml:"relations"`
+	Relations     []Relation     `mapstructure:"relations" yaml:"relations"`
+	RelationRules []RelationRule `mapstructure:"relation-rules" yaml:"relation-rules"`
+
+	RiskFactors []RiskFactor `mapstructure:"risk-factors" yaml:"risk-factors"`
+
+	Storage struct {
+		Dir                  string `mapstructure:"dir" yaml:"dir"`
+		ExportRetentionHours int    `mapstructure:"export-retention-hours" yaml:"export-retention-hours"`
+	} `mapstructure:"storage" yaml:"storage"`
+
+	AccountDeletion struct {
+		GraceDays int `mapstructure:"grace-days" yaml:"grace-days"`
+	} `mapstructure:"account-deletion" yaml:"account-deletion"`
+
+	CriticalAlerts struct {
+		EscalateAfterMinutes int `mapstructure:"escalate-after-minutes" yaml:"escalate-after-minutes"`
+	} `mapstructure:"critical-alerts" yaml:"critical-alerts"`
+
+	Security struct {
+		ActiveKey     string          `mapstructure:"active-key" yaml:"active-key"`
+		Keys          []EncryptionKey `mapstructure:"keys" yaml:"keys"`
+		BlindIndexKey string          `mapstructure:"blind-index-key" yaml:"blind-index-key"`
+		KeysFile      string          `mapstructure:"keys-file" yaml:"keys-file"` // 单独保存密钥的文件，设置后覆盖以上三项
+	} `mapstructure:"security" yaml:"security"`
 
 	EMail struct {
 		Host        string `mapstructure:"host" yaml:"host"`
@@ -33,15 +59,65 @@ type Config struct {
 	} `mapstructure:"email" yaml:"email"`
 }
 
+// EncryptionKey 敏感字段加密使用的主密钥，Key 为 base64 编码的 32 字节
+type EncryptionKey struct {
+	ID  string `mapstructure:"id" yaml:"id"`
+	Key string `mapstructure:"key" yaml:"key"`
+}
+
+// RiskFactor 健康风险因素，Keywords 为相关检查项目名称中的关键词，
+// 用于从异常检查结果推断风险因素，并匹配套餐的适用风险因素
+type RiskFactor struct {
+	Code     string   `mapstructure:"code" yaml:"code" json:"code"`
+	Name     string   `mapstructure:"name" yaml:"name" json:"name"`
+	NameEn   string   `mapstructure:"name-en" yaml:"name-en" json:"name_en"`
+	Keywords []string `mapstructure:"keywords" yaml:"keywords" json:"keywords"`
+}
+
+// Relation 亲属称谓，Name 表示 "对方是我的什么人"
+// Inverse 按我的性别给出我在对方眼中的称谓；Specific 将性别中立的称谓（如配偶）按对方性别细化；
+// Base 为推导传递关系时使用的泛称（如哥哥、弟弟 -> 兄弟）；Degree 用于家族病史评估
+type Relation struct {
+	Name     string       `mapstructure:"name" yaml:"name" json:"name"`
+	NameEn   string       `mapstructure:"name-en" yaml:"name-en" json:"name_en"`
+	Gender   string       `mapstructure:"gender" yaml:"gender" json:"gender"` // 对方的性别 M/F，为空表示不限
+	Inverse  GenderedName `mapstructure:"inverse" yaml:"inverse" json:"inverse"`
+	Specific GenderedName `mapstructure:"specific" yaml:"specific" json:"-"`
+	Base     string       `mapstructure:"base" yaml:"base" json:"-"`
+	Degree   int          `mapstructure:"degree" yaml:"degree" json:"degree"` // 血缘亲等，0 表示非血亲（配偶、姻亲）
+}
+
+// GenderedName 按性别区分的称谓，为空表示该性别不适用
+type GenderedName struct {
+	Male   string `mapstructure:"male" yaml:"male" json:"male"`
+	Female string `mapstructure:"female" yaml:"female" json:"female"`
+}
+
+// RelationRule 传递关系推导规则：我的 Via 的 Then 是我的 Result
+type RelationRule struct {
+	Via    string `mapstructure:"via" yaml:"via"`
+	Then   string `mapstructure:"then" yaml:"then"`
+	Result string `mapstructure:"result" yaml:"result"`
+}
+
+// KeyPlaceholder 示例配置中尚未替换的密钥
+const KeyPlaceholder = "CHANGE_ME"
+
 var (
-	AppConfig      *Config
-	ValidRelations = make(map[string]bool)
+	AppConfig        *Config
+	RelationByName   = make(map[string]Relation)
+	RelationRuleMap  = make(map[[2]string]string)
+	RiskFactorByCode = make(map[string]RiskFactor)
 )
 
 func InitConfig() {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath("./config")
+	// 环境变量覆盖配置文件中的同名项，如 HEALTHCARE_SECURITY_BLIND_INDEX_KEY 对应 security.blind-index-key
+	viper.SetEnvPrefix("HEALTHCARE")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err != nil {
 		log.Fatalf("Error reading config file: %v", err)
@@ -52,8 +128,49 @@ func InitConfig() {
 		log.Fatalf("Unable to decode into struct: %v", err)
 	}
 
+	if AppConfig.App.PublicURL == "" {
+		AppConfig.App.PublicURL = "http://localhost:" + AppConfig.App.BackendPort
+	}
+
+	if AppConfig.CriticalAlerts.EscalateAfterMinutes <= 0 {
+		AppConfig.CriticalAlerts.EscalateAfterMinutes = 30
+	}
+
 	for _, rel := range AppConfig.Relations {
-		ValidRelations[rel] = true
+		RelationByName[rel.Name] = rel
+	}
+	for _, rule := range AppConfig.RelationRules {
+		RelationRuleMap[[2]string{rule.Via, rule.Then}] = rule.Result
+	}
+
+	for _, factor := range AppConfig.RiskFactors {
+		RiskFactorByCode[factor.Code] = factor
+	}
+
+	if path := AppConfig.Security.KeysFile; path != "" {
+		keyConfig := viper.New()
+		keyConfig.SetConfigFile(path)
+		if err := keyConfig.ReadInConfig(); err != nil {
+			log.Fatalf("Error reading security keys file: %v", err)
+		}
+		if err := keyConfig.Unmarshal(&AppConfig.Security); err != nil {
+			log.Fatalf("Unable to decode security keys file: %v", err)
+		}
+	}
+
+	// 示例配置中的密钥是占位符，部署时必须替换
+	keys := make(map[string]string, len(AppConfig.Security.Keys))
+	for _, key := range AppConfig.Security.Keys {
+		if key.Key == KeyPlaceholder {
+			log.Fatalf("Invalid security config: key %q is still the placeholder %s", key.ID, KeyPlaceholder)
+		}
+		keys[key.ID] = key.Key
+	}
+	if AppConfig.Security.BlindIndexKey == KeyPlaceholder {
+		log.Fatalf("Invalid security config: blind-index-key is still the placeholder %s", KeyPlaceholder)
+	}
+	if err := security.Configure(keys, AppConfig.Security.ActiveKey, AppConfig.Security.BlindIndexKey); err != nil {
+		log.Fatalf("Invalid security config: %v", err)
 	}
 
 	InitDB()
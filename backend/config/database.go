@@ -28,6 +28,9 @@ func InitDB() {
 		log.Fatalf("Failed to configure database, got error: %v", err)
 	}
 
+	// 结果审核状态上线前录入的结果客户本已可见，迁移后视为已发布
+	backfillReview := !db.Migrator().HasColumn(&models.UserPackage{}, "review_status")
+
 	// // 自动迁移数据库表
 	err = db.AutoMigrate(
 		&models.User{}, 
@@ -38,10 +41,56 @@ func InitDB() {
 		&models.PlanHeathItem{}, 
 		&models.Commentary{}, 
 		&models.UserHealthItem{}, 
-		&models.UserPackage{})
+		&models.UserPackage{},
+		&models.HealthItemTranslation{},
+		&models.SearchDocument{},
+		&models.PlanVersion{},
+		&models.PlanVersionItem{},
+		&models.CommentaryReport{},
+		&models.FamilyGrant{},
+		&models.FamilyAccessLog{},
+		&models.DependentClaim{},
+		&models.AuditLog{},
+		&models.ReportFile{},
+		&models.ExportJob{},
+		&models.DeletionRequest{},
+		&models.LoincMapping{},
+		&models.ImportReport{},
+		&models.BatchImport{},
+		&models.ReportIssue{},
+		&models.CheckupConclusion{},
+		&models.ConclusionComment{},
+		&models.ConclusionRevision{},
+		&models.InstitutionMember{},
+		&models.CriticalAck{},
+		&models.CriticalAlert{},
+		&models.Notification{},
+		&models.ItemCategory{},
+		&models.HealthItemSynonym{},
+	)
 	if err != nil {
 		log.Fatalf("Failed to migrate database tables, got error: %v", err)
 	}
 
+	// 检查结果改为加密保存后按值建立的索引已无意义
+	if db.Migrator().HasIndex(&models.UserHealthItem{}, "idx_user_health_items_item_value") {
+		if err := db.Migrator().DropIndex(&models.UserHealthItem{}, "idx_user_health_items_item_value"); err != nil {
+			log.Fatalf("Failed to drop index, got error: %v", err)
+		}
+	}
+
+	// 通知不再保存客户姓名，读取时按套餐查找
+	if db.Migrator().HasColumn(&models.Notification{}, "subject") {
+		if err := db.Migrator().DropColumn(&models.Notification{}, "subject"); err != nil {
+			log.Fatalf("Failed to drop column, got error: %v", err)
+		}
+	}
+
+	if backfillReview {
+		if err := db.Model(&models.UserPackage{}).Where("1 = 1").Update("review_status", models.ReviewReleased).Error; err != nil {
+			log.Fatalf("Failed to backfill review status, got error: %v", err)
+		}
+	}
+
 	global.DB = db
 }
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DeleteUser 申请注销账号，本人或管理员可以发起
+// 账号在冷静期（account-deletion.grace-days）后由后台任务匿名化，期间可撤销；
+// 管理员可用 immediate=true 跳过冷静期立即执行
+func DeleteUser(ctx *gin.Context) {
+	var input struct {
+		Reason string `json:"reason" binding:"max=255"`
+	}
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&input); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+			})
+			return
+		}
+	}
+
+	user, actor, ok := deletionTarget(ctx)
+	if !ok {
+		return
+	}
+	immediate := ctx.Query("immediate") == "true"
+	if immediate && actor.UserType != 2 {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "admin_required"),
+		})
+		return
+	}
+	if err := utils.CheckDeletable(global.DB, user.ID); err != nil {
+		respondDeletionError(ctx, err)
+		return
+	}
+
+	request := models.DeletionRequest{
+		RelationUserId: user.ID,
+		RequestedBy:    actor.ID,
+		Status:         models.DeletionPending,
+		Reason:         input.Reason,
+		ScheduledAt:    time.Now().Add(utils.DeletionGracePeriod()),
+	}
+	if immediate {
+		request.ScheduledAt = time.Now()
+	}
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var pending int64
+		if err := tx.Model(&models.DeletionRequest{}).
+			Where("user_id = ? AND status = ?", user.ID, models.DeletionPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 && !immediate {
+			return errDeletionPending
+		}
+		if err := tx.Create(&request).Error; err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditUserDeletionRequest,
+			TargetType: "user",
+			TargetID:   user.ID,
+			After: map[string]interface{}{
+				"deletion_request_id": request.ID,
+				"scheduled_at":        request.ScheduledAt.Format(time.RFC3339),
+				"immediate":           immediate,
+			},
+		})
+	})
+	if err != nil {
+		respondDeletionError(ctx, err)
+		return
+	}
+
+	if !immediate {
+		ctx.JSON(http.StatusAccepted, gin.H{
+			"message":  i18n.T(ctx, "deletion_scheduled", request.ScheduledAt.Format("2006-01-02")),
+			"deletion": request,
+		})
+		return
+	}
+
+	if err := utils.ExecuteDeletion(global.DB, request, func(tx *gorm.DB, entry utils.AuditEntry) error {
+		return utils.WriteAudit(ctx, tx, entry)
+	}); err != nil {
+		respondDeletionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "user_deleted"),
+		"logout":  actor.ID == user.ID,
+	})
+}
+
+// GetUserDeletion 查看账号最近一次注销申请
+func GetUserDeletion(ctx *gin.Context) {
+	user, _, ok := deletionTarget(ctx)
+	if !ok {
+		return
+	}
+	var request models.DeletionRequest
+	err := global.DB.Where("user_id = ?", user.ID).Order("id DESC").First(&request).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "deletion_not_found"),
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, request)
+}
+
+// CancelUserDeletion 冷静期内撤销注销申请
+func CancelUserDeletion(ctx *gin.Context) {
+	user, _, ok := deletionTarget(ctx)
+	if !ok {
+		return
+	}
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var request models.DeletionRequest
+		if err := tx.Where("user_id = ? AND status = ?", user.ID, models.DeletionPending).
+			First(&request).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&request).Update("status", models.DeletionCancelled).Error; err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditUserDeletionCancel,
+			TargetType: "user",
+			TargetID:   user.ID,
+			After:      map[string]interface{}{"deletion_request_id": request.ID},
+		})
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "deletion_not_found"),
+		})
+		return
+	}
+	if err != nil {
+		respondDeletionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "deletion_cancelled"),
+	})
+}
+
+var errDeletionPending = errors.New("deletion already scheduled")
+
+// deletionTarget 按路径参数 id 查找要注销的账号，只有本人或管理员可以操作
+func deletionTarget(ctx *gin.Context) (user models.User, actor models.User, ok bool) {
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&actor).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return user, actor, false
+	}
+	if err := global.DB.Where("id = ?", ctx.Param("id")).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": i18n.T(ctx, "user_not_found"),
+			})
+			return user, actor, false
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return user, actor, false
+	}
+	if actor.ID != user.ID && actor.UserType != 2 {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "delete_user_forbidden"),
+		})
+		return user, actor, false
+	}
+	return user, actor, true
+}
+
+// respondDeletionError 注销失败时的统一响应
+func respondDeletionError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errDeletionPending):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "deletion_already_scheduled"),
+		})
+	case errors.Is(err, utils.ErrInstitutionAccount):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "institution_account_not_deletable"),
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "delete_failed") + err.Error(),
+		})
+	}
+}
@@ -1,9 +1,10 @@
 package controllers
 
 import (
+	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
-	"fmt"
 	"net/http"
 	"strconv"
 
@@ -25,7 +26,7 @@ func AddUserDateByPlanID(ctx *gin.Context) {
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(400, gin.H{
-			"error": "Invalid request data: " + err.Error(),
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
 		})
 		return
 	}
@@ -35,7 +36,7 @@ func AddUserDateByPlanID(ctx *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid user",
+			"error": i18n.T(ctx, "invalid_user"),
 		})
 		return
 	}
@@ -43,7 +44,7 @@ func AddUserDateByPlanID(ctx *gin.Context) {
 	// 检查是否为机构用户 (UserType = 3)
 	if user.UserType != 3 && user.UserType != 2 { // 不是机构用户也不是管理员
 		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "Permission denied: only institution users can add health data",
+			"error": i18n.T(ctx, "only_institution_add_data"),
 		})
 		return
 	}
@@ -52,14 +53,14 @@ func AddUserDateByPlanID(ctx *gin.Context) {
 	customerIDUint, err := strconv.ParseUint(customerID, 10, 32)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid customer ID",
+			"error": i18n.T(ctx, "invalid_customer_id"),
 		})
 		return
 	}
 	planIDUint, err := strconv.ParseUint(planID, 10, 32)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid plan ID",
+			"error": i18n.T(ctx, "invalid_plan_id"),
 		})
 		return
 	}
@@ -68,39 +69,88 @@ func AddUserDateByPlanID(ctx *gin.Context) {
 	var plan models.Plan
 	if err := global.DB.First(&plan, planIDUint).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "Plan not found",
+			"error": i18n.T(ctx, "plan_not_found"),
 		})
 		return
 	}
 
+	// 机构账号须为套餐所属机构的主账号或录入成员，首次录入与修改相同
+	if user.UserType != 2 {
+		role, err := utils.InstitutionRole(global.DB, plan.RelationInstitutionID, user.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
+		}
+		if !utils.CanEnterResults(role) {
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error": i18n.T(ctx, "result_entry_forbidden"),
+			})
+			return
+		}
+	}
+
 	// 验证用户存在
 	var customer models.User
 	if err := global.DB.First(&customer, customerIDUint).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "Customer not found",
+			"error": i18n.T(ctx, "customer_not_found"),
 		})
 		return
 	}
 
+	// 体检数据、套餐状态与审计日志在同一事务中写入
+	tx := global.DB.Begin()
+
 	// 验证用户是否已订阅该套餐
 	var userPackage models.UserPackage
-	if err := global.DB.Where("user_id = ? AND plan_id = ?", customerIDUint, planIDUint).First(&userPackage).Error; err != nil {
-		// 如果用户没有订阅该套餐，自动创建订阅关系
+	if err := tx.Where("user_id = ? AND plan_id = ?", customerIDUint, planIDUint).First(&userPackage).Error; err != nil {
+		// 如果用户没有订阅该套餐，按当前版本自动创建订阅关系
+		version, err := utils.CurrentPlanVersion(tx, plan.ID)
+		if err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "plan_version_create_failed") + err.Error(),
+			})
+			return
+		}
 		userPackage = models.UserPackage{
 			UserID:        uint(customerIDUint),
 			PlanID:        uint(planIDUint),
 			InstitutionID: plan.RelationInstitutionID,
 			Status:        0, // 0表示待检测
+			PlanVersionID: &version.ID,
 		}
-		if err := global.DB.Create(&userPackage).Error; err != nil {
+		if err := tx.Create(&userPackage).Error; err != nil {
+			tx.Rollback()
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to create user package association",
+				"error": i18n.T(ctx, "user_package_create_failed"),
 			})
 			return
 		}
 	}
 
-	// 处理每个体检项目的数据
+	// 用户所购版本包含的项目，录入的数据须属于该版本
+	pinned, err := utils.LoadPlanVersion(tx, userPackage.PlanVersionID)
+	if err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "plan_item_validate_failed"),
+		})
+		return
+	}
+	var versionItems map[uint]bool
+	if pinned != nil {
+		versionItems = make(map[uint]bool, len(pinned.Items))
+		for _, vi := range pinned.Items {
+			versionItems[vi.RelationHealthItemId] = true
+		}
+	}
+
+	// 校验每个体检项目后按提交顺序写入，同一项目提交多次时以最后一次为准
+	values := make(map[uint]string, len(input))
+	var order []uint
 	for _, item := range input {
 		if item.ItemValue == nil || *item.ItemValue == "" {
 			continue // 跳过空值
@@ -111,7 +161,7 @@ func AddUserDateByPlanID(ctx *gin.Context) {
 		var planHeathItem models.PlanHeathItem
 
 		// 尝试作为PlanHeathItem的ID查询
-		if err := global.DB.First(&planHeathItem, item.ItemID).Error; err == nil {
+		if err := tx.First(&planHeathItem, item.ItemID).Error; err == nil {
 			// 找到了匹配的PlanHeathItem
 			healthItemID = planHeathItem.RelationHealthItemId
 		} else {
@@ -119,66 +169,80 @@ func AddUserDateByPlanID(ctx *gin.Context) {
 			healthItemID = uint(item.ItemID)
 		}
 
-		// 检查此健康项目是否属于该套餐
+		// 检查此健康项目是否属于该套餐（已记录版本时按所购版本检查）
 		var count int64
-		if err := global.DB.Model(&models.PlanHeathItem{}).
+		if versionItems != nil {
+			if versionItems[healthItemID] {
+				count = 1
+			}
+		} else if err := tx.Model(&models.PlanHeathItem{}).
 			Where("plan_id = ? AND health_item_id = ?", planIDUint, healthItemID).
 			Count(&count).Error; err != nil {
+			tx.Rollback()
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to validate health item in plan",
+				"error": i18n.T(ctx, "plan_item_validate_failed"),
 			})
 			return
 		}
 
 		if count == 0 {
+			tx.Rollback()
 			ctx.JSON(http.StatusBadRequest, gin.H{
-				"error": fmt.Sprintf("Health item ID %d is not associated with plan ID %s", healthItemID, planID),
+				"error": i18n.T(ctx, "health_item_not_in_plan", healthItemID, planID),
+			})
+			return
+		}
+		// 衍生项目由公式计算，不能手工录入
+		derived, err := utils.IsDerivedItem(tx, healthItemID)
+		if err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "plan_item_validate_failed"),
+			})
+			return
+		}
+		if derived {
+			tx.Rollback()
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "derived_item_not_editable", healthItemID),
 			})
 			return
 		}
 
-		// 通过customerID、planID和healthItemID查找userhealthitem表中对应的记录
-		var userHealthItem models.UserHealthItem
-		result := global.DB.Where("user_id = ? AND plan_id = ? AND health_item_id = ?",
-			customerIDUint, planIDUint, healthItemID).First(&userHealthItem)
-
-		// 如果不存在记录，则创建新记录
-		if result.Error != nil {
-			userHealthItem = models.UserHealthItem{
-				RelationUserId:       uint(customerIDUint),
-				RelationPlanId:       uint(planIDUint),
-				RelationHealthItemId: healthItemID,
-				ItemValue:            *item.ItemValue,
-			}
-			if err := global.DB.Create(&userHealthItem).Error; err != nil {
-				ctx.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Failed to create user health item: " + err.Error(),
-				})
-				return
-			}
-		} else {
-			// 更新现有记录
-			userHealthItem.ItemValue = *item.ItemValue
-			if err := global.DB.Save(&userHealthItem).Error; err != nil {
-				ctx.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Failed to update user health item: " + err.Error(),
-				})
-				return
-			}
+		if _, ok := values[healthItemID]; !ok {
+			order = append(order, healthItemID)
 		}
+		values[healthItemID] = *item.ItemValue
+	}
+
+	// 写入结果、重新计算衍生项目、触发危急值提醒并将套餐标记为已完成，与导入使用同一流程
+	saved, err := utils.SaveCheckupResults(tx, userPackage, values, order, user.ID)
+	if err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "user_health_item_update_failed") + err.Error(),
+		})
+		return
+	}
+
+	if err := utils.WriteAudit(ctx, tx, saved.Audit); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "audit_write_failed") + err.Error(),
+		})
+		return
 	}
 
-	// 更新用户套餐状态为已完成
-	if err := global.DB.Model(&models.UserPackage{}).
-		Where("user_id = ? AND plan_id = ?", customerIDUint, planIDUint).
-		Update("status", 1).Error; err != nil {
+	if err := tx.Commit().Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update user package status",
+			"error": i18n.T(ctx, "commit_failed"),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "User health items updated successfully",
+		"message":         i18n.T(ctx, "user_health_items_updated"),
+		"critical_alerts": saved.Alerts,
+		"derived":         saved.Derived,
 	})
 }
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAuditLogs 管理员查询审计日志
+// 支持分页排序，按 actor_id、action、target_type、target_id、request_id 及 date_from/date_to 筛选
+func GetAuditLogs(ctx *gin.Context) {
+	page, err := utils.ParsePagination(ctx, map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+	}, "-id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+	actorID, err := utils.ParseUintQuery(ctx, "actor_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_user_id"),
+		})
+		return
+	}
+	targetID, err := utils.ParseUintQuery(ctx, "target_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+	dateFrom, dateTo, err := utils.ParseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	query := global.DB.Model(&models.AuditLog{})
+	if actorID != nil {
+		query = query.Where("actor_id = ?", *actorID)
+	}
+	if targetID != nil {
+		query = query.Where("target_id = ?", *targetID)
+	}
+	for _, field := range []string{"action", "target_type", "request_id"} {
+		if value := ctx.Query(field); value != "" {
+			query = query.Where(field+" = ?", value)
+		}
+	}
+	query = utils.WhereRange(query, "created_at", dateFrom, dateTo)
+
+	var logs []models.AuditLog
+	pageInfo, err := utils.Paginate(query, page, &logs)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "audit_logs_fetch_failed") + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"logs":       logs,
+		"pagination": pageInfo,
+	})
+}
+
+// VerifyAuditLogs 管理员校验审计日志哈希链是否完整
+func VerifyAuditLogs(ctx *gin.Context) {
+	result, err := utils.VerifyAuditChain(global.DB)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "audit_logs_fetch_failed") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
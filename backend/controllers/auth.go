@@ -3,6 +3,7 @@ package controllers
 import (
 	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
 	"errors"
 	"net/http"
@@ -22,10 +23,13 @@ func Register(ctx *gin.Context) {
 		return
 	}
 
+	// 被监护人档案只能由监护人创建
+	user.GuardianID = nil
+
 	// Validate user type
 	if user.UserType < 1 || user.UserType > 3 {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid user type. Must be 1 (normal), 2 (admin), or 3 (institution)",
+			"error": i18n.T(ctx, "invalid_user_type"),
 		})
 		return
 	}
@@ -89,7 +93,7 @@ func Login(ctx *gin.Context) {
 			})
 		} else {
 			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid Credentials",
+				"error": i18n.T(ctx, "invalid_credentials"),
 			})
 		}
 		return
@@ -97,7 +101,15 @@ func Login(ctx *gin.Context) {
 
 	if !utils.CheckPassword(input.Password, user.Password) {
 		ctx.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid Credentials",
+			"error": i18n.T(ctx, "invalid_credentials"),
+		})
+		return
+	}
+
+	// 被监护人档案认领前不能登录；在校验密码之后判断，避免泄露账号是否为被监护人
+	if user.GuardianID != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "dependent_login_forbidden"),
 		})
 		return
 	}
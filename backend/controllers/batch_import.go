@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"HealthCare/backend/sheet"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxBatchFileSize 批量上传文件的最大长度
+const maxBatchFileSize = 5 << 20
+
+// UploadBatchResults 机构批量上传一个套餐的体检结果（CSV/XLSX，每行一位客户，每列一个项目）
+// dry_run=true 时只返回校验报告；正式提交时存在任一行级错误则整批不写入（422），
+// 校验通过后在一个事务中写入；同一文件重复上传时返回已有批次
+func UploadBatchResults(ctx *gin.Context) {
+	importer, plan, ok := batchPlan(ctx, ctx.PostForm("plan_id"))
+	if !ok {
+		return
+	}
+	dryRun := ctx.PostForm("dry_run") == "true"
+
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "batch_file_required"),
+		})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxBatchFileSize+1))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+	if len(data) > maxBatchFileSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": i18n.T(ctx, "import_body_too_large", maxBatchFileSize>>20),
+		})
+		return
+	}
+	sum := sha256.Sum256(data)
+	fileHash := hex.EncodeToString(sum[:])
+
+	if !dryRun {
+		var existing models.BatchImport
+		err := global.DB.Where("imported_by = ? AND plan_id = ? AND file_hash = ?", importer.ID, plan.ID, fileHash).
+			First(&existing).Error
+		if err == nil {
+			ctx.JSON(http.StatusOK, gin.H{
+				"message":   i18n.T(ctx, "batch_already_imported", existing.ID),
+				"duplicate": true,
+				"batch":     existing,
+			})
+			return
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
+		}
+	}
+
+	// 表头加最多 MaxBatchRows 行客户，超出时不再继续解析
+	table, err := sheet.Read(header.Filename, data, utils.MaxBatchRows+1)
+	if errors.Is(err, sheet.ErrTooManyRows) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "batch_too_many_rows", utils.MaxBatchRows),
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "batch_file_invalid") + err.Error(),
+		})
+		return
+	}
+	upload, err := utils.PrepareBatch(global.DB, plan, table, i18n.Lang(ctx))
+	switch {
+	case errors.Is(err, utils.ErrBatchEmpty):
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "batch_empty"),
+		})
+		return
+	case errors.Is(err, utils.ErrBatchTooManyRows):
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "batch_too_many_rows", utils.MaxBatchRows),
+		})
+		return
+	case errors.Is(err, utils.ErrBatchNoCustomerCol):
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "batch_no_customer_column"),
+		})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+
+	upload.Report.DryRun = dryRun
+	if dryRun {
+		ctx.JSON(http.StatusOK, gin.H{
+			"report": upload.Report,
+		})
+		return
+	}
+	if !upload.Report.Valid {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  i18n.T(ctx, "batch_validation_failed", len(upload.Report.Errors)),
+			"report": upload.Report,
+		})
+		return
+	}
+
+	batch := models.BatchImport{
+		ImportedBy: importer.ID,
+		FileHash:   fileHash,
+		FileName:   header.Filename,
+	}
+	if err := utils.CommitBatch(global.DB, upload, &batch, func(tx *gorm.DB, entry utils.AuditEntry) error {
+		return utils.WriteAudit(ctx, tx, entry)
+	}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "batch_commit_failed") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "batch_imported", batch.Rows, batch.Results),
+		"batch":   batch,
+		"report":  upload.Report,
+	})
+}
+
+// GetBatchTemplate 下载套餐批量上传的 CSV 模板，表头为客户信息列与当前版本的项目名称
+func GetBatchTemplate(ctx *gin.Context) {
+	_, plan, ok := batchPlan(ctx, ctx.Query("plan_id"))
+	if !ok {
+		return
+	}
+	version, err := utils.CurrentPlanVersion(global.DB, plan.ID)
+	if err == nil {
+		version, err = utils.LoadPlanVersion(global.DB, &version.ID)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+
+	header := []string{"user_id", "name", "phone", "birthday"}
+	for _, item := range version.Items {
+		header = append(header, item.ItemName)
+	}
+	var buf bytes.Buffer
+	buf.WriteString("\xef\xbb\xbf") // BOM，便于 Excel 识别 UTF-8
+	w := csv.NewWriter(&buf)
+	_ = w.Write(header)
+	w.Flush()
+
+	ctx.Header("Content-Disposition", `attachment; filename="plan-`+strconv.FormatUint(uint64(plan.ID), 10)+`-template.csv"`)
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// GetBatchImports 查询已提交的批量上传，机构只能查看本账号的批次，管理员可查看全部
+func GetBatchImports(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return
+	}
+	page, err := utils.ParsePagination(ctx, map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+	}, "-id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+	planID, err := utils.ParseUintQuery(ctx, "plan_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_plan_id"),
+		})
+		return
+	}
+
+	query := global.DB.Model(&models.BatchImport{})
+	if user.UserType != 2 {
+		query = query.Where("imported_by = ?", user.ID)
+	}
+	if planID != nil {
+		query = query.Where("plan_id = ?", *planID)
+	}
+	var batches []models.BatchImport
+	pageInfo, err := utils.Paginate(query, page, &batches)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"batches":    batches,
+		"pagination": pageInfo,
+	})
+}
+
+// GetBatchImport 查看一个已提交的批次
+func GetBatchImport(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return
+	}
+	var batch models.BatchImport
+	if err := global.DB.First(&batch, ctx.Param("id")).Error; err != nil ||
+		(batch.ImportedBy != user.ID && user.UserType != 2) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "batch_not_found"),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, batch)
+}
+
+// batchPlan 查找批量上传的目标套餐，只有套餐所属机构的主账号、录入成员与管理员可以操作
+func batchPlan(ctx *gin.Context, planID string) (importer models.User, plan models.Plan, ok bool) {
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&importer).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return importer, plan, false
+	}
+	id, err := utils.UnmarshalUint(planID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_plan_id"),
+		})
+		return importer, plan, false
+	}
+	if err := global.DB.First(&plan, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "plan_not_found"),
+		})
+		return importer, plan, false
+	}
+	if importer.UserType != 2 {
+		role, err := utils.InstitutionRole(global.DB, plan.RelationInstitutionID, importer.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "internal_error"),
+			})
+			return importer, plan, false
+		}
+		if !utils.CanEnterResults(role) {
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error": i18n.T(ctx, "plan_operate_forbidden"),
+			})
+			return importer, plan, false
+		}
+	}
+	return importer, plan, true
+}
@@ -0,0 +1,515 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// categoryDefined 分类是否已定义，空分类（归入 "其他"）总是允许
+func categoryDefined(name string) (bool, error) {
+	if name == "" {
+		return true, nil
+	}
+	var count int64
+	err := global.DB.Model(&models.ItemCategory{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+// itemCategoryView 分类及其下的项目数
+type itemCategoryView struct {
+	models.ItemCategory
+	ItemCount int64 `json:"item_count"`
+}
+
+// GetItemCategories 查看项目分类，按 sort_order、名称排序
+func GetItemCategories(ctx *gin.Context) {
+	var categories []models.ItemCategory
+	if err := global.DB.Order("sort_order, name").Find(&categories).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	var counts []struct {
+		Category string
+		Count    int64
+	}
+	if err := global.DB.Model(&models.HealthItem{}).Scopes(utils.CatalogItems).
+		Select("category, COUNT(*) AS count").Group("category").Scan(&counts).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	byName := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		byName[c.Category] = c.Count
+	}
+	views := make([]itemCategoryView, 0, len(categories))
+	for _, c := range categories {
+		views = append(views, itemCategoryView{ItemCategory: c, ItemCount: byName[c.Name]})
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"categories":    views,
+		"uncategorized": byName[""],
+	})
+}
+
+type itemCategoryInput struct {
+	Name      string `json:"name" binding:"required"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// CreateItemCategory 管理员新建项目分类
+func CreateItemCategory(ctx *gin.Context) {
+	var input itemCategoryInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len([]rune(name)) > 50 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request"),
+		})
+		return
+	}
+	if exists, err := utils.CheckExists(&models.ItemCategory{}, "name", name); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	} else if exists {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "catalog_category_exists", name),
+		})
+		return
+	}
+
+	category := models.ItemCategory{Name: name, SortOrder: input.SortOrder}
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditCatalogUpdate,
+			TargetType: "item_category",
+			TargetID:   category.ID,
+			After:      map[string]interface{}{"name": category.Name, "sort_order": category.SortOrder},
+		})
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  i18n.T(ctx, "catalog_category_saved"),
+		"category": category,
+	})
+}
+
+// UpdateItemCategory 管理员修改分类名称或排序，改名时该分类下的项目随之改为新名称
+func UpdateItemCategory(ctx *gin.Context) {
+	var category models.ItemCategory
+	if err := global.DB.First(&category, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "catalog_category_not_found", ctx.Param("id")),
+		})
+		return
+	}
+	var input itemCategoryInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len([]rune(name)) > 50 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request"),
+		})
+		return
+	}
+	if name != category.Name {
+		if exists, err := utils.CheckExists(&models.ItemCategory{}, "name", name); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
+		} else if exists {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error": i18n.T(ctx, "catalog_category_exists", name),
+			})
+			return
+		}
+	}
+
+	before := map[string]interface{}{"name": category.Name, "sort_order": category.SortOrder}
+	oldName := category.Name
+	category.Name = name
+	category.SortOrder = input.SortOrder
+	var itemIDs []uint
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&category).Error; err != nil {
+			return err
+		}
+		if name != oldName {
+			if err := tx.Model(&models.HealthItem{}).Where("category = ?", oldName).Pluck("id", &itemIDs).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.HealthItem{}).Where("category = ?", oldName).Update("category", name).Error; err != nil {
+				return err
+			}
+			for _, id := range itemIDs {
+				if err := utils.SnapshotPlansWithItem(tx, id); err != nil {
+					return err
+				}
+			}
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditCatalogUpdate,
+			TargetType: "item_category",
+			TargetID:   category.ID,
+			Before:     before,
+			After:      map[string]interface{}{"name": category.Name, "sort_order": category.SortOrder},
+		})
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  i18n.T(ctx, "catalog_category_saved"),
+		"category": category,
+	})
+}
+
+// DeleteItemCategory 管理员删除分类（物理删除），仍有项目使用时不能删除
+func DeleteItemCategory(ctx *gin.Context) {
+	var category models.ItemCategory
+	if err := global.DB.First(&category, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "catalog_category_not_found", ctx.Param("id")),
+		})
+		return
+	}
+	var count int64
+	if err := global.DB.Model(&models.HealthItem{}).Where("category = ?", category.Name).Count(&count).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	if count > 0 {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "catalog_category_in_use", count),
+		})
+		return
+	}
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&category).Error; err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditCatalogUpdate,
+			TargetType: "item_category",
+			TargetID:   category.ID,
+			Before:     map[string]interface{}{"name": category.Name, "sort_order": category.SortOrder},
+		})
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "catalog_category_deleted"),
+	})
+}
+
+// CreateCatalogItem 管理员向项目目录添加项目，名称、同义名称与编码不能与已有项目重复
+func CreateCatalogItem(ctx *gin.Context) {
+	var input struct {
+		ItemName      string   `json:"item_name" binding:"required"`
+		Code          string   `json:"code"`
+		Category      string   `json:"category"`
+		Unit          string   `json:"unit"`
+		ReferenceLow  *float64 `json:"reference_low"`
+		ReferenceHigh *float64 `json:"reference_high"`
+		CriticalLow   *float64 `json:"critical_low"`
+		CriticalHigh  *float64 `json:"critical_high"`
+		Synonyms      []string `json:"synonyms"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+	item := models.HealthItem{
+		ItemName:      strings.TrimSpace(input.ItemName),
+		Code:          strings.TrimSpace(input.Code),
+		Category:      strings.TrimSpace(input.Category),
+		Unit:          strings.TrimSpace(input.Unit),
+		ReferenceLow:  input.ReferenceLow,
+		ReferenceHigh: input.ReferenceHigh,
+		CriticalLow:   input.CriticalLow,
+		CriticalHigh:  input.CriticalHigh,
+	}
+	if item.ItemName == "" || len(item.Code) > 50 || len(item.Unit) > 20 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request"),
+		})
+		return
+	}
+	if item.ReferenceLow != nil && item.ReferenceHigh != nil && *item.ReferenceLow > *item.ReferenceHigh {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_reference_range"),
+		})
+		return
+	}
+	if item.CriticalLow != nil && item.CriticalHigh != nil && *item.CriticalLow > *item.CriticalHigh {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_critical_range"),
+		})
+		return
+	}
+	if ok, err := categoryDefined(item.Category); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	} else if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "catalog_category_not_found", item.Category),
+		})
+		return
+	}
+
+	var synonyms []models.HealthItemSynonym
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		index, err := utils.LoadCatalogIndex(tx)
+		if err != nil {
+			return err
+		}
+		if err := index.Check(0, append([]string{item.ItemName}, input.Synonyms...), item.Code); err != nil {
+			return err
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		if synonyms, err = utils.SetSynonyms(tx, item, input.Synonyms); err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditCatalogUpdate,
+			TargetType: "health_item",
+			TargetID:   item.ID,
+			After:      map[string]interface{}{"item_name": item.ItemName, "code": item.Code, "category": item.Category, "synonyms": input.Synonyms},
+		})
+	})
+	if errors.Is(err, utils.ErrCatalogNameTaken) || errors.Is(err, utils.ErrCatalogCodeTaken) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "catalog_name_taken") + err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "health_item_create_failed") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  i18n.T(ctx, "health_item_saved"),
+		"item":     item,
+		"synonyms": synonyms,
+	})
+}
+
+// SetItemSynonyms 管理员替换项目的全部同义名称
+func SetItemSynonyms(ctx *gin.Context) {
+	var item models.HealthItem
+	if err := global.DB.Scopes(utils.CatalogItems).First(&item, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "health_check_item_not_found"),
+		})
+		return
+	}
+	var input struct {
+		Synonyms []string `json:"synonyms"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+	for _, name := range input.Synonyms {
+		if len([]rune(name)) > 512 {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "invalid_request"),
+			})
+			return
+		}
+	}
+
+	var synonyms []models.HealthItemSynonym
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var before []string
+		if err := tx.Model(&models.HealthItemSynonym{}).Where("health_item_id = ?", item.ID).Order("id").
+			Pluck("name", &before).Error; err != nil {
+			return err
+		}
+		var err error
+		if synonyms, err = utils.SetSynonyms(tx, item, input.Synonyms); err != nil {
+			return err
+		}
+		after := make([]string, 0, len(synonyms))
+		for _, s := range synonyms {
+			after = append(after, s.Name)
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditCatalogUpdate,
+			TargetType: "health_item",
+			TargetID:   item.ID,
+			Before:     map[string]interface{}{"synonyms": before},
+			After:      map[string]interface{}{"synonyms": after},
+		})
+	})
+	if errors.Is(err, utils.ErrCatalogNameTaken) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "catalog_name_taken") + err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  i18n.T(ctx, "catalog_synonyms_saved"),
+		"synonyms": synonyms,
+	})
+}
+
+// GetDuplicateItems 管理员查看疑似重复的项目分组，供合并前核对
+func GetDuplicateItems(ctx *gin.Context) {
+	groups, err := utils.FindDuplicateItems(global.DB)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"groups": groups,
+	})
+}
+
+// MergeCatalogItems 管理员将重复项目合并到路径中的项目，全部引用迁移后删除被合并的项目
+func MergeCatalogItems(ctx *gin.Context) {
+	var target models.HealthItem
+	if err := global.DB.Scopes(utils.CatalogItems).First(&target, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "health_check_item_not_found"),
+		})
+		return
+	}
+	var input struct {
+		SourceIDs []uint `json:"source_ids" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+	ids := make([]uint, 0, len(input.SourceIDs))
+	seen := make(map[uint]bool)
+	for _, id := range input.SourceIDs {
+		if id == target.ID || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	var sources []models.HealthItem
+	if len(ids) > 0 {
+		if err := global.DB.Scopes(utils.CatalogItems).Where("id IN ?", ids).Order("id").Find(&sources).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
+		}
+	}
+	if len(sources) == 0 || len(sources) != len(ids) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "catalog_merge_invalid_sources"),
+		})
+		return
+	}
+
+	sourceNames := make([]string, 0, len(sources))
+	for _, s := range sources {
+		sourceNames = append(sourceNames, s.ItemName)
+	}
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := utils.MergeHealthItems(tx, target, sources); err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditCatalogMerge,
+			TargetType: "health_item",
+			TargetID:   target.ID,
+			Before:     map[string]interface{}{"source_ids": ids, "source_names": sourceNames},
+			After:      map[string]interface{}{"merged_into": target.ID},
+		})
+	})
+	var conflict *utils.MergeConflictError
+	switch {
+	case errors.As(err, &conflict):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":     i18n.T(ctx, "catalog_merge_conflict"),
+			"conflicts": gin.H{"user_packages": conflict.Results, "plan_versions": conflict.Versions, "conclusions": conflict.Comments},
+		})
+		return
+	case errors.Is(err, utils.ErrFormulaCycle), errors.Is(err, utils.ErrCatalogNameTaken):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "catalog_merge_failed") + err.Error(),
+		})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "catalog_merge_failed") + err.Error(),
+		})
+		return
+	}
+	utils.ReindexHealthItem(target.ID)
+
+	var synonyms []models.HealthItemSynonym
+	global.DB.Where("health_item_id = ?", target.ID).Order("id").Find(&synonyms)
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  i18n.T(ctx, "catalog_items_merged", len(sources)),
+		"item":     target,
+		"synonyms": synonyms,
+	})
+}
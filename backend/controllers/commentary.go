@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
 	"net/http"
 
@@ -9,36 +11,91 @@ import (
 )
 
 // 发布评论
+// 评论者为当前登录用户，只有已完成该套餐体检的用户可以评价，每次完成的套餐只能评价一次
 func AddCommentary(ctx *gin.Context) {
 
 	// 从请求中获取评论内容
 	var input struct {
-		RelationUserId uint   `json:"user_id"`
-		RelationPlanId uint   `json:"plan_id"`
-		Commentary     string `json:"commentary"`
+		RelationPlanId uint   `json:"plan_id" binding:"required"`
+		Rating         uint8  `json:"rating" binding:"required,min=1,max=5"`
+		Commentary     string `json:"commentary" binding:"max=255"`
 	}
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request data",
+			"error": i18n.T(ctx, "invalid_request"),
+		})
+		return
+	}
+
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	// 验证购买：查找该用户已完成、结果已发布且尚未评价的套餐
+	var completed int64
+	if err := global.DB.Model(&models.UserPackage{}).
+		Where("user_id = ? AND plan_id = ? AND status = 1 AND review_status = ?", user.ID, input.RelationPlanId, models.ReviewReleased).
+		Count(&completed).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	if completed == 0 {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "commentary_purchase_required"),
+		})
+		return
+	}
+	var userPackage models.UserPackage
+	if err := global.DB.
+		Where("user_id = ? AND plan_id = ? AND status = 1 AND review_status = ?", user.ID, input.RelationPlanId, models.ReviewReleased).
+		Where("id NOT IN (?)", global.DB.Model(&models.Commentary{}).Select("user_package_id").Where("user_package_id IS NOT NULL")).
+		Order("id").
+		First(&userPackage).Error; err != nil {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "commentary_already_reviewed"),
 		})
 		return
 	}
 
 	var commentary models.Commentary
-	commentary.RelationUserId = input.RelationUserId
+	commentary.RelationUserId = user.ID
 	commentary.RelationPlanId = input.RelationPlanId
 	commentary.Commentary = input.Commentary
+	commentary.Rating = input.Rating
+	commentary.UserPackageID = &userPackage.ID
 
-	// 添加记录到commentary表
-	if err := global.DB.Create(&commentary).Error; err != nil {
+	// 添加记录到commentary表，同时更新评分汇总
+	tx := global.DB.Begin()
+	if err := tx.Create(&commentary).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "commentary_add_failed"),
+		})
+		return
+	}
+	if err := utils.RefreshRatings(tx, commentary.RelationPlanId); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "rating_refresh_failed") + err.Error(),
+		})
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to add commentary",
+			"error": i18n.T(ctx, "commit_failed"),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Commentary added successfully",
+		"message":    i18n.T(ctx, "commentary_added"),
+		"commentary": commentary,
 	})
 }
 
@@ -48,7 +105,7 @@ func DeleteCommentary(ctx *gin.Context) {
 	var commentary models.Commentary
 	if err := global.DB.Where("id = ?", commentaryID).First(&commentary).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "Commentary not found",
+			"error": i18n.T(ctx, "commentary_not_found"),
 		})
 		return
 	}
@@ -58,7 +115,7 @@ func DeleteCommentary(ctx *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
+			"error": i18n.T(ctx, "user_not_found"),
 		})
 		return
 	}
@@ -66,51 +123,138 @@ func DeleteCommentary(ctx *gin.Context) {
 	// 只有评论的创建者或管理员可以删除评论
 	if commentary.RelationUserId != user.ID && user.UserType != 2 {
 		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "You do not have permission to delete this commentary",
+			"error": i18n.T(ctx, "commentary_delete_forbidden"),
 		})
 		return
 	}
 
-	if err := global.DB.Unscoped().Delete(&commentary).Error; err != nil {
+	tx := global.DB.Begin()
+	if err := tx.Unscoped().Delete(&commentary).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "commentary_delete_failed"),
+		})
+		return
+	}
+	if err := utils.RefreshRatings(tx, commentary.RelationPlanId); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "rating_refresh_failed") + err.Error(),
+		})
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete commentary",
+			"error": i18n.T(ctx, "commit_failed"),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Commentary deleted successfully",
+		"message": i18n.T(ctx, "commentary_deleted"),
 	})
 }
 
-// 查看评论(套餐id)
+// 查看评论(套餐id)，只返回未被隐藏的评论及套餐评分汇总
 func GetCommentaryByPlanID(ctx *gin.Context) {
 	planID := ctx.Param("id")
+	var plan models.Plan
+	if err := global.DB.Select("id, rating_avg, rating_count").First(&plan, planID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "plan_not_found"),
+		})
+		return
+	}
+
 	var commentaries []models.Commentary
-	if err := global.DB.Where("plan_id = ?", planID).Find(&commentaries).Error; err != nil {
+	if err := global.DB.Where("plan_id = ? AND status = ?", planID, models.CommentaryVisible).
+		Order("id DESC").Find(&commentaries).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve commentaries",
+			"error": i18n.T(ctx, "commentaries_fetch_failed"),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"commentaries": commentaries,
+		"rating":       plan.RatingAvg,
+		"rating_count": plan.RatingCount,
 	})
 }
 
 // 查看评论(所有) oy
+// 支持分页排序，按 plan_id、user_id、rating 及 date_from/date_to（发布时间）筛选
+// 被隐藏的评论仅管理员可见
 func GetCommentaryList(ctx *gin.Context) {
+	page, err := utils.ParsePagination(ctx, map[string]string{
+		"id":           "id",
+		"created_at":   "created_at",
+		"rating":       "rating",
+		"report_count": "report_count",
+	}, "-id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	planID, err := utils.ParseUintQuery(ctx, "plan_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_plan_id"),
+		})
+		return
+	}
+	userID, err := utils.ParseUintQuery(ctx, "user_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_user_id"),
+		})
+		return
+	}
+	dateFrom, dateTo, err := utils.ParseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	query := global.DB.Model(&models.Commentary{})
+	if planID != nil {
+		query = query.Where("plan_id = ?", *planID)
+	}
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	rating, err := utils.ParseUintQuery(ctx, "rating")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+	if rating != nil {
+		query = query.Where("rating = ?", *rating)
+	}
+	if userType, _ := ctx.Get("user_type"); userType != uint8(2) {
+		query = query.Where("status = ?", models.CommentaryVisible)
+	}
+	query = utils.WhereRange(query, "created_at", dateFrom, dateTo)
+
 	var commentaries []models.Commentary
-	if err := global.DB.Find(&commentaries).Error; err != nil {
+	pageInfo, err := utils.Paginate(query, page, &commentaries)
+	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve commentaries",
+			"error": i18n.T(ctx, "commentaries_fetch_failed"),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"commentaries": commentaries,
+		"pagination":   pageInfo,
 	})
 }
 
@@ -120,7 +264,7 @@ func GetCommentaryByUserID(ctx *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
+			"error": i18n.T(ctx, "user_not_found"),
 		})
 		return
 	}
@@ -128,7 +272,7 @@ func GetCommentaryByUserID(ctx *gin.Context) {
 	var commentaries []models.Commentary
 	if err := global.DB.Where("user_id = ?", user.ID).Find(&commentaries).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve commentaries",
+			"error": i18n.T(ctx, "commentaries_fetch_failed"),
 		})
 		return
 	}
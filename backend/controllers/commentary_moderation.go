@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ReplyCommentary 机构回复评论，仅评论套餐所属机构的主账号、成员或管理员可以回复，重复回复会覆盖之前的内容
+func ReplyCommentary(ctx *gin.Context) {
+	var input struct {
+		Reply string `json:"reply" binding:"required,max=500"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request"),
+		})
+		return
+	}
+
+	var commentary models.Commentary
+	if err := global.DB.Preload("ThisPlan").First(&commentary, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "commentary_not_found"),
+		})
+		return
+	}
+
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+	staff, err := utils.InstitutionStaff(global.DB, commentary.ThisPlan.RelationInstitutionID, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	if !staff {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "commentary_reply_forbidden"),
+		})
+		return
+	}
+
+	now := time.Now()
+	if err := global.DB.Model(&commentary).Updates(map[string]interface{}{
+		"institution_reply": input.Reply,
+		"replied_at":        &now,
+	}).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "commentary_replied"),
+	})
+}
+
+// ReportCommentary 举报评论，同一用户对同一评论只能举报一次
+func ReportCommentary(ctx *gin.Context) {
+	var input struct {
+		Reason string `json:"reason" binding:"required,max=255"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request"),
+		})
+		return
+	}
+
+	var commentary models.Commentary
+	if err := global.DB.Where("status = ?", models.CommentaryVisible).First(&commentary, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "commentary_not_found"),
+		})
+		return
+	}
+
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	var existing models.CommentaryReport
+	err := global.DB.Where("commentary_id = ? AND reporter_id = ?", commentary.ID, user.ID).First(&existing).Error
+	if err == nil {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "commentary_already_reported"),
+		})
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+
+	report := models.CommentaryReport{
+		CommentaryID: commentary.ID,
+		ReporterID:   user.ID,
+		Reason:       input.Reason,
+	}
+	tx := global.DB.Begin()
+	if err := tx.Create(&report).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "commentary_report_failed") + err.Error(),
+		})
+		return
+	}
+	if err := tx.Model(&commentary).UpdateColumn("report_count", gorm.Expr("report_count + 1")).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "commentary_report_failed") + err.Error(),
+		})
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "commit_failed"),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "commentary_reported"),
+	})
+}
+
+// GetModerationQueue 管理员审核队列
+// 查询参数 status: reported（默认，有待处理举报的可见评论）或 hidden（已隐藏的评论），支持分页排序
+func GetModerationQueue(ctx *gin.Context) {
+	page, err := utils.ParsePagination(ctx, map[string]string{
+		"id":           "id",
+		"created_at":   "created_at",
+		"report_count": "report_count",
+	}, "-report_count")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	query := global.DB.Model(&models.Commentary{})
+	switch ctx.DefaultQuery("status", "reported") {
+	case "reported":
+		query = query.Where("status = ?", models.CommentaryVisible).
+			Where("EXISTS (?)", global.DB.Model(&models.CommentaryReport{}).
+				Select("1").
+				Where("commentary_reports.commentary_id = commentaries.id AND commentary_reports.status = ?", models.ReportPending))
+	case "hidden":
+		query = query.Where("status = ?", models.CommentaryHidden)
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_moderation_status"),
+		})
+		return
+	}
+
+	var commentaries []models.Commentary
+	pageInfo, err := utils.Paginate(query, page, &commentaries)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "commentaries_fetch_failed"),
+		})
+		return
+	}
+
+	// 附带各评论的举报记录
+	ids := make([]uint, 0, len(commentaries))
+	for _, c := range commentaries {
+		ids = append(ids, c.ID)
+	}
+	var reports []models.CommentaryReport
+	if len(ids) > 0 {
+		if err := global.DB.Where("commentary_id IN ?", ids).Order("id DESC").Find(&reports).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "commentaries_fetch_failed"),
+			})
+			return
+		}
+	}
+	byCommentary := make(map[uint][]models.CommentaryReport)
+	for _, r := range reports {
+		byCommentary[r.CommentaryID] = append(byCommentary[r.CommentaryID], r)
+	}
+	for i := range commentaries {
+		commentaries[i].Reports = byCommentary[commentaries[i].ID]
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"commentaries": commentaries,
+		"pagination":   pageInfo,
+	})
+}
+
+// HideCommentary 管理员隐藏评论，待处理的举报标记为已处理，评论不再计入评分
+func HideCommentary(ctx *gin.Context) {
+	var input struct {
+		Reason string `json:"reason" binding:"max=255"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request"),
+		})
+		return
+	}
+	moderateCommentary(ctx, models.CommentaryHidden, input.Reason, models.ReportResolved, "commentary_hidden")
+}
+
+// RestoreCommentary 管理员恢复被隐藏的评论，或保留被举报的评论，待处理的举报标记为已驳回
+func RestoreCommentary(ctx *gin.Context) {
+	moderateCommentary(ctx, models.CommentaryVisible, "", models.ReportDismissed, "commentary_restored")
+}
+
+func moderateCommentary(ctx *gin.Context, status uint8, reason string, reportStatus uint8, messageKey string) {
+	var commentary models.Commentary
+	if err := global.DB.First(&commentary, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "commentary_not_found"),
+		})
+		return
+	}
+
+	tx := global.DB.Begin()
+	if err := tx.Model(&commentary).Updates(map[string]interface{}{
+		"status":        status,
+		"hidden_reason": reason,
+	}).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	if err := tx.Model(&models.CommentaryReport{}).
+		Where("commentary_id = ? AND status = ?", commentary.ID, models.ReportPending).
+		Update("status", reportStatus).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	if err := utils.RefreshRatings(tx, commentary.RelationPlanId); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "rating_refresh_failed") + err.Error(),
+		})
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "commit_failed"),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, messageKey),
+	})
+}
@@ -0,0 +1,348 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 结论各字段的最大长度（字符）
+const (
+	maxConclusionLength = 5000
+	maxItemCommentSize  = 1000
+)
+
+// GetConclusion 查看用户套餐的体检结论
+// 客户本人及获得 view_records 授权的家人只能看到最新签署的版本；套餐所属机构与管理员还能看到草稿
+func GetConclusion(ctx *gin.Context) {
+	_, pkg, writer, ok := conclusionPackage(ctx, false)
+	if !ok {
+		return
+	}
+	latest, err := utils.LatestConclusion(global.DB, pkg.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	if !writer && pkg.ReviewStatus != models.ReviewReleased {
+		// 结果发布前客户看不到结论
+		latest = nil
+	}
+	response := gin.H{
+		"user_package_id": pkg.ID,
+		"conclusion":      latest,
+	}
+	if writer {
+		draft, err := loadConclusion(pkg.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
+		}
+		response["draft"] = draft
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetConclusionRevisions 查看结论的全部签署版本，按修订号排列
+func GetConclusionRevisions(ctx *gin.Context) {
+	_, pkg, writer, ok := conclusionPackage(ctx, false)
+	if !ok {
+		return
+	}
+	revisions := []models.ConclusionRevision{}
+	if !writer && pkg.ReviewStatus != models.ReviewReleased {
+		ctx.JSON(http.StatusOK, gin.H{
+			"revisions": revisions,
+		})
+		return
+	}
+	if err := global.DB.Where("user_package_id = ?", pkg.ID).Order("revision").Find(&revisions).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"revisions": revisions,
+	})
+}
+
+// SaveConclusion 机构医生保存结论草稿，项目点评整体替换，草稿版本号加一；已签署的结论修改后回到草稿，需重新签署
+func SaveConclusion(ctx *gin.Context) {
+	user, pkg, _, ok := conclusionPackage(ctx, true)
+	if !ok {
+		return
+	}
+	var input struct {
+		Summary        string `json:"summary"`
+		Recommendation string `json:"recommendation"`
+		Comments       []struct {
+			HealthItemID uint   `json:"health_item_id" binding:"required"`
+			Comment      string `json:"comment"`
+		} `json:"comments"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+	input.Summary = strings.TrimSpace(input.Summary)
+	input.Recommendation = strings.TrimSpace(input.Recommendation)
+	if len([]rune(input.Summary)) > maxConclusionLength || len([]rune(input.Recommendation)) > maxConclusionLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "conclusion_too_long", maxConclusionLength),
+		})
+		return
+	}
+
+	_, contents, err := utils.PackageContents(global.DB, pkg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "plan_items_retrieve_failed"),
+		})
+		return
+	}
+	inPackage := make(map[uint]bool, len(contents))
+	for _, c := range contents {
+		inPackage[c.HealthItemID] = true
+	}
+	comments := make([]models.ConclusionComment, 0, len(input.Comments))
+	seen := make(map[uint]bool)
+	for _, c := range input.Comments {
+		text := strings.TrimSpace(c.Comment)
+		switch {
+		case !inPackage[c.HealthItemID]:
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "conclusion_item_not_in_package", c.HealthItemID),
+			})
+			return
+		case seen[c.HealthItemID]:
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "conclusion_item_duplicate", c.HealthItemID),
+			})
+			return
+		case len([]rune(text)) > maxItemCommentSize:
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "conclusion_too_long", maxItemCommentSize),
+			})
+			return
+		case text == "":
+			continue
+		}
+		seen[c.HealthItemID] = true
+		comments = append(comments, models.ConclusionComment{HealthItemID: c.HealthItemID, Comment: text})
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		var conclusion models.CheckupConclusion
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_package_id = ?", pkg.ID).First(&conclusion).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		conclusion.UserPackageID = pkg.ID
+		conclusion.Status = models.ConclusionDraft
+		conclusion.Summary = input.Summary
+		conclusion.Recommendation = input.Recommendation
+		conclusion.UpdatedBy = user.ID
+		conclusion.Version++
+		if err := tx.Omit("Comments").Save(&conclusion).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("conclusion_id = ?", conclusion.ID).Delete(&models.ConclusionComment{}).Error; err != nil {
+			return err
+		}
+		for i := range comments {
+			comments[i].ConclusionID = conclusion.ID
+		}
+		if len(comments) > 0 {
+			return tx.Create(&comments).Error
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "conclusion_save_failed") + err.Error(),
+		})
+		return
+	}
+	draft, err := loadConclusion(pkg.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "conclusion_saved"),
+		"draft":   draft,
+	})
+}
+
+// SignConclusion 机构医生签署结论草稿，生成新的修订版本后客户可见；修订已签署的结论时须填写原因。
+// 请求须带上所看到的草稿版本号，签署时在事务中锁定并重新读取草稿，版本已变化时拒绝签署
+func SignConclusion(ctx *gin.Context) {
+	user, pkg, _, ok := conclusionPackage(ctx, true)
+	if !ok {
+		return
+	}
+	var input struct {
+		Version uint   `json:"version" binding:"required"`
+		Reason  string `json:"reason" binding:"max=255"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if pkg.Status != 1 {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "conclusion_package_not_completed"),
+		})
+		return
+	}
+
+	var revision models.ConclusionRevision
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var conclusion models.CheckupConclusion
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Comments", func(db *gorm.DB) *gorm.DB { return db.Order("health_item_id") }).
+			Where("user_package_id = ?", pkg.ID).First(&conclusion).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return errConclusionNotFound
+		case err != nil:
+			return err
+		case conclusion.Version != input.Version:
+			return errConclusionChanged
+		case conclusion.Status == models.ConclusionFinal:
+			return errConclusionSigned
+		case conclusion.Summary == "":
+			return errConclusionIncomplete
+		case conclusion.Revision > 0 && input.Reason == "":
+			return errConclusionReason
+		}
+		if revision, err = utils.SignConclusion(tx, &conclusion, user, input.Reason); err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditConclusionSign,
+			TargetType: "user_package",
+			TargetID:   pkg.ID,
+			After: map[string]interface{}{
+				"revision":     revision.Revision,
+				"version":      conclusion.Version,
+				"signed_by":    revision.SignedBy,
+				"reason":       revision.Reason,
+				"content_hash": revision.ContentHash,
+			},
+		})
+	})
+	if err != nil {
+		if resp, ok := conclusionSignErrors[err]; ok {
+			ctx.JSON(resp.status, gin.H{
+				"error": i18n.T(ctx, resp.key),
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "conclusion_sign_failed") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":    i18n.T(ctx, "conclusion_signed", revision.Revision),
+		"conclusion": revision,
+	})
+}
+
+var (
+	errConclusionNotFound   = errors.New("conclusion not found")
+	errConclusionChanged    = errors.New("conclusion changed since it was read")
+	errConclusionSigned     = errors.New("conclusion already signed")
+	errConclusionIncomplete = errors.New("conclusion incomplete")
+	errConclusionReason     = errors.New("amendment reason required")
+)
+
+// conclusionSignErrors 签署前校验失败时的响应状态码与提示
+var conclusionSignErrors = map[error]struct {
+	status int
+	key    string
+}{
+	errConclusionNotFound:   {http.StatusNotFound, "conclusion_not_found"},
+	errConclusionChanged:    {http.StatusConflict, "conclusion_changed"},
+	errConclusionSigned:     {http.StatusConflict, "conclusion_already_signed"},
+	errConclusionIncomplete: {http.StatusBadRequest, "conclusion_incomplete"},
+	errConclusionReason:     {http.StatusBadRequest, "conclusion_reason_required"},
+}
+
+// conclusionPackage 查找路径中的用户套餐并检查权限：write 为 true 时只允许可以填写结论的账号，
+// 否则还允许客户本人及获得 view_records 授权的家人；writer 表示能否填写结论
+func conclusionPackage(ctx *gin.Context, write bool) (user models.User, pkg models.UserPackage, writer bool, ok bool) {
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return user, pkg, false, false
+	}
+	if err := global.DB.First(&pkg, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "package_not_found"),
+		})
+		return user, pkg, false, false
+	}
+	role := ""
+	if user.UserType == 3 {
+		var err error
+		if role, err = utils.InstitutionRole(global.DB, pkg.InstitutionID, user.ID); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return user, pkg, false, false
+		}
+	}
+	if user.UserType == 2 || utils.CanWriteConclusion(role) {
+		return user, pkg, true, true
+	}
+	if write {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "conclusion_forbidden"),
+		})
+		return user, pkg, false, false
+	}
+	if pkg.UserID != user.ID {
+		if err := utils.AuthorizeFamilyAccess(ctx, pkg.UserID, user.ID, models.ScopeViewRecords); err != nil {
+			respondFamilyAccessError(ctx, err)
+			return user, pkg, false, false
+		}
+	}
+	return user, pkg, false, true
+}
+
+// loadConclusion 读取用户套餐的结论（含项目点评），尚未填写时返回 nil
+func loadConclusion(packageID uint) (*models.CheckupConclusion, error) {
+	var conclusion models.CheckupConclusion
+	err := global.DB.Preload("Comments", func(db *gorm.DB) *gorm.DB { return db.Order("health_item_id") }).
+		Where("user_package_id = ?", packageID).First(&conclusion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &conclusion, nil
+}
@@ -0,0 +1,419 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 认领码有效期
+const dependentClaimValidity = 7 * 24 * time.Hour
+
+// dependentView 被监护人档案
+type dependentView struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Gender       string `json:"gender"`
+	Birthday     string `json:"birthday"`
+	Phone        string `json:"phone"`
+	Address      string `json:"address"`
+	FamilyID     uint   `json:"family_id"`
+	Relationship string `json:"relationship"`
+}
+
+// CreateDependent 监护人创建被监护人档案（儿童、老人等没有自己账号的家人）
+// 档案是一个不能登录的普通用户，通过已确认的 Family 关系与监护人关联，监护人可代为预约和查看记录
+func CreateDependent(ctx *gin.Context) {
+	var input struct {
+		Name         string `json:"name" binding:"required,max=50"`
+		Gender       string `json:"gender" binding:"required,oneof=M F"`
+		Birthday     string `json:"birthday" binding:"required,datetime=2006-01-02"`
+		Phone        string `json:"phone" binding:"max=11"` // 为空时使用监护人的手机号
+		Address      string `json:"address" binding:"max=255"`
+		Relationship string `json:"relationship" binding:"required,max=50"` // 被监护人相对监护人的称谓，如 "儿子"、"母亲"
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+
+	guardian, ok := currentGuardian(ctx)
+	if !ok {
+		return
+	}
+	if err := utils.ValidateRelationship(input.Relationship, guardian.Gender, input.Gender); err != nil {
+		respondRelationshipError(ctx, input.Relationship, err)
+		return
+	}
+
+	username, err := utils.RandomToken(8)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "dependent_create_failed") + err.Error(),
+		})
+		return
+	}
+	secret, err := utils.RandomToken(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "dependent_create_failed") + err.Error(),
+		})
+		return
+	}
+	password, err := utils.HashPassword(secret)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "dependent_create_failed") + err.Error(),
+		})
+		return
+	}
+
+	dependent := models.User{
+		Username:   "dependent-" + username,
+		Password:   password,
+		Name:       input.Name,
+		Gender:     input.Gender,
+		Birthday:   input.Birthday,
+		Phone:      input.Phone,
+		Address:    input.Address,
+		UserType:   1,
+		GuardianID: &guardian.ID,
+	}
+	if dependent.Phone == "" {
+		dependent.Phone = guardian.Phone
+	}
+
+	var family models.Family
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&dependent).Error; err != nil {
+			return err
+		}
+		family = models.Family{
+			UserID:       guardian.ID,
+			RelativeID:   dependent.ID,
+			Relationship: input.Relationship,
+			Status:       1,
+		}
+		return tx.Create(&family).Error
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "dependent_create_failed") + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   i18n.T(ctx, "dependent_created"),
+		"dependent": newDependentView(dependent, family),
+	})
+}
+
+// GetDependents 获取当前用户监护的被监护人档案
+func GetDependents(ctx *gin.Context) {
+	guardian, ok := currentGuardian(ctx)
+	if !ok {
+		return
+	}
+
+	var dependents []models.User
+	if err := global.DB.Where("guardian_id = ?", guardian.ID).Order("id").Find(&dependents).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "dependents_fetch_failed") + err.Error(),
+		})
+		return
+	}
+
+	var families []models.Family
+	if err := global.DB.Where("user_id = ? AND relative_id IN (?)", guardian.ID,
+		global.DB.Model(&models.User{}).Select("id").Where("guardian_id = ?", guardian.ID)).
+		Find(&families).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "dependents_fetch_failed") + err.Error(),
+		})
+		return
+	}
+	familyByDependent := make(map[uint]models.Family, len(families))
+	for _, f := range families {
+		familyByDependent[f.RelativeID] = f
+	}
+
+	result := make([]dependentView, 0, len(dependents))
+	for _, d := range dependents {
+		result = append(result, newDependentView(d, familyByDependent[d.ID]))
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"dependents": result,
+	})
+}
+
+// UpdateDependent 监护人修改被监护人档案
+func UpdateDependent(ctx *gin.Context) {
+	var input struct {
+		Name     *string `json:"name" binding:"omitempty,min=1,max=50"`
+		Gender   *string `json:"gender" binding:"omitempty,oneof=M F"`
+		Birthday *string `json:"birthday" binding:"omitempty,datetime=2006-01-02"`
+		Phone    *string `json:"phone" binding:"omitempty,max=11"`
+		Address  *string `json:"address" binding:"omitempty,max=255"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+
+	dependent, ok := guardedDependent(ctx)
+	if !ok {
+		return
+	}
+
+	updates := make(map[string]interface{})
+	for column, value := range map[string]*string{
+		"name":     input.Name,
+		"gender":   input.Gender,
+		"birthday": input.Birthday,
+		"phone":    input.Phone,
+		"address":  input.Address,
+	} {
+		if value != nil {
+			updates[column] = *value
+		}
+	}
+	if len(updates) > 0 {
+		audit := utils.ProfileAudit(dependent, updates)
+		if err := models.SealUserFields(updates); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "dependent_update_failed") + err.Error(),
+			})
+			return
+		}
+		err := global.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&dependent).Updates(updates).Error; err != nil {
+				return err
+			}
+			return utils.WriteAudit(ctx, tx, audit)
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "dependent_update_failed") + err.Error(),
+			})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "dependent_updated"),
+	})
+}
+
+// CreateDependentClaimCode 监护人为被监护人生成认领码，之前生成的认领码失效
+// 认领码只在此时返回一次，由监护人交给被监护人用于认领账号
+func CreateDependentClaimCode(ctx *gin.Context) {
+	dependent, ok := guardedDependent(ctx)
+	if !ok {
+		return
+	}
+
+	code, hash, err := utils.NewClaimCode()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "claim_code_create_failed") + err.Error(),
+		})
+		return
+	}
+	claim := models.DependentClaim{
+		UserID:    dependent.ID,
+		CodeHash:  hash,
+		ExpiresAt: time.Now().Add(dependentClaimValidity),
+	}
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", dependent.ID).Delete(&models.DependentClaim{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&claim).Error
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "claim_code_create_failed") + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"code":       code,
+		"expires_at": claim.ExpiresAt,
+	})
+}
+
+// ClaimDependentAccount 被监护人使用认领码将托管档案转换为可登录的账号
+// 档案ID不变，原有套餐、体检记录与家庭关系全部保留；监护人的代理权限改为普通授权，由本人决定是否保留
+func ClaimDependentAccount(ctx *gin.Context) {
+	var input struct {
+		Code     string `json:"code" binding:"required"`
+		Username string `json:"username" binding:"required,max=50"`
+		Password string `json:"password" binding:"required,min=6"`
+		Email    string `json:"email" binding:"omitempty,email,max=100"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+
+	var claim models.DependentClaim
+	if err := global.DB.Where("code_hash = ? AND expires_at > ?", utils.HashClaimCode(input.Code), time.Now()).
+		First(&claim).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "claim_code_invalid"),
+		})
+		return
+	}
+
+	var dependent models.User
+	if err := global.DB.Where("id = ? AND guardian_id IS NOT NULL", claim.UserID).First(&dependent).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "claim_code_invalid"),
+		})
+		return
+	}
+
+	exists, err := utils.CheckExists(&models.User{}, "username", input.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	if exists {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "username_taken"),
+		})
+		return
+	}
+
+	password, err := utils.HashPassword(input.Password)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	guardianID := *dependent.GuardianID
+	updates := map[string]interface{}{
+		"username":    input.Username,
+		"password":    password,
+		"guardian_id": nil,
+	}
+	if input.Email != "" {
+		updates["email"] = input.Email
+	}
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&dependent).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&claim).Error; err != nil {
+			return err
+		}
+		// 认领前监护人凭监护关系拥有全部权限，认领后转为可由本人撤销的授权
+		var family models.Family
+		if err := tx.Where("user_id = ? AND relative_id = ? AND status = 1", guardianID, dependent.ID).
+			First(&family).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		for _, scope := range models.FamilyScopes {
+			grant := models.FamilyGrant{FamilyID: family.ID, OwnerID: dependent.ID, GranteeID: guardianID, Scope: scope}
+			if err := tx.Where(models.FamilyGrant{OwnerID: dependent.ID, GranteeID: guardianID, Scope: scope}).
+				FirstOrCreate(&grant).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "claim_failed") + err.Error(),
+		})
+		return
+	}
+
+	token, err := utils.GenerateJWT(input.Username, dependent.UserType)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   i18n.T(ctx, "dependent_claimed"),
+		"token":     token,
+		"uid":       dependent.ID,
+		"user_type": dependent.UserType,
+	})
+}
+
+// currentGuardian 返回当前登录用户，被监护人档案不能再监护他人
+func currentGuardian(ctx *gin.Context) (models.User, bool) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return user, false
+	}
+	if user.GuardianID != nil || user.UserType != 1 {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "guardian_required"),
+		})
+		return user, false
+	}
+	return user, true
+}
+
+// guardedDependent 返回路径参数 id 指定的、由当前用户监护的被监护人档案
+func guardedDependent(ctx *gin.Context) (models.User, bool) {
+	var dependent models.User
+	guardian, ok := currentGuardian(ctx)
+	if !ok {
+		return dependent, false
+	}
+	if err := global.DB.Where("id = ? AND guardian_id = ?", ctx.Param("id"), guardian.ID).First(&dependent).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "dependent_not_found"),
+		})
+		return dependent, false
+	}
+	return dependent, true
+}
+
+func newDependentView(user models.User, family models.Family) dependentView {
+	birthday := user.Birthday
+	if len(birthday) > 10 {
+		birthday = birthday[:10]
+	}
+	return dependentView{
+		ID:           user.ID,
+		Name:         user.Name,
+		Gender:       user.Gender,
+		Birthday:     birthday,
+		Phone:        user.Phone,
+		Address:      user.Address,
+		FamilyID:     family.ID,
+		Relationship: family.Relationship,
+	}
+}
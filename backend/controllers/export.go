@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateExport 发起个人数据导出，归档在后台生成；同一用户同时只能有一个进行中的导出任务
+func CreateExport(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	var running int64
+	if err := global.DB.Model(&models.ExportJob{}).
+		Where("user_id = ? AND status IN ?", user.ID, []uint8{models.ExportPending, models.ExportRunning}).
+		Count(&running).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "export_create_failed") + err.Error(),
+		})
+		return
+	}
+	if running > 0 {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "export_in_progress"),
+		})
+		return
+	}
+
+	job := models.ExportJob{
+		RelationUserId: user.ID,
+		Status:         models.ExportPending,
+		Language:       i18n.Lang(ctx),
+	}
+	if err := global.DB.Create(&job).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "export_create_failed") + err.Error(),
+		})
+		return
+	}
+	go utils.RunExportJob(job.ID)
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message": i18n.T(ctx, "export_started"),
+		"export":  job,
+	})
+}
+
+// GetExports 获取当前用户的导出任务
+func GetExports(ctx *gin.Context) {
+	var jobs []models.ExportJob
+	if err := global.DB.Joins("JOIN users ON users.id = export_jobs.user_id").
+		Where("users.username = ?", ctx.GetString("username")).
+		Order("export_jobs.id DESC").
+		Find(&jobs).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "exports_fetch_failed") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"exports": jobs,
+	})
+}
+
+// GetExport 查询导出任务状态
+func GetExport(ctx *gin.Context) {
+	job, ok := ownExportJob(ctx)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, job)
+}
+
+// DownloadExport 下载已生成的导出归档
+func DownloadExport(ctx *gin.Context) {
+	job, ok := ownExportJob(ctx)
+	if !ok {
+		return
+	}
+	if job.Status == models.ExportExpired ||
+		(job.Status == models.ExportReady && job.ExpiresAt != nil && job.ExpiresAt.Before(time.Now())) {
+		ctx.JSON(http.StatusGone, gin.H{
+			"error": i18n.T(ctx, "export_expired"),
+		})
+		return
+	}
+	if job.Status != models.ExportReady {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "export_not_ready"),
+		})
+		return
+	}
+
+	path := utils.StoragePath(job.Path)
+	if _, err := os.Stat(path); err != nil {
+		ctx.JSON(http.StatusGone, gin.H{
+			"error": i18n.T(ctx, "export_expired"),
+		})
+		return
+	}
+	ctx.FileAttachment(path, fmt.Sprintf("healthcare-export-%s.zip", job.CreatedAt.Format("20060102-150405")))
+}
+
+// ownExportJob 按路径参数 id 查找当前用户的导出任务，其他用户的任务按不存在处理
+func ownExportJob(ctx *gin.Context) (models.ExportJob, bool) {
+	var job models.ExportJob
+	id, err := utils.UnmarshalUint(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_export_id"),
+		})
+		return job, false
+	}
+	err = global.DB.Joins("JOIN users ON users.id = export_jobs.user_id").
+		Where("export_jobs.id = ? AND users.username = ?", id, ctx.GetString("username")).
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "export_not_found"),
+		})
+		return job, false
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "exports_fetch_failed") + err.Error(),
+		})
+		return job, false
+	}
+	return job, true
+}
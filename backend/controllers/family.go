@@ -3,6 +3,7 @@ package controllers
 import (
 	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
 	"errors"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"gorm.io/gorm"
 )
 
+// CreatFamily 发起家庭关系请求，relationship 为对方相对发起人的称谓，须在关系图中定义且与双方性别相符
 func CreatFamily(ctx *gin.Context) {
 	var input struct {
 		RelativeUsername string `json:"relative_username"`
@@ -33,6 +35,14 @@ func CreatFamily(ctx *gin.Context) {
 		return
 	}
 
+	var thisUser models.User
+	if err := global.DB.First(&thisUser, thisUserID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
 	var relative models.User
 	if err := global.DB.Where("username = ?", input.RelativeUsername).First(&relative).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -41,17 +51,27 @@ func CreatFamily(ctx *gin.Context) {
 			})
 		} else {
 			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid Credentials",
+				"error": i18n.T(ctx, "invalid_credentials"),
 			})
 		}
 		return
 	}
 
+	if err := utils.ValidateRelationship(input.Relationship, thisUser.Gender, relative.Gender); err != nil {
+		respondRelationshipError(ctx, input.Relationship, err)
+		return
+	}
+
 	if err := utils.CreateFamilyRequest(thisUserID, relative.ID, relative.Email, input.Relationship); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
+		return
 	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "family_request_sent"),
+	})
 }
 
 // GetPendingFamilyRequests 获取待处理的家庭关系请求
@@ -65,7 +85,7 @@ func GetPendingFamilyRequests(ctx *gin.Context) {
 
 	var requests []models.Family
 	if err := global.DB.Preload("ThisUser").Where("relative_id = ? AND status = 0", thisUserID).Find(&requests).Error; err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pending requests"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "family_pending_fetch_failed")})
 		return
 	}
 
@@ -111,9 +131,9 @@ func HandleFamilyRequest(ctx *gin.Context) {
 	var request models.Family
 	if err := global.DB.Where("id = ? AND relative_id = ? AND status = 0", reqID, thisUserID).First(&request).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
+			ctx.JSON(http.StatusNotFound, gin.H{"error": i18n.T(ctx, "family_request_not_found")})
 		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch request"})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "family_request_fetch_failed")})
 		}
 		return
 	}
@@ -121,16 +141,16 @@ func HandleFamilyRequest(ctx *gin.Context) {
 	if input.Accept {
 		request.Status = 1
 		if err := global.DB.Save(&request).Error; err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update request"})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "family_request_update_failed")})
 			return
 		}
-		ctx.JSON(http.StatusOK, gin.H{"message": "Request accepted successfully"})
+		ctx.JSON(http.StatusOK, gin.H{"message": i18n.T(ctx, "family_request_accepted")})
 	} else {
 		if err := global.DB.Delete(&request).Error; err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete request"})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "family_request_delete_failed")})
 			return
 		}
-		ctx.JSON(http.StatusOK, gin.H{"message": "Request rejected successfully"})
+		ctx.JSON(http.StatusOK, gin.H{"message": i18n.T(ctx, "family_request_rejected")})
 	}
 }
 
@@ -147,14 +167,13 @@ func GetConfirmedFamilyMembers(ctx *gin.Context) {
 	if err := global.DB.Preload("ThisUser").Preload("Relative").
 		Where("(user_id = ? OR relative_id = ?) AND status = 1", thisUserID, thisUserID).
 		Find(&relationships).Error; err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch family relationships"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "family_fetch_failed")})
 		return
 	}
 
 	var response []gin.H
 	for _, rel := range relationships {
 		var member gin.H
-		var relationship string
 		if rel.UserID == thisUserID {
 			member = gin.H{
 				"username": rel.Relative.Username,
@@ -163,7 +182,6 @@ func GetConfirmedFamilyMembers(ctx *gin.Context) {
 				"rid":      rel.ID,
 				"name":     rel.Relative.Name,
 			}
-			relationship = rel.Relationship
 		} else {
 			member = gin.H{
 				"username": rel.ThisUser.Username,
@@ -172,7 +190,6 @@ func GetConfirmedFamilyMembers(ctx *gin.Context) {
 				"rid":      rel.ID,
 				"name":     rel.ThisUser.Name,
 			}
-			relationship = reverseRelationship(rel.Relationship)
 		}
 		response = append(response, gin.H{
 			"username":     member["username"],
@@ -180,31 +197,13 @@ func GetConfirmedFamilyMembers(ctx *gin.Context) {
 			"user_id":      member["user_id"],
 			"id":           member["id"],
 			"rid":          member["rid"],
-			"relationship": relationship,
+			"relationship": utils.RelationshipFrom(rel, thisUserID),
 		})
 	}
 
 	ctx.JSON(http.StatusOK, response)
 }
 
-// reverseRelationship 反转关系类型
-func reverseRelationship(relationship string) string {
-	switch relationship {
-	case "父亲":
-		return "儿子"
-	case "母亲":
-		return "儿子"
-	case "儿子":
-		return "父亲" // 这里可能需要根据性别判断是父亲还是母亲
-	case "女儿":
-		return "父亲" // 这里可能需要根据性别判断是父亲还是母亲
-	case "配偶":
-		return "配偶"
-	default:
-		return relationship
-	}
-}
-
 // 删除授权状态 oy
 func DelFamilyStatus(ctx *gin.Context) {
 	rid := ctx.Param("id")
@@ -213,13 +212,31 @@ func DelFamilyStatus(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// 监护人与被监护人档案之间的关系不能删除
+	var managed int64
+	if err := global.DB.Model(&models.Family{}).
+		Joins("JOIN users ON users.id = families.relative_id AND users.guardian_id = families.user_id").
+		Where("families.id = ?", id).
+		Count(&managed).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	if managed > 0 {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "dependent_family_link_required"),
+		})
+		return
+	}
+
 	// 开启事务
 	tx := global.DB.Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "服务器内部错误",
+				"error": i18n.T(ctx, "internal_error"),
 			})
 		}
 	}()
@@ -229,7 +246,7 @@ func DelFamilyStatus(ctx *gin.Context) {
 	if result.Error != nil {
 		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "删除授权状态失败: " + result.Error.Error(),
+			"error": i18n.T(ctx, "family_status_delete_failed") + result.Error.Error(),
 		})
 		return
 	}
@@ -238,7 +255,7 @@ func DelFamilyStatus(ctx *gin.Context) {
 	if result.RowsAffected == 0 {
 		tx.Rollback()
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "未找到对应的授权记录",
+			"error": i18n.T(ctx, "family_status_not_found"),
 		})
 		return
 	}
@@ -246,17 +263,18 @@ func DelFamilyStatus(ctx *gin.Context) {
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "提交事务失败: " + err.Error(),
+			"error": i18n.T(ctx, "commit_failed") + err.Error(),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "删除授权状态成功",
+		"message": i18n.T(ctx, "family_status_deleted"),
 	})
 }
 
 // 修改亲友关系名字 oy
+// relationship 为对方相对当前用户的称谓；当前用户是关系中的 relative 一方时按自己的性别反转后保存
 func UpdateFamilyName(ctx *gin.Context) {
 	var input struct {
 		ID           uint   `json:"id" binding:"required"`
@@ -266,38 +284,61 @@ func UpdateFamilyName(ctx *gin.Context) {
 	// 绑定输入参数
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的请求数据: " + err.Error(),
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
 		})
 		return
 	}
+
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	var family models.Family
+	if err := global.DB.Preload("ThisUser").Preload("Relative").
+		Where("id = ? AND (user_id = ? OR relative_id = ?)", input.ID, user.ID, user.ID).
+		First(&family).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "family_member_not_found"),
+		})
+		return
+	}
+
+	other := family.Relative
+	if family.RelativeID == user.ID {
+		other = family.ThisUser
+	}
+	if err := utils.ValidateRelationship(input.Relationship, user.Gender, other.Gender); err != nil {
+		respondRelationshipError(ctx, input.Relationship, err)
+		return
+	}
+	relationship := input.Relationship
+	if family.RelativeID == user.ID {
+		relationship = utils.InverseRelationship(input.Relationship, user.Gender)
+	}
+
 	// 开启事务
 	tx := global.DB.Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "服务器内部错误",
+				"error": i18n.T(ctx, "internal_error"),
 			})
 		}
 	}()
 
 	// 执行更新操作
 	result := tx.Exec(`UPDATE families SET relationship = ? WHERE id = ?`,
-		input.Relationship, input.ID)
+		relationship, family.ID)
 
 	if result.Error != nil {
 		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "修改亲友关系失败: " + result.Error.Error(),
-		})
-		return
-	}
-
-	// 检查是否实际更新了记录
-	if result.RowsAffected == 0 {
-		tx.Rollback()
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "未找到对应的亲友记录",
+			"error": i18n.T(ctx, "family_rename_failed") + result.Error.Error(),
 		})
 		return
 	}
@@ -305,12 +346,12 @@ func UpdateFamilyName(ctx *gin.Context) {
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "提交事务失败: " + err.Error(),
+			"error": i18n.T(ctx, "commit_failed") + err.Error(),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "修改亲友关系成功",
+		"message": i18n.T(ctx, "family_renamed"),
 	})
 }
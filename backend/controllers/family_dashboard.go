@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dashboardPackage 家人尚未完成体检的套餐
+type dashboardPackage struct {
+	ID              uint       `json:"id"`
+	PlanID          uint       `json:"plan_id"`
+	PlanName        string     `json:"plan_name"`
+	InstitutionName string     `json:"institution_name"`
+	AppointmentAt   *time.Time `json:"appointment_at"`
+}
+
+// dashboardAbnormal 家人某项目最近一次的异常结果
+type dashboardAbnormal struct {
+	ItemID   uint      `json:"item_id"`
+	ItemName string    `json:"item_name"`
+	Value    string    `json:"value"`
+	Date     time.Time `json:"date"`
+}
+
+// familyDashboardMember 家庭看板中的一位家人，未授予 view_records 授权时只返回基本信息
+type familyDashboardMember struct {
+	UserID               uint                `json:"user_id"`
+	Name                 string              `json:"name"`
+	Relationship         string              `json:"relationship"`
+	Degree               int                 `json:"degree"`
+	Consented            bool                `json:"consented"`
+	LatestCheckupAt      *time.Time          `json:"latest_checkup_at"`
+	PendingPackages      []dashboardPackage  `json:"pending_packages"`
+	UpcomingAppointments []dashboardPackage  `json:"upcoming_appointments"`
+	Abnormal             []dashboardAbnormal `json:"abnormal"`
+}
+
+// familyHistoryEntry 家族病史中的一个风险因素，level 为 high（两位及以上一级亲属）、
+// moderate（一位一级亲属或两位及以上二级亲属）或 low
+type familyHistoryEntry struct {
+	Code         string   `json:"code"`
+	Name         string   `json:"name"`
+	Relatives    []string `json:"relatives"`
+	FirstDegree  int      `json:"first_degree"`
+	SecondDegree int      `json:"second_degree"`
+	Level        string   `json:"level"`
+}
+
+// GetFamilyDashboard 家庭健康看板：汇总已确认家人的最近体检日期、待体检套餐、即将到来的预约和异常结果，
+// 并根据血亲的异常结果生成家族病史摘要；家人的健康数据需要对方授予 view_records 授权
+func GetFamilyDashboard(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	var relations []models.Family
+	if err := global.DB.Preload("ThisUser").Preload("Relative").
+		Where("(user_id = ? OR relative_id = ?) AND status = 1", user.ID, user.ID).
+		Order("id").
+		Find(&relations).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "family_fetch_failed"),
+		})
+		return
+	}
+
+	members := make([]*familyDashboardMember, 0, len(relations))
+	byUser := make(map[uint]*familyDashboardMember)
+	var consentedIDs []uint
+	for _, rel := range relations {
+		relative := rel.Relative
+		if rel.RelativeID == user.ID {
+			relative = rel.ThisUser
+		}
+		relationship := utils.RelationshipFrom(rel, user.ID)
+		member := &familyDashboardMember{
+			UserID:               relative.ID,
+			Name:                 relative.Name,
+			Relationship:         relationship,
+			Degree:               utils.RelationshipDegree(relationship),
+			PendingPackages:      []dashboardPackage{},
+			UpcomingAppointments: []dashboardPackage{},
+			Abnormal:             []dashboardAbnormal{},
+		}
+		members = append(members, member)
+		byUser[relative.ID] = member
+
+		err := utils.AuthorizeFamilyAccess(ctx, relative.ID, user.ID, models.ScopeViewRecords)
+		if errors.Is(err, utils.ErrFamilyScopeDenied) {
+			continue
+		}
+		if err != nil {
+			respondFamilyAccessError(ctx, err)
+			return
+		}
+		member.Consented = true
+		consentedIDs = append(consentedIDs, relative.ID)
+	}
+
+	history := make([]*familyHistoryEntry, 0)
+	if len(consentedIDs) > 0 {
+		now := time.Now()
+		var packages []models.UserPackage
+		if err := global.DB.Preload("Plan").Preload("Institution").
+			Where("user_id IN ? AND status = 0", consentedIDs).
+			Order("id").
+			Find(&packages).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "user_packages_fetch_failed") + err.Error(),
+			})
+			return
+		}
+		for _, pkg := range packages {
+			view := dashboardPackage{
+				ID:              pkg.ID,
+				PlanID:          pkg.PlanID,
+				PlanName:        pkg.Plan.PlanName,
+				InstitutionName: pkg.Institution.InstitutionName,
+				AppointmentAt:   pkg.AppointmentAt,
+			}
+			member := byUser[pkg.UserID]
+			member.PendingPackages = append(member.PendingPackages, view)
+			if pkg.AppointmentAt != nil && !pkg.AppointmentAt.Before(now) {
+				member.UpcomingAppointments = append(member.UpcomingAppointments, view)
+			}
+		}
+		for _, member := range members {
+			sort.SliceStable(member.UpcomingAppointments, func(i, j int) bool {
+				return member.UpcomingAppointments[i].AppointmentAt.Before(*member.UpcomingAppointments[j].AppointmentAt)
+			})
+		}
+
+		var results []models.UserHealthItem
+		if err := global.DB.Preload("ThisHeathItem").Scopes(utils.ReleasedResults).
+			Where("user_id IN ?", consentedIDs).
+			Order("created_at DESC").
+			Find(&results).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "health_items_fetch_failed") + err.Error(),
+			})
+			return
+		}
+		itemIDs := make([]uint, 0, len(results))
+		for _, r := range results {
+			itemIDs = append(itemIDs, r.RelationHealthItemId)
+		}
+		lang := i18n.Lang(ctx)
+		itemNames := utils.LocalizedItemNames(lang, itemIDs)
+
+		// 每人每个项目只看最近一次结果
+		factors := make(map[string]*familyHistoryEntry)
+		counted := make(map[string]map[uint]bool)
+		seen := make(map[[2]uint]bool)
+		for _, r := range results {
+			member := byUser[r.RelationUserId]
+			if member.LatestCheckupAt == nil {
+				createdAt := r.CreatedAt
+				member.LatestCheckupAt = &createdAt
+			}
+			key := [2]uint{r.RelationUserId, r.RelationHealthItemId}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if !utils.IsAbnormal(r.ItemValue, r.ThisHeathItem) {
+				continue
+			}
+			member.Abnormal = append(member.Abnormal, dashboardAbnormal{
+				ItemID:   r.RelationHealthItemId,
+				ItemName: utils.LocalizeItemName(itemNames, r.RelationHealthItemId, r.ThisHeathItem.ItemName),
+				Value:    r.ItemValue,
+				Date:     r.CreatedAt,
+			})
+
+			// 家族病史只统计血亲
+			if member.Degree == 0 {
+				continue
+			}
+			for _, code := range utils.RiskFactorsForItem(r.ThisHeathItem.ItemName) {
+				entry, ok := factors[code]
+				if !ok {
+					entry = &familyHistoryEntry{Code: code, Name: utils.RiskFactorName(lang, code), Relatives: []string{}}
+					factors[code] = entry
+					counted[code] = make(map[uint]bool)
+					history = append(history, entry)
+				}
+				if counted[code][member.UserID] {
+					continue
+				}
+				counted[code][member.UserID] = true
+				entry.Relatives = append(entry.Relatives, member.Relationship)
+				if member.Degree == 1 {
+					entry.FirstDegree++
+				} else {
+					entry.SecondDegree++
+				}
+			}
+		}
+	}
+
+	for _, entry := range history {
+		switch {
+		case entry.FirstDegree >= 2:
+			entry.Level = "high"
+		case entry.FirstDegree == 1 || entry.SecondDegree >= 2:
+			entry.Level = "moderate"
+		default:
+			entry.Level = "low"
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		if history[i].FirstDegree != history[j].FirstDegree {
+			return history[i].FirstDegree > history[j].FirstDegree
+		}
+		return history[i].SecondDegree > history[j].SecondDegree
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"members":        members,
+		"family_history": history,
+	})
+}
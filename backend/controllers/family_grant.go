@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// familyGrantView 授权列表中的一条记录
+type familyGrantView struct {
+	models.FamilyGrant
+	OwnerName   string `json:"owner_name"`
+	GranteeName string `json:"grantee_name"`
+	Active      bool   `json:"active"`
+}
+
+// GrantFamilyAccess 数据所有者向已确认关系的亲属授予授权
+// 已存在的同范围授权会被重新激活并更新到期时间；expires_at 为空表示长期有效
+func GrantFamilyAccess(ctx *gin.Context) {
+	var input struct {
+		RelativeID uint       `json:"relative_id" binding:"required"`
+		Scopes     []string   `json:"scopes" binding:"required,min=1"`
+		ExpiresAt  *time.Time `json:"expires_at"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+	for _, scope := range input.Scopes {
+		if !utils.ValidFamilyScope(scope) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "invalid_family_scope", scope),
+			})
+			return
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "family_grant_expiry_past"),
+		})
+		return
+	}
+
+	var owner models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&owner).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	var family models.Family
+	if err := global.DB.Where("((user_id = ? AND relative_id = ?) OR (user_id = ? AND relative_id = ?)) AND status = 1",
+		owner.ID, input.RelativeID, input.RelativeID, owner.ID).First(&family).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "family_relation_not_found"),
+		})
+		return
+	}
+
+	grants := make([]models.FamilyGrant, 0, len(input.Scopes))
+	before := make(map[string]interface{}, len(input.Scopes))
+	after := map[string]interface{}{"grantee_id": input.RelativeID}
+	for _, scope := range input.Scopes {
+		grants = append(grants, models.FamilyGrant{
+			FamilyID:  family.ID,
+			OwnerID:   owner.ID,
+			GranteeID: input.RelativeID,
+			Scope:     scope,
+			ExpiresAt: input.ExpiresAt,
+		})
+		before[scope] = grantState(nil)
+		after[scope] = grantState(&grants[len(grants)-1])
+	}
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var existing []models.FamilyGrant
+		if err := tx.Unscoped().Where("owner_id = ? AND grantee_id = ? AND scope IN ?", owner.ID, input.RelativeID, input.Scopes).
+			Find(&existing).Error; err != nil {
+			return err
+		}
+		for i := range existing {
+			before[existing[i].Scope] = grantState(&existing[i])
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"family_id":  family.ID,
+				"expires_at": input.ExpiresAt,
+				"revoked_at": nil,
+				"deleted_at": nil,
+				"updated_at": time.Now(),
+			}),
+		}).Create(&grants).Error; err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditFamilyGrant,
+			TargetType: "family",
+			TargetID:   family.ID,
+			Before:     before,
+			After:      after,
+		})
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "family_grant_failed") + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "family_grant_saved"),
+	})
+}
+
+// GetFamilyGrants 查看当前用户授予他人的授权（granted）及获得的授权（received）
+func GetFamilyGrants(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	var grants []models.FamilyGrant
+	if err := global.DB.Preload("Owner").Preload("Grantee").
+		Where("owner_id = ? OR grantee_id = ?", user.ID, user.ID).
+		Order("id DESC").Find(&grants).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "family_grants_fetch_failed") + err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	granted := make([]familyGrantView, 0)
+	received := make([]familyGrantView, 0)
+	for _, g := range grants {
+		view := familyGrantView{
+			FamilyGrant: g,
+			OwnerName:   g.Owner.Name,
+			GranteeName: g.Grantee.Name,
+			Active:      g.Active(now),
+		}
+		if g.OwnerID == user.ID {
+			granted = append(granted, view)
+		} else {
+			received = append(received, view)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"granted":  granted,
+		"received": received,
+	})
+}
+
+// RevokeFamilyGrant 数据所有者撤销授权
+func RevokeFamilyGrant(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	var grant models.FamilyGrant
+	if err := global.DB.Where("id = ? AND owner_id = ?", ctx.Param("id"), user.ID).First(&grant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": i18n.T(ctx, "family_grant_not_found"),
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+
+	if grant.RevokedAt == nil {
+		before := grantState(&grant)
+		now := time.Now()
+		err := global.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&grant).Update("revoked_at", now).Error; err != nil {
+				return err
+			}
+			grant.RevokedAt = &now
+			return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+				Action:     models.AuditFamilyRevoke,
+				TargetType: "family",
+				TargetID:   grant.FamilyID,
+				Before:     map[string]interface{}{grant.Scope: before},
+				After:      map[string]interface{}{grant.Scope: grantState(&grant), "grantee_id": grant.GranteeID},
+			})
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "family_grant_revoked"),
+	})
+}
+
+// GetFamilyAccessLogs 数据所有者查看亲属通过授权访问其数据的记录
+// 支持分页排序，按 actor_id、scope 及 date_from/date_to 筛选
+func GetFamilyAccessLogs(ctx *gin.Context) {
+	page, err := utils.ParsePagination(ctx, map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+	}, "-id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+	actorID, err := utils.ParseUintQuery(ctx, "actor_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_user_id"),
+		})
+		return
+	}
+	dateFrom, dateTo, err := utils.ParseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	query := global.DB.Model(&models.FamilyAccessLog{}).Where("owner_id = ?", user.ID)
+	if actorID != nil {
+		query = query.Where("actor_id = ?", *actorID)
+	}
+	if scope := ctx.Query("scope"); scope != "" {
+		query = query.Where("scope = ?", scope)
+	}
+	query = utils.WhereRange(query, "created_at", dateFrom, dateTo)
+
+	var logs []models.FamilyAccessLog
+	pageInfo, err := utils.Paginate(query, page, &logs)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "access_logs_fetch_failed") + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"logs":       logs,
+		"pagination": pageInfo,
+	})
+}
+
+// grantState 授权在审计日志中的状态：none（不存在）、revoked、active 或 active until <到期时间>
+func grantState(g *models.FamilyGrant) string {
+	switch {
+	case g == nil || g.DeletedAt.Valid:
+		return "none"
+	case g.RevokedAt != nil:
+		return "revoked"
+	case g.ExpiresAt != nil:
+		return "active until " + g.ExpiresAt.Format(time.RFC3339)
+	default:
+		return "active"
+	}
+}
+
+// respondFamilyAccessError 授权检查失败时的统一响应
+func respondFamilyAccessError(ctx *gin.Context, err error) {
+	if errors.Is(err, utils.ErrFamilyScopeDenied) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "family_scope_required"),
+		})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{
+		"error": i18n.T(ctx, "db_error") + err.Error(),
+	})
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"HealthCare/backend/config"
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// derivedRelative 通过已确认的家庭关系推导出的亲属
+type derivedRelative struct {
+	UserID            uint   `json:"user_id"`
+	Username          string `json:"username"`
+	Name              string `json:"name"`
+	Relationship      string `json:"relationship"`
+	RelationshipLabel string `json:"relationship_label"`
+	ViaUserID         uint   `json:"via_user_id"`
+	ViaName           string `json:"via_name"`
+	ViaRelationship   string `json:"via_relationship"`
+}
+
+// GetRelations 返回关系图中可选的亲属称谓，gender 为对方性别，inverse 为按我的性别给出的反向称谓
+func GetRelations(ctx *gin.Context) {
+	lang := i18n.Lang(ctx)
+	relations := make([]gin.H, 0, len(config.AppConfig.Relations))
+	for _, rel := range config.AppConfig.Relations {
+		relations = append(relations, gin.H{
+			"name":    rel.Name,
+			"label":   utils.RelationshipName(lang, rel.Name),
+			"gender":  rel.Gender,
+			"inverse": rel.Inverse,
+		})
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"relations": relations,
+	})
+}
+
+// GetDerivedFamilyMembers 根据已确认的家庭关系推导间接亲属（如父亲的父亲为祖父、妻子的母亲为岳母）
+// 只推导一层，已有直接关系的用户不重复列出；只有本人与管理员可以查看
+func GetDerivedFamilyMembers(ctx *gin.Context) {
+	thisUserID, err := utils.UnmarshalUint(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": i18n.T(ctx, "user_not_found")})
+		return
+	}
+	if user.ID != thisUserID && user.UserType != 2 {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": i18n.T(ctx, "permission_denied_view_data")})
+		return
+	}
+
+	var direct []models.Family
+	if err := global.DB.Preload("ThisUser").Preload("Relative").
+		Where("(user_id = ? OR relative_id = ?) AND status = 1", thisUserID, thisUserID).
+		Find(&direct).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "family_fetch_failed")})
+		return
+	}
+
+	// 直接亲属及其相对当前用户的称谓
+	relatives := make(map[uint]models.User)
+	labels := make(map[uint]string)
+	relativeIDs := make([]uint, 0, len(direct))
+	for _, rel := range direct {
+		relative := rel.Relative
+		if rel.RelativeID == thisUserID {
+			relative = rel.ThisUser
+		}
+		relatives[relative.ID] = relative
+		labels[relative.ID] = utils.RelationshipFrom(rel, thisUserID)
+		relativeIDs = append(relativeIDs, relative.ID)
+	}
+
+	response := make([]derivedRelative, 0)
+	if len(relativeIDs) == 0 {
+		ctx.JSON(http.StatusOK, response)
+		return
+	}
+
+	var second []models.Family
+	if err := global.DB.Preload("ThisUser").Preload("Relative").
+		Where("(user_id IN ? OR relative_id IN ?) AND status = 1", relativeIDs, relativeIDs).
+		Order("id").
+		Find(&second).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "family_fetch_failed")})
+		return
+	}
+
+	lang := i18n.Lang(ctx)
+	seen := make(map[uint]bool)
+	for _, rel := range second {
+		for _, pair := range [][2]models.User{{rel.ThisUser, rel.Relative}, {rel.Relative, rel.ThisUser}} {
+			via, target := pair[0], pair[1]
+			viaLabel, ok := labels[via.ID]
+			if !ok || target.ID == thisUserID || seen[target.ID] {
+				continue
+			}
+			if _, isDirect := relatives[target.ID]; isDirect {
+				continue
+			}
+			result, ok := utils.DeriveRelationship(viaLabel, via.Gender, utils.RelationshipFrom(rel, via.ID), target.Gender)
+			if !ok {
+				continue
+			}
+			seen[target.ID] = true
+			response = append(response, derivedRelative{
+				UserID:            target.ID,
+				Username:          target.Username,
+				Name:              target.Name,
+				Relationship:      result,
+				RelationshipLabel: utils.RelationshipName(lang, result),
+				ViaUserID:         via.ID,
+				ViaName:           via.Name,
+				ViaRelationship:   viaLabel,
+			})
+		}
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// respondRelationshipError 称谓校验失败时的统一响应
+func respondRelationshipError(ctx *gin.Context, relationship string, err error) {
+	if errors.Is(err, utils.ErrRelationshipGender) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "relationship_gender_mismatch", relationship),
+		})
+		return
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{
+		"error": i18n.T(ctx, "unknown_relationship", relationship),
+	})
+}
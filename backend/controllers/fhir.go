@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/fhir"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// loincCode LOINC 编码格式：1 到 7 位数字、连字符与一位校验位
+var loincCode = regexp.MustCompile(`^\d{1,7}-\d$`)
+
+// GetUserPackageFHIR 以 FHIR R4 Bundle 导出用户套餐的检查结果，供医院等外部系统使用
+// 本人、获得 view_records 授权的家人、套餐所属机构的主账号与成员、管理员可以导出
+func GetUserPackageFHIR(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	var pkg models.UserPackage
+	if err := global.DB.Preload("User").Preload("Institution").Preload("Plan").Preload("PlanVersion").
+		First(&pkg, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "package_not_found"),
+		})
+		return
+	}
+	staff, err := utils.InstitutionStaff(global.DB, pkg.InstitutionID, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	if pkg.UserID != user.ID && !staff {
+		if err := utils.AuthorizeFamilyAccess(ctx, pkg.UserID, user.ID, models.ScopeViewRecords); err != nil {
+			respondFamilyAccessError(ctx, err)
+			return
+		}
+	}
+
+	// 结果发布前只有套餐所属机构与管理员可以导出
+	if pkg.ReviewStatus != models.ReviewReleased && !staff {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "results_not_released"),
+		})
+		return
+	}
+
+	var results []models.UserHealthItem
+	if err := global.DB.Preload("ThisHeathItem").
+		Where("user_id = ? AND plan_id = ?", pkg.UserID, pkg.PlanID).
+		Order("id").
+		Find(&results).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "health_items_fetch_failed") + err.Error(),
+		})
+		return
+	}
+
+	itemIDs := make([]uint, 0, len(results))
+	for _, r := range results {
+		itemIDs = append(itemIDs, r.RelationHealthItemId)
+	}
+	var mappingList []models.LoincMapping
+	if len(itemIDs) > 0 {
+		if err := global.DB.Where("health_item_id IN ?", itemIDs).Find(&mappingList).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
+		}
+	}
+	mappings := make(map[uint]models.LoincMapping, len(mappingList))
+	for _, m := range mappingList {
+		mappings[m.RelationHealthItemId] = m
+	}
+
+	ctx.Header("Content-Type", fhir.MediaType)
+	ctx.JSON(http.StatusOK, utils.FHIRBundle(pkg, results, mappings, i18n.Lang(ctx)))
+}
+
+// GetLoincMappings 获取体检项目到 LOINC 编码的映射表
+func GetLoincMappings(ctx *gin.Context) {
+	type mappingView struct {
+		models.LoincMapping
+		ItemName string `json:"item_name"`
+		ItemUnit string `json:"item_unit"`
+	}
+	var mappings []models.LoincMapping
+	if err := global.DB.Preload("ThisHeathItem").Order("health_item_id").Find(&mappings).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	views := make([]mappingView, 0, len(mappings))
+	for _, m := range mappings {
+		views = append(views, mappingView{LoincMapping: m, ItemName: m.ThisHeathItem.ItemName, ItemUnit: m.ThisHeathItem.Unit})
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"mappings": views,
+	})
+}
+
+// SaveLoincMapping 管理员设置体检项目的 LOINC 编码，已有映射时覆盖
+func SaveLoincMapping(ctx *gin.Context) {
+	var input struct {
+		Code    string `json:"code" binding:"required"`
+		Display string `json:"display" binding:"max=255"`
+		Unit    string `json:"unit" binding:"max=30"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+	if !loincCode.MatchString(input.Code) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_loinc_code", input.Code),
+		})
+		return
+	}
+
+	var item models.HealthItem
+	if err := global.DB.First(&item, ctx.Param("item_id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "health_item_not_found"),
+		})
+		return
+	}
+
+	var mapping models.LoincMapping
+	err := global.DB.Where("health_item_id = ?", item.ID).First(&mapping).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	mapping.RelationHealthItemId = item.ID
+	mapping.Code = input.Code
+	mapping.Display = input.Display
+	mapping.Unit = input.Unit
+	if err := global.DB.Save(&mapping).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "loinc_mapping_saved"),
+		"mapping": mapping,
+	})
+}
+
+// DeleteLoincMapping 管理员删除体检项目的 LOINC 映射
+func DeleteLoincMapping(ctx *gin.Context) {
+	result := global.DB.Unscoped().Where("health_item_id = ?", ctx.Param("item_id")).Delete(&models.LoincMapping{})
+	if result.Error != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "delete_failed") + result.Error.Error(),
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "loinc_mapping_not_found"),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "loinc_mapping_deleted"),
+	})
+}
@@ -3,13 +3,16 @@ package controllers
 import (
 	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // CreateHealthItemTemplate 创建检查项目模板
@@ -22,7 +25,7 @@ func CreateHealthItemTemplate(ctx *gin.Context) {
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "输入格式不正确",
+			"error": i18n.T(ctx, "invalid_input"),
 		})
 		return
 	}
@@ -31,7 +34,7 @@ func CreateHealthItemTemplate(ctx *gin.Context) {
 	items := strings.Split(input.HealthItems, ",")
 	if len(items) == 0 {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "至少需要输入一个检查项目",
+			"error": i18n.T(ctx, "at_least_one_item"),
 		})
 		return
 	}
@@ -67,7 +70,7 @@ func UpdateHealthItemValues(ctx *gin.Context) {
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "输入格式不正确",
+			"error": i18n.T(ctx, "invalid_input"),
 		})
 		return
 	}
@@ -98,7 +101,7 @@ func UpdateHealthItemValues(ctx *gin.Context) {
 	var healthItem models.HealthItem
 	if err := global.DB.First(&healthItem, input.ItemID).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "检查项目不存在",
+			"error": i18n.T(ctx, "check_item_not_found"),
 		})
 		return
 	}
@@ -106,7 +109,7 @@ func UpdateHealthItemValues(ctx *gin.Context) {
 	// 更新健康项目模板字符串
 	if err := global.DB.Model(&healthItem).Update("item_name", newItemString).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "更新检查项目失败: " + err.Error(),
+			"error": i18n.T(ctx, "check_item_update_failed") + err.Error(),
 		})
 		return
 	}
@@ -124,7 +127,7 @@ func UpdateHealthItemValues(ctx *gin.Context) {
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message":     "检查项目更新成功",
+		"message":     i18n.T(ctx, "check_item_updated"),
 		"item_string": newItemString,
 	})
 }
@@ -142,7 +145,7 @@ func GetHealthItemValues(ctx *gin.Context) {
 	if itemID == "undefined" || itemID == "" {
 		fmt.Println("GetHealthItemValues - 无效的项目ID (undefined 或空)")
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的检查项目ID",
+			"error": i18n.T(ctx, "invalid_check_item_id"),
 		})
 		return
 	}
@@ -152,7 +155,7 @@ func GetHealthItemValues(ctx *gin.Context) {
 	if err != nil {
 		fmt.Printf("GetHealthItemValues - 项目ID不是有效数字: %v\n", err)
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的检查项目ID格式",
+			"error": i18n.T(ctx, "invalid_check_item_id_format"),
 		})
 		return
 	}
@@ -161,7 +164,7 @@ func GetHealthItemValues(ctx *gin.Context) {
 	if err := global.DB.First(&healthItem, itemID).Error; err != nil {
 		fmt.Printf("GetHealthItemValues - 数据库查询失败: %v\n", err)
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "检查项目不存在",
+			"error": i18n.T(ctx, "check_item_not_found"),
 		})
 		return
 	}
@@ -184,25 +187,56 @@ func SaveHealthItemTemplate(ctx *gin.Context) {
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "输入格式不正确",
+			"error": i18n.T(ctx, "invalid_input"),
+		})
+		return
+	}
+
+	// 名称（含同义名称、编码）已在目录中时返回目录中的项目，不再重复创建
+	healthItem, err := utils.ResolveCatalogItem(global.DB, input.ItemName)
+	if err == nil {
+		ctx.JSON(http.StatusOK, gin.H{
+			"message": i18n.T(ctx, "catalog_item_exists"),
+			"item":    healthItem,
+		})
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "health_item_query_failed") + err.Error(),
+		})
+		return
+	}
+
+	// 项目目录由管理员维护，其他账号只能选择已有项目
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return
+	}
+	if user.UserType != 2 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "catalog_item_not_found", input.ItemName),
 		})
 		return
 	}
 
 	// 创建新的健康项目记录
-	healthItem := models.HealthItem{
-		ItemName: input.ItemName,
+	healthItem = models.HealthItem{
+		ItemName: strings.TrimSpace(input.ItemName),
 	}
 
 	if err := global.DB.Create(&healthItem).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "创建健康项目失败: " + err.Error(),
+			"error": i18n.T(ctx, "health_item_save_failed") + err.Error(),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "健康项目保存成功",
+		"message": i18n.T(ctx, "health_item_saved"),
 		"item":    healthItem,
 	})
 }
@@ -1,9 +1,10 @@
 package controllers
 
 import (
+	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
-	"fmt"
 	"net/http"
 	"strconv"
 
@@ -16,7 +17,7 @@ func GetPlanHealthItems(c *gin.Context) {
 	pid, err := strconv.ParseUint(planID, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的套餐ID",
+			"error": i18n.T(c, "invalid_plan_id"),
 		})
 		return
 	}
@@ -25,7 +26,7 @@ func GetPlanHealthItems(c *gin.Context) {
 	var plan models.Plan
 	if err := global.DB.First(&plan, pid).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "套餐不存在",
+			"error": i18n.T(c, "plan_not_found"),
 		})
 		return
 	}
@@ -46,11 +47,20 @@ func GetPlanHealthItems(c *gin.Context) {
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取套餐项目失败: " + err.Error(),
+			"error": i18n.T(c, "plan_items_fetch_failed") + err.Error(),
 		})
 		return
 	}
 
+	itemIDs := make([]uint, 0, len(planItems))
+	for _, item := range planItems {
+		itemIDs = append(itemIDs, item.HealthItemID)
+	}
+	itemNames := utils.LocalizedItemNames(i18n.Lang(c), itemIDs)
+	for i := range planItems {
+		planItems[i].ItemName = utils.LocalizeItemName(itemNames, planItems[i].HealthItemID, planItems[i].ItemName)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"plan_id":   pid,
 		"plan_name": plan.PlanName,
@@ -64,7 +74,7 @@ func AssociatePlanHealthItems(c *gin.Context) {
 	pid, err := strconv.ParseUint(planID, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的套餐ID",
+			"error": i18n.T(c, "invalid_plan_id"),
 		})
 		return
 	}
@@ -73,7 +83,7 @@ func AssociatePlanHealthItems(c *gin.Context) {
 	var plan models.Plan
 	if err := global.DB.First(&plan, pid).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "套餐不存在",
+			"error": i18n.T(c, "plan_not_found"),
 		})
 		return
 	}
@@ -83,7 +93,7 @@ func AssociatePlanHealthItems(c *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "用户不存在",
+			"error": i18n.T(c, "user_not_found"),
 		})
 		return
 	}
@@ -93,20 +103,20 @@ func AssociatePlanHealthItems(c *gin.Context) {
 		var institution models.Institution
 		if err := global.DB.Where("user_id = ?", user.ID).First(&institution).Error; err != nil {
 			c.JSON(http.StatusForbidden, gin.H{
-				"error": "您没有关联的机构",
+				"error": i18n.T(c, "no_associated_institution"),
 			})
 			return
 		}
 
 		if institution.ID != plan.RelationInstitutionID {
 			c.JSON(http.StatusForbidden, gin.H{
-				"error": "您没有权限操作此套餐",
+				"error": i18n.T(c, "plan_operate_forbidden"),
 			})
 			return
 		}
 	} else if user.UserType != 2 && user.UserType != 1 { // 不是管理员或超级管理员
 		c.JSON(http.StatusForbidden, gin.H{
-			"error": "您没有权限进行此操作",
+			"error": i18n.T(c, "permission_denied"),
 		})
 		return
 	}
@@ -120,7 +130,7 @@ func AssociatePlanHealthItems(c *gin.Context) {
 	var input []HealthItemInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的请求数据",
+			"error": i18n.T(c, "invalid_request"),
 		})
 		return
 	}
@@ -132,7 +142,7 @@ func AssociatePlanHealthItems(c *gin.Context) {
 	if err := tx.Where("plan_id = ?", pid).Delete(&models.PlanHeathItem{}).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "删除现有关联失败: " + err.Error(),
+			"error": i18n.T(c, "plan_association_delete_failed") + err.Error(),
 		})
 		return
 	}
@@ -141,10 +151,10 @@ func AssociatePlanHealthItems(c *gin.Context) {
 	for _, item := range input {
 		// 验证健康项目存在
 		var healthItem models.HealthItem
-		if err := tx.First(&healthItem, item.HealthItemID).Error; err != nil {
+		if err := tx.Scopes(utils.CatalogItems).First(&healthItem, item.HealthItemID).Error; err != nil {
 			tx.Rollback()
 			c.JSON(http.StatusNotFound, gin.H{
-				"error": fmt.Sprintf("ID为%d的健康项目不存在", item.HealthItemID),
+				"error": i18n.T(c, "health_item_id_not_found", item.HealthItemID),
 			})
 			return
 		}
@@ -159,22 +169,32 @@ func AssociatePlanHealthItems(c *gin.Context) {
 		if err := tx.Create(&planHealthItem).Error; err != nil {
 			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "创建健康项目关联失败: " + err.Error(),
+				"error": i18n.T(c, "plan_association_create_failed") + err.Error(),
 			})
 			return
 		}
 	}
 
+	// 关联变更后生成新的套餐版本，已购买的用户仍按原版本展示
+	if _, err := utils.SnapshotPlan(tx, uint(pid)); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(c, "plan_version_create_failed") + err.Error(),
+		})
+		return
+	}
+
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "提交事务失败: " + err.Error(),
+			"error": i18n.T(c, "commit_failed") + err.Error(),
 		})
 		return
 	}
+	utils.ReindexPlan(uint(pid))
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "套餐关联健康项目成功",
+		"message": i18n.T(c, "plan_items_associated"),
 		"count":   len(input),
 	})
 }
@@ -185,7 +205,7 @@ func AddHealthItemToPlan(c *gin.Context) {
 	pid, err := strconv.ParseUint(planID, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的套餐ID",
+			"error": i18n.T(c, "invalid_plan_id"),
 		})
 		return
 	}
@@ -194,7 +214,7 @@ func AddHealthItemToPlan(c *gin.Context) {
 	var plan models.Plan
 	if err := global.DB.First(&plan, pid).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "套餐不存在",
+			"error": i18n.T(c, "plan_not_found"),
 		})
 		return
 	}
@@ -204,7 +224,7 @@ func AddHealthItemToPlan(c *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "用户不存在",
+			"error": i18n.T(c, "user_not_found"),
 		})
 		return
 	}
@@ -214,20 +234,20 @@ func AddHealthItemToPlan(c *gin.Context) {
 		var institution models.Institution
 		if err := global.DB.Where("user_id = ?", user.ID).First(&institution).Error; err != nil {
 			c.JSON(http.StatusForbidden, gin.H{
-				"error": "您没有关联的机构",
+				"error": i18n.T(c, "no_associated_institution"),
 			})
 			return
 		}
 
 		if institution.ID != plan.RelationInstitutionID {
 			c.JSON(http.StatusForbidden, gin.H{
-				"error": "您没有权限操作此套餐",
+				"error": i18n.T(c, "plan_operate_forbidden"),
 			})
 			return
 		}
 	} else if user.UserType != 2 && user.UserType != 1 { // 不是管理员或超级管理员
 		c.JSON(http.StatusForbidden, gin.H{
-			"error": "您没有权限进行此操作",
+			"error": i18n.T(c, "permission_denied"),
 		})
 		return
 	}
@@ -240,16 +260,16 @@ func AddHealthItemToPlan(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的请求数据",
+			"error": i18n.T(c, "invalid_request"),
 		})
 		return
 	}
 
 	// 验证健康项目存在
 	var healthItem models.HealthItem
-	if err := global.DB.First(&healthItem, input.HealthItemID).Error; err != nil {
+	if err := global.DB.Scopes(utils.CatalogItems).First(&healthItem, input.HealthItemID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "健康项目不存在",
+			"error": i18n.T(c, "health_item_not_found"),
 		})
 		return
 	}
@@ -259,7 +279,7 @@ func AddHealthItemToPlan(c *gin.Context) {
 	result := global.DB.Where("plan_id = ? AND health_item_id = ?", pid, input.HealthItemID).First(&existingItem)
 	if result.Error == nil {
 		c.JSON(http.StatusConflict, gin.H{
-			"error": "该健康项目已关联到此套餐",
+			"error": i18n.T(c, "health_item_already_in_plan"),
 		})
 		return
 	}
@@ -274,13 +294,20 @@ func AddHealthItemToPlan(c *gin.Context) {
 
 	if err := global.DB.Create(&planHealthItem).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "创建健康项目关联失败: " + err.Error(),
+			"error": i18n.T(c, "plan_association_create_failed") + err.Error(),
 		})
 		return
 	}
+	if _, err := utils.SnapshotPlan(global.DB, uint(pid)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(c, "plan_version_create_failed") + err.Error(),
+		})
+		return
+	}
+	utils.ReindexPlan(uint(pid))
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "成功添加健康项目到套餐",
+		"message": i18n.T(c, "health_item_added_to_plan"),
 		"id":      planHealthItem.ID,
 	})
 }
@@ -293,7 +320,7 @@ func GetUserPlanHealthItems(c *gin.Context) {
 	uid, err := strconv.ParseUint(userID, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的用户ID",
+			"error": i18n.T(c, "invalid_user_id"),
 		})
 		return
 	}
@@ -301,7 +328,7 @@ func GetUserPlanHealthItems(c *gin.Context) {
 	pid, err := strconv.ParseUint(planID, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的套餐ID",
+			"error": i18n.T(c, "invalid_plan_id"),
 		})
 		return
 	}
@@ -311,17 +338,25 @@ func GetUserPlanHealthItems(c *gin.Context) {
 	var currentUser models.User
 	if err := global.DB.Where("username = ?", username).First(&currentUser).Error; err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "未授权",
+			"error": i18n.T(c, "unauthorized"),
 		})
 		return
 	}
 
-	// 处理权限：管理员可以查看任何用户，普通用户只能查看自己，机构用户可以查看选择了其套餐的用户
-	var hasPermission bool
-	if currentUser.UserType == 1 || currentUser.UserType == 2 { // 超级管理员或管理员
+	// 处理权限：管理员可以查看任何用户，普通用户只能查看自己或已授予 view_records 授权的亲属，
+	// 机构用户可以查看选择了其套餐的用户
+	// 客户本人与亲属只能看到已发布的结果
+	var hasPermission, releasedOnly bool
+	if currentUser.UserType == 2 { // 管理员
 		hasPermission = true
 	} else if currentUser.ID == uint(uid) { // 查看自己的数据
-		hasPermission = true
+		hasPermission, releasedOnly = true, true
+	} else if currentUser.UserType == 1 { // 亲属代为查看
+		if err := utils.AuthorizeFamilyAccess(c, uint(uid), currentUser.ID, models.ScopeViewRecords); err != nil {
+			respondFamilyAccessError(c, err)
+			return
+		}
+		hasPermission, releasedOnly = true, true
 	} else if currentUser.UserType == 3 { // 机构用户
 		// 查询该机构是否拥有该套餐，以及该用户是否选择了该套餐
 		var count int64
@@ -336,7 +371,7 @@ func GetUserPlanHealthItems(c *gin.Context) {
 
 	if !hasPermission {
 		c.JSON(http.StatusForbidden, gin.H{
-			"error": "您没有权限查看此数据",
+			"error": i18n.T(c, "permission_denied_view_data"),
 		})
 		return
 	}
@@ -345,13 +380,17 @@ func GetUserPlanHealthItems(c *gin.Context) {
 	type HealthItemData struct {
 		ItemID      uint   `json:"item_id"`
 		ItemName    string `json:"item_name"`
-		ItemValue   string `json:"item_value"`
+		ItemValue   string `gorm:"serializer:encrypted" json:"item_value"`
 		Description string `json:"description"`
 	}
 
 	var healthData []HealthItemData
 
-	err = global.DB.Model(&models.UserHealthItem{}).
+	query := global.DB.Model(&models.UserHealthItem{})
+	if releasedOnly {
+		query = query.Scopes(utils.ReleasedResults)
+	}
+	err = query.
 		Select("user_health_items.health_item_id as item_id, health_items.item_name, user_health_items.item_value, plan_heath_items.item_description as description").
 		Joins("JOIN health_items ON user_health_items.health_item_id = health_items.id").
 		Joins("LEFT JOIN plan_heath_items ON plan_heath_items.plan_id = user_health_items.plan_id AND plan_heath_items.health_item_id = user_health_items.health_item_id").
@@ -360,7 +399,7 @@ func GetUserPlanHealthItems(c *gin.Context) {
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取健康数据失败: " + err.Error(),
+			"error": i18n.T(c, "health_data_fetch_failed") + err.Error(),
 		})
 		return
 	}
@@ -369,15 +408,47 @@ func GetUserPlanHealthItems(c *gin.Context) {
 	var plan models.Plan
 	if err := global.DB.First(&plan, pid).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "套餐不存在",
+			"error": i18n.T(c, "plan_not_found"),
 		})
 		return
 	}
 
+	// 用户已购买时，项目名称与描述按所购版本展示
+	var userPackage models.UserPackage
+	if err := global.DB.Where("user_id = ? AND plan_id = ?", uid, pid).First(&userPackage).Error; err == nil {
+		planName, items, err := utils.PackageContents(global.DB, userPackage)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(c, "health_data_fetch_failed") + err.Error(),
+			})
+			return
+		}
+		pinned := make(map[uint]utils.PackageItem, len(items))
+		for _, item := range items {
+			pinned[item.HealthItemID] = item
+		}
+		for i := range healthData {
+			if item, ok := pinned[healthData[i].ItemID]; ok {
+				healthData[i].ItemName = item.ItemName
+				healthData[i].Description = item.ItemDescription
+			}
+		}
+		plan.PlanName = planName
+	}
+
+	itemIDs := make([]uint, 0, len(healthData))
+	for _, item := range healthData {
+		itemIDs = append(itemIDs, item.ItemID)
+	}
+	itemNames := utils.LocalizedItemNames(i18n.Lang(c), itemIDs)
+	for i := range healthData {
+		healthData[i].ItemName = utils.LocalizeItemName(itemNames, healthData[i].ItemID, healthData[i].ItemName)
+	}
+
 	var targetUser models.User
 	if err := global.DB.Select("id, username, name, gender, birthday").First(&targetUser, uid).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "用户不存在",
+			"error": i18n.T(c, "user_not_found"),
 		})
 		return
 	}
@@ -391,8 +462,9 @@ func GetUserPlanHealthItems(c *gin.Context) {
 			"birthday": targetUser.Birthday,
 		},
 		"plan": gin.H{
-			"id":   plan.ID,
-			"name": plan.PlanName,
+			"id":         plan.ID,
+			"name":       plan.PlanName,
+			"version_id": userPackage.PlanVersionID,
 		},
 		"health_data": healthData,
 	})
@@ -406,7 +478,7 @@ func GetUserInstitutionHealthItems(c *gin.Context) {
 	uid, err := strconv.ParseUint(userID, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的用户ID",
+			"error": i18n.T(c, "invalid_user_id"),
 		})
 		return
 	}
@@ -414,7 +486,7 @@ func GetUserInstitutionHealthItems(c *gin.Context) {
 	iid, err := strconv.ParseUint(institutionID, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的机构ID",
+			"error": i18n.T(c, "invalid_institution_id"),
 		})
 		return
 	}
@@ -424,17 +496,25 @@ func GetUserInstitutionHealthItems(c *gin.Context) {
 	var currentUser models.User
 	if err := global.DB.Where("username = ?", username).First(&currentUser).Error; err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "未授权",
+			"error": i18n.T(c, "unauthorized"),
 		})
 		return
 	}
 
-	// 处理权限：管理员可以查看任何用户，普通用户只能查看自己，机构用户可以查看选择了其套餐的用户
-	var hasPermission bool
-	if currentUser.UserType == 1 || currentUser.UserType == 2 { // 超级管理员或管理员
+	// 处理权限：管理员可以查看任何用户，普通用户只能查看自己或已授予 view_records 授权的亲属，
+	// 机构用户可以查看选择了其套餐的用户
+	// 客户本人与亲属只能看到已发布的结果
+	var hasPermission, releasedOnly bool
+	if currentUser.UserType == 2 { // 管理员
 		hasPermission = true
 	} else if currentUser.ID == uint(uid) { // 查看自己的数据
-		hasPermission = true
+		hasPermission, releasedOnly = true, true
+	} else if currentUser.UserType == 1 { // 亲属代为查看
+		if err := utils.AuthorizeFamilyAccess(c, uint(uid), currentUser.ID, models.ScopeViewRecords); err != nil {
+			respondFamilyAccessError(c, err)
+			return
+		}
+		hasPermission, releasedOnly = true, true
 	} else if currentUser.UserType == 3 { // 机构用户
 		// 查询该用户是否为该机构的管理员
 		var institution models.Institution
@@ -445,7 +525,7 @@ func GetUserInstitutionHealthItems(c *gin.Context) {
 
 	if !hasPermission {
 		c.JSON(http.StatusForbidden, gin.H{
-			"error": "您没有权限查看此数据",
+			"error": i18n.T(c, "permission_denied_view_data"),
 		})
 		return
 	}
@@ -464,14 +544,14 @@ func GetUserInstitutionHealthItems(c *gin.Context) {
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取用户套餐失败: " + err.Error(),
+			"error": i18n.T(c, "user_packages_fetch_failed") + err.Error(),
 		})
 		return
 	}
 
 	if len(userPackages) == 0 {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "用户未选择该机构的套餐",
+			"error": i18n.T(c, "user_has_no_institution_plan"),
 		})
 		return
 	}
@@ -480,7 +560,7 @@ func GetUserInstitutionHealthItems(c *gin.Context) {
 	var institution models.Institution
 	if err := global.DB.First(&institution, iid).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "机构不存在",
+			"error": i18n.T(c, "institution_not_found"),
 		})
 		return
 	}
@@ -489,7 +569,7 @@ func GetUserInstitutionHealthItems(c *gin.Context) {
 	var targetUser models.User
 	if err := global.DB.Select("id, username, name, gender, birthday").First(&targetUser, uid).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "用户不存在",
+			"error": i18n.T(c, "user_not_found"),
 		})
 		return
 	}
@@ -505,20 +585,30 @@ func GetUserInstitutionHealthItems(c *gin.Context) {
 		var healthItems []struct {
 			ItemID    uint   `json:"item_id"`
 			ItemName  string `json:"item_name"`
-			ItemValue string `json:"item_value"`
+			ItemValue string `gorm:"serializer:encrypted" json:"item_value"`
 		}
 
-		global.DB.Model(&models.UserHealthItem{}).
+		query := global.DB.Model(&models.UserHealthItem{})
+		if releasedOnly {
+			query = query.Scopes(utils.ReleasedResults)
+		}
+		query.
 			Select("user_health_items.health_item_id as item_id, health_items.item_name, user_health_items.item_value").
 			Joins("JOIN health_items ON user_health_items.health_item_id = health_items.id").
 			Where("user_health_items.user_id = ? AND user_health_items.plan_id = ?", uid, pkg.PlanID).
 			Find(&healthItems)
 
+		itemIDs := make([]uint, 0, len(healthItems))
+		for _, item := range healthItems {
+			itemIDs = append(itemIDs, item.ItemID)
+		}
+		itemNames := utils.LocalizedItemNames(i18n.Lang(c), itemIDs)
+
 		var healthData []map[string]interface{}
 		for _, item := range healthItems {
 			healthData = append(healthData, map[string]interface{}{
 				"item_id":    item.ItemID,
-				"item_name":  item.ItemName,
+				"item_name":  utils.LocalizeItemName(itemNames, item.ItemID, item.ItemName),
 				"item_value": item.ItemValue,
 			})
 		}
@@ -3,29 +3,35 @@ package controllers
 import (
 	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// GetAllHealthItems 获取所有健康检查项目
+// GetAllHealthItems 获取项目目录中的所有健康检查项目
 func GetAllHealthItems(ctx *gin.Context) {
 	var healthItems []models.HealthItem
 
 	// 支持关键词搜索
 	keyword := ctx.Query("keyword")
 	if keyword != "" {
-		if err := global.DB.Where("item_name LIKE ?", "%"+keyword+"%").Find(&healthItems).Error; err != nil {
+		if err := global.DB.Scopes(utils.CatalogItems, catalogKeyword(keyword)).Find(&healthItems).Error; err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "获取健康检查项目失败: " + err.Error(),
+				"error": i18n.T(ctx, "health_items_fetch_failed") + err.Error(),
 			})
 			return
 		}
 	} else {
-		if err := global.DB.Find(&healthItems).Error; err != nil {
+		if err := global.DB.Scopes(utils.CatalogItems).Find(&healthItems).Error; err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "获取健康检查项目失败: " + err.Error(),
+				"error": i18n.T(ctx, "health_items_fetch_failed") + err.Error(),
 			})
 			return
 		}
@@ -37,18 +43,54 @@ func GetAllHealthItems(ctx *gin.Context) {
 }
 
 // oy
+// 分页获取项目目录，支持 keyword（名称、同义名称、编码）与 category 筛选，按 id、item_name、code、category、created_at 排序
 func GetAllHealthItemsList(ctx *gin.Context) {
-	var items []map[string]interface{}
+	page, err := utils.ParsePagination(ctx, map[string]string{
+		"id":         "id",
+		"item_name":  "item_name",
+		"code":       "code",
+		"category":   "category",
+		"created_at": "created_at",
+	}, "id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
 
-	global.DB.
-		Table("health_items").
-		Scan(&items)
+	query := global.DB.Table("health_items").Where("health_items.deleted_at IS NULL").Scopes(utils.CatalogItems)
+	if keyword := ctx.Query("keyword"); keyword != "" {
+		query = query.Scopes(catalogKeyword(keyword))
+	}
+	if category, ok := ctx.GetQuery("category"); ok {
+		query = query.Where("category = ?", category)
+	}
+
+	var items []map[string]interface{}
+	pageInfo, err := utils.Paginate(query, page, &items)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "health_items_fetch_failed") + err.Error(),
+		})
+		return
+	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"items": items,
+		"items":      items,
+		"pagination": pageInfo,
 	})
+}
 
+// catalogKeyword 按名称、同义名称或编码模糊查找项目
+func catalogKeyword(keyword string) func(*gorm.DB) *gorm.DB {
+	like := "%" + keyword + "%"
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("health_items.item_name LIKE ? OR health_items.code LIKE ? OR health_items.id IN (?)", like, like,
+			global.DB.Model(&models.HealthItemSynonym{}).Select("health_item_id").Where("name LIKE ?", like))
+	}
 }
+
 func GetAllHealthItemsByID(ctx *gin.Context) {
 	userID := ctx.Param("id")
 	thisUserID, _ := utils.UnmarshalUint(userID)
@@ -70,7 +112,7 @@ func GetHealthItemByID(ctx *gin.Context) {
 	var healthItem models.HealthItem
 	if err := global.DB.First(&healthItem, itemID).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "健康检查项目不存在",
+			"error": i18n.T(ctx, "health_check_item_not_found"),
 		})
 		return
 	}
@@ -89,37 +131,209 @@ func GetHealthItemByID(ctx *gin.Context) {
 		Where("plan_heath_items.health_item_id = ?", itemID).
 		Find(&plans)
 
+	var synonyms []models.HealthItemSynonym
+	global.DB.Where("health_item_id = ?", healthItem.ID).Order("id").Find(&synonyms)
+
 	ctx.JSON(http.StatusOK, gin.H{
-		"item":  healthItem,
-		"plans": plans,
+		"item":     healthItem,
+		"plans":    plans,
+		"synonyms": synonyms,
 	})
 }
 
-// UpdateHealthItem 更新健康检查项目
+// optionalFloat 区分请求中未提供的字段与显式的 null：Set 表示提供了该字段，Value 为 nil 表示清除
+type optionalFloat struct {
+	Set   bool
+	Value *float64
+}
+
+func (o *optionalFloat) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	o.Value = nil
+	if string(data) == "null" {
+		return nil
+	}
+	var v float64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	o.Value = &v
+	return nil
+}
+
+// merge 返回更新后的值：未提供时保留 stored
+func (o optionalFloat) merge(stored *float64) *float64 {
+	if o.Set {
+		return o.Value
+	}
+	return stored
+}
+
+// validRange 下限与上限都有值时下限不能大于上限
+func validRange(low, high *float64) bool {
+	return low == nil || high == nil || *low <= *high
+}
+
+// UpdateHealthItem 管理员更新项目目录中的健康检查项目，名称与编码不能与其他项目重复，分类须为已定义的分类；
+// 参考范围与危急值界限传 null 表示清除，更新后的下限不能大于上限
 func UpdateHealthItem(ctx *gin.Context) {
 	itemID := ctx.Param("id")
 
 	var input struct {
-		ItemName string `json:"item_name"`
+		ItemName      string        `json:"item_name"`
+		Code          *string       `json:"code"`           // 标准编码
+		Unit          *string       `json:"unit"`           // 结果单位
+		ReferenceLow  optionalFloat `json:"reference_low"`  // 参考范围下限
+		ReferenceHigh optionalFloat `json:"reference_high"` // 参考范围上限
+		Category      *string       `json:"category"`       // 项目分类
+		CriticalLow   optionalFloat `json:"critical_low"`   // 危急值下限
+		CriticalHigh  optionalFloat `json:"critical_high"`  // 危急值上限
+		Formula       *string       `json:"formula"`        // 衍生项目的计算公式，空字符串表示取消
 	}
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的请求数据",
+			"error": i18n.T(ctx, "invalid_request"),
+		})
+		return
+	}
+
+	id, _ := strconv.ParseUint(itemID, 10, 32)
+	var item models.HealthItem
+	if err := global.DB.First(&item, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": i18n.T(ctx, "health_item_not_found"),
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	// 只修改一端时与已保存的另一端比较
+	if !validRange(input.ReferenceLow.merge(item.ReferenceLow), input.ReferenceHigh.merge(item.ReferenceHigh)) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_reference_range"),
+		})
+		return
+	}
+	if !validRange(input.CriticalLow.merge(item.CriticalLow), input.CriticalHigh.merge(item.CriticalHigh)) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_critical_range"),
+		})
+		return
+	}
+
+	index, err := utils.LoadCatalogIndex(global.DB)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	input.ItemName = strings.TrimSpace(input.ItemName)
+	code := ""
+	if input.Code != nil {
+		code = strings.TrimSpace(*input.Code)
+		if len(code) > 50 {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "invalid_request"),
+			})
+			return
+		}
+	}
+	var names []string
+	if input.ItemName != "" {
+		names = append(names, input.ItemName)
+	}
+	if err := index.Check(uint(id), names, code); err != nil {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "catalog_name_taken") + err.Error(),
+		})
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if input.ItemName != "" {
+		updates["item_name"] = input.ItemName
+	}
+	if input.Code != nil {
+		updates["code"] = code
+	}
+	if input.Unit != nil {
+		updates["unit"] = *input.Unit
+	}
+	for column, value := range map[string]optionalFloat{
+		"reference_low":  input.ReferenceLow,
+		"reference_high": input.ReferenceHigh,
+		"critical_low":   input.CriticalLow,
+		"critical_high":  input.CriticalHigh,
+	} {
+		if value.Set {
+			updates[column] = value.Value
+		}
+	}
+	if input.Category != nil {
+		if len([]rune(*input.Category)) > 50 {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "invalid_request"),
+			})
+			return
+		}
+		category := strings.TrimSpace(*input.Category)
+		if ok, err := categoryDefined(category); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
+		} else if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "catalog_category_not_found", category),
+			})
+			return
+		}
+		updates["category"] = category
+	}
+	if input.Formula != nil {
+		src := strings.TrimSpace(*input.Formula)
+		if src != "" {
+			if _, err := utils.ValidateFormula(global.DB, uint(id), src); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{
+					"error": i18n.T(ctx, "invalid_formula") + err.Error(),
+				})
+				return
+			}
+		}
+		updates["formula"] = src
+	}
+	if len(updates) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request"),
 		})
 		return
 	}
 
 	// 更新健康检查项目
-	if err := global.DB.Model(&models.HealthItem{}).Where("id = ?", itemID).Update("item_name", input.ItemName).Error; err != nil {
+	if err := global.DB.Model(&models.HealthItem{}).Where("id = ?", itemID).Updates(updates).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "更新健康检查项目失败: " + err.Error(),
+			"error": i18n.T(ctx, "health_check_item_update_failed") + err.Error(),
 		})
 		return
 	}
+	if id != 0 {
+		if err := utils.SnapshotPlansWithItem(global.DB, uint(id)); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "plan_version_create_failed") + err.Error(),
+			})
+			return
+		}
+		utils.ReindexHealthItem(uint(id))
+	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "健康检查项目已更新",
+		"message": i18n.T(ctx, "health_check_item_updated"),
 	})
 }
 
@@ -133,7 +347,7 @@ func UpdatePlanItemDescription(ctx *gin.Context) {
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的请求数据",
+			"error": i18n.T(ctx, "invalid_request"),
 		})
 		return
 	}
@@ -143,12 +357,97 @@ func UpdatePlanItemDescription(ctx *gin.Context) {
 		Where("plan_id = ? AND health_item_id = ?", input.PlanID, input.ItemID).
 		Update("item_description", input.ItemDescription).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "更新项目描述失败: " + err.Error(),
+			"error": i18n.T(ctx, "item_description_update_failed") + err.Error(),
+		})
+		return
+	}
+	if _, err := utils.SnapshotPlan(global.DB, input.PlanID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "plan_version_create_failed") + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "item_description_updated"),
+	})
+}
+
+// GetHealthItemTranslations 获取健康项目的各语言译名
+func GetHealthItemTranslations(ctx *gin.Context) {
+	itemID := ctx.Param("id")
+
+	var healthItem models.HealthItem
+	if err := global.DB.First(&healthItem, itemID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "health_check_item_not_found"),
+		})
+		return
+	}
+
+	var translations []models.HealthItemTranslation
+	if err := global.DB.Where("health_item_id = ?", healthItem.ID).Find(&translations).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "translations_fetch_failed") + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"item_id":      healthItem.ID,
+		"item_name":    healthItem.ItemName,
+		"translations": translations,
+	})
+}
+
+// SetHealthItemTranslation 设置健康项目在指定语言下的显示名称
+func SetHealthItemTranslation(ctx *gin.Context) {
+	itemID := ctx.Param("id")
+
+	var input struct {
+		Language string `json:"language" binding:"required"`
+		ItemName string `json:"item_name" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+
+	// 默认语言的名称即 HealthItem.ItemName，不单独存储
+	language := i18n.Normalize(input.Language)
+	if language == "" || language == i18n.DefaultLanguage {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "unsupported_language", input.Language),
+		})
+		return
+	}
+
+	var healthItem models.HealthItem
+	if err := global.DB.First(&healthItem, itemID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "health_check_item_not_found"),
+		})
+		return
+	}
+
+	translation := models.HealthItemTranslation{
+		RelationHealthItemId: healthItem.ID,
+		Language:             language,
+	}
+	if err := global.DB.Where(&translation).
+		Assign(models.HealthItemTranslation{ItemName: strings.TrimSpace(input.ItemName)}).
+		FirstOrCreate(&translation).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "translation_save_failed") + err.Error(),
 		})
 		return
 	}
+	utils.ReindexHealthItem(healthItem.ID)
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "项目描述已更新",
+		"message":     i18n.T(ctx, "translation_saved"),
+		"translation": translation,
 	})
 }
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOptionalFloat(t *testing.T) {
+	stored := 3.9
+	tests := []struct {
+		body string
+		set  bool
+		want *float64 // 与已保存的 3.9 合并后的值
+	}{
+		{`{}`, false, &stored},
+		{`{"low": null}`, true, nil},
+		{`{"low": 0}`, true, new(float64)},
+		{`{"low": 5.5}`, true, ptr(5.5)},
+	}
+	for _, tt := range tests {
+		var input struct {
+			Low optionalFloat `json:"low"`
+		}
+		if err := json.Unmarshal([]byte(tt.body), &input); err != nil {
+			t.Fatalf("%s: %v", tt.body, err)
+		}
+		got := input.Low.merge(&stored)
+		if input.Low.Set != tt.set || (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("%s: got set=%v value=%v, want set=%v value=%v", tt.body, input.Low.Set, got, tt.set, tt.want)
+		}
+	}
+
+	var input struct {
+		Low optionalFloat `json:"low"`
+	}
+	if err := json.Unmarshal([]byte(`{"low": "high"}`), &input); err == nil {
+		t.Error("accepted a string threshold")
+	}
+}
+
+func TestValidRange(t *testing.T) {
+	tests := []struct {
+		low, high *float64
+		want      bool
+	}{
+		{nil, nil, true},
+		{ptr(1), nil, true},
+		{nil, ptr(1), true},
+		{ptr(1), ptr(1), true},
+		{ptr(1), ptr(2), true},
+		{ptr(2), ptr(1), false},
+	}
+	for _, tt := range tests {
+		if got := validRange(tt.low, tt.high); got != tt.want {
+			t.Errorf("validRange(%v, %v) = %v, want %v", tt.low, tt.high, got, tt.want)
+		}
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
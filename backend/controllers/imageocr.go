@@ -1,6 +1,10 @@
 package controllers
 
 import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -13,9 +17,9 @@ import (
 func ImageOcr(c *gin.Context) {
 	//我需要调用外部的py的http接口来实现图像文字识别
 	// 获取上传的图片文件
-	file, _, err := c.Request.FormFile("image")
+	file, header, err := c.Request.FormFile("image")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "图片上传失败"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.T(c, "image_upload_failed")})
 		return
 	}
 	defer file.Close()
@@ -23,7 +27,19 @@ func ImageOcr(c *gin.Context) {
 	// 读取图片内容
 	imgData, err := io.ReadAll(file)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "图片读取失败"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(c, "image_read_failed")})
+		return
+	}
+
+	// 保存上传的报告文件，供用户日后查看与导出
+	var user models.User
+	if err := global.DB.Where("username = ?", c.GetString("username")).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": i18n.T(c, "user_not_found")})
+		return
+	}
+	reportFile, err := utils.SaveReportFile(user.ID, header.Filename, header.Header.Get("Content-Type"), imgData)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(c, "report_file_save_failed") + err.Error()})
 		return
 	}
 
@@ -31,7 +47,7 @@ func ImageOcr(c *gin.Context) {
 	pyOcrUrl := "http://127.0.0.1:8080/ocr" // 假设你的py服务在这个地址
 	req, err := http.NewRequest("POST", pyOcrUrl, bytes.NewReader(imgData))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "请求OCR服务失败"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(c, "ocr_request_failed")})
 		return
 	}
 	req.Header.Set("Content-Type", "application/octet-stream")
@@ -39,20 +55,20 @@ func ImageOcr(c *gin.Context) {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用OCR服务失败"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(c, "ocr_call_failed")})
 		return
 	}
 	defer resp.Body.Close()
 
 	ocrResult, err := io.ReadAll(resp.Body)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取OCR结果失败"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(c, "ocr_read_failed")})
 		return
 	}
 
 	var result map[string]interface{}
 	if err := json.Unmarshal(ocrResult, &result); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "解析OCR结果失败"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(c, "ocr_parse_failed")})
 		return
 	}
 	fmt.Println("OCR结果:", result)
@@ -66,7 +82,7 @@ func ImageOcr(c *gin.Context) {
 		// fmt.Println("key:", key)
 		// itemID, err := strconv.ParseUint(key, 10, 32)
 		// if err != nil {
-		// 	c.JSON(http.StatusBadRequest, gin.H{"error": "无效的项目ID"})
+		// 	c.JSON(http.StatusBadRequest, gin.H{"error": i18n.T(c, "invalid_item_id")})
 		// 	return
 		// }
 
@@ -77,6 +93,7 @@ func ImageOcr(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"result": outResult,
+		"result":         outResult,
+		"report_file_id": reportFile.ID,
 	})
 }
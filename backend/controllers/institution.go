@@ -3,6 +3,7 @@ package controllers
 import (
 	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
 	"errors"
 	"fmt"
@@ -20,7 +21,7 @@ func CreateInstitution(ctx *gin.Context) {
 		fmt.Printf("Failed to bind JSON: %v\n", err)
 		fmt.Printf("Request body: %v\n", ctx.Request.Body)
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "请求格式错误: " + err.Error(),
+			"error": i18n.T(ctx, "bad_request_format") + err.Error(),
 		})
 		return
 	}
@@ -30,7 +31,7 @@ func CreateInstitution(ctx *gin.Context) {
 	uid, err := strconv.ParseUint(userID, 10, 32)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的用户ID",
+			"error": i18n.T(ctx, "invalid_user_id"),
 		})
 		return
 	}
@@ -39,14 +40,14 @@ func CreateInstitution(ctx *gin.Context) {
 	var user models.User
 	if err := global.DB.First(&user, uid).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "用户不存在",
+			"error": i18n.T(ctx, "user_not_found"),
 		})
 		return
 	}
 
 	if user.UserType != 3 {
 		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "只有机构用户可以创建机构信息",
+			"error": i18n.T(ctx, "only_institution_create"),
 		})
 		return
 	}
@@ -57,13 +58,13 @@ func CreateInstitution(ctx *gin.Context) {
 	if err == nil {
 		// 找到了已存在的机构记录
 		ctx.JSON(http.StatusConflict, gin.H{
-			"error": "该用户已经创建过机构信息",
+			"error": i18n.T(ctx, "institution_already_created"),
 		})
 		return
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		// 发生了除了"记录未找到"之外的错误
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "数据库错误: " + err.Error(),
+			"error": i18n.T(ctx, "db_error") + err.Error(),
 		})
 		return
 	}
@@ -76,13 +77,14 @@ func CreateInstitution(ctx *gin.Context) {
 
 	if err := global.DB.Create(&institution).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "创建机构信息失败: " + err.Error(),
+			"error": i18n.T(ctx, "institution_create_failed") + err.Error(),
 		})
 		return
 	}
+	utils.ReindexInstitution(institution.ID)
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message":     "机构信息提交成功，等待管理员审核",
+		"message":     i18n.T(ctx, "institution_submitted"),
 		"institution": institution,
 	})
 }
@@ -93,14 +95,14 @@ func GetPendingInstitutions(ctx *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
+			"error": i18n.T(ctx, "user_not_found"),
 		})
 		return
 	}
 
 	if user.UserType != 2 {
 		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "Only admin users can view pending institutions",
+			"error": i18n.T(ctx, "only_admin_view_pending"),
 		})
 		return
 	}
@@ -122,14 +124,14 @@ func ReviewInstitution(ctx *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
+			"error": i18n.T(ctx, "user_not_found"),
 		})
 		return
 	}
 
 	if user.UserType != 2 {
 		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "Only admin users can review institutions",
+			"error": i18n.T(ctx, "only_admin_review"),
 		})
 		return
 	}
@@ -148,37 +150,102 @@ func ReviewInstitution(ctx *gin.Context) {
 	var institution models.Institution
 	if err := global.DB.First(&institution, institutionID).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "Institution not found",
+			"error": i18n.T(ctx, "institution_not_found"),
 		})
 		return
 	}
 
+	previousStatus := institution.Status
 	institution.Status = map[bool]uint8{true: 1, false: 2}[input.Approved]
-	if err := global.DB.Save(&institution).Error; err != nil {
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&institution).Error; err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditInstitutionReview,
+			TargetType: "institution",
+			TargetID:   institution.ID,
+			Before:     map[string]interface{}{"status": previousStatus},
+			After:      map[string]interface{}{"status": institution.Status},
+		})
+	})
+	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
+	utils.ReindexInstitution(institution.ID)
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": map[bool]string{
-			true:  "Institution approved successfully",
-			false: "Institution rejected",
+			true:  i18n.T(ctx, "institution_approved"),
+			false: i18n.T(ctx, "institution_rejected"),
 		}[input.Approved],
 	})
 }
 
+// GetInstitutions 分页获取机构列表，支持 keyword（名称/地址）与 date_from/date_to 筛选
+// 普通用户只能看到已审核通过的机构，管理员可通过 status 查看任意状态
 func GetInstitutions(ctx *gin.Context) {
-	var institutions models.Institution
-	if err := global.DB.Where("status = ?", 1).Find(&institutions).Error; err != nil {
+	page, err := utils.ParsePagination(ctx, map[string]string{
+		"id":               "id",
+		"institution_name": "institution_name",
+		"created_at":       "created_at",
+	}, "id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	username := ctx.GetString("username")
+	var user models.User
+	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	status := uint(1)
+	if s, err := utils.ParseUintQuery(ctx, "status"); err != nil || (s != nil && *s > 2) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_institution_status"),
+		})
+		return
+	} else if s != nil && user.UserType == 2 {
+		status = *s
+	}
+
+	dateFrom, dateTo, err := utils.ParseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	query := global.DB.Model(&models.Institution{}).Where("status = ?", status)
+	if keyword := ctx.Query("keyword"); keyword != "" {
+		query = query.Where("institution_name LIKE ? OR institution_address LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	query = utils.WhereRange(query, "created_at", dateFrom, dateTo)
+
+	var institutions []models.Institution
+	pageInfo, err := utils.Paginate(query, page, &institutions)
+	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, institutions)
+	ctx.JSON(http.StatusOK, gin.H{
+		"institutions": institutions,
+		"pagination":   pageInfo,
+	})
 }
 
 func GetInstitutionDetail(ctx *gin.Context) {
@@ -187,7 +254,7 @@ func GetInstitutionDetail(ctx *gin.Context) {
 
 	if err := global.DB.First(&institution, institutionID).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "Institution not found",
+			"error": i18n.T(ctx, "institution_not_found"),
 		})
 		return
 	}
@@ -197,7 +264,7 @@ func GetInstitutionDetail(ctx *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
+			"error": i18n.T(ctx, "user_not_found"),
 		})
 		return
 	}
@@ -205,7 +272,7 @@ func GetInstitutionDetail(ctx *gin.Context) {
 	// 如果不是管理员，只能看到已批准的机构
 	if user.UserType != 2 && institution.Status != 1 {
 		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "You don't have permission to view this institution",
+			"error": i18n.T(ctx, "institution_view_forbidden"),
 		})
 		return
 	}
@@ -224,7 +291,7 @@ func CreateInstitutionPlans(ctx *gin.Context) {
 
 	if err := global.DB.First(&institution, institutionID).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "Institution not found",
+			"error": i18n.T(ctx, "institution_not_found"),
 		})
 		return
 	}
@@ -233,25 +300,30 @@ func CreateInstitutionPlans(ctx *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
+			"error": i18n.T(ctx, "user_not_found"),
 		})
 		return
 	}
 
 	if user.ID != institution.UserID && user.UserType != 2 {
 		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "You don't have permission to create plans for this institution",
+			"error": i18n.T(ctx, "plan_create_forbidden"),
 		})
 		return
 	}
 
 	var input struct {
-		PlanName        *string  `json:"plan_name"` //允许没有套餐名称
-		HealthItem      string   `json:"health_item"`
+		PlanName        *string  `json:"plan_name"`        //允许没有套餐名称
+		HealthItem      string   `json:"health_item"`      // 项目名称、同义名称或编码，在项目目录中查找
+		HealthItemID    uint     `json:"health_item_id"`   // 从项目目录中选择的项目，优先于 health_item
 		ItemDescription *string  `json:"item_description"` //允许没有描述
 		PlanPrice       *float64 `json:"plan_price"`       // 套餐价格
 		Description     *string  `json:"description"`      // 套餐描述
 		SuitableFor     *string  `json:"suitable_for"`     // 适用人群
+		MinAge          *uint    `json:"min_age"`          // 适用年龄下限
+		MaxAge          *uint    `json:"max_age"`          // 适用年龄上限
+		Sex             string   `json:"sex"`              // 适用性别 M/F，为空不限
+		RiskFactors     []string `json:"risk_factors"`     // 针对的风险因素代码
 	}
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
@@ -262,13 +334,48 @@ func CreateInstitutionPlans(ctx *gin.Context) {
 	fmt.Printf("Received input: %+v\n", input)
 
 	// 验证必要的输入字段
-	if input.HealthItem == "" {
+	if strings.TrimSpace(input.HealthItem) == "" && input.HealthItemID == 0 {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "健康项目名称是必填的",
+			"error": i18n.T(ctx, "health_item_name_required"),
 		})
 		return
 	}
 
+	// 项目从目录中选择，机构不能新建项目；管理员使用目录中没有的名称时加入目录
+	var newHealthItem models.HealthItem
+	var findErr error
+	if input.HealthItemID != 0 {
+		findErr = global.DB.Scopes(utils.CatalogItems).First(&newHealthItem, input.HealthItemID).Error
+	} else {
+		newHealthItem, findErr = utils.ResolveCatalogItem(global.DB, input.HealthItem)
+	}
+	switch {
+	case findErr == nil:
+	case !errors.Is(findErr, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "health_item_query_failed") + findErr.Error(),
+		})
+		return
+	case input.HealthItemID != 0:
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "health_check_item_not_found"),
+		})
+		return
+	case user.UserType != 2:
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "catalog_item_not_found", input.HealthItem),
+		})
+		return
+	default:
+		newHealthItem = models.HealthItem{ItemName: strings.TrimSpace(input.HealthItem)}
+		if err := global.DB.Create(&newHealthItem).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "health_item_create_failed") + err.Error(),
+			})
+			return
+		}
+	}
+
 	// 将中文名称添加到日志
 	fmt.Printf("添加健康项目: '%s', 机构ID: %s, 套餐ID: %s\n", input.HealthItem, institutionID, planID)
 
@@ -292,18 +399,29 @@ func CreateInstitutionPlans(ctx *gin.Context) {
 		if input.SuitableFor != nil {
 			newPlan.SuitableFor = *input.SuitableFor
 		}
+		sex, riskFactors, err := utils.NormalizeEligibility(input.MinAge, input.MaxAge, input.Sex, input.RiskFactors)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "invalid_eligibility") + err.Error(),
+			})
+			return
+		}
+		newPlan.MinAge = input.MinAge
+		newPlan.MaxAge = input.MaxAge
+		newPlan.Sex = sex
+		newPlan.RiskFactors = riskFactors
 
 		// 检查套餐名称是否已存在
 		exists, err := utils.CheckExists(&models.Plan{}, "plan_name", *input.PlanName)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "数据库错误: " + err.Error(),
+				"error": i18n.T(ctx, "db_error") + err.Error(),
 			})
 			return
 		}
 		if exists {
 			ctx.JSON(http.StatusConflict, gin.H{
-				"error": fmt.Sprintf("%v 已经存在，请更换名称或者是更新已有内容", input.PlanName)})
+				"error": i18n.T(ctx, "plan_name_exists", *input.PlanName)})
 			return
 		}
 
@@ -317,58 +435,25 @@ func CreateInstitutionPlans(ctx *gin.Context) {
 		// 检查planid对应的套餐是否存在
 		if err := global.DB.First(&newPlan, planID).Error; err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{
-				"error": "套餐不存在",
+				"error": i18n.T(ctx, "plan_not_found"),
 			})
 			return
 		}
 	}
 
-	// 打印健康项目名称用于调试
-	fmt.Printf("Looking for health item: '%s'\n", input.HealthItem)
-
-	var newHealthItem models.HealthItem
-	newHealthItem.ItemName = input.HealthItem
-
-	// 先检查健康项目是否已存在
-	var existingItem models.HealthItem
-	findErr := global.DB.Where("item_name = ?", input.HealthItem).First(&existingItem).Error
-
-	// 如果已存在，则使用现有的健康项目
-	if findErr == nil {
-		fmt.Printf("Found existing health item with ID: %d\n", existingItem.ID)
-		newHealthItem = existingItem
-	} else if errors.Is(findErr, gorm.ErrRecordNotFound) {
-		// 如果不存在，则创建新的健康项目
-		fmt.Printf("Creating new health item: '%s'\n", input.HealthItem)
-		if createErr := global.DB.Create(&newHealthItem).Error; createErr != nil {
-			fmt.Printf("Error creating health item: %v\n", createErr)
-			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "创建健康检查项目失败: " + createErr.Error(),
-			})
-			return
-		}
-		fmt.Printf("Successfully created health item with ID: %d\n", newHealthItem.ID)
-	} else {
-		// 查询过程中出现其他错误
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "查询健康检查项目失败: " + findErr.Error(),
-		})
-		return
-	}
-
 	// 检查此套餐是否已包含该健康项目
 	var existingPlanItem models.PlanHeathItem
-	planItemErr := global.DB.Where("plan_id = ? AND item_id = ?", newPlan.ID, newHealthItem.ID).First(&existingPlanItem).Error
+	planItemErr := global.DB.Where("plan_id = ? AND health_item_id = ?", newPlan.ID, newHealthItem.ID).First(&existingPlanItem).Error
 	if planItemErr == nil {
 		// 已存在此项目，返回409冲突错误
 		ctx.JSON(http.StatusConflict, gin.H{
-			"error": fmt.Sprintf("此套餐已包含健康项目: %s", input.HealthItem),
+			"error": i18n.T(ctx, "plan_item_exists", newHealthItem.ItemName),
 		})
 		return
 	} else if !errors.Is(planItemErr, gorm.ErrRecordNotFound) {
 		// 如果是其他错误（不是记录未找到），返回500错误
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "检查套餐项目关系时出错: " + planItemErr.Error(),
+			"error": i18n.T(ctx, "plan_item_check_failed") + planItemErr.Error(),
 		})
 		return
 	}
@@ -386,11 +471,18 @@ func CreateInstitutionPlans(ctx *gin.Context) {
 		})
 		return
 	}
+	if _, err := utils.SnapshotPlan(global.DB, newPlan.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "plan_version_create_failed") + err.Error(),
+		})
+		return
+	}
+	utils.ReindexPlan(newPlan.ID)
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": map[bool]string{
-			true:  "套餐创建成功",
-			false: "体检项目创建成功"}[planID == ""],
+			true:  i18n.T(ctx, "plan_created"),
+			false: i18n.T(ctx, "plan_item_created")}[planID == ""],
 
 		"plan":      newPlan,
 		"item":      newHealthItem,
@@ -404,7 +496,7 @@ func GetInstitutionPlans(ctx *gin.Context) {
 
 	if err := global.DB.First(&institution, institutionID).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "Institution not found",
+			"error": i18n.T(ctx, "institution_not_found"),
 		})
 		return
 	}
@@ -414,7 +506,7 @@ func GetInstitutionPlans(ctx *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
+			"error": i18n.T(ctx, "user_not_found"),
 		})
 		return
 	}
@@ -423,7 +515,7 @@ func GetInstitutionPlans(ctx *gin.Context) {
 	// 但如果机构已批准，任何用户都可以查看套餐
 	if institution.Status != 1 && user.ID != institution.UserID && user.UserType != 2 {
 		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "This institution is not approved",
+			"error": i18n.T(ctx, "institution_not_approved"),
 		})
 		return
 	}
@@ -473,6 +565,12 @@ func GetInstitutionPlans(ctx *gin.Context) {
 		planItemsMap[item.RelationPlanId] = append(planItemsMap[item.RelationPlanId], item)
 	}
 
+	itemIDs := make([]uint, 0, len(planItems))
+	for _, item := range planItems {
+		itemIDs = append(itemIDs, item.RelationHealthItemId)
+	}
+	localizedNames := utils.LocalizedItemNames(i18n.Lang(ctx), itemIDs)
+
 	// 构建每个套餐的详细信息
 	for _, plan := range plans {
 		items := planItemsMap[plan.ID]
@@ -481,7 +579,7 @@ func GetInstitutionPlans(ctx *gin.Context) {
 		// 收集所有指标项的名称
 		for _, item := range items {
 			if item.ThisHeathItem.ItemName != "" {
-				itemNames = append(itemNames, item.ThisHeathItem.ItemName)
+				itemNames = append(itemNames, utils.LocalizeItemName(localizedNames, item.RelationHealthItemId, item.ThisHeathItem.ItemName))
 			}
 		}
 
@@ -517,12 +615,15 @@ func UpdateInstitutionPlanorItem(ctx *gin.Context) {
 	var input struct {
 		PlanID          uint     `json:"plan_id"`
 		ItemID          *uint    `json:"item_id"`
-		ItemName        *string  `json:"item_name"`
-		ItemDescription *string  `json:"item_description"`
+		ItemDescription *string  `json:"item_description"` // 只修改本套餐中的项目说明，项目名称由管理员在项目目录中修改
 		PlanName        *string  `json:"plan_name"`
 		PlanPrice       *float64 `json:"plan_price"`
 		PlanDescription *string  `json:"description"`
 		PlanSuitableFor *string  `json:"suitable_for"`
+		MinAge          *uint    `json:"min_age"` // 0 表示不限
+		MaxAge          *uint    `json:"max_age"` // 0 表示不限
+		Sex             *string  `json:"sex"`
+		RiskFactors     []string `json:"risk_factors"` // 传入时整体替换，传空数组清除
 	}
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
@@ -534,13 +635,13 @@ func UpdateInstitutionPlanorItem(ctx *gin.Context) {
 	var institution models.Institution
 	if err := global.DB.First(&institution, institutionID).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "Institution not found",
+			"error": i18n.T(ctx, "institution_not_found"),
 		})
 		return
 	}
 	if institution.Status != 1 {
 		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "This institution is not approved",
+			"error": i18n.T(ctx, "institution_not_approved"),
 		})
 		return
 	}
@@ -564,20 +665,69 @@ func UpdateInstitutionPlanorItem(ctx *gin.Context) {
 	if input.PlanSuitableFor != nil && *input.PlanSuitableFor != "" {
 		utils.UpdateIt(&models.Plan{}, input.PlanID, "suitable_for", *input.PlanSuitableFor)
 	}
-	if input.ItemID != nil {
-		// Update item name
-		if input.ItemName != nil && *input.ItemName != "" {
-			utils.UpdateIt(&models.HealthItem{}, input.ItemID, "item_name", *input.ItemName)
-		}
 
+	// Update plan eligibility
+	if input.MinAge != nil || input.MaxAge != nil || input.Sex != nil || input.RiskFactors != nil {
+		var plan models.Plan
+		if err := global.DB.First(&plan, input.PlanID).Error; err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": i18n.T(ctx, "plan_not_found"),
+			})
+			return
+		}
+		if input.MinAge != nil {
+			plan.MinAge = nilIfZero(*input.MinAge)
+		}
+		if input.MaxAge != nil {
+			plan.MaxAge = nilIfZero(*input.MaxAge)
+		}
+		if input.Sex != nil {
+			plan.Sex = *input.Sex
+		}
+		riskFactors := utils.ParseRiskFactors(plan.RiskFactors)
+		if input.RiskFactors != nil {
+			riskFactors = input.RiskFactors
+		}
+		sex, codes, err := utils.NormalizeEligibility(plan.MinAge, plan.MaxAge, plan.Sex, riskFactors)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "invalid_eligibility") + err.Error(),
+			})
+			return
+		}
+		if err := global.DB.Model(&models.Plan{}).Where("id = ?", plan.ID).Updates(map[string]interface{}{
+			"min_age":      plan.MinAge,
+			"max_age":      plan.MaxAge,
+			"sex":          sex,
+			"risk_factors": codes,
+		}).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
+		}
+	}
+	if input.ItemID != nil && input.ItemDescription != nil {
 		// Update item description
-		if input.ItemDescription != nil {
-			utils.UpdateIt(&models.PlanHeathItem{}, input.ItemID, "item_description", *input.ItemDescription)
+		if err := global.DB.Model(&models.PlanHeathItem{}).
+			Where("plan_id = ? AND health_item_id = ?", input.PlanID, *input.ItemID).
+			Update("item_description", *input.ItemDescription).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
 		}
 	}
+	if _, err := utils.SnapshotPlan(global.DB, input.PlanID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "plan_version_create_failed") + err.Error(),
+		})
+		return
+	}
+	utils.ReindexPlan(input.PlanID)
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "更新成功"})
+		"message": i18n.T(ctx, "update_success")})
 
 }
 
@@ -595,11 +745,11 @@ func DeleteInsistutionPlanonItem(ctx *gin.Context) {
 	}
 	fmt.Println("input:", input)
 
-	// 根据planid，itemid查找删除
+	// 根据planid，itemid查找删除；只移除套餐与项目的关联，项目目录中的项目保留
 	var planheathitem models.PlanHeathItem
-	if err := global.DB.Where("plan_id = ? AND item_id = ?", input.PlanID, input.ItemID).First(&planheathitem).Error; err != nil {
+	if err := global.DB.Where("plan_id = ? AND health_item_id = ?", input.PlanID, input.ItemID).First(&planheathitem).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "套餐内体检项目不存在",
+			"error": i18n.T(ctx, "plan_item_not_found"),
 		})
 		return
 	}
@@ -607,43 +757,20 @@ func DeleteInsistutionPlanonItem(ctx *gin.Context) {
 	// 删除套餐内体检项目
 	if err := global.DB.Unscoped().Delete(&planheathitem).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "删除失败: " + err.Error(),
-		})
-		return
-	}
-
-	var remainitem []models.PlanHeathItem
-	if err := global.DB.Where("item_id = ?", input.ItemID).Find(&remainitem).Error; err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "数据库错误" + err.Error(),
+			"error": i18n.T(ctx, "delete_failed") + err.Error(),
 		})
 		return
 	}
-
-	// 如果没有其他套餐引用该体检项目，则删除该体检项目
-	if len(remainitem) == 0 {
-		var healthitem models.HealthItem
-		if err := global.DB.Where("id = ?", input.ItemID).First(&healthitem).Error; err != nil {
-			ctx.JSON(http.StatusNotFound, gin.H{
-				"error": "体检项目不存在",
-			})
-			return
-		}
-		// 删除体检项目
-		if err := global.DB.Unscoped().Delete(&healthitem).Error; err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "删除失败: " + err.Error(),
-			})
-			return
-		}
-		ctx.JSON(http.StatusOK, gin.H{
-			"message": "体检项目删除成功",
+	if _, err := utils.SnapshotPlan(global.DB, input.PlanID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "plan_version_create_failed") + err.Error(),
 		})
 		return
 	}
+	utils.ReindexPlan(input.PlanID)
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "套餐内体检项目删除成功",
+		"message": i18n.T(ctx, "plan_item_deleted"),
 	})
 
 }
@@ -671,7 +798,7 @@ func DeleteInstitutionPlan(ctx *gin.Context) {
 	if err := tx.Model(&models.UserPackage{}).Where("plan_id = ?", input.PlanID).Count(&userPackagesCount).Error; err != nil {
 		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "检查用户套餐关联失败: " + err.Error(),
+			"error": i18n.T(ctx, "user_package_check_failed") + err.Error(),
 		})
 		return
 	}
@@ -680,7 +807,7 @@ func DeleteInstitutionPlan(ctx *gin.Context) {
 		if err := tx.Unscoped().Where("plan_id = ?", input.PlanID).Delete(&models.UserPackage{}).Error; err != nil {
 			tx.Rollback()
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "删除用户套餐关联失败: " + err.Error(),
+				"error": i18n.T(ctx, "user_package_delete_failed") + err.Error(),
 			})
 			return
 		}
@@ -690,7 +817,7 @@ func DeleteInstitutionPlan(ctx *gin.Context) {
 	if err := tx.Unscoped().Where("plan_id = ?", input.PlanID).Delete(&models.UserHealthItem{}).Error; err != nil {
 		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "删除用户健康项目失败: " + err.Error(),
+			"error": i18n.T(ctx, "user_health_item_delete_failed") + err.Error(),
 		})
 		return
 	}
@@ -699,55 +826,26 @@ func DeleteInstitutionPlan(ctx *gin.Context) {
 	if err := tx.Unscoped().Where("plan_id = ?", input.PlanID).Delete(&models.Commentary{}).Error; err != nil {
 		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "删除套餐评论失败: " + err.Error(),
+			"error": i18n.T(ctx, "plan_commentary_delete_failed") + err.Error(),
 		})
 		return
 	}
 
-	// Delete PlanHeathItems and conditionally HealthItems associated with the plan (Hard Deletes)
-	var planHeathItems []models.PlanHeathItem
-	if err := tx.Where("plan_id = ?", input.PlanID).Find(&planHeathItems).Error; err != nil {
+	// Delete PlanHeathItems associated with the plan (Hard Delete); catalog HealthItems are shared and kept
+	if err := tx.Unscoped().Where("plan_id = ?", input.PlanID).Delete(&models.PlanHeathItem{}).Error; err != nil {
 		tx.Rollback()
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "查找套餐内体检项目失败: " + err.Error(),
-		})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "plan_item_relation_delete_failed") + err.Error()})
 		return
 	}
 
-	for _, phi := range planHeathItems {
-		// Delete PlanHeathItem
-		if err := tx.Unscoped().Where("id = ?", phi.ID).Delete(&models.PlanHeathItem{}).Error; err != nil {
-			tx.Rollback()
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "删除套餐内体检项目关联失败: " + err.Error()})
-			return
-		}
-
-		// Check if the related HealthItem is still used by other PlanHeathItems
-		var count int64
-		if err := tx.Model(&models.PlanHeathItem{}).Where("item_id = ?", phi.RelationHealthItemId).Count(&count).Error; err != nil {
-			tx.Rollback()
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "统计关联体检项目使用情况失败: " + err.Error()})
-			return
-		}
-
-		if count == 0 {
-			// If not used by others, delete the HealthItem
-			if err := tx.Unscoped().Where("id = ?", phi.RelationHealthItemId).Delete(&models.HealthItem{}).Error; err != nil {
-				tx.Rollback()
-				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "删除体检项目失败: " + err.Error()})
-				return
-			}
-		}
-	}
-
 	// Fetch the plan to be deleted
 	var plan models.Plan
 	if err := tx.Where("id = ?", input.PlanID).First(&plan).Error; err != nil {
 		tx.Rollback()
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "套餐不存在"})
+			ctx.JSON(http.StatusNotFound, gin.H{"error": i18n.T(ctx, "plan_not_found")})
 		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "查找套餐失败: " + err.Error()})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "plan_query_failed") + err.Error()})
 		}
 		return
 	}
@@ -767,37 +865,55 @@ func DeleteInstitutionPlan(ctx *gin.Context) {
 			var pkgCount int64
 			tx.Model(&models.UserPackage{}).Where("plan_id = ?", input.PlanID).Count(&pkgCount)
 			if pkgCount > 0 {
-				remainingRelations += fmt.Sprintf("用户套餐关联(%d) ", pkgCount)
+				remainingRelations += i18n.T(ctx, "remaining_user_packages", pkgCount)
 			}
 
 			// Check for user health items
 			var itemCount int64
 			tx.Model(&models.UserHealthItem{}).Where("plan_id = ?", input.PlanID).Count(&itemCount)
 			if itemCount > 0 {
-				remainingRelations += fmt.Sprintf("用户健康项目(%d) ", itemCount)
+				remainingRelations += i18n.T(ctx, "remaining_user_health_items", itemCount)
 			}
 
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "删除套餐失败: 存在外键约束，请先删除套餐相关的" + remainingRelations,
+				"error": i18n.T(ctx, "plan_delete_fk") + remainingRelations,
 			})
 		} else {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "删除套餐失败: " + err.Error(),
+				"error": i18n.T(ctx, "plan_delete_failed") + err.Error(),
 			})
 		}
 		return
 	}
 
+	// 已删除套餐的评论不再计入机构评分
+	if err := utils.RefreshRatings(tx, input.PlanID); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "rating_refresh_failed") + err.Error(),
+		})
+		return
+	}
+
 	// Commit the transaction
 	if err := tx.Commit().Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "提交事务失败: " + err.Error(),
+			"error": i18n.T(ctx, "commit_failed") + err.Error(),
 		})
 		return
 	}
+	utils.ReindexPlan(input.PlanID)
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "套餐删除成功",
+		"message": i18n.T(ctx, "plan_deleted"),
 	})
 
 }
+
+// nilIfZero 将 0 转换为 nil，用于表示"不限"的可选条件
+func nilIfZero(v uint) *uint {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
 	"net/http"
 
@@ -17,7 +19,7 @@ func DeleteInstitution(ctx *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
+			"error": i18n.T(ctx, "user_not_found"),
 		})
 		return
 	}
@@ -26,7 +28,7 @@ func DeleteInstitution(ctx *gin.Context) {
 	var institution models.Institution
 	if err := global.DB.First(&institution, institutionID).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "Institution not found",
+			"error": i18n.T(ctx, "institution_not_found"),
 		})
 		return
 	}
@@ -34,7 +36,7 @@ func DeleteInstitution(ctx *gin.Context) {
 	// 验证权限：仅允许机构所有者或管理员删除机构
 	if user.ID != institution.UserID && user.UserType != 2 {
 		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "You don't have permission to delete this institution",
+			"error": i18n.T(ctx, "institution_delete_forbidden"),
 		})
 		return
 	}
@@ -47,7 +49,7 @@ func DeleteInstitution(ctx *gin.Context) {
 	if err := tx.Where("institution_id = ?", institution.ID).Find(&plans).Error; err != nil {
 		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to query institution plans: " + err.Error(),
+			"error": i18n.T(ctx, "institution_plans_query_failed") + err.Error(),
 		})
 		return
 	}
@@ -63,7 +65,7 @@ func DeleteInstitution(ctx *gin.Context) {
 		if err := tx.Where("plan_id IN ?", planIDs).Delete(&models.PlanHeathItem{}).Error; err != nil {
 			tx.Rollback()
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to delete plan health items: " + err.Error(),
+				"error": i18n.T(ctx, "plan_health_items_delete_failed") + err.Error(),
 			})
 			return
 		}
@@ -72,7 +74,7 @@ func DeleteInstitution(ctx *gin.Context) {
 		if err := tx.Where("relation_institution_id = ?", institution.ID).Delete(&models.Plan{}).Error; err != nil {
 			tx.Rollback()
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to delete institution plans: " + err.Error(),
+				"error": i18n.T(ctx, "institution_plans_delete_failed") + err.Error(),
 			})
 			return
 		}
@@ -82,7 +84,7 @@ func DeleteInstitution(ctx *gin.Context) {
 	if err := tx.Delete(&institution).Error; err != nil {
 		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete institution: " + err.Error(),
+			"error": i18n.T(ctx, "institution_delete_failed") + err.Error(),
 		})
 		return
 	}
@@ -90,12 +92,13 @@ func DeleteInstitution(ctx *gin.Context) {
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to commit transaction: " + err.Error(),
+			"error": i18n.T(ctx, "commit_failed") + err.Error(),
 		})
 		return
 	}
+	utils.ReindexInstitution(institution.ID)
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "机构及相关数据已成功删除",
+		"message": i18n.T(ctx, "institution_deleted"),
 	})
 }
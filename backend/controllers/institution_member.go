@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetInstitutionMembers 查看机构的成员及其角色，机构主账号、成员与管理员可见
+func GetInstitutionMembers(ctx *gin.Context) {
+	user, institution, ok := memberInstitution(ctx)
+	if !ok {
+		return
+	}
+	if user.UserType != 2 && user.ID != institution.UserID {
+		role, err := utils.InstitutionRole(global.DB, institution.ID, user.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
+		}
+		if role == "" {
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error": i18n.T(ctx, "member_forbidden"),
+			})
+			return
+		}
+	}
+	var members []models.InstitutionMember
+	if err := global.DB.Where("institution_id = ?", institution.ID).Order("id").Find(&members).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"owner_id": institution.UserID,
+		"members":  members,
+	})
+}
+
+// SetInstitutionMember 机构主账号或管理员添加成员或修改成员角色及值班状态，成员须为机构账号
+func SetInstitutionMember(ctx *gin.Context) {
+	user, institution, ok := memberInstitution(ctx)
+	if !ok {
+		return
+	}
+	if user.UserType != 2 && user.ID != institution.UserID {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "member_forbidden"),
+		})
+		return
+	}
+	var input struct {
+		UserID uint   `json:"user_id" binding:"required"`
+		Role   string `json:"role" binding:"required"`
+		OnCall *bool  `json:"on_call"` // 不传时保持原值，新成员默认不值班
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+	if input.Role != models.MemberRoleEntry && input.Role != models.MemberRoleReviewer {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "member_invalid_role"),
+		})
+		return
+	}
+	if input.UserID == institution.UserID {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "member_is_owner"),
+		})
+		return
+	}
+	var target models.User
+	if err := global.DB.First(&target, input.UserID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+	if target.UserType != 3 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "member_not_institution_user"),
+		})
+		return
+	}
+
+	var member models.InstitutionMember
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("institution_id = ? AND user_id = ?", institution.ID, target.ID).First(&member).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		before := map[string]interface{}{"user_id": target.ID, "role": member.Role, "on_call": member.OnCall}
+		member.InstitutionID = institution.ID
+		member.UserID = target.ID
+		member.Role = input.Role
+		member.AddedBy = user.ID
+		if input.OnCall != nil {
+			member.OnCall = *input.OnCall
+		}
+		if err := tx.Save(&member).Error; err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditMemberUpdate,
+			TargetType: "institution",
+			TargetID:   institution.ID,
+			Before:     before,
+			After:      map[string]interface{}{"user_id": target.ID, "role": member.Role, "on_call": member.OnCall},
+		})
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "member_saved"),
+		"member":  member,
+	})
+}
+
+// DeleteInstitutionMember 机构主账号或管理员移除成员（物理删除），已录入结果的录入人记录保留
+func DeleteInstitutionMember(ctx *gin.Context) {
+	user, institution, ok := memberInstitution(ctx)
+	if !ok {
+		return
+	}
+	if user.UserType != 2 && user.ID != institution.UserID {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "member_forbidden"),
+		})
+		return
+	}
+	var member models.InstitutionMember
+	if err := global.DB.Where("institution_id = ? AND user_id = ?", institution.ID, ctx.Param("user_id")).First(&member).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "member_not_found"),
+		})
+		return
+	}
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&member).Error; err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditMemberUpdate,
+			TargetType: "institution",
+			TargetID:   institution.ID,
+			Before:     map[string]interface{}{"user_id": member.UserID, "role": member.Role},
+		})
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "member_removed"),
+	})
+}
+
+// memberInstitution 查找当前用户与路径中的机构
+func memberInstitution(ctx *gin.Context) (user models.User, institution models.Institution, ok bool) {
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return user, institution, false
+	}
+	if err := global.DB.First(&institution, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "institution_not_found"),
+		})
+		return user, institution, false
+	}
+	return user, institution, true
+}
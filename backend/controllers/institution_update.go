@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
 	"net/http"
 
@@ -30,7 +32,7 @@ func UpdateInstitution(ctx *gin.Context) {
 	var institution models.Institution
 	if err := global.DB.First(&institution, institutionID).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "Institution not found",
+			"error": i18n.T(ctx, "institution_not_found"),
 		})
 		return
 	}
@@ -40,7 +42,7 @@ func UpdateInstitution(ctx *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
+			"error": i18n.T(ctx, "user_not_found"),
 		})
 		return
 	}
@@ -48,7 +50,7 @@ func UpdateInstitution(ctx *gin.Context) {
 	// 验证权限：仅允许机构所有者或管理员更新机构信息
 	if user.ID != institution.UserID && user.UserType != 2 {
 		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "You don't have permission to update this institution",
+			"error": i18n.T(ctx, "institution_update_forbidden"),
 		})
 		return
 	}
@@ -61,14 +63,14 @@ func UpdateInstitution(ctx *gin.Context) {
 			Where("institution_name = ? AND id != ?", *input.InstitutionName, institution.ID).
 			Count(&count).Error; err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "数据库错误: " + err.Error(),
+				"error": i18n.T(ctx, "db_error") + err.Error(),
 			})
 			return
 		}
 
 		if count > 0 {
 			ctx.JSON(http.StatusConflict, gin.H{
-				"error": "机构名称已存在",
+				"error": i18n.T(ctx, "institution_name_exists"),
 			})
 			return
 		}
@@ -92,13 +94,14 @@ func UpdateInstitution(ctx *gin.Context) {
 	// 保存更新
 	if err := global.DB.Save(&institution).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "更新机构信息失败: " + err.Error(),
+			"error": i18n.T(ctx, "institution_update_failed") + err.Error(),
 		})
 		return
 	}
+	utils.ReindexInstitution(institution.ID)
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message":     "机构信息更新成功",
+		"message":     i18n.T(ctx, "institution_updated"),
 		"institution": institution,
 	})
 }
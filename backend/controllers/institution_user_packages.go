@@ -1,8 +1,11 @@
 package controllers
 
 import (
+	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
+	"HealthCare/backend/security"
 	"net/http"
 	"strconv"
 
@@ -16,7 +19,7 @@ func GetUserPackagesByInstitution(c *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid user",
+			"error": i18n.T(c, "invalid_user"),
 		})
 		return
 	}
@@ -24,7 +27,7 @@ func GetUserPackagesByInstitution(c *gin.Context) {
 	// 检查是否为机构用户 (UserType = 3)
 	if user.UserType != 3 && user.UserType != 2 { // 不是机构用户也不是管理员
 		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Permission denied: only institution users can view user packages",
+			"error": i18n.T(c, "only_institution_view_packages"),
 		})
 		return
 	}
@@ -35,7 +38,7 @@ func GetUserPackagesByInstitution(c *gin.Context) {
 		var institution models.Institution
 		if err := global.DB.Where("user_id = ?", user.ID).Select("id").First(&institution).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Institution not found for this user",
+				"error": i18n.T(c, "institution_for_user_not_found"),
 			})
 			return
 		}
@@ -47,19 +50,39 @@ func GetUserPackagesByInstitution(c *gin.Context) {
 			id, err := strconv.ParseUint(institutionIDStr, 10, 32)
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{
-					"error": "Invalid institution ID",
+					"error": i18n.T(c, "invalid_institution_id"),
 				})
 				return
 			}
 			institutionID = uint(id)
 		} else {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Institution ID is required for admin users",
+				"error": i18n.T(c, "institution_id_required"),
 			})
 			return
 		}
 	}
 
+	page, err := utils.ParsePagination(c, map[string]string{
+		"id":         "user_packages.id",
+		"status":     "user_packages.status",
+		"created_at": "user_packages.created_at",
+	}, "-id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(c, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+	// date_from / date_to 按预约（创建）时间筛选
+	dateFrom, dateTo, err := utils.ParseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(c, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
 	// 构造查询参数
 	query := global.DB.Table("user_packages").
 		Select(`user_packages.id, 
@@ -67,10 +90,12 @@ func GetUserPackagesByInstitution(c *gin.Context) {
                  user_packages.plan_id, 
                  user_packages.institution_id, 
                  user_packages.status,
+                 user_packages.plan_version_id,
                  users.name as user_name, 
-                 plans.plan_name`).
+                 COALESCE(plan_versions.plan_name, plans.plan_name) as plan_name`).
 		Joins("JOIN users ON user_packages.user_id = users.id").
 		Joins("JOIN plans ON user_packages.plan_id = plans.id").
+		Joins("LEFT JOIN plan_versions ON user_packages.plan_version_id = plan_versions.id").
 		Where("user_packages.institution_id = ?", institutionID)
 
 	// 处理搜索参数
@@ -78,6 +103,11 @@ func GetUserPackagesByInstitution(c *gin.Context) {
 		query = query.Where("users.name LIKE ?", "%"+userName+"%")
 	}
 
+	// 手机号加密保存，只能按盲索引精确匹配
+	if phone := c.Query("phone"); phone != "" {
+		query = query.Where("users.phone_bidx = ?", security.BlindIndex("phone", phone))
+	}
+
 	if planName := c.Query("plan_name"); planName != "" {
 		query = query.Where("plans.plan_name LIKE ?", "%"+planName+"%")
 	}
@@ -88,6 +118,7 @@ func GetUserPackagesByInstitution(c *gin.Context) {
 			query = query.Where("user_packages.status = ?", status)
 		}
 	}
+	query = utils.WhereRange(query, "user_packages.created_at", dateFrom, dateTo)
 
 	// 定义结果结构
 	type UserPackageInfo struct {
@@ -96,6 +127,7 @@ func GetUserPackagesByInstitution(c *gin.Context) {
 		PlanID         uint   `json:"plan_id"`
 		InstitutionID  uint   `json:"institution_id"`
 		Status         uint8  `json:"status"`
+		PlanVersionID  *uint  `json:"plan_version_id"`
 		UserName       string `json:"user_name"`
 		PlanName       string `json:"plan_name"`
 		CompletedItems int    `json:"completed_items"`
@@ -105,20 +137,23 @@ func GetUserPackagesByInstitution(c *gin.Context) {
 	var userPackages []UserPackageInfo
 
 	// 执行查询
-	if err := query.Find(&userPackages).Error; err != nil {
+	pageInfo, err := utils.Paginate(query, page, &userPackages)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve user packages: " + err.Error(),
+			"error": i18n.T(c, "user_packages_fetch_failed") + err.Error(),
 		})
 		return
 	}
 
 	// 补充每个套餐的完成项目数量
 	for i := range userPackages {
-		// 获取套餐总项目数
+		// 获取套餐总项目数（按用户所购版本）
 		var totalItems int64
-		if err := global.DB.Model(&models.PlanHeathItem{}).
-			Where("plan_id = ?", userPackages[i].PlanID).
-			Count(&totalItems).Error; err != nil {
+		totalQuery := global.DB.Model(&models.PlanHeathItem{}).Where("plan_id = ?", userPackages[i].PlanID)
+		if userPackages[i].PlanVersionID != nil {
+			totalQuery = global.DB.Model(&models.PlanVersionItem{}).Where("plan_version_id = ?", *userPackages[i].PlanVersionID)
+		}
+		if err := totalQuery.Count(&totalItems).Error; err != nil {
 			continue
 		}
 		userPackages[i].TotalItems = int(totalItems)
@@ -135,6 +170,7 @@ func GetUserPackagesByInstitution(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"packages": userPackages,
+		"packages":   userPackages,
+		"pagination": pageInfo,
 	})
 }
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notificationView 通知及按读者语言生成的消息文本
+type notificationView struct {
+	models.Notification
+	Message string `json:"message"`
+}
+
+// GetNotifications 查看当前用户的通知，unread=true 时只返回未读通知
+func GetNotifications(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return
+	}
+	page, err := utils.ParsePagination(ctx, map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+	}, "-id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	query := global.DB.Model(&models.Notification{}).Where("user_id = ?", user.ID)
+	if ctx.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+	var notifications []models.Notification
+	pageInfo, err := utils.Paginate(query, page, &notifications)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	var unread int64
+	if err := global.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", user.ID).Count(&unread).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+
+	itemIDs := make([]uint, 0, len(notifications))
+	packageIDs := make([]uint, 0, len(notifications))
+	for _, n := range notifications {
+		if n.HealthItemID != nil {
+			itemIDs = append(itemIDs, *n.HealthItemID)
+		}
+		if n.UserPackageID != nil {
+			packageIDs = append(packageIDs, *n.UserPackageID)
+		}
+	}
+	// 客户姓名按套餐查找，账号注销后姓名已清空
+	var patients []struct {
+		ID   uint
+		Name string
+	}
+	if len(packageIDs) > 0 {
+		if err := global.DB.Table("user_packages").Select("user_packages.id, users.name").
+			Joins("JOIN users ON users.id = user_packages.user_id").
+			Where("user_packages.id IN ?", packageIDs).Scan(&patients).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
+		}
+	}
+	patientNames := make(map[uint]string, len(patients))
+	for _, p := range patients {
+		patientNames[p.ID] = p.Name
+	}
+	var items []models.HealthItem
+	if len(itemIDs) > 0 {
+		if err := global.DB.Unscoped().Select("id, item_name").Where("id IN ?", itemIDs).Find(&items).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
+		}
+	}
+	baseNames := make(map[uint]string, len(items))
+	for _, item := range items {
+		baseNames[item.ID] = item.ItemName
+	}
+	itemNames := utils.LocalizedItemNames(i18n.Lang(ctx), itemIDs)
+
+	views := make([]notificationView, 0, len(notifications))
+	for _, n := range notifications {
+		itemName, patientName := "", ""
+		if n.HealthItemID != nil {
+			itemName = utils.LocalizeItemName(itemNames, *n.HealthItemID, baseNames[*n.HealthItemID])
+		}
+		if n.UserPackageID != nil {
+			patientName = patientNames[*n.UserPackageID]
+		}
+		views = append(views, notificationView{
+			Notification: n,
+			Message:      i18n.T(ctx, "notification_"+n.Kind, patientName, itemName),
+		})
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"notifications": views,
+		"unread":        unread,
+		"pagination":    pageInfo,
+	})
+}
+
+// MarkNotificationRead 将一条通知标记为已读
+func MarkNotificationRead(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return
+	}
+	result := global.DB.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", ctx.Param("id"), user.ID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + result.Error.Error(),
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		global.DB.Model(&models.Notification{}).Where("id = ? AND user_id = ?", ctx.Param("id"), user.ID).Count(&count)
+		if count == 0 {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": i18n.T(ctx, "notification_not_found"),
+			})
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "notification_marked_read"),
+	})
+}
+
+// MarkAllNotificationsRead 将当前用户的全部未读通知标记为已读
+func MarkAllNotificationsRead(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return
+	}
+	result := global.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", user.ID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + result.Error.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "notifications_marked_read", result.RowsAffected),
+	})
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxComparePlans 单次最多对比的套餐数量
+const MaxComparePlans = 5
+
+// ComparePlan 对比表中的一列（一个套餐）
+type ComparePlan struct {
+	PlanID          uint     `json:"plan_id"`
+	PlanName        string   `json:"plan_name"`
+	InstitutionID   uint     `json:"institution_id"`
+	InstitutionName string   `json:"institution_name"`
+	Price           float64  `json:"price"`
+	ItemCount       int      `json:"item_count"`
+	PricePerItem    *float64 `json:"price_per_item"` // 套餐没有项目时为 null
+	SuitableFor     string   `json:"suitable_for"`
+	Description     string   `json:"description"`
+	Rating          *float64 `json:"rating"` // 暂无评分时为 null
+	RatingCount     int64    `json:"rating_count"`
+}
+
+// CompareItem 对比表中的一行（一个健康项目），Included 与 plans 的顺序一一对应
+type CompareItem struct {
+	ItemID   uint     `json:"item_id"`
+	ItemName string   `json:"item_name"`
+	Included []bool   `json:"included"`
+	Details  []string `json:"details"` // 各套餐中该项目的描述，未包含时为空字符串
+}
+
+// ComparePlans 对比多个套餐
+// 查询参数 ids 为逗号分隔的套餐ID（2 到 MaxComparePlans 个），返回套餐信息与项目包含矩阵
+// 项目按被包含的套餐数量降序排列，各套餐共有的项目在前
+func ComparePlans(c *gin.Context) {
+	var planIDs []uint
+	seen := make(map[uint]bool)
+	for _, s := range strings.Split(c.Query("ids"), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(c, "invalid_plan_id"),
+			})
+			return
+		}
+		if !seen[uint(id)] {
+			seen[uint(id)] = true
+			planIDs = append(planIDs, uint(id))
+		}
+	}
+	if len(planIDs) < 2 || len(planIDs) > MaxComparePlans {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(c, "compare_plan_count", MaxComparePlans),
+		})
+		return
+	}
+
+	var plans []models.Plan
+	if err := global.DB.Preload("ThisInstitution").Where("id IN ?", planIDs).Find(&plans).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(c, "db_error") + err.Error(),
+		})
+		return
+	}
+	planByID := make(map[uint]models.Plan, len(plans))
+	for _, plan := range plans {
+		planByID[plan.ID] = plan
+	}
+	for _, id := range planIDs {
+		if _, ok := planByID[id]; !ok {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": i18n.T(c, "compare_plan_not_found", id),
+			})
+			return
+		}
+	}
+
+	var planItems []struct {
+		PlanID          uint
+		ItemID          uint
+		ItemName        string
+		ItemDescription string
+	}
+	if err := global.DB.Model(&models.PlanHeathItem{}).
+		Select("plan_heath_items.plan_id, plan_heath_items.health_item_id as item_id, health_items.item_name, plan_heath_items.item_description").
+		Joins("JOIN health_items ON plan_heath_items.health_item_id = health_items.id").
+		Where("plan_heath_items.plan_id IN ?", planIDs).
+		Find(&planItems).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(c, "plan_items_fetch_failed") + err.Error(),
+		})
+		return
+	}
+
+	column := make(map[uint]int, len(planIDs))
+	for i, id := range planIDs {
+		column[id] = i
+	}
+
+	rows := make(map[uint]*CompareItem)
+	var itemIDs []uint
+	itemCounts := make([]int, len(planIDs))
+	for _, pi := range planItems {
+		row, ok := rows[pi.ItemID]
+		if !ok {
+			row = &CompareItem{
+				ItemID:   pi.ItemID,
+				ItemName: pi.ItemName,
+				Included: make([]bool, len(planIDs)),
+				Details:  make([]string, len(planIDs)),
+			}
+			rows[pi.ItemID] = row
+			itemIDs = append(itemIDs, pi.ItemID)
+		}
+		col := column[pi.PlanID]
+		if !row.Included[col] {
+			row.Included[col] = true
+			itemCounts[col]++
+		}
+		row.Details[col] = pi.ItemDescription
+	}
+
+	itemNames := utils.LocalizedItemNames(i18n.Lang(c), itemIDs)
+	items := make([]CompareItem, 0, len(rows))
+	for _, id := range itemIDs {
+		row := rows[id]
+		row.ItemName = utils.LocalizeItemName(itemNames, id, row.ItemName)
+		items = append(items, *row)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		ci, cj := countTrue(items[i].Included), countTrue(items[j].Included)
+		if ci != cj {
+			return ci > cj
+		}
+		return items[i].ItemName < items[j].ItemName
+	})
+
+	result := make([]ComparePlan, 0, len(planIDs))
+	for i, id := range planIDs {
+		plan := planByID[id]
+		cp := ComparePlan{
+			PlanID:          plan.ID,
+			PlanName:        plan.PlanName,
+			InstitutionID:   plan.RelationInstitutionID,
+			InstitutionName: plan.ThisInstitution.InstitutionName,
+			Price:           plan.PlanPrice,
+			ItemCount:       itemCounts[i],
+			SuitableFor:     plan.SuitableFor,
+			Description:     plan.Description,
+			RatingCount:     int64(plan.RatingCount),
+		}
+		if plan.RatingCount > 0 {
+			rating := plan.RatingAvg
+			cp.Rating = &rating
+		}
+		if cp.ItemCount > 0 {
+			perItem := math.Round(plan.PlanPrice/float64(cp.ItemCount)*100) / 100
+			cp.PricePerItem = &perItem
+		}
+		result = append(result, cp)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"plans": result,
+		"items": items,
+	})
+}
+
+func countTrue(values []bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
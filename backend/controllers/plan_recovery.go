@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
 	"net/http"
 	"strconv"
@@ -16,7 +18,7 @@ func RecoverDeletedPlan(c *gin.Context) {
 	pid, err := strconv.ParseUint(planID, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的套餐ID",
+			"error": i18n.T(c, "invalid_plan_id"),
 		})
 		return
 	}
@@ -26,7 +28,7 @@ func RecoverDeletedPlan(c *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid user",
+			"error": i18n.T(c, "invalid_user"),
 		})
 		return
 	}
@@ -34,7 +36,7 @@ func RecoverDeletedPlan(c *gin.Context) {
 	// 只有管理员可以恢复已删除的套餐
 	if user.UserType != 2 { // 2表示管理员
 		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Permission denied: only admins can recover deleted plans",
+			"error": i18n.T(c, "only_admin_recover_plans"),
 		})
 		return
 	}
@@ -43,7 +45,7 @@ func RecoverDeletedPlan(c *gin.Context) {
 	var plan models.Plan
 	if err := global.DB.Unscoped().First(&plan, pid).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "套餐不存在，无法恢复",
+			"error": i18n.T(c, "plan_not_found_unrecoverable"),
 		})
 		return
 	}
@@ -51,7 +53,7 @@ func RecoverDeletedPlan(c *gin.Context) {
 	// 检查套餐是否已被删除
 	if plan.DeletedAt.Time.IsZero() {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "套餐未被删除，无需恢复",
+			"error": i18n.T(c, "plan_not_deleted"),
 		})
 		return
 	}
@@ -59,7 +61,7 @@ func RecoverDeletedPlan(c *gin.Context) {
 	// 恢复套餐
 	if err := global.DB.Unscoped().Model(&models.Plan{}).Where("id = ?", pid).Update("deleted_at", nil).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "恢复套餐失败: " + err.Error(),
+			"error": i18n.T(c, "plan_recover_failed") + err.Error(),
 		})
 		return
 	}
@@ -67,13 +69,20 @@ func RecoverDeletedPlan(c *gin.Context) {
 	// 恢复相关的PlanHealthItem（如果需要）
 	if err := global.DB.Unscoped().Model(&models.PlanHeathItem{}).Where("plan_id = ?", pid).Update("deleted_at", nil).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "恢复套餐项目失败: " + err.Error(),
+			"error": i18n.T(c, "plan_items_recover_failed") + err.Error(),
 		})
 		return
 	}
+	if err := utils.RefreshRatings(global.DB, uint(pid)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(c, "rating_refresh_failed") + err.Error(),
+		})
+		return
+	}
+	utils.ReindexPlan(uint(pid))
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "套餐恢复成功",
+		"message": i18n.T(c, "plan_recovered"),
 		"plan_id": pid,
 	})
 }
@@ -85,7 +94,7 @@ func GetDeletedPlans(c *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid user",
+			"error": i18n.T(c, "invalid_user"),
 		})
 		return
 	}
@@ -93,7 +102,42 @@ func GetDeletedPlans(c *gin.Context) {
 	// 只有管理员可以查看已删除的套餐
 	if user.UserType != 2 { // 2表示管理员
 		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Permission denied: only admins can view deleted plans",
+			"error": i18n.T(c, "only_admin_view_deleted_plans"),
+		})
+		return
+	}
+
+	page, err := utils.ParsePagination(c, map[string]string{
+		"id":         "plans.id",
+		"plan_price": "plans.plan_price",
+		"deleted_at": "plans.deleted_at",
+	}, "-deleted_at")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(c, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	institutionID, err := utils.ParseUintQuery(c, "institution_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(c, "invalid_institution_id"),
+		})
+		return
+	}
+	minPrice, maxPrice, err := utils.ParseFloatRange(c, "min_price", "max_price")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(c, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+	// date_from / date_to 按删除时间筛选
+	dateFrom, dateTo, err := utils.ParseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(c, "invalid_query_params") + err.Error(),
 		})
 		return
 	}
@@ -109,15 +153,20 @@ func GetDeletedPlans(c *gin.Context) {
 		ItemCount     int64   `json:"item_count"`
 	}
 
-	err := global.DB.Table("plans").
+	query := global.DB.Table("plans").
 		Select("plans.id, plans.plan_name, plans.institution_id, institutions.institution_name as inst_name, plans.plan_price, plans.deleted_at").
 		Joins("JOIN institutions ON plans.institution_id = institutions.id").
-		Where("plans.deleted_at IS NOT NULL").
-		Find(&plans).Error
+		Where("plans.deleted_at IS NOT NULL")
+	if institutionID != nil {
+		query = query.Where("plans.institution_id = ?", *institutionID)
+	}
+	query = utils.WhereRange(query, "plans.plan_price", minPrice, maxPrice)
+	query = utils.WhereRange(query, "plans.deleted_at", dateFrom, dateTo)
 
+	pageInfo, err := utils.Paginate(query, page, &plans)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取已删除套餐列表失败: " + err.Error(),
+			"error": i18n.T(c, "deleted_plans_fetch_failed") + err.Error(),
 		})
 		return
 	}
@@ -133,5 +182,6 @@ func GetDeletedPlans(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"deleted_plans": plans,
+		"pagination":    pageInfo,
 	})
 }
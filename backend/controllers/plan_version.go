@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPlanVersions 获取套餐的历史版本列表（不含项目），按版本号降序
+func GetPlanVersions(c *gin.Context) {
+	pid, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(c, "invalid_plan_id"),
+		})
+		return
+	}
+
+	var plan models.Plan
+	if err := global.DB.Unscoped().First(&plan, pid).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(c, "plan_not_found"),
+		})
+		return
+	}
+
+	var versions []models.PlanVersion
+	if err := global.DB.Where("plan_id = ?", pid).Order("version DESC").Find(&versions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(c, "plan_versions_fetch_failed") + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"plan_id":         plan.ID,
+		"current_version": plan.CurrentVersion,
+		"versions":        versions,
+	})
+}
+
+// GetPlanVersion 获取套餐指定版本的内容及项目
+func GetPlanVersion(c *gin.Context) {
+	pid, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(c, "invalid_plan_id"),
+		})
+		return
+	}
+	number, err := strconv.ParseUint(c.Param("version"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(c, "invalid_plan_version"),
+		})
+		return
+	}
+
+	var version models.PlanVersion
+	if err := global.DB.Where("plan_id = ? AND version = ?", pid, number).First(&version).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(c, "plan_version_not_found"),
+		})
+		return
+	}
+
+	loaded, err := utils.LoadPlanVersion(global.DB, &version.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(c, "plan_versions_fetch_failed") + err.Error(),
+		})
+		return
+	}
+
+	itemIDs := make([]uint, 0, len(loaded.Items))
+	for _, item := range loaded.Items {
+		itemIDs = append(itemIDs, item.RelationHealthItemId)
+	}
+	itemNames := utils.LocalizedItemNames(i18n.Lang(c), itemIDs)
+	for i := range loaded.Items {
+		loaded.Items[i].ItemName = utils.LocalizeItemName(itemNames, loaded.Items[i].RelationHealthItemId, loaded.Items[i].ItemName)
+	}
+
+	c.JSON(http.StatusOK, loaded)
+}
@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"HealthCare/backend/config"
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 推荐打分权重
+const (
+	scoreOwnRisk     = 3.0 // 套餐针对的风险因素与本人异常结果相关
+	scoreFamilyRisk  = 1.5 // 套餐针对的风险因素与家人异常结果相关
+	scoreItemCover   = 1.0 // 套餐包含可复查本人异常的项目（未标注风险因素时）
+	scoreAgeTargeted = 1.0 // 套餐限定了年龄范围且本人在范围内
+	scoreSexTargeted = 0.5 // 套餐限定了性别且与本人一致
+
+	defaultRecommendationLimit = 10
+	maxRecommendationLimit     = 50
+)
+
+// RiskFactorSource 用户画像中的一个风险因素及其来源
+type RiskFactorSource struct {
+	Code   string   `json:"code"`
+	Name   string   `json:"name"`
+	Items  []string `json:"items"`  // 本人结果异常的项目
+	Family []string `json:"family"` // 结果异常的家人（关系称谓）
+}
+
+// Recommendation 推荐的套餐，reasons 为推荐理由
+type Recommendation struct {
+	PlanID          uint     `json:"plan_id"`
+	PlanName        string   `json:"plan_name"`
+	InstitutionID   uint     `json:"institution_id"`
+	InstitutionName string   `json:"institution_name"`
+	Price           float64  `json:"price"`
+	Score           float64  `json:"score"`
+	Reasons         []string `json:"reasons"`
+}
+
+// GetRecommendations 为当前登录用户推荐套餐
+// 按年龄、性别过滤已审核机构的套餐，再根据本人与家人（已确认的家庭关系）最近一次异常结果
+// 对应的风险因素打分排序；查询参数 limit 默认 10，最大 50
+func GetRecommendations(ctx *gin.Context) {
+	limit := defaultRecommendationLimit
+	if s := ctx.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxRecommendationLimit {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "invalid_recommendation_limit", maxRecommendationLimit),
+			})
+			return
+		}
+		limit = n
+	}
+
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+	lang := i18n.Lang(ctx)
+	age, hasAge := utils.Age(user.Birthday, time.Now())
+
+	// 已确认的家人及其称谓
+	var relations []models.Family
+	if err := global.DB.Preload("ThisUser").Where("(user_id = ? OR relative_id = ?) AND status = 1", user.ID, user.ID).
+		Find(&relations).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "family_fetch_failed"),
+		})
+		return
+	}
+	// 家族史只使用授予了查看记录权限的家人的结果
+	relativeLabels := make(map[uint]string)
+	for _, rel := range relations {
+		relativeID := rel.RelativeID
+		if rel.RelativeID == user.ID {
+			relativeID = rel.UserID
+		}
+		err := utils.AuthorizeFamilyAccess(ctx, relativeID, user.ID, models.ScopeViewRecords)
+		if errors.Is(err, utils.ErrFamilyScopeDenied) {
+			continue
+		}
+		if err != nil {
+			respondFamilyAccessError(ctx, err)
+			return
+		}
+		relativeLabels[relativeID] = utils.RelationshipFrom(rel, user.ID)
+	}
+
+	userIDs := []uint{user.ID}
+	for id := range relativeLabels {
+		userIDs = append(userIDs, id)
+	}
+	var results []models.UserHealthItem
+	if err := global.DB.Preload("ThisHeathItem").Scopes(utils.ReleasedResults).
+		Where("user_id IN ?", userIDs).
+		Order("created_at DESC").
+		Find(&results).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "health_items_fetch_failed") + err.Error(),
+		})
+		return
+	}
+
+	var resultItemIDs []uint
+	for _, r := range results {
+		resultItemIDs = append(resultItemIDs, r.RelationHealthItemId)
+	}
+	itemNames := utils.LocalizedItemNames(lang, resultItemIDs)
+
+	// 每人每个项目只看最近一次结果
+	factors := make(map[string]*RiskFactorSource)
+	var factorOrder []string
+	seen := make(map[[2]uint]bool)
+	for _, r := range results {
+		key := [2]uint{r.RelationUserId, r.RelationHealthItemId}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if !utils.IsAbnormal(r.ItemValue, r.ThisHeathItem) {
+			continue
+		}
+		itemName := utils.LocalizeItemName(itemNames, r.RelationHealthItemId, r.ThisHeathItem.ItemName)
+		for _, code := range utils.RiskFactorsForItem(r.ThisHeathItem.ItemName) {
+			factor, ok := factors[code]
+			if !ok {
+				factor = &RiskFactorSource{Code: code, Name: utils.RiskFactorName(lang, code), Items: []string{}, Family: []string{}}
+				factors[code] = factor
+				factorOrder = append(factorOrder, code)
+			}
+			if r.RelationUserId == user.ID {
+				factor.Items = appendUnique(factor.Items, itemName)
+			} else {
+				factor.Family = appendUnique(factor.Family, relativeLabels[r.RelationUserId])
+			}
+		}
+	}
+
+	var plans []models.Plan
+	if err := global.DB.Preload("ThisInstitution").
+		Joins("JOIN institutions ON institutions.id = plans.institution_id AND institutions.deleted_at IS NULL").
+		Where("institutions.status = 1").
+		Find(&plans).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+
+	planIDs := make([]uint, 0, len(plans))
+	for _, plan := range plans {
+		planIDs = append(planIDs, plan.ID)
+	}
+	var planItems []struct {
+		PlanID   uint
+		ItemID   uint
+		ItemName string
+	}
+	if len(planIDs) > 0 {
+		if err := global.DB.Model(&models.PlanHeathItem{}).
+			Select("plan_heath_items.plan_id, plan_heath_items.health_item_id as item_id, health_items.item_name").
+			Joins("JOIN health_items ON plan_heath_items.health_item_id = health_items.id").
+			Where("plan_heath_items.plan_id IN ?", planIDs).
+			Find(&planItems).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "plan_items_fetch_failed") + err.Error(),
+			})
+			return
+		}
+	}
+	// 套餐中可复查各风险因素的项目
+	coverage := make(map[uint]map[string][]string)
+	for _, pi := range planItems {
+		for _, code := range utils.RiskFactorsForItem(pi.ItemName) {
+			if coverage[pi.PlanID] == nil {
+				coverage[pi.PlanID] = make(map[string][]string)
+			}
+			coverage[pi.PlanID][code] = appendUnique(coverage[pi.PlanID][code], pi.ItemName)
+		}
+	}
+
+	recommendations := make([]Recommendation, 0, len(plans))
+	for _, plan := range plans {
+		if hasAge && ((plan.MinAge != nil && uint(age) < *plan.MinAge) || (plan.MaxAge != nil && uint(age) > *plan.MaxAge)) {
+			continue
+		}
+		if plan.Sex != "" && user.Gender != "" && !strings.EqualFold(plan.Sex, user.Gender) {
+			continue
+		}
+
+		rec := Recommendation{
+			PlanID:          plan.ID,
+			PlanName:        plan.PlanName,
+			InstitutionID:   plan.RelationInstitutionID,
+			InstitutionName: plan.ThisInstitution.InstitutionName,
+			Price:           plan.PlanPrice,
+			Reasons:         []string{},
+		}
+
+		targeted := make(map[string]bool)
+		for _, code := range utils.ParseRiskFactors(plan.RiskFactors) {
+			targeted[code] = true
+			factor, ok := factors[code]
+			if !ok {
+				continue
+			}
+			if len(factor.Items) > 0 {
+				rec.Score += scoreOwnRisk
+				rec.Reasons = append(rec.Reasons, i18n.T(ctx, "recommend_reason_own_risk", strings.Join(factor.Items, "、"), factor.Name))
+			}
+			if len(factor.Family) > 0 {
+				rec.Score += scoreFamilyRisk
+				rec.Reasons = append(rec.Reasons, i18n.T(ctx, "recommend_reason_family_risk", strings.Join(factor.Family, "、"), factor.Name))
+			}
+		}
+		for _, code := range factorOrder {
+			factor := factors[code]
+			covered := coverage[plan.ID][code]
+			if targeted[code] || len(factor.Items) == 0 || len(covered) == 0 {
+				continue
+			}
+			rec.Score += scoreItemCover
+			rec.Reasons = append(rec.Reasons, i18n.T(ctx, "recommend_reason_item_cover", strings.Join(covered, "、"), factor.Name))
+		}
+		if hasAge && (plan.MinAge != nil || plan.MaxAge != nil) {
+			rec.Score += scoreAgeTargeted
+			rec.Reasons = append(rec.Reasons, i18n.T(ctx, "recommend_reason_age", age))
+		}
+		if plan.Sex != "" && user.Gender != "" {
+			rec.Score += scoreSexTargeted
+			rec.Reasons = append(rec.Reasons, i18n.T(ctx, "recommend_reason_sex_"+strings.ToUpper(plan.Sex)))
+		}
+		if len(rec.Reasons) == 0 {
+			rec.Reasons = append(rec.Reasons, i18n.T(ctx, "recommend_reason_general"))
+		}
+		recommendations = append(recommendations, rec)
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		if recommendations[i].Score != recommendations[j].Score {
+			return recommendations[i].Score > recommendations[j].Score
+		}
+		return recommendations[i].Price < recommendations[j].Price
+	})
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+
+	profileFactors := make([]RiskFactorSource, 0, len(factorOrder))
+	for _, code := range factorOrder {
+		profileFactors = append(profileFactors, *factors[code])
+	}
+	profile := gin.H{
+		"gender":       user.Gender,
+		"age":          nil,
+		"risk_factors": profileFactors,
+	}
+	if hasAge {
+		profile["age"] = age
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"profile":         profile,
+		"recommendations": recommendations,
+	})
+}
+
+// GetRiskFactors 返回可用于套餐适用条件的风险因素列表
+func GetRiskFactors(ctx *gin.Context) {
+	lang := i18n.Lang(ctx)
+	factors := make([]gin.H, 0, len(config.AppConfig.RiskFactors))
+	for _, factor := range config.AppConfig.RiskFactors {
+		factors = append(factors, gin.H{
+			"code": factor.Code,
+			"name": utils.RiskFactorName(lang, factor.Code),
+		})
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"risk_factors": factors,
+	})
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"HealthCare/backend/config"
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reportVerifyPath 报告验证接口的路径，需与路由 /api/v1/reports/verify/:code 保持一致
+const reportVerifyPath = "/api/v1/reports/verify/"
+
+// GetUserPackageReport 生成已完成用户套餐的 PDF 体检报告
+// 本人、获得 view_records 授权的家人、套餐所属机构的主账号与成员、管理员可以下载
+func GetUserPackageReport(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	var pkg models.UserPackage
+	if err := global.DB.Preload("User").Preload("Institution").First(&pkg, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "package_not_found"),
+		})
+		return
+	}
+	staff, err := utils.InstitutionStaff(global.DB, pkg.InstitutionID, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	if pkg.UserID != user.ID && !staff {
+		if err := utils.AuthorizeFamilyAccess(ctx, pkg.UserID, user.ID, models.ScopeViewRecords); err != nil {
+			respondFamilyAccessError(ctx, err)
+			return
+		}
+	}
+	if pkg.Status != 1 {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "report_package_not_completed"),
+		})
+		return
+	}
+	// 报告是对外出具的正式文件，结果审核发布后才能生成
+	if pkg.ReviewStatus != models.ReviewReleased {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "results_not_released"),
+		})
+		return
+	}
+
+	lang := i18n.Lang(ctx)
+	report, err := utils.BuildCheckupReport(global.DB, pkg, lang)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	issue, err := utils.IssueReport(global.DB, report, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	verifyURL := strings.TrimRight(config.AppConfig.App.PublicURL, "/") + reportVerifyPath + issue.Code
+	data, err := utils.RenderReportPDF(report, issue, verifyURL, lang)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "report_render_failed") + err.Error(),
+		})
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="report-`+strconv.FormatUint(uint64(pkg.ID), 10)+`.pdf"`)
+	ctx.Data(http.StatusOK, "application/pdf", data)
+}
+
+// VerifyReport 扫描报告二维码访问的真伪验证接口，无需登录；
+// 只返回出具机构、套餐与脱敏后的姓名，modified 表示报告出具后检查结果已被修改
+func VerifyReport(ctx *gin.Context) {
+	var issue models.ReportIssue
+	if err := global.DB.Preload("UserPackage.User").Preload("UserPackage.Institution").
+		Where("code = ?", ctx.Param("code")).First(&issue).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"valid": false,
+			"error": i18n.T(ctx, "report_verify_not_found"),
+		})
+		return
+	}
+
+	report, err := utils.BuildCheckupReport(global.DB, issue.UserPackage, i18n.Lang(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	modified := report.HashFor(issue.HashVersion) != issue.ContentHash
+	message := i18n.T(ctx, "report_verified")
+	if modified {
+		message = i18n.T(ctx, "report_verified_modified")
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"valid":            true,
+		"modified":         modified,
+		"message":          message,
+		"code":             issue.Code,
+		"issued_at":        issue.CreatedAt,
+		"institution_name": issue.UserPackage.Institution.InstitutionName,
+		"plan_name":        report.PlanName,
+		"patient_name":     utils.MaskName(issue.UserPackage.User.Name),
+	})
+}
@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/fhir"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxImportBodySize 一次导入请求的最大长度
+const maxImportBodySize = 5 << 20
+
+// ImportLabResults 导入外部系统的检验结果，请求体为 HL7 v2 ORU^R01 消息（可多条）或 FHIR Bundle
+// 格式由 format 参数（hl7v2、fhir）或 Content-Type 指定，均未指定时按内容判断；
+// 每条消息单独匹配、校验并写入，生成一份导入报告
+func ImportLabResults(ctx *gin.Context) {
+	var importer models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&importer).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return
+	}
+	packageID, err := utils.ParseUintQuery(ctx, "user_package_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxImportBodySize+1))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+	if len(body) > maxImportBodySize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": i18n.T(ctx, "import_body_too_large", maxImportBodySize>>20),
+		})
+		return
+	}
+
+	var messages []utils.ImportMessage
+	switch importFormat(ctx, body) {
+	case models.ImportFormatHL7v2:
+		messages = utils.HL7Messages(string(body))
+	case models.ImportFormatFHIR:
+		messages, err = utils.FHIRMessages(body)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "import_invalid_bundle") + err.Error(),
+			})
+			return
+		}
+	default:
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": i18n.T(ctx, "import_unknown_format"),
+		})
+		return
+	}
+	if len(messages) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "import_no_messages"),
+		})
+		return
+	}
+
+	opts := utils.ImportOptions{
+		Importer: importer,
+		Lang:     i18n.Lang(ctx),
+		Audit: func(tx *gorm.DB, entry utils.AuditEntry) error {
+			return utils.WriteAudit(ctx, tx, entry)
+		},
+	}
+	if packageID != nil {
+		opts.UserPackageID = *packageID
+	}
+	reports := make([]models.ImportReport, 0, len(messages))
+	accepted, rejected := 0, 0
+	for _, message := range messages {
+		report, err := utils.ImportResults(global.DB, message, opts)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":   i18n.T(ctx, "db_error") + err.Error(),
+				"reports": reports,
+			})
+			return
+		}
+		accepted += report.Accepted
+		rejected += report.Rejected
+		reports = append(reports, report)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(ctx, "import_finished", len(reports), accepted, rejected),
+		"reports": reports,
+	})
+}
+
+// importFormat 确定请求体的格式，无法识别时返回空字符串
+func importFormat(ctx *gin.Context, body []byte) string {
+	switch ctx.Query("format") {
+	case models.ImportFormatHL7v2, models.ImportFormatFHIR:
+		return ctx.Query("format")
+	case "":
+	default:
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	switch mediaType {
+	case "x-application/hl7-v2+er7", "application/hl7-v2":
+		return models.ImportFormatHL7v2
+	case fhir.MediaType, "application/json":
+		return models.ImportFormatFHIR
+	}
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("MSH")):
+		return models.ImportFormatHL7v2
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return models.ImportFormatFHIR
+	}
+	return ""
+}
+
+// GetImportReports 查询检验结果导入报告，机构只能查看本账号的导入，管理员可查看全部
+// 支持分页排序，按 status、format、message_id、user_id、user_package_id 筛选
+func GetImportReports(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return
+	}
+	page, err := utils.ParsePagination(ctx, map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+	}, "-id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	query := global.DB.Model(&models.ImportReport{})
+	if user.UserType != 2 {
+		query = query.Where("imported_by = ?", user.ID)
+	}
+	for _, field := range []string{"user_id", "user_package_id", "status"} {
+		value, err := utils.ParseUintQuery(ctx, field)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+			})
+			return
+		}
+		if value != nil {
+			query = query.Where(field+" = ?", *value)
+		}
+	}
+	for _, field := range []string{"format", "message_id"} {
+		if value := ctx.Query(field); value != "" {
+			query = query.Where(field+" = ?", value)
+		}
+	}
+
+	var reports []models.ImportReport
+	pageInfo, err := utils.Paginate(query, page, &reports)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"reports":    reports,
+		"pagination": pageInfo,
+	})
+}
+
+// GetImportReport 查看一份导入报告
+func GetImportReport(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return
+	}
+	var report models.ImportReport
+	if err := global.DB.First(&report, ctx.Param("id")).Error; err != nil ||
+		(report.ImportedBy != user.ID && user.UserType != 2) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "import_report_not_found"),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,332 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetPendingReviews 查询待审核发布的用户套餐：机构账号看到其担任主账号或审核人的机构，管理员看到全部
+func GetPendingReviews(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return
+	}
+	page, err := utils.ParsePagination(ctx, map[string]string{
+		"id":         "id",
+		"updated_at": "updated_at",
+	}, "updated_at")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	query := global.DB.Model(&models.UserPackage{}).Preload("User").Preload("Plan").
+		Where("status = 1 AND review_status = ?", models.ReviewDraft)
+	if user.UserType != 2 {
+		owned := global.DB.Model(&models.Institution{}).Select("id").Where("user_id = ?", user.ID)
+		reviewing := global.DB.Model(&models.InstitutionMember{}).Select("institution_id").
+			Where("user_id = ? AND role = ?", user.ID, models.MemberRoleReviewer)
+		query = query.Where("institution_id IN (?) OR institution_id IN (?)", owned, reviewing)
+	}
+	var packages []models.UserPackage
+	pageInfo, err := utils.Paginate(query, page, &packages)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"packages":   packages,
+		"pagination": pageInfo,
+	})
+}
+
+// GetResultReview 查看用户套餐结果的审核状态：各项结果、危急值及确认情况、待审核结果的录入人
+func GetResultReview(ctx *gin.Context) {
+	user, pkg, role, ok := reviewPackage(ctx)
+	if !ok {
+		return
+	}
+	if role == "" && user.UserType != 2 {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "review_forbidden"),
+		})
+		return
+	}
+	state, err := utils.LoadReviewState(global.DB, pkg, i18n.Lang(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+	selfEntered := false
+	for _, id := range state.Enterers {
+		selfEntered = selfEntered || id == user.ID
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"review":      state,
+		"can_release": utils.CanReview(role) && !selfEntered && pkg.ReviewStatus == models.ReviewDraft,
+	})
+}
+
+// AcknowledgeCriticalValue 审核人确认一项危急值（如已通知客户或医生），确认绑定当前结果，结果修改后需重新确认
+func AcknowledgeCriticalValue(ctx *gin.Context) {
+	user, pkg, role, ok := reviewPackage(ctx)
+	if !ok {
+		return
+	}
+	if !utils.CanReview(role) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "review_forbidden"),
+		})
+		return
+	}
+	itemID, err := utils.UnmarshalUint(ctx.Param("item_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_item_id"),
+		})
+		return
+	}
+	var input struct {
+		Note string `json:"note" binding:"required,max=255"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "critical_ack_note_required"),
+		})
+		return
+	}
+
+	var ack models.CriticalAck
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if ack, err = utils.AcknowledgeCritical(tx, pkg, itemID, user, strings.TrimSpace(input.Note)); err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditCriticalAck,
+			TargetType: "user_package",
+			TargetID:   pkg.ID,
+			After: map[string]interface{}{
+				"health_item_id": itemID,
+				"note":           ack.Note,
+			},
+		})
+	})
+	switch {
+	case errors.Is(err, utils.ErrReviewResultNotEntered):
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "review_result_not_entered"),
+		})
+	case errors.Is(err, utils.ErrReviewNotCritical):
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "review_not_critical"),
+		})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusOK, gin.H{
+			"message":        i18n.T(ctx, "critical_acknowledged"),
+			"acknowledgment": ack,
+		})
+	}
+}
+
+// ReleaseResultReview 审核人发布用户套餐的结果，发布后客户及家人可见；
+// 审核人不能是待审核结果的录入人，危急值须全部确认
+func ReleaseResultReview(ctx *gin.Context) {
+	user, pkg, role, ok := reviewPackage(ctx)
+	if !ok {
+		return
+	}
+	if !utils.CanReview(role) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "review_forbidden"),
+		})
+		return
+	}
+
+	var state *utils.ReviewState
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if state, err = utils.LoadReviewState(tx, pkg, i18n.Lang(ctx)); err != nil {
+			return err
+		}
+		if err := utils.ReleaseResults(tx, pkg, user, state); err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditResultsRelease,
+			TargetType: "user_package",
+			TargetID:   pkg.ID,
+			Before:     map[string]interface{}{"review_status": pkg.ReviewStatus},
+			After: map[string]interface{}{
+				"review_status": models.ReviewReleased,
+				"results":       len(state.Results),
+				"enterers":      state.Enterers,
+			},
+		})
+	})
+	switch {
+	case errors.Is(err, utils.ErrReviewNotDraft):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "review_not_draft"),
+		})
+	case errors.Is(err, utils.ErrReviewNoResults):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "review_no_results"),
+		})
+	case errors.Is(err, utils.ErrReviewUnacknowledged):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":          i18n.T(ctx, "review_critical_unacknowledged", len(state.Unacknowledged)),
+			"unacknowledged": state.Unacknowledged,
+		})
+	case errors.Is(err, utils.ErrReviewSelfRelease):
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "review_self_release"),
+		})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusOK, gin.H{
+			"message": i18n.T(ctx, "results_released"),
+		})
+	}
+}
+
+// reviewPackage 查找路径中的用户套餐及当前用户在其机构中的角色
+func reviewPackage(ctx *gin.Context) (user models.User, pkg models.UserPackage, role string, ok bool) {
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return user, pkg, "", false
+	}
+	if err := global.DB.First(&pkg, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "package_not_found"),
+		})
+		return user, pkg, "", false
+	}
+	role, err := utils.InstitutionRole(global.DB, pkg.InstitutionID, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return user, pkg, "", false
+	}
+	return user, pkg, role, true
+}
+
+// criticalAlertView 危急值告警及确认记录，ResponseSeconds 为从录入到确认的用时
+type criticalAlertView struct {
+	models.CriticalAlert
+	ItemName        string `json:"item_name"`
+	PatientName     string `json:"patient_name"`
+	ResponseSeconds *int64 `json:"response_seconds"`
+}
+
+// GetCriticalAlerts 危急值告警及确认记录：机构主账号与成员看到其所在机构的告警，管理员看到全部；
+// 可按 status、institution_id、user_package_id 筛选
+func GetCriticalAlerts(ctx *gin.Context) {
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": i18n.T(ctx, "invalid_user"),
+		})
+		return
+	}
+	page, err := utils.ParsePagination(ctx, map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+	}, "-id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+	query := global.DB.Model(&models.CriticalAlert{}).Preload("Ack").Preload("UserPackage.User")
+	for _, filter := range []string{"status", "institution_id", "user_package_id"} {
+		value, err := utils.ParseUintQuery(ctx, filter)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": i18n.T(ctx, "invalid_query_params") + filter,
+			})
+			return
+		}
+		if value != nil {
+			query = query.Where(filter+" = ?", *value)
+		}
+	}
+	if user.UserType != 2 {
+		owned := global.DB.Model(&models.Institution{}).Select("id").Where("user_id = ?", user.ID)
+		member := global.DB.Model(&models.InstitutionMember{}).Select("institution_id").Where("user_id = ?", user.ID)
+		query = query.Where("institution_id IN (?) OR institution_id IN (?)", owned, member)
+	}
+	var alerts []models.CriticalAlert
+	pageInfo, err := utils.Paginate(query, page, &alerts)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+
+	itemIDs := make([]uint, 0, len(alerts))
+	for _, a := range alerts {
+		itemIDs = append(itemIDs, a.HealthItemID)
+	}
+	var items []models.HealthItem
+	if len(itemIDs) > 0 {
+		if err := global.DB.Unscoped().Select("id, item_name").Where("id IN ?", itemIDs).Find(&items).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": i18n.T(ctx, "db_error") + err.Error(),
+			})
+			return
+		}
+	}
+	baseNames := make(map[uint]string, len(items))
+	for _, item := range items {
+		baseNames[item.ID] = item.ItemName
+	}
+	itemNames := utils.LocalizedItemNames(i18n.Lang(ctx), itemIDs)
+
+	views := make([]criticalAlertView, 0, len(alerts))
+	for _, a := range alerts {
+		view := criticalAlertView{
+			CriticalAlert: a,
+			ItemName:      utils.LocalizeItemName(itemNames, a.HealthItemID, baseNames[a.HealthItemID]),
+			PatientName:   a.UserPackage.User.Name,
+		}
+		if a.AcknowledgedAt != nil {
+			seconds := int64(a.AcknowledgedAt.Sub(a.CreatedAt).Seconds())
+			view.ResponseSeconds = &seconds
+		}
+		views = append(views, view)
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"alerts":     views,
+		"pagination": pageInfo,
+	})
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchResult 搜索结果，entity_type 为 plan 时 entity_id 为套餐ID，为 institution 时为机构ID
+type SearchResult struct {
+	ID              uint     `json:"-"`
+	EntityType      string   `json:"entity_type"`
+	EntityID        uint     `json:"entity_id"`
+	Title           string   `json:"title"`
+	InstitutionID   uint     `json:"institution_id"`
+	InstitutionName string   `json:"institution_name"`
+	Price           *float64 `json:"price,omitempty"`
+	Keywords        string   `json:"keywords,omitempty"`
+	Content         string   `json:"content"`
+	Score           float64  `json:"score"`
+}
+
+// Search 在已审核机构的套餐、机构与健康项目名称中全文检索
+// 查询参数: q（必填）, type（plan/institution，默认全部）, institution_id, min_price, max_price
+// 默认按相关度排序：套餐/机构名称权重最高，其次为健康项目名称，最后为描述、适用人群与地址
+func Search(ctx *gin.Context) {
+	q := strings.TrimSpace(ctx.Query("q"))
+	terms := utils.SearchTerms(q)
+	if len(terms) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "search_query_required"),
+		})
+		return
+	}
+
+	entityType := ctx.Query("type")
+	if entityType != "" && entityType != models.SearchEntityPlan && entityType != models.SearchEntityInstitution {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_search_type"),
+		})
+		return
+	}
+
+	page, err := utils.ParsePagination(ctx, map[string]string{
+		"id":        "search_documents.id",
+		"relevance": "score",
+		"price":     "search_documents.price",
+	}, "-relevance")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	institutionID, err := utils.ParseUintQuery(ctx, "institution_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_institution_id"),
+		})
+		return
+	}
+	minPrice, maxPrice, err := utils.ParseFloatRange(ctx, "min_price", "max_price")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_query_params") + err.Error(),
+		})
+		return
+	}
+
+	booleanQuery, shortTerms := utils.BooleanQuery(terms)
+
+	query := global.DB.Table("search_documents").
+		Joins("JOIN institutions ON institutions.id = search_documents.institution_id AND institutions.status = 1 AND institutions.deleted_at IS NULL")
+
+	if booleanQuery != "" {
+		query = query.Select(`search_documents.id, search_documents.entity_type, search_documents.entity_id,
+			search_documents.title, search_documents.institution_id, institutions.institution_name,
+			search_documents.price, search_documents.keywords, search_documents.content,
+			MATCH(search_documents.title) AGAINST (? IN BOOLEAN MODE) * 3 +
+			MATCH(search_documents.keywords) AGAINST (? IN BOOLEAN MODE) * 2 +
+			MATCH(search_documents.content) AGAINST (? IN BOOLEAN MODE) AS score`,
+			booleanQuery, booleanQuery, booleanQuery).
+			Where("MATCH(search_documents.title, search_documents.keywords, search_documents.content) AGAINST (? IN BOOLEAN MODE)", booleanQuery)
+	} else {
+		query = query.Select(`search_documents.id, search_documents.entity_type, search_documents.entity_id,
+			search_documents.title, search_documents.institution_id, institutions.institution_name,
+			search_documents.price, search_documents.keywords, search_documents.content, 0 AS score`)
+	}
+	// 单字无法命中 ngram 索引，退化为模糊匹配
+	for _, term := range shortTerms {
+		like := "%" + term + "%"
+		query = query.Where("(search_documents.title LIKE ? OR search_documents.keywords LIKE ? OR search_documents.content LIKE ?)", like, like, like)
+	}
+
+	if entityType != "" {
+		query = query.Where("search_documents.entity_type = ?", entityType)
+	}
+	if institutionID != nil {
+		query = query.Where("search_documents.institution_id = ?", *institutionID)
+	}
+	query = utils.WhereRange(query, "search_documents.price", minPrice, maxPrice)
+
+	var results []SearchResult
+	pageInfo, err := utils.Paginate(query, page, &results)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "search_failed") + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"query":      q,
+		"terms":      terms,
+		"results":    results,
+		"pagination": pageInfo,
+	})
+}
+
+// RebuildSearchIndex 管理员重建全文搜索索引
+func RebuildSearchIndex(ctx *gin.Context) {
+	count, err := utils.RebuildSearchIndex()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "search_index_rebuild_failed") + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   i18n.T(ctx, "search_index_rebuilt"),
+		"documents": count,
+	})
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
 	"errors"
 	"fmt"
@@ -17,7 +18,7 @@ func GetUserProfileByID(ctx *gin.Context) {
 	user_id := ctx.Param("id")
 
 	var user models.User
-	if err := global.DB.Select("id, username, name, email, phone, gender, birthday, address, user_type").
+	if err := global.DB.Select("id, username, name, email, phone, gender, birthday, address, user_type, language").
 		Where("id = ?", user_id).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{
@@ -41,6 +42,7 @@ func GetUserProfileByID(ctx *gin.Context) {
 		Birthday string `json:"birthday"`
 		Address  string `json:"address"`
 		UserType uint8  `json:"user_type"`
+		Language string `json:"language"`
 	}{
 		ID:       user.ID,
 		Username: user.Username,
@@ -51,6 +53,7 @@ func GetUserProfileByID(ctx *gin.Context) {
 		Birthday: user.Birthday,
 		Address:  user.Address,
 		UserType: user.UserType,
+		Language: user.Language,
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
@@ -68,7 +71,7 @@ func CreateHealthItem(ctx *gin.Context) {
 	// 绑定输入参数
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的请求数据: " + err.Error(),
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
 		})
 		return
 	}
@@ -81,7 +84,7 @@ func CreateHealthItem(ctx *gin.Context) {
 		if r := recover(); r != nil {
 			tx.Rollback()
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "服务器内部错误",
+				"error": i18n.T(ctx, "internal_error"),
 			})
 		}
 	}()
@@ -97,7 +100,7 @@ func CreateHealthItem(ctx *gin.Context) {
 	if result.Error != nil {
 		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "新建个人健康档案指标失败: " + result.Error.Error(),
+			"error": i18n.T(ctx, "personal_item_create_failed") + result.Error.Error(),
 		})
 		return
 	}
@@ -106,7 +109,7 @@ func CreateHealthItem(ctx *gin.Context) {
 	if result.RowsAffected == 0 {
 		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "未能创建健康档案指标",
+			"error": i18n.T(ctx, "personal_item_not_created"),
 		})
 		return
 	}
@@ -114,13 +117,13 @@ func CreateHealthItem(ctx *gin.Context) {
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "提交事务失败: " + err.Error(),
+			"error": i18n.T(ctx, "commit_failed") + err.Error(),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "新建个人健康档案指标成功",
+		"message": i18n.T(ctx, "personal_item_created"),
 		"data": gin.H{
 			"user_id":          input.UserID,
 			"user_health_info": input.UserHealthInfo,
@@ -135,7 +138,7 @@ func DelHealthItem(ctx *gin.Context) {
 	// 参数验证
 	if ID == "" {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "用户ID不能为空",
+			"error": i18n.T(ctx, "user_id_required"),
 		})
 		return
 	}
@@ -150,18 +153,19 @@ func DelHealthItem(ctx *gin.Context) {
 		if r := recover(); r != nil {
 			tx.Rollback()
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "服务器内部错误",
+				"error": i18n.T(ctx, "internal_error"),
 			})
 		}
 	}()
 
 	// 执行删除操作
-	result := tx.Exec(`DELETE FROM health_items WHERE id = ?`, id)
+	// 只能删除个人健康指标，不能删除项目目录中的项目
+	result := tx.Exec(`DELETE FROM health_items WHERE id = ? AND user_id <> 0`, id)
 
 	if result.Error != nil {
 		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "删除健康档案指标失败: " + result.Error.Error(),
+			"error": i18n.T(ctx, "personal_item_delete_failed") + result.Error.Error(),
 		})
 		return
 	}
@@ -170,7 +174,7 @@ func DelHealthItem(ctx *gin.Context) {
 	if result.RowsAffected == 0 {
 		tx.Rollback()
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "未找到对应的健康档案记录",
+			"error": i18n.T(ctx, "personal_item_not_found"),
 		})
 		return
 	}
@@ -178,13 +182,13 @@ func DelHealthItem(ctx *gin.Context) {
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "提交事务失败: " + err.Error(),
+			"error": i18n.T(ctx, "commit_failed") + err.Error(),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "删除个人健康档案指标成功",
+		"message": i18n.T(ctx, "personal_item_deleted"),
 		"data": gin.H{
 			"user_id": id,
 		},
@@ -201,7 +205,7 @@ func UpdateUserHealthItem(ctx *gin.Context) {
 	// 绑定输入参数
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的请求数据: " + err.Error(),
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
 		})
 		return
 	}
@@ -214,39 +218,41 @@ func UpdateUserHealthItem(ctx *gin.Context) {
 		if r := recover(); r != nil {
 			tx.Rollback()
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "服务器内部错误",
+				"error": i18n.T(ctx, "internal_error"),
 			})
 		}
 	}()
 
-	// fmt.Println("检查ID是否存在")
-	// var originalInfo string
-	// if err := tx.Raw("SELECT user_health_info FROM health_items WHERE id = ?", input.ID).Scan(&originalInfo).Error; err != nil {
-	// 	tx.Rollback()
-	// 	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "查询原始数据失败: " + err.Error()})
-	// 	return
-	// }
-	// fmt.Printf("原始值为: %s\n", originalInfo)
-
-	// // 执行更新操作
-	// fmt.Println("修改")
-	
+	var original models.HealthItem
+	if err := tx.Select("id, user_health_info").Where("user_id <> 0").First(&original, input.ID).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "personal_item_not_found"),
+		})
+		return
+	}
+
 	result := tx.Exec(`UPDATE health_items SET user_health_info = ? WHERE id = ?`,
 		input.UserHealthInfo, input.ID)
 
 	if result.Error != nil {
 		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "修改健康档案指标失败: " + result.Error.Error(),
+			"error": i18n.T(ctx, "personal_item_update_failed") + result.Error.Error(),
 		})
 		return
 	}
 
-	// 检查是否实际更新了记录
-	if result.RowsAffected == 0 {
+	if err := utils.WriteAudit(ctx, tx, utils.AuditEntry{
+		Action:     models.AuditPersonalItemUpdate,
+		TargetType: "health_item",
+		TargetID:   original.ID,
+		Before:     map[string]interface{}{"user_health_info": utils.AuditValue(original.UserHealthInfo)},
+		After:      map[string]interface{}{"user_health_info": utils.AuditValue(input.UserHealthInfo)},
+	}); err != nil {
 		tx.Rollback()
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "未找到对应的健康档案记录",
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "audit_write_failed") + err.Error(),
 		})
 		return
 	}
@@ -254,13 +260,13 @@ func UpdateUserHealthItem(ctx *gin.Context) {
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "提交事务失败: " + err.Error(),
+			"error": i18n.T(ctx, "commit_failed") + err.Error(),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "修改个人健康档案指标成功",
+		"message": i18n.T(ctx, "personal_item_updated"),
 		"data": gin.H{
 			"id":               input.ID,
 			"user_health_info": input.UserHealthInfo,
@@ -304,7 +310,7 @@ func ResetPwd(ctx *gin.Context) {
 	// Check if the previous password matches the user's password
 	if !utils.CheckPassword(input.PrevPassword, user.Password) {
 		ctx.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid Credentials: password does not match",
+			"error": i18n.T(ctx, "password_mismatch"),
 		})
 		return
 	}
@@ -312,7 +318,7 @@ func ResetPwd(ctx *gin.Context) {
 	// Check if the new passwords match
 	if input.NewPassword != input.NewPasswordConfirm {
 		ctx.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid Credentials: new passwords do not match",
+			"error": i18n.T(ctx, "new_password_mismatch"),
 		})
 		return
 	}
@@ -339,7 +345,7 @@ func ResetPwd(ctx *gin.Context) {
 	// Check if the password was updated
 	if result.RowsAffected == 0 {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found or no changes made",
+			"error": i18n.T(ctx, "user_not_found_or_unchanged"),
 		})
 		return
 	}
@@ -349,7 +355,7 @@ func ResetPwd(ctx *gin.Context) {
 
 	// Return a success message
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Password updated successfully. Please login again.",
+		"message": i18n.T(ctx, "password_updated"),
 		"logout":  true, // Frontend should redirect to login page
 	})
 }
@@ -361,7 +367,7 @@ func GetInstitutionByUserId(ctx *gin.Context) {
 	if err := global.DB.Where("user_id = ?", userID).First(&institution).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{
-				"error": "No institution found for this user",
+				"error": i18n.T(ctx, "no_institution_for_user"),
 			})
 			return
 		}
@@ -398,82 +404,126 @@ func UpdateUserProfile(ctx *gin.Context) {
 		if r := recover(); r != nil {
 			tx.Rollback()
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error": "服务器内部错误",
+				"error": i18n.T(ctx, "internal_error"),
 			})
 		}
 	}()
 
-	result := tx.Exec(`UPDATE users SET username = ?,name=?,gender=?,birthday=?,phone=?,email=?,address=?  WHERE id = ?`,
-		input.Username, input.Name, input.Gender, input.Birthday, input.Phone, input.Email, input.Address, userID)
+	var current models.User
+	if err := tx.Where("id = ?", userID).First(&current).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	// 手机号、地址、生日需加密保存，按 map 更新不会经过序列化器
+	updates := map[string]interface{}{
+		"username": input.Username,
+		"name":     input.Name,
+		"gender":   input.Gender,
+		"birthday": input.Birthday,
+		"phone":    input.Phone,
+		"email":    input.Email,
+		"address":  input.Address,
+	}
+	audit := utils.ProfileAudit(current, updates)
+	if err := models.SealUserFields(updates); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "update_failed") + err.Error(),
+		})
+		return
+	}
+	result := tx.Model(&models.User{}).Where("id = ?", userID).Updates(updates)
 
 	if result.Error != nil {
 		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "修改失败: " + result.Error.Error(),
+			"error": i18n.T(ctx, "update_failed") + result.Error.Error(),
+		})
+		return
+	}
+	if err := utils.WriteAudit(ctx, tx, audit); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "update_failed") + err.Error(),
 		})
 		return
 	}
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "提交事务失败: " + err.Error(),
+			"error": i18n.T(ctx, "commit_failed") + err.Error(),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "User profile updated successfully",
+		"message": i18n.T(ctx, "profile_updated"),
 	})
 
 }
 
-// 删除(注销)用户
-func DeleteUser(ctx *gin.Context) {
-	userID := ctx.Param("id")
-	var user models.User
-	if err := global.DB.Where("id = ?", userID).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, gin.H{
-				"error": "User not found",
-			})
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{
+// 管理员更改用户权限
+func UpdateUserPermission(ctx *gin.Context) {
+	changeuserID := ctx.Param("id")
+
+	var input struct {
+		UserType uint8 `json:"user_type"`
+	}
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
-	// 检查是本人还是管理员
-	username := ctx.GetString("username")
-	if username != user.Username && user.UserType != 2 {
-		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "You do not have permission to delete this user",
+	var target models.User
+	if err := global.DB.Where("id = ?", changeuserID).First(&target).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
 		})
 		return
 	}
 
-	if err := global.DB.Unscoped().Delete(&user).Error; err != nil {
+	previousType := target.UserType
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&target).Update("user_type", input.UserType).Error; err != nil {
+			return err
+		}
+		return utils.WriteAudit(ctx, tx, utils.AuditEntry{
+			Action:     models.AuditUserPermissionUpdate,
+			TargetType: "user",
+			TargetID:   target.ID,
+			Before:     map[string]interface{}{"user_type": previousType},
+			After:      map[string]interface{}{"user_type": input.UserType},
+		})
+	})
+	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
+			"error": i18n.T(ctx, "db_error") + err.Error(),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "User deleted successfully",
-		"logout":  true,
+		"message":   i18n.T(ctx, "user_permission_updated"),
+		"user_id":   changeuserID,
+		"user_type": input.UserType,
+		"logout":    true,
 	})
 }
 
-// 管理员更改用户权限
-func UpdateUserPermission(ctx *gin.Context) {
-	changeuserID := ctx.Param("id")
+// 用户设置语言偏好
+func UpdateUserLanguage(ctx *gin.Context) {
+	userID := ctx.Param("id")
 
 	var input struct {
-		UserType uint8 `json:"user_type"`
+		Language string `json:"language"`
 	}
-
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
@@ -481,12 +531,43 @@ func UpdateUserPermission(ctx *gin.Context) {
 		return
 	}
 
-	utils.UpdateIt(&models.User{}, changeuserID, "user_type", input.UserType)
+	var user models.User
+	if err := global.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	// 只能修改自己的语言偏好
+	if user.Username != ctx.GetString("username") {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": i18n.T(ctx, "permission_denied"),
+		})
+		return
+	}
+
+	// 空字符串表示清除偏好，改为跟随 Accept-Language
+	language := i18n.Normalize(input.Language)
+	if input.Language != "" && language == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "unsupported_language", input.Language),
+		})
+		return
+	}
 
+	if err := global.DB.Model(&user).Update("language", language).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if language != "" {
+		ctx.Set(i18n.ContextKey, language)
+	}
 	ctx.JSON(http.StatusOK, gin.H{
-		"message":   "User permission updated successfully",
-		"user_id":   changeuserID,
-		"user_type": input.UserType,
-		"logout":    true,
+		"message":  i18n.T(ctx, "language_updated"),
+		"language": language,
 	})
 }
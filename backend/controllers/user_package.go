@@ -1,29 +1,35 @@
 package controllers
 
 import (
+	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SelectPackage allows users to select a package
+// 传入 user_id 时为亲属代为选择，需要对方授予 book_packages 授权
 func SelectPackage(ctx *gin.Context) {
 	// Get user info from context
 	username := ctx.GetString("username")
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
+			"error": i18n.T(ctx, "user_not_found"),
 		})
 		return
 	}
 
 	var input struct {
-		InstitutionID uint `json:"institution_id" binding:"required"`
-		PlanID        uint `json:"plan_id" binding:"required"`
+		InstitutionID uint       `json:"institution_id" binding:"required"`
+		PlanID        uint       `json:"plan_id" binding:"required"`
+		UserID        uint       `json:"user_id"`        // 代为选择时为亲属的用户ID
+		AppointmentAt *time.Time `json:"appointment_at"` // 预约的体检时间，可稍后再预约
 	}
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
@@ -33,18 +39,41 @@ func SelectPackage(ctx *gin.Context) {
 		return
 	}
 
+	if input.AppointmentAt != nil && !input.AppointmentAt.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "appointment_in_past"),
+		})
+		return
+	}
+
+	// Book on behalf of a relative
+	if input.UserID != 0 && input.UserID != user.ID {
+		if err := utils.AuthorizeFamilyAccess(ctx, input.UserID, user.ID, models.ScopeBookPackages); err != nil {
+			respondFamilyAccessError(ctx, err)
+			return
+		}
+		var owner models.User
+		if err := global.DB.First(&owner, input.UserID).Error; err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": i18n.T(ctx, "user_not_found"),
+			})
+			return
+		}
+		user = owner
+	}
+
 	// Verify the institution and plan exist and the institution is approved
 	var institution models.Institution
 	if err := global.DB.First(&institution, input.InstitutionID).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "Institution not found",
+			"error": i18n.T(ctx, "institution_not_found"),
 		})
 		return
 	}
 
 	if institution.Status != 1 {
 		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "The institution is not approved",
+			"error": i18n.T(ctx, "institution_not_approved"),
 		})
 		return
 	}
@@ -52,7 +81,7 @@ func SelectPackage(ctx *gin.Context) {
 	var plan models.Plan
 	if err := global.DB.Where("id = ? AND institution_id = ?", input.PlanID, input.InstitutionID).First(&plan).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "Plan not found for this institution",
+			"error": i18n.T(ctx, "plan_not_found_for_institution"),
 		})
 		return
 	}
@@ -65,29 +94,40 @@ func SelectPackage(ctx *gin.Context) {
 	if result.Error == nil {
 		// Already exists
 		ctx.JSON(http.StatusConflict, gin.H{
-			"message":      "You have already selected this package",
+			"message":      i18n.T(ctx, "package_already_selected"),
 			"user_package": existingSelection,
 		})
 		return
 	}
 
+	// Pin the plan version being purchased
+	version, err := utils.CurrentPlanVersion(global.DB, plan.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "plan_version_create_failed") + err.Error(),
+		})
+		return
+	}
+
 	// Create new user package selection
 	newUserPackage := models.UserPackage{
 		UserID:        user.ID,
 		InstitutionID: input.InstitutionID,
 		PlanID:        input.PlanID,
 		Status:        0, // Default to pending
+		PlanVersionID: &version.ID,
+		AppointmentAt: input.AppointmentAt,
 	}
 
 	if err := global.DB.Create(&newUserPackage).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to select package: " + err.Error(),
+			"error": i18n.T(ctx, "package_select_failed") + err.Error(),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message":      "Package selected successfully",
+		"message":      i18n.T(ctx, "package_selected"),
 		"user_package": newUserPackage,
 	})
 }
@@ -98,7 +138,7 @@ func GetUserPackages(ctx *gin.Context) {
 	uid, err := strconv.ParseUint(userID, 10, 32)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid user ID",
+			"error": i18n.T(ctx, "invalid_user_id"),
 		})
 		return
 	}
@@ -108,7 +148,7 @@ func GetUserPackages(ctx *gin.Context) {
 	var currentUser models.User
 	if err := global.DB.Where("username = ?", username).First(&currentUser).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
+			"error": i18n.T(ctx, "user_not_found"),
 		})
 		return
 	}
@@ -116,7 +156,7 @@ func GetUserPackages(ctx *gin.Context) {
 	// Only allow users to view their own packages unless they're an admin
 	if currentUser.ID != uint(uid) && currentUser.UserType != 2 {
 		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "You don't have permission to view other user's packages",
+			"error": i18n.T(ctx, "view_packages_forbidden"),
 		})
 		return
 	}
@@ -125,9 +165,10 @@ func GetUserPackages(ctx *gin.Context) {
 	if err := global.DB.Where("user_id = ?", uid).
 		Preload("Institution").
 		Preload("Plan").
+		Preload("PlanVersion").
 		Find(&userPackages).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to fetch user packages: " + err.Error(),
+			"error": i18n.T(ctx, "user_packages_fetch_failed") + err.Error(),
 		})
 		return
 	}
@@ -136,3 +177,63 @@ func GetUserPackages(ctx *gin.Context) {
 		"user_packages": userPackages,
 	})
 }
+
+// UpdatePackageAppointment 预约或改约待体检套餐的体检时间，appointment_at 为空表示取消预约
+// 亲属的套餐需要对方授予 book_packages 授权
+func UpdatePackageAppointment(ctx *gin.Context) {
+	var input struct {
+		AppointmentAt *time.Time `json:"appointment_at"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "invalid_request_detail") + err.Error(),
+		})
+		return
+	}
+	if input.AppointmentAt != nil && !input.AppointmentAt.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": i18n.T(ctx, "appointment_in_past"),
+		})
+		return
+	}
+
+	var user models.User
+	if err := global.DB.Where("username = ?", ctx.GetString("username")).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "user_not_found"),
+		})
+		return
+	}
+
+	var userPackage models.UserPackage
+	if err := global.DB.First(&userPackage, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": i18n.T(ctx, "package_not_found"),
+		})
+		return
+	}
+	if userPackage.UserID != user.ID {
+		if err := utils.AuthorizeFamilyAccess(ctx, userPackage.UserID, user.ID, models.ScopeBookPackages); err != nil {
+			respondFamilyAccessError(ctx, err)
+			return
+		}
+	}
+	if userPackage.Status != 0 {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": i18n.T(ctx, "package_not_pending"),
+		})
+		return
+	}
+
+	if err := global.DB.Model(&userPackage).Update("appointment_at", input.AppointmentAt).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": i18n.T(ctx, "db_error") + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      i18n.T(ctx, "appointment_saved"),
+		"user_package": userPackage,
+	})
+}
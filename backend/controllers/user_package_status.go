@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
 	"net/http"
 	"strconv"
@@ -19,7 +20,7 @@ func UpdateUserPackageStatus(c *gin.Context) {
 	uid, err := strconv.ParseUint(userID, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的用户ID",
+			"error": i18n.T(c, "invalid_user_id"),
 		})
 		return
 	}
@@ -27,7 +28,7 @@ func UpdateUserPackageStatus(c *gin.Context) {
 	pid, err := strconv.ParseUint(planID, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的套餐ID",
+			"error": i18n.T(c, "invalid_plan_id"),
 		})
 		return
 	}
@@ -38,14 +39,14 @@ func UpdateUserPackageStatus(c *gin.Context) {
 	var user models.User
 	if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid user",
+			"error": i18n.T(c, "invalid_user"),
 		})
 		return
 	}
 
 	if user.UserType != 2 && user.UserType != 3 {
 		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Permission denied: only institution users or admins can update package status",
+			"error": i18n.T(c, "only_institution_update_status"),
 		})
 		return
 	}
@@ -56,7 +57,7 @@ func UpdateUserPackageStatus(c *gin.Context) {
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request data: status is required",
+			"error": i18n.T(c, "status_required"),
 		})
 		return
 	}
@@ -64,7 +65,7 @@ func UpdateUserPackageStatus(c *gin.Context) {
 	// 验证状态值
 	if input.Status > 1 {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid status value: must be 0 (pending) or 1 (completed)",
+			"error": i18n.T(c, "invalid_status_value"),
 		})
 		return
 	}
@@ -76,19 +77,19 @@ func UpdateUserPackageStatus(c *gin.Context) {
 
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update package status: " + result.Error.Error(),
+			"error": i18n.T(c, "package_status_update_failed") + result.Error.Error(),
 		})
 		return
 	}
 
 	if result.RowsAffected == 0 {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Package not found for this user and plan",
+			"error": i18n.T(c, "package_not_found"),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Package status updated successfully",
+		"message": i18n.T(c, "package_status_updated"),
 	})
 }
@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
 	"net/http"
 	"strconv"
@@ -15,7 +17,7 @@ func GetUserPlans(c *gin.Context) {
 	uid, err := strconv.ParseUint(userID, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的用户ID",
+			"error": i18n.T(c, "invalid_user_id"),
 		})
 		return
 	}
@@ -24,21 +26,25 @@ func GetUserPlans(c *gin.Context) {
 		ID              uint   `json:"id"`
 		PlanID          uint   `json:"plan_id"`
 		PlanName        string `json:"plan_name"`
+		PlanVersionID   *uint  `json:"plan_version_id"`
+		PlanVersion     *uint  `json:"plan_version"`
 		InstitutionID   uint   `json:"institution_id"`
 		InstitutionName string `json:"institution_name"`
 		Status          uint8  `json:"status"`
 	}
 
+	// 套餐名称按用户所购版本展示
 	err = global.DB.Model(&models.UserPackage{}).
-		Select("user_packages.id, user_packages.plan_id, plans.plan_name, user_packages.institution_id, institutions.institution_name, user_packages.status").
+		Select("user_packages.id, user_packages.plan_id, COALESCE(plan_versions.plan_name, plans.plan_name) AS plan_name, user_packages.plan_version_id, plan_versions.version AS plan_version, user_packages.institution_id, institutions.institution_name, user_packages.status").
 		Joins("JOIN plans ON user_packages.plan_id = plans.id").
+		Joins("LEFT JOIN plan_versions ON user_packages.plan_version_id = plan_versions.id").
 		Joins("JOIN institutions ON user_packages.institution_id = institutions.id").
 		Where("user_packages.user_id = ?", uid).
 		Find(&userPackages).Error
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取用户套餐列表失败: " + err.Error(),
+			"error": i18n.T(c, "user_plans_fetch_failed") + err.Error(),
 		})
 		return
 	}
@@ -52,7 +58,7 @@ func GetPlanItems(c *gin.Context) {
 	pid, err := strconv.ParseUint(planID, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的套餐ID",
+			"error": i18n.T(c, "invalid_plan_id"),
 		})
 		return
 	}
@@ -61,7 +67,7 @@ func GetPlanItems(c *gin.Context) {
 	var plan models.Plan
 	if err := global.DB.First(&plan, pid).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "套餐不存在",
+			"error": i18n.T(c, "plan_not_found"),
 		})
 		return
 	}
@@ -80,11 +86,20 @@ func GetPlanItems(c *gin.Context) {
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取套餐项目失败: " + err.Error(),
+			"error": i18n.T(c, "plan_items_fetch_failed") + err.Error(),
 		})
 		return
 	}
 
+	itemIDs := make([]uint, 0, len(planItems))
+	for _, item := range planItems {
+		itemIDs = append(itemIDs, item.ID)
+	}
+	itemNames := utils.LocalizedItemNames(i18n.Lang(c), itemIDs)
+	for i := range planItems {
+		planItems[i].ItemName = utils.LocalizeItemName(itemNames, planItems[i].ID, planItems[i].ItemName)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"plan_id":   pid,
 		"plan_name": plan.PlanName,
@@ -98,7 +113,7 @@ func GetPlanDetails(c *gin.Context) {
 	pid, err := strconv.ParseUint(planID, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "无效的套餐ID",
+			"error": i18n.T(c, "invalid_plan_id"),
 		})
 		return
 	}
@@ -106,7 +121,7 @@ func GetPlanDetails(c *gin.Context) {
 	var plan models.Plan
 	if err := global.DB.First(&plan, pid).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "套餐不存在",
+			"error": i18n.T(c, "plan_not_found"),
 		})
 		return
 	}
@@ -1,11 +1,13 @@
 package controllers
 
 import (
+	"HealthCare/backend/i18n"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 
+	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/global"
 	"HealthCare/backend/models"
 
@@ -14,53 +16,102 @@ import (
 )
 
 // 查看该用户下的所有健康记录，包含机构和套餐信息
+// 传入 user_id 时查看该亲属的记录，需要对方授予 view_records 授权
 func GetAllItems(ctx *gin.Context) {
 	username := ctx.GetString("username")
 	var userID uint
 	if err := global.DB.Model(&models.User{}).Where("username = ?", username).Select("id").First(&userID).Error; err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		ctx.JSON(http.StatusNotFound, gin.H{"error": i18n.T(ctx, "user_not_found")})
 		return
 	}
 
+	ownerID, err := utils.ParseUintQuery(ctx, "user_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T(ctx, "invalid_user_id")})
+		return
+	}
+	if ownerID != nil && *ownerID != userID {
+		if err := utils.AuthorizeFamilyAccess(ctx, *ownerID, userID, models.ScopeViewRecords); err != nil {
+			respondFamilyAccessError(ctx, err)
+			return
+		}
+		userID = *ownerID
+	}
+
 	// 获取用户所选套餐
 	var userPackages []models.UserPackage
 	if err := global.DB.Preload("Institution").Preload("Plan").Where("user_id = ?", userID).Find(&userPackages).Error; err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user packages"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "user_packages_retrieve_failed")})
+		return
+	}
+
+	// 各套餐最新签署的医生结论
+	packageIDs := make([]uint, 0, len(userPackages))
+	for _, pkg := range userPackages {
+		packageIDs = append(packageIDs, pkg.ID)
+	}
+	conclusions, err := utils.LatestConclusions(global.DB, packageIDs)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "db_error") + err.Error()})
 		return
 	}
 
 	type record struct {
+		UserPackageID   uint   `json:"user_package_id"`
 		PlanID          uint   `json:"plan_id"`
+		PlanVersionID   *uint  `json:"plan_version_id"`
 		InstitutionID   uint   `json:"institution_id"`
 		InstitutionName string `json:"institution_name"`
 		PlanName        string `json:"plan_name"`
-		Status          uint8  `json:"status"` // 0: pending, 1: completed
+		Status          uint8  `json:"status"`        // 0: pending, 1: completed
+		ReviewStatus    uint8  `json:"review_status"` // 0: 结果待审核发布，此时不返回结果与结论, 1: 已发布
 		Items           string `json:"items"`
 		ItemCount       int    `json:"item_count"`
 		CompletedCount  int    `json:"completed_count"`
+
+		Conclusion *models.ConclusionRevision `json:"conclusion"` // 最新签署的医生结论，未签署时为空
 	}
 	var records []record
 
 	// 对每个套餐聚合健康记录
 	for _, pkg := range userPackages {
-		// 查询该套餐下的所有健康项目
-		var planHealthItems []models.PlanHeathItem
-		if err := global.DB.Preload("ThisHeathItem").Where("plan_id = ?", pkg.PlanID).Find(&planHealthItems).Error; err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve plan health items"})
+		// 查询该套餐下的所有健康项目，按用户所购版本展示
+		planName, planHealthItems, err := utils.PackageContents(global.DB, pkg)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "plan_items_retrieve_failed")})
 			return
 		}
 
 		// 查询该用户该套餐下的所有健康项目记录
 		var userHealthItems []models.UserHealthItem
 		if err := global.DB.Where("user_id = ? AND plan_id = ?", userID, pkg.PlanID).Find(&userHealthItems).Error; err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user health items"})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "user_health_items_fetch_failed")})
 			return
 		}
 
-		// 创建一个映射，方便快速查找用户的检查结果
+		// 创建一个映射，方便快速查找用户的检查结果；结果发布前客户不可见
 		userItemValues := make(map[uint]string)
 		for _, uhi := range userHealthItems {
-			userItemValues[uhi.RelationHealthItemId] = uhi.ItemValue
+			if pkg.ReviewStatus == models.ReviewReleased {
+				userItemValues[uhi.RelationHealthItemId] = uhi.ItemValue
+			}
+		}
+
+		// 按请求语言取健康项目显示名称
+		itemIDs := make([]uint, 0, len(planHealthItems))
+		for _, phi := range planHealthItems {
+			itemIDs = append(itemIDs, phi.HealthItemID)
+		}
+		itemNames := utils.LocalizedItemNames(i18n.Lang(ctx), itemIDs)
+
+		var conclusion *models.ConclusionRevision
+		if c, ok := conclusions[pkg.ID]; ok && pkg.ReviewStatus == models.ReviewReleased {
+			conclusion = &c
+		}
+		comments, err := utils.ConclusionComments(global.DB, conclusion)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "db_error") + err.Error()})
+			return
 		}
 
 		// 构建列表
@@ -68,34 +119,39 @@ func GetAllItems(ctx *gin.Context) {
 		completedCount := 0
 
 		for _, phi := range planHealthItems {
-			itemValue, exists := userItemValues[phi.RelationHealthItemId]
+			itemValue, exists := userItemValues[phi.HealthItemID]
 			if exists && itemValue != "" {
 				completedCount++
 			}
 
 			list = append(list, map[string]string{
-				"item_id":          fmt.Sprintf("%d", phi.RelationHealthItemId),
-				"item_name":        phi.ThisHeathItem.ItemName,
+				"item_id":          fmt.Sprintf("%d", phi.HealthItemID),
+				"item_name":        utils.LocalizeItemName(itemNames, phi.HealthItemID, phi.ItemName),
 				"item_description": phi.ItemDescription,
 				"item_value":       itemValue,
+				"item_comment":     comments[phi.HealthItemID],
 			})
 		}
 
 		bytes, err := json.Marshal(list)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal items"})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(ctx, "marshal_items_failed")})
 			return
 		}
 
 		records = append(records, record{
+			UserPackageID:   pkg.ID,
 			PlanID:          pkg.PlanID,
+			PlanVersionID:   pkg.PlanVersionID,
 			InstitutionID:   pkg.InstitutionID,
 			InstitutionName: pkg.Institution.InstitutionName,
-			PlanName:        pkg.Plan.PlanName,
+			PlanName:        planName,
 			Status:          pkg.Status,
+			ReviewStatus:    pkg.ReviewStatus,
 			Items:           string(bytes),
 			ItemCount:       len(planHealthItems),
 			CompletedCount:  completedCount,
+			Conclusion:      conclusion,
 		})
 	}
 
@@ -108,7 +164,7 @@ func GetItemsByPlanID(c *gin.Context) {
 		PlanID uint `form:"plan_id" binding:"required"`
 	}
 	if err := c.ShouldBindQuery(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.T(c, "invalid_input")})
 		return
 	}
 
@@ -116,7 +172,7 @@ func GetItemsByPlanID(c *gin.Context) {
 	username := c.GetString("username")
 	var userID uint
 	if err := global.DB.Model(&models.User{}).Where("username = ?", username).Select("id").First(&userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": i18n.T(c, "user_not_found")})
 		return
 	}
 
@@ -125,7 +181,7 @@ func GetItemsByPlanID(c *gin.Context) {
 	var isInstitution bool
 	var user models.User
 	if err := global.DB.Where("id = ?", userID).First(&user).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": i18n.T(c, "user_not_found")})
 		return
 	}
 
@@ -137,10 +193,24 @@ func GetItemsByPlanID(c *gin.Context) {
 		customerIDStr := c.Query("customer_id")
 		customerIDUint, err := strconv.ParseUint(customerIDStr, 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid customer ID"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": i18n.T(c, "invalid_customer_id")})
 			return
 		}
 		customerID = uint(customerIDUint)
+	} else if !isInstitution && c.Query("user_id") != "" {
+		// 查看亲属的记录，需要对方授予 view_records 授权
+		ownerID, err := strconv.ParseUint(c.Query("user_id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": i18n.T(c, "invalid_user_id")})
+			return
+		}
+		customerID = uint(ownerID)
+		if customerID != userID && user.UserType != 2 {
+			if err := utils.AuthorizeFamilyAccess(c, customerID, userID, models.ScopeViewRecords); err != nil {
+				respondFamilyAccessError(c, err)
+				return
+			}
+		}
 	} else {
 		// 否则使用当前登录用户的ID
 		customerID = userID
@@ -173,7 +243,7 @@ func GetItemsByPlanID(c *gin.Context) {
 			if err := global.DB.Model(&models.PlanHeathItem{}).
 				Where("plan_id = ?", input.PlanID).
 				Count(&planItemCount).Error; err != nil || planItemCount == 0 {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Plan not found and no items associated"})
+				c.JSON(http.StatusNotFound, gin.H{"error": i18n.T(c, "plan_not_found_no_items")})
 				return
 			}
 
@@ -185,24 +255,31 @@ func GetItemsByPlanID(c *gin.Context) {
 		} else {
 			// 如果是普通用户，并且没有关联到这个套餐，返回404
 			if userPackageCount == 0 {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Plan not found or not associated with this user"})
+				c.JSON(http.StatusNotFound, gin.H{"error": i18n.T(c, "plan_not_found_for_user")})
 				return
 			}
-			c.JSON(http.StatusNotFound, gin.H{"error": "Plan not found"})
+			c.JSON(http.StatusNotFound, gin.H{"error": i18n.T(c, "plan_not_found")})
 			return
 		}
 	} else if !isInstitution && user.UserType != 2 && userPackageCount == 0 {
 		// 如果套餐存在，但普通用户没有关联到这个套餐，拒绝访问
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this plan"})
+		c.JSON(http.StatusForbidden, gin.H{"error": i18n.T(c, "plan_access_forbidden")})
 		return
 	}
 
-	// 查询套餐下的所有体检项目
-	var planItems []models.PlanHeathItem
-	if err := global.DB.Preload("ThisHeathItem").Where("plan_id = ?", input.PlanID).Find(&planItems).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve plan items"})
+	// 查询套餐下的所有体检项目，用户已购买时按所购版本展示
+	userPackage := models.UserPackage{PlanID: input.PlanID}
+	if userPackageCount > 0 {
+		global.DB.Where("user_id = ? AND plan_id = ?", customerID, input.PlanID).First(&userPackage)
+	}
+	planName, planItems, err := utils.PackageContents(global.DB, userPackage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(c, "plan_items_retrieve_failed")})
 		return
 	}
+	if planName != "" {
+		plan.PlanName = planName
+	}
 
 	// 如果没有找到任何项目，返回空列表
 	if len(planItems) == 0 {
@@ -211,41 +288,59 @@ func GetItemsByPlanID(c *gin.Context) {
 			"plan_name":   plan.PlanName,
 			"customer_id": customerID,
 			"plan_items":  []output{},
-			"message":     "No health items found for this plan",
+			"message":     i18n.T(c, "no_plan_items"),
 		})
 		return
 	}
 
+	itemIDs := make([]uint, 0, len(planItems))
+	for _, item := range planItems {
+		itemIDs = append(itemIDs, item.HealthItemID)
+	}
+	itemNames := utils.LocalizedItemNames(i18n.Lang(c), itemIDs)
+
+	// 结果发布前只有套餐所属机构与管理员可见
+	showValues := userPackage.ReviewStatus == models.ReviewReleased || user.UserType == 2
+	if !showValues && isInstitution && userPackageCount > 0 {
+		if showValues, err = utils.CanViewDraftResults(global.DB, user, userPackage); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T(c, "db_error") + err.Error()})
+			return
+		}
+	}
+
 	// 对于每个套餐项目，获取用户数据(如果存在)
 	for _, item := range planItems {
-		var itemValue string
-		_ = global.DB.Model(&models.UserHealthItem{}).
-			Where("user_id = ? AND plan_id = ? AND health_item_id = ?",
-				customerID, input.PlanID, item.RelationHealthItemId).
+		var userItem models.UserHealthItem
+		_ = global.DB.Where("user_id = ? AND plan_id = ? AND health_item_id = ?",
+			customerID, input.PlanID, item.HealthItemID).
 			Select("item_value").
-			First(&itemValue).Error
+			First(&userItem).Error
+
+		if !showValues {
+			userItem.ItemValue = ""
+		}
 
-		// 确保item.ThisHeathItem存在
 		itemName := "Unknown Item"
-		if item.ThisHeathItem.ID > 0 {
-			itemName = item.ThisHeathItem.ItemName
+		if item.ItemName != "" {
+			itemName = utils.LocalizeItemName(itemNames, item.HealthItemID, item.ItemName)
 		}
 
 		outplanitems = append(outplanitems, output{
-			PlanItemID:      item.ID,
+			PlanItemID:      item.PlanItemID,
 			PlanID:          input.PlanID,
 			PlanName:        plan.PlanName,
-			ItemID:          item.RelationHealthItemId,
+			ItemID:          item.HealthItemID,
 			ItemName:        itemName,
 			ItemDescription: item.ItemDescription,
-			ItemValue:       itemValue,
+			ItemValue:       userItem.ItemValue,
 		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"plan_id":     input.PlanID,
-		"plan_name":   plan.PlanName,
-		"customer_id": customerID,
-		"plan_items":  outplanitems,
+		"plan_id":         input.PlanID,
+		"plan_name":       plan.PlanName,
+		"customer_id":     customerID,
+		"plan_version_id": userPackage.PlanVersionID,
+		"plan_items":      outplanitems,
 	})
 }
@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"HealthCare/backend/config"
+	"HealthCare/backend/global"
+	"HealthCare/backend/models"
+	"HealthCare/backend/security"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInstitutionAccount 机构账号需先删除名下机构才能注销
+var ErrInstitutionAccount = errors.New("account owns an institution")
+
+// DeletionGracePeriod 注销冷静期
+func DeletionGracePeriod() time.Duration {
+	days := config.AppConfig.AccountDeletion.GraceDays
+	if days < 0 {
+		days = 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// CheckDeletable 检查账号能否注销：名下仍有机构的账号不能注销
+func CheckDeletable(db *gorm.DB, userID uint) error {
+	var count int64
+	if err := db.Model(&models.Institution{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrInstitutionAccount
+	}
+	return nil
+}
+
+// ExecuteDeletion 匿名化注销申请对应的账号（连同其被监护人），申请随之标记为完成
+// audit 写入审计日志：请求中由管理员立即执行时使用 WriteAudit，后台任务使用 WriteSystemAudit
+func ExecuteDeletion(db *gorm.DB, request models.DeletionRequest, audit func(*gorm.DB, AuditEntry) error) error {
+	var files []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		err := tx.First(&user, request.RelationUserId).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 账号已被匿名化（如随监护人一并注销）
+			return tx.Model(&request).Updates(map[string]interface{}{
+				"status":       models.DeletionCompleted,
+				"completed_at": time.Now(),
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+		if err := CheckDeletable(tx, user.ID); err != nil {
+			return err
+		}
+
+		// 被监护人档案由监护人管理，随监护人一并注销
+		var dependents []models.User
+		if err := tx.Where("guardian_id = ?", user.ID).Find(&dependents).Error; err != nil {
+			return err
+		}
+		dependentIDs := make([]uint, 0, len(dependents))
+		for _, dependent := range append(dependents, user) {
+			removed, err := anonymizeUser(tx, dependent)
+			if err != nil {
+				return err
+			}
+			files = append(files, removed...)
+			if dependent.ID != user.ID {
+				dependentIDs = append(dependentIDs, dependent.ID)
+			}
+		}
+
+		return audit(tx, AuditEntry{
+			Action:     models.AuditUserDelete,
+			TargetType: "user",
+			TargetID:   user.ID,
+			Before:     map[string]interface{}{"user_type": user.UserType},
+			After: map[string]interface{}{
+				"deletion_request_id": request.ID,
+				"dependents":          dependentIDs,
+			},
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// 数据库提交后再删除文件，事务回滚时文件仍在
+	for _, file := range files {
+		if err := os.Remove(StoragePath(file)); err != nil && !os.IsNotExist(err) {
+			log.Printf("account deletion: remove %s failed: %v", file, err)
+		}
+	}
+	return nil
+}
+
+// anonymizeUser 匿名化账号：删除家庭关系、授权、上传文件与导出文件，清空评论内容与个人信息，
+// 体检套餐与检查结果保留并关联到假名化的账号；返回需要删除的文件（相对 storage.dir）
+func anonymizeUser(tx *gorm.DB, user models.User) ([]string, error) {
+	if err := tx.Unscoped().Where("owner_id = ? OR grantee_id = ?", user.ID, user.ID).
+		Delete(&models.FamilyGrant{}).Error; err != nil {
+		return nil, err
+	}
+	// 他人访问本人数据的记录随账号删除；本人访问他人数据的记录属于对方，保留
+	if err := tx.Where("owner_id = ?", user.ID).Delete(&models.FamilyAccessLog{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Where("user_id = ? OR relative_id = ?", user.ID, user.ID).
+		Delete(&models.Family{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.DependentClaim{}).Error; err != nil {
+		return nil, err
+	}
+	// 机构成员身份随账号删除，注销的账号不再接收危急值等通知
+	if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.InstitutionMember{}).Error; err != nil {
+		return nil, err
+	}
+	// 发给本人的通知随账号删除；他人收到的关于本人的通知不含姓名，读取时按套餐查到的姓名随下方资料一并清空。
+	// 审计日志受哈希链保护不做修改，其中的结果值只记录盲索引
+	if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Notification{}).Error; err != nil {
+		return nil, err
+	}
+
+	// 评分计入套餐评分，保留；评论内容可能含个人信息，清空
+	if err := tx.Model(&models.Commentary{}).Where("user_id = ?", user.ID).
+		Update("commentary", "").Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.UserPackage{}).Where("user_id = ? AND status = 0", user.ID).
+		Update("appointment_at", nil).Error; err != nil {
+		return nil, err
+	}
+
+	var files []string
+	var reports []models.ReportFile
+	if err := tx.Unscoped().Where("user_id = ?", user.ID).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	for _, report := range reports {
+		files = append(files, report.Path)
+	}
+	if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.ReportFile{}).Error; err != nil {
+		return nil, err
+	}
+	var exports []models.ExportJob
+	if err := tx.Unscoped().Where("user_id = ?", user.ID).Find(&exports).Error; err != nil {
+		return nil, err
+	}
+	for _, export := range exports {
+		if export.Path != "" {
+			files = append(files, export.Path)
+		}
+	}
+	if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.ExportJob{}).Error; err != nil {
+		return nil, err
+	}
+
+	// 只保留性别与出生年份，供保留的检查结果解读使用
+	birthYear := ""
+	if len(user.Birthday) >= 4 {
+		birthYear = user.Birthday[:4]
+	}
+	updates := map[string]interface{}{
+		"username":    Pseudonym(user.ID),
+		"password":    "",
+		"name":        "",
+		"birthday":    birthYear,
+		"phone":       "",
+		"email":       "",
+		"address":     "",
+		"language":    "",
+		"guardian_id": nil,
+	}
+	if err := models.SealUserFields(updates); err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Delete(&models.User{}, user.ID).Error; err != nil {
+		return nil, err
+	}
+	// 被监护人可能也有单独的注销申请，一并完成
+	if err := tx.Model(&models.DeletionRequest{}).
+		Where("user_id = ? AND status = ?", user.ID, models.DeletionPending).
+		Updates(map[string]interface{}{
+			"status":       models.DeletionCompleted,
+			"completed_at": time.Now(),
+		}).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Pseudonym 匿名化账号的用户名，由盲索引密钥派生，不掌握密钥无法由用户ID反推
+func Pseudonym(userID uint) string {
+	return "deleted_" + security.BlindIndex("pseudonym", strconv.FormatUint(uint64(userID), 10))[:16]
+}
+
+// ProcessDueDeletions 执行冷静期已满的注销申请
+func ProcessDueDeletions() {
+	var requests []models.DeletionRequest
+	if err := global.DB.Where("status = ? AND scheduled_at <= ?", models.DeletionPending, time.Now()).
+		Order("id").Find(&requests).Error; err != nil {
+		log.Printf("account deletion: load due requests failed: %v", err)
+		return
+	}
+	for _, request := range requests {
+		if err := ExecuteDeletion(global.DB, request, WriteSystemAudit); err != nil {
+			log.Printf("account deletion: request %d failed: %v", request.ID, err)
+		}
+	}
+}
+
+// StartDeletionWorker 启动时调用，每小时执行一次到期的注销申请
+func StartDeletionWorker() {
+	go func() {
+		ProcessDueDeletions()
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			ProcessDueDeletions()
+		}
+	}()
+}
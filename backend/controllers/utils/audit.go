@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"HealthCare/backend/models"
+	"HealthCare/backend/security"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errAuditChainBroken 校验发现断链时停止遍历
+var errAuditChainBroken = errors.New("audit chain broken")
+
+// AuditSystemActor 后台任务写入审计日志时的操作人
+const AuditSystemActor = "system"
+
+// AuditEntry 一条待写入的审计记录，Before/After 为变更前后的字段，创建时 Before 为空，删除时 After 为空
+type AuditEntry struct {
+	Action     string
+	TargetType string
+	TargetID   uint
+	Before     map[string]interface{}
+	After      map[string]interface{}
+}
+
+// AuditChainResult 哈希链校验结果，BrokenID 为第一条校验失败的记录
+type AuditChainResult struct {
+	Valid    bool   `json:"valid"`
+	Checked  int    `json:"checked"`
+	BrokenID uint   `json:"broken_id,omitempty"`
+	HeadHash string `json:"head_hash"` // 链尾哈希，可另行保存用于发现尾部记录被删除
+}
+
+// WriteAudit 在 tx 中追加一条审计日志，须与变更在同一事务中调用，写入失败时调用方应回滚变更
+// Before/After 中取值相同的字段会被去掉，只保留差异；并发写入时 prev_hash 唯一约束使后写入的事务失败
+func WriteAudit(ctx *gin.Context, tx *gorm.DB, entry AuditEntry) error {
+	return writeAudit(tx, ctx.GetString("username"), ctx.ClientIP(), ctx.GetString("request_id"), entry)
+}
+
+// WriteSystemAudit 追加一条由后台任务执行的审计日志，操作人记为 system
+func WriteSystemAudit(tx *gorm.DB, entry AuditEntry) error {
+	return writeAudit(tx, AuditSystemActor, "", "", entry)
+}
+
+func writeAudit(tx *gorm.DB, actorName, ip, requestID string, entry AuditEntry) error {
+	before, after := auditDiff(entry.Before, entry.After)
+	beforeJSON, err := marshalAudit(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := marshalAudit(after)
+	if err != nil {
+		return err
+	}
+
+	log := models.AuditLog{
+		CreatedAt:  time.Now().Truncate(time.Second),
+		ActorName:  actorName,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		IP:         ip,
+		RequestID:  requestID,
+	}
+	if actorName != AuditSystemActor {
+		var actor models.User
+		if err := tx.Select("id").Where("username = ?", log.ActorName).First(&actor).Error; err == nil {
+			log.ActorID = &actor.ID
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	var last models.AuditLog
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+		return err
+	}
+	log.PrevHash = last.Hash
+	log.Hash = AuditHash(log)
+	return tx.Create(&log).Error
+}
+
+// AuditHash 计算审计记录的哈希，覆盖上一条记录的哈希与本条全部内容
+func AuditHash(log models.AuditLog) string {
+	actorID := ""
+	if log.ActorID != nil {
+		actorID = strconv.FormatUint(uint64(*log.ActorID), 10)
+	}
+	h := sha256.New()
+	for _, field := range []string{
+		log.PrevHash,
+		strconv.FormatInt(log.CreatedAt.Unix(), 10),
+		actorID,
+		log.ActorName,
+		log.Action,
+		log.TargetType,
+		strconv.FormatUint(uint64(log.TargetID), 10),
+		log.Before,
+		log.After,
+		log.IP,
+		log.RequestID,
+	} {
+		// 写入长度前缀，避免字段拼接产生歧义
+		fmt.Fprintf(h, "%d:%s|", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyAuditChain 按顺序校验全部审计记录的哈希链
+func VerifyAuditChain(db *gorm.DB) (AuditChainResult, error) {
+	result := AuditChainResult{Valid: true}
+	var batch []models.AuditLog
+	err := db.Order("id").FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+		return verifyAuditLogs(&result, batch)
+	}).Error
+	if errors.Is(err, errAuditChainBroken) {
+		err = nil
+	}
+	return result, err
+}
+
+// verifyAuditLogs 接着 result 继续校验按 ID 排序的一批记录，断链时记录位置并返回 errAuditChainBroken
+func verifyAuditLogs(result *AuditChainResult, logs []models.AuditLog) error {
+	for _, log := range logs {
+		result.Checked++
+		if log.PrevHash != result.HeadHash || AuditHash(log) != log.Hash {
+			result.Valid = false
+			result.BrokenID = log.ID
+			return errAuditChainBroken
+		}
+		result.HeadHash = log.Hash
+	}
+	return nil
+}
+
+// AuditValue 审计日志中检查结果等敏感取值的记录形式：取值的盲索引，可核对是否变化及与已知取值是否一致，
+// 但不能还原取值；审计日志不加密，不能保存原值
+func AuditValue(value string) string {
+	return security.BlindIndex("audit_value", value)
+}
+
+// ProfileAudit 个人资料变更的审计记录，updates 为按 map 更新的字段（加密前），取值只记录盲索引
+func ProfileAudit(user models.User, updates map[string]interface{}) AuditEntry {
+	current := map[string]string{
+		"username": user.Username,
+		"name":     user.Name,
+		"gender":   user.Gender,
+		"birthday": user.Birthday,
+		"phone":    user.Phone,
+		"email":    user.Email,
+		"address":  user.Address,
+	}
+	before := make(map[string]interface{}, len(updates))
+	after := make(map[string]interface{}, len(updates))
+	for column, value := range updates {
+		if old, ok := current[column]; ok {
+			before[column] = AuditValue(old)
+			after[column] = AuditValue(fmt.Sprint(value))
+		}
+	}
+	return AuditEntry{
+		Action:     models.AuditUserProfileUpdate,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Before:     before,
+		After:      after,
+	}
+}
+
+// auditDiff 去掉前后取值相同的字段
+func auditDiff(before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	if before == nil || after == nil {
+		return before, after
+	}
+	b := make(map[string]interface{})
+	a := make(map[string]interface{})
+	for k, v := range before {
+		if av, ok := after[k]; !ok || fmt.Sprint(av) != fmt.Sprint(v) {
+			b[k] = v
+		}
+	}
+	for k, v := range after {
+		if bv, ok := before[k]; !ok || fmt.Sprint(bv) != fmt.Sprint(v) {
+			a[k] = v
+		}
+	}
+	return b, a
+}
+
+func marshalAudit(fields map[string]interface{}) (string, error) {
+	if len(fields) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(fields)
+	return string(data), err
+}
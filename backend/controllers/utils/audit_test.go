@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"HealthCare/backend/models"
+	"errors"
+	"testing"
+	"time"
+)
+
+// auditChain 按 WriteAudit 的方式生成 n 条相连的审计记录
+func auditChain(n int) []models.AuditLog {
+	logs := make([]models.AuditLog, n)
+	prev := ""
+	for i := range logs {
+		actor := uint(7)
+		logs[i] = models.AuditLog{
+			ID:         uint(i + 1),
+			CreatedAt:  time.Date(2026, 3, 15, 9, 0, i, 0, time.UTC),
+			ActorID:    &actor,
+			ActorName:  "admin",
+			Action:     models.AuditCheckupResultsWrite,
+			TargetType: "user_package",
+			TargetID:   uint(100 + i),
+			Before:     `{"status":0}`,
+			After:      `{"status":1}`,
+			IP:         "10.0.0.1",
+			RequestID:  "req",
+			PrevHash:   prev,
+		}
+		logs[i].Hash = AuditHash(logs[i])
+		prev = logs[i].Hash
+	}
+	return logs
+}
+
+func TestVerifyAuditLogs(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(logs []models.AuditLog) []models.AuditLog
+		broken uint
+	}{
+		{"intact", func(logs []models.AuditLog) []models.AuditLog { return logs }, 0},
+		{"after edited", func(logs []models.AuditLog) []models.AuditLog { logs[2].After = `{"status":2}`; return logs }, 3},
+		{"before edited", func(logs []models.AuditLog) []models.AuditLog { logs[1].Before = ""; return logs }, 2},
+		{"actor changed", func(logs []models.AuditLog) []models.AuditLog { logs[0].ActorID = nil; return logs }, 1},
+		{"time changed", func(logs []models.AuditLog) []models.AuditLog {
+			logs[3].CreatedAt = logs[3].CreatedAt.Add(time.Second)
+			return logs
+		}, 4},
+		{"rehashed after edit", func(logs []models.AuditLog) []models.AuditLog {
+			logs[1].TargetID = 1
+			logs[1].Hash = AuditHash(logs[1])
+			return logs
+		}, 3},
+		{"row deleted", func(logs []models.AuditLog) []models.AuditLog { return append(logs[:2], logs[3:]...) }, 4},
+		{"rows swapped", func(logs []models.AuditLog) []models.AuditLog {
+			logs[1], logs[2] = logs[2], logs[1]
+			return logs
+		}, 3},
+		{"first row deleted", func(logs []models.AuditLog) []models.AuditLog { return logs[1:] }, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := tt.tamper(auditChain(5))
+			// 分两批校验，与 VerifyAuditChain 分批读取相同
+			result := AuditChainResult{Valid: true}
+			err := verifyAuditLogs(&result, logs[:2])
+			if err == nil {
+				err = verifyAuditLogs(&result, logs[2:])
+			}
+			if tt.broken == 0 {
+				if err != nil || !result.Valid || result.Checked != len(logs) || result.HeadHash != logs[len(logs)-1].Hash {
+					t.Fatalf("got %+v, %v for an intact chain", result, err)
+				}
+				return
+			}
+			if !errors.Is(err, errAuditChainBroken) || result.Valid || result.BrokenID != tt.broken {
+				t.Fatalf("got %+v, %v, want broken at %d", result, err, tt.broken)
+			}
+		})
+	}
+}
+
+// 字段带长度前缀，内容在相邻字段间移动会改变哈希
+func TestAuditHashFieldBoundaries(t *testing.T) {
+	a := models.AuditLog{ActorName: "ab", Action: "c"}
+	b := models.AuditLog{ActorName: "a", Action: "bc"}
+	if AuditHash(a) == AuditHash(b) {
+		t.Fatal("hash does not separate fields")
+	}
+}
+
+func TestAuditDiff(t *testing.T) {
+	before, after := auditDiff(
+		map[string]interface{}{"status": 0, "name": "a", "same": 1, "removed": "x"},
+		map[string]interface{}{"status": 1, "name": "a", "same": uint(1), "added": "y"},
+	)
+	if len(before) != 2 || before["status"] != 0 || before["removed"] != "x" {
+		t.Errorf("got before %v", before)
+	}
+	if len(after) != 2 || after["status"] != 1 || after["added"] != "y" {
+		t.Errorf("got after %v", after)
+	}
+	if b, a := auditDiff(nil, map[string]interface{}{"x": 1}); b != nil || len(a) != 1 {
+		t.Errorf("creation entry changed: %v, %v", b, a)
+	}
+	if data, err := marshalAudit(map[string]interface{}{}); err != nil || data != "" {
+		t.Errorf("empty fields marshalled to %q, %v", data, err)
+	}
+}
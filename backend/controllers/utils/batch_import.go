@@ -0,0 +1,321 @@
+package utils
+
+import (
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxBatchRows 一次批量上传允许的最大客户行数
+const MaxBatchRows = 2000
+
+var (
+	ErrBatchEmpty         = errors.New("file has no data rows")
+	ErrBatchTooManyRows   = errors.New("too many rows")
+	ErrBatchNoCustomerCol = errors.New("missing user_id or phone column")
+)
+
+// batchCustomerColumns 表头中表示客户信息的列（不区分大小写），其余列为体检项目
+var batchCustomerColumns = map[string]string{
+	"user_id":  "user_id",
+	"用户id":     "user_id",
+	"name":     "name",
+	"姓名":       "name",
+	"phone":    "phone",
+	"手机号":      "phone",
+	"birthday": "birthday",
+	"出生日期":     "birthday",
+}
+
+// BatchColumn 表头中识别出的体检项目列
+type BatchColumn struct {
+	Column       string `json:"column"`
+	HealthItemID uint   `json:"health_item_id"`
+	ItemName     string `json:"item_name"`
+}
+
+// BatchRowError 行级错误，Row 为表格中的行号（表头为第 1 行）
+type BatchRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// BatchReport 批量上传的校验报告
+type BatchReport struct {
+	DryRun  bool            `json:"dry_run"`
+	Valid   bool            `json:"valid"`
+	Rows    int             `json:"rows"`
+	Results int             `json:"results"`
+	Columns []BatchColumn   `json:"columns"`
+	Errors  []BatchRowError `json:"errors"`
+}
+
+type batchRow struct {
+	line   int
+	user   models.User
+	pkg    *models.UserPackage // 为空表示提交时按套餐当前版本创建订阅
+	values map[uint]string
+	order  []uint
+}
+
+// BatchUpload 解析并校验后的批量上传，校验通过后可由 CommitBatch 写入
+type BatchUpload struct {
+	Plan   models.Plan
+	Report BatchReport
+	rows   []batchRow
+}
+
+// PrepareBatch 校验批量上传的表格：首行为表头，每行一位客户；
+// 客户按 user_id 或手机号加姓名（可附出生日期）匹配，项目列按项目ID或名称（含译名）匹配；
+// 只读取数据库，所有问题记录为行级错误
+func PrepareBatch(db *gorm.DB, plan models.Plan, table [][]string, lang string) (*BatchUpload, error) {
+	if len(table) < 2 {
+		return nil, ErrBatchEmpty
+	}
+	upload := &BatchUpload{Plan: plan, Report: BatchReport{Columns: []BatchColumn{}, Errors: []BatchRowError{}}}
+	addError := func(line int, column, key string, args ...interface{}) {
+		upload.Report.Errors = append(upload.Report.Errors, BatchRowError{
+			Row:     line,
+			Column:  column,
+			Message: i18n.Translate(lang, key, args...),
+		})
+	}
+
+	current, err := CurrentPlanVersion(db, plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	currentItems, names, err := importPlanItems(db, models.UserPackage{PlanID: plan.ID, PlanVersionID: &current.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析表头
+	header := table[0]
+	customerCols := make(map[string]int)
+	itemCols := make(map[int]uint)
+	for i, title := range header {
+		if title == "" {
+			continue
+		}
+		if field, ok := batchCustomerColumns[strings.ToLower(title)]; ok {
+			customerCols[field] = i
+			continue
+		}
+		itemID := names[NormalizeItemName(title)]
+		if id, err := strconv.ParseUint(strings.TrimPrefix(title, "#"), 10, 32); err == nil {
+			if _, ok := currentItems[uint(id)]; ok {
+				itemID = uint(id)
+			}
+		}
+		if itemID == 0 {
+			addError(1, title, "batch_unknown_column")
+			continue
+		}
+		if currentItems[itemID].Formula != "" {
+			addError(1, title, "batch_derived_column")
+			continue
+		}
+		itemCols[i] = itemID
+		upload.Report.Columns = append(upload.Report.Columns, BatchColumn{
+			Column:       title,
+			HealthItemID: itemID,
+			ItemName:     currentItems[itemID].ItemName,
+		})
+	}
+	_, hasUserID := customerCols["user_id"]
+	_, hasPhone := customerCols["phone"]
+	if !hasUserID && !hasPhone {
+		return nil, ErrBatchNoCustomerCol
+	}
+
+	cell := func(row []string, col int) string {
+		if col < len(row) {
+			return row[col]
+		}
+		return ""
+	}
+	field := func(row []string, name string) string {
+		if col, ok := customerCols[name]; ok {
+			return cell(row, col)
+		}
+		return ""
+	}
+
+	// 各版本包含的项目，按版本ID缓存；未记录版本的旧订阅按套餐项目校验
+	versionItems := map[uint]map[uint]models.HealthItem{current.ID: currentItems}
+	seen := make(map[uint]int)
+	for i, row := range table[1:] {
+		line := i + 2
+		if len(row) == 0 {
+			continue
+		}
+		upload.Report.Rows++
+		if upload.Report.Rows > MaxBatchRows {
+			return nil, ErrBatchTooManyRows
+		}
+		errorCount := len(upload.Report.Errors)
+
+		patient := ImportPatient{Phone: field(row, "phone"), BirthDate: batchDate(field(row, "birthday"))}
+		if name := field(row, "name"); name != "" {
+			patient.Names = []string{name}
+		}
+		if s := field(row, "user_id"); s != "" {
+			id, err := UnmarshalUint(s)
+			if err != nil {
+				addError(line, "user_id", "batch_invalid_user_id", s)
+				continue
+			}
+			patient.UserID = id
+		}
+		if s := field(row, "birthday"); s != "" && patient.BirthDate == "" {
+			addError(line, "birthday", "batch_invalid_birthday", s)
+			continue
+		}
+		user, err := matchImportPatient(db, patient, []uint{plan.RelationInstitutionID})
+		if err != nil {
+			key, ok := importErrorKeys[err]
+			if !ok {
+				return nil, err
+			}
+			addError(line, "", key)
+			continue
+		}
+		if first, ok := seen[user.ID]; ok {
+			addError(line, "", "batch_duplicate_customer", first)
+			continue
+		}
+		seen[user.ID] = line
+
+		r := batchRow{line: line, user: user, values: make(map[uint]string)}
+		allowed := currentItems
+		var pkg models.UserPackage
+		err = db.Where("user_id = ? AND plan_id = ?", user.ID, plan.ID).First(&pkg).Error
+		switch {
+		case err == nil:
+			r.pkg = &pkg
+			if pkg.PlanVersionID == nil || versionItems[*pkg.PlanVersionID] == nil {
+				items, _, err := importPlanItems(db, pkg)
+				if err != nil {
+					return nil, err
+				}
+				if pkg.PlanVersionID != nil {
+					versionItems[*pkg.PlanVersionID] = items
+				}
+				allowed = items
+			} else {
+				allowed = versionItems[*pkg.PlanVersionID]
+			}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+
+		for col := range header {
+			itemID, ok := itemCols[col]
+			value := cell(row, col)
+			if !ok || value == "" {
+				continue
+			}
+			item, inPlan := allowed[itemID]
+			switch {
+			case !inPlan:
+				addError(line, header[col], "batch_item_not_in_version")
+			case len(value) > maxImportValueLength:
+				addError(line, header[col], "import_line_too_long", maxImportValueLength)
+			case (item.ReferenceLow != nil || item.ReferenceHigh != nil) && !isLeadingNumber(value):
+				addError(line, header[col], "import_line_not_numeric", value)
+			case r.values[itemID] != "":
+				addError(line, header[col], "import_line_duplicate", itemID)
+			default:
+				r.values[itemID] = value
+				r.order = append(r.order, itemID)
+			}
+		}
+		if len(r.values) == 0 && len(upload.Report.Errors) == errorCount {
+			addError(line, "", "batch_row_empty")
+		}
+		if len(upload.Report.Errors) == errorCount {
+			upload.rows = append(upload.rows, r)
+			upload.Report.Results += len(r.values)
+		}
+	}
+	if upload.Report.Rows == 0 {
+		return nil, ErrBatchEmpty
+	}
+	upload.Report.Valid = len(upload.Report.Errors) == 0
+	return upload, nil
+}
+
+// CommitBatch 在一个事务中写入校验通过的批量上传：保存批次记录，为尚未订阅的客户按当前版本创建订阅，
+// 写入结果并将套餐标记为已完成；每个用户套餐记一条审计日志。任一步失败则全部回滚
+func CommitBatch(db *gorm.DB, upload *BatchUpload, batch *models.BatchImport, audit func(*gorm.DB, AuditEntry) error) error {
+	if !upload.Report.Valid {
+		return errors.New("batch has validation errors")
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		batch.PlanID = upload.Plan.ID
+		batch.Rows = len(upload.rows)
+		batch.Results = upload.Report.Results
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+
+		var current *models.PlanVersion
+		for _, r := range upload.rows {
+			pkg := r.pkg
+			if pkg == nil {
+				if current == nil {
+					var err error
+					if current, err = CurrentPlanVersion(tx, upload.Plan.ID); err != nil {
+						return err
+					}
+				}
+				pkg = &models.UserPackage{
+					UserID:        r.user.ID,
+					PlanID:        upload.Plan.ID,
+					InstitutionID: upload.Plan.RelationInstitutionID,
+					Status:        0,
+					PlanVersionID: &current.ID,
+				}
+				if err := tx.Create(pkg).Error; err != nil {
+					return err
+				}
+			}
+			saved, err := SaveCheckupResults(tx, *pkg, r.values, r.order, batch.ImportedBy)
+			if err != nil {
+				return err
+			}
+			saved.Audit.After["batch_import_id"] = batch.ID
+			saved.Audit.After["row"] = r.line
+			if err := audit(tx, saved.Audit); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// batchDate 规范化出生日期（YYYY-MM-DD、YYYY/MM/DD、YYYYMMDD），格式不符时返回空字符串
+func batchDate(value string) string {
+	value = strings.ReplaceAll(value, "/", "-")
+	if len(value) == 8 && !strings.Contains(value, "-") {
+		value = value[:4] + "-" + value[4:6] + "-" + value[6:]
+	}
+	if len(value) < 10 {
+		return ""
+	}
+	if _, err := strconv.Atoi(strings.ReplaceAll(value[:10], "-", "")); err != nil || value[4] != '-' || value[7] != '-' {
+		return ""
+	}
+	return value[:10]
+}
+
+func isLeadingNumber(value string) bool {
+	_, ok := leadingNumber(value)
+	return ok
+}
@@ -0,0 +1,396 @@
+package utils
+
+import (
+	"HealthCare/backend/formula"
+	"HealthCare/backend/models"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCatalogNameTaken = errors.New("name is already used by another catalog item")
+	ErrCatalogCodeTaken = errors.New("code is already used by another catalog item")
+)
+
+// MergeConflictError 合并会使同一记录中出现两个相同项目，需先人工处理：
+// Results 为同时录入了多个待合并项目的用户套餐数，Versions 为同时包含多个待合并项目的套餐版本数，
+// Comments 为同时点评了多个待合并项目的结论数
+type MergeConflictError struct {
+	Results  int64
+	Versions int64
+	Comments int64
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("merge conflicts: %d user packages, %d plan versions, %d conclusions", e.Results, e.Versions, e.Comments)
+}
+
+// NormalizeItemName 项目名称的比较键：全角字符转半角、去除全部空白、转小写，
+// 使 "血压"、"血压 "、"ＷＢＣ" 与 "wbc" 视为同一名称
+func NormalizeItemName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r == '　' || unicode.IsSpace(r):
+			continue
+		case r >= '！' && r <= '～':
+			r -= 0xFEE0
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// CatalogItems 只保留项目目录中的项目，排除旧版写入 health_items 的个人健康指标
+func CatalogItems(db *gorm.DB) *gorm.DB {
+	return db.Where("health_items.user_id = 0 OR health_items.user_id IS NULL")
+}
+
+// CatalogIndex 项目目录的名称、同义名称与编码索引
+type CatalogIndex struct {
+	names map[string]uint // 规范化的名称或同义名称 -> 项目ID
+	codes map[string]uint // 大写编码 -> 项目ID
+}
+
+// LoadCatalogIndex 读取全部目录项目的名称、同义名称与编码；名称重复的项目取ID最小者
+func LoadCatalogIndex(db *gorm.DB) (*CatalogIndex, error) {
+	var items []models.HealthItem
+	if err := db.Scopes(CatalogItems).Select("id, item_name, code").Order("id").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	var synonyms []models.HealthItemSynonym
+	if err := db.Order("id").Find(&synonyms).Error; err != nil {
+		return nil, err
+	}
+	index := &CatalogIndex{names: make(map[string]uint), codes: make(map[string]uint)}
+	for _, item := range items {
+		if key := NormalizeItemName(item.ItemName); key != "" {
+			if _, ok := index.names[key]; !ok {
+				index.names[key] = item.ID
+			}
+		}
+		if code := strings.ToUpper(strings.TrimSpace(item.Code)); code != "" {
+			if _, ok := index.codes[code]; !ok {
+				index.codes[code] = item.ID
+			}
+		}
+	}
+	for _, s := range synonyms {
+		if _, ok := index.names[s.NameKey]; !ok {
+			index.names[s.NameKey] = s.RelationHealthItemId
+		}
+	}
+	return index, nil
+}
+
+// Lookup 按名称、同义名称、编码的顺序查找项目，未找到返回 0
+func (c *CatalogIndex) Lookup(name string) uint {
+	if id, ok := c.names[NormalizeItemName(name)]; ok {
+		return id
+	}
+	return c.codes[strings.ToUpper(strings.TrimSpace(name))]
+}
+
+// Check 检查名称与编码未被 itemID 以外的项目占用
+func (c *CatalogIndex) Check(itemID uint, names []string, code string) error {
+	for _, name := range names {
+		if id, ok := c.names[NormalizeItemName(name)]; ok && id != itemID {
+			return fmt.Errorf("%w: %q (item %d)", ErrCatalogNameTaken, name, id)
+		}
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if id, ok := c.codes[code]; ok && code != "" && id != itemID {
+		return fmt.Errorf("%w: %q (item %d)", ErrCatalogCodeTaken, code, id)
+	}
+	return nil
+}
+
+// ResolveCatalogItem 按名称、同义名称或编码在目录中查找项目，未找到返回 gorm.ErrRecordNotFound
+func ResolveCatalogItem(db *gorm.DB, name string) (models.HealthItem, error) {
+	var item models.HealthItem
+	index, err := LoadCatalogIndex(db)
+	if err != nil {
+		return item, err
+	}
+	id := index.Lookup(name)
+	if id == 0 {
+		return item, gorm.ErrRecordNotFound
+	}
+	err = db.First(&item, id).Error
+	return item, err
+}
+
+// SetSynonyms 以 names 替换项目的全部同义名称；与项目名称相同或重复的名称忽略，
+// 已被其他项目用作名称或同义名称时返回 ErrCatalogNameTaken
+func SetSynonyms(tx *gorm.DB, item models.HealthItem, names []string) ([]models.HealthItemSynonym, error) {
+	index, err := LoadCatalogIndex(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := index.Check(item.ID, names, ""); err != nil {
+		return nil, err
+	}
+	// 同义名称唯一，旧记录须物理删除
+	if err := tx.Unscoped().Where("health_item_id = ?", item.ID).Delete(&models.HealthItemSynonym{}).Error; err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{NormalizeItemName(item.ItemName): true}
+	synonyms := make([]models.HealthItemSynonym, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := NormalizeItemName(name)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		synonyms = append(synonyms, models.HealthItemSynonym{RelationHealthItemId: item.ID, Name: name, NameKey: key})
+	}
+	if len(synonyms) > 0 {
+		if err := tx.Create(&synonyms).Error; err != nil {
+			return nil, err
+		}
+	}
+	return synonyms, nil
+}
+
+// FindDuplicateItems 找出疑似重复的目录项目：名称规范化后相同、名称与其他项目的同义名称相同或编码相同，
+// 每组按ID排序，组间按第一个项目的ID排序
+func FindDuplicateItems(db *gorm.DB) ([][]models.HealthItem, error) {
+	var items []models.HealthItem
+	if err := db.Scopes(CatalogItems).Order("id").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	var synonyms []models.HealthItemSynonym
+	if err := db.Find(&synonyms).Error; err != nil {
+		return nil, err
+	}
+
+	// 并查集：共用同一个名称键或编码的项目归入同一组
+	parent := make(map[uint]uint, len(items))
+	var find func(id uint) uint
+	find = func(id uint) uint {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+	owners := make(map[string]uint)
+	join := func(key string, id uint) {
+		if key == "" {
+			return
+		}
+		if other, ok := owners[key]; ok {
+			a, b := find(other), find(id)
+			if a > b {
+				a, b = b, a
+			}
+			parent[b] = a
+			return
+		}
+		owners[key] = id
+	}
+	byID := make(map[uint]models.HealthItem, len(items))
+	for _, item := range items {
+		parent[item.ID] = item.ID
+		byID[item.ID] = item
+	}
+	for _, item := range items {
+		join("name:"+NormalizeItemName(item.ItemName), item.ID)
+		if code := strings.ToUpper(strings.TrimSpace(item.Code)); code != "" {
+			join("code:"+code, item.ID)
+		}
+	}
+	for _, s := range synonyms {
+		if _, ok := byID[s.RelationHealthItemId]; ok {
+			join("name:"+s.NameKey, s.RelationHealthItemId)
+		}
+	}
+
+	groups := make(map[uint][]models.HealthItem)
+	for _, item := range items {
+		root := find(item.ID)
+		groups[root] = append(groups[root], item)
+	}
+	duplicates := make([][]models.HealthItem, 0)
+	for _, group := range groups {
+		if len(group) > 1 {
+			duplicates = append(duplicates, group)
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool { return duplicates[i][0].ID < duplicates[j][0].ID })
+	return duplicates, nil
+}
+
+// MergedItemTargets 返回 ids 中已合并项目合并后的项目ID；合并时会拉平合并链，一次查询即可
+func MergedItemTargets(db *gorm.DB, ids []uint) (map[uint]uint, error) {
+	targets := make(map[uint]uint)
+	if len(ids) == 0 {
+		return targets, nil
+	}
+	var merged []models.HealthItem
+	if err := db.Unscoped().Select("id", "merged_into_id").
+		Where("id IN ? AND merged_into_id IS NOT NULL", ids).Find(&merged).Error; err != nil {
+		return nil, err
+	}
+	for _, item := range merged {
+		targets[item.ID] = *item.MergedIntoID
+	}
+	return targets, nil
+}
+
+// MergeHealthItems 将 sources 合并到 target，须在事务中调用：
+// 套餐、套餐版本、检查结果、结论点评、危急值与通知等对来源项目的引用改为 target，
+// 译名与 LOINC 映射在 target 没有时迁移，来源项目的名称与同义名称成为 target 的同义名称，
+// 公式中的引用随之改写，最后标记来源项目已合并并删除。已签署的结论修订版本不改写，读取时按 merged_into_id 换算；
+// 早期按项目ID计算摘要的报告记录在合并前换成按结果记录ID计算的摘要。
+// 同一用户套餐、套餐版本或结论同时包含多个待合并项目时返回 *MergeConflictError
+func MergeHealthItems(tx *gorm.DB, target models.HealthItem, sources []models.HealthItem) error {
+	sourceIDs := make([]uint, 0, len(sources))
+	rename := make(map[uint]uint, len(sources))
+	for _, s := range sources {
+		sourceIDs = append(sourceIDs, s.ID)
+		rename[s.ID] = target.ID
+	}
+	allIDs := append([]uint{target.ID}, sourceIDs...)
+
+	conflicts := &MergeConflictError{}
+	for _, check := range []struct {
+		table, group string
+		count        *int64
+	}{
+		{"user_health_items", "user_id, plan_id", &conflicts.Results},
+		{"plan_version_items", "plan_version_id", &conflicts.Versions},
+		{"conclusion_comments", "conclusion_id", &conflicts.Comments},
+	} {
+		sub := tx.Table(check.table).Select(check.group).
+			Where("health_item_id IN ? AND deleted_at IS NULL", allIDs).
+			Group(check.group).Having("COUNT(DISTINCT health_item_id) > 1")
+		if err := tx.Table("(?) AS conflicts", sub).Count(check.count).Error; err != nil {
+			return err
+		}
+	}
+	if conflicts.Results+conflicts.Versions+conflicts.Comments > 0 {
+		return conflicts
+	}
+
+	// 公式中的引用改写为 target，target 引用来源项目会变成引用自身
+	var derived []models.HealthItem
+	if err := tx.Scopes(CatalogItems).Where("formula <> ''").Find(&derived).Error; err != nil {
+		return err
+	}
+	for _, item := range derived {
+		rewritten := formula.RenameItems(item.Formula, rename)
+		if rewritten == item.Formula {
+			continue
+		}
+		if item.ID == target.ID {
+			return ErrFormulaCycle
+		}
+		if err := tx.Model(&models.HealthItem{}).Where("id = ?", item.ID).Update("formula", rewritten).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, source := range sources {
+		// 已包含 target 的套餐直接去掉来源项目，其余改为 target
+		var planIDs []uint
+		if err := tx.Model(&models.PlanHeathItem{}).Where("health_item_id = ?", target.ID).
+			Pluck("plan_id", &planIDs).Error; err != nil {
+			return err
+		}
+		if len(planIDs) > 0 {
+			if err := tx.Where("health_item_id = ? AND plan_id IN ?", source.ID, planIDs).
+				Delete(&models.PlanHeathItem{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.PlanHeathItem{}).Where("health_item_id = ?", source.ID).
+			Update("health_item_id", target.ID).Error; err != nil {
+			return err
+		}
+
+		// 译名与 LOINC 映射每个项目只有一份，target 已有的保留 target 的
+		var languages []string
+		if err := tx.Model(&models.HealthItemTranslation{}).Where("health_item_id = ?", target.ID).
+			Pluck("language", &languages).Error; err != nil {
+			return err
+		}
+		if len(languages) > 0 {
+			if err := tx.Unscoped().Where("health_item_id = ? AND language IN ?", source.ID, languages).
+				Delete(&models.HealthItemTranslation{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.HealthItemTranslation{}).Where("health_item_id = ?", source.ID).
+			Update("health_item_id", target.ID).Error; err != nil {
+			return err
+		}
+		var mapped int64
+		if err := tx.Model(&models.LoincMapping{}).Where("health_item_id = ?", target.ID).Count(&mapped).Error; err != nil {
+			return err
+		}
+		if mapped > 0 {
+			if err := tx.Unscoped().Where("health_item_id = ?", source.ID).Delete(&models.LoincMapping{}).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Model(&models.LoincMapping{}).Where("health_item_id = ?", source.ID).
+			Update("health_item_id", target.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := upgradeReportIssues(tx, sourceIDs); err != nil {
+		return err
+	}
+	for _, model := range []interface{}{
+		&models.UserHealthItem{},
+		&models.ConclusionComment{},
+		&models.CriticalAck{},
+		&models.CriticalAlert{},
+		&models.Notification{},
+	} {
+		if err := tx.Model(model).Where("health_item_id IN ?", sourceIDs).
+			Update("health_item_id", target.ID).Error; err != nil {
+			return err
+		}
+	}
+	// 套餐版本不可修改，合并只替换其中的项目ID，保存的名称与描述不变
+	if err := tx.Model(&models.PlanVersionItem{}).Where("health_item_id IN ?", sourceIDs).
+		UpdateColumn("health_item_id", target.ID).Error; err != nil {
+		return err
+	}
+
+	// 来源项目的名称与同义名称归入 target
+	var synonyms []models.HealthItemSynonym
+	if err := tx.Where("health_item_id IN ?", allIDs).Order("id").Find(&synonyms).Error; err != nil {
+		return err
+	}
+	names := make([]string, 0, len(synonyms)+len(sources))
+	for _, s := range synonyms {
+		names = append(names, s.Name)
+	}
+	for _, s := range sources {
+		names = append(names, s.ItemName)
+	}
+	if err := tx.Unscoped().Where("health_item_id IN ?", sourceIDs).Delete(&models.HealthItemSynonym{}).Error; err != nil {
+		return err
+	}
+
+	// 先前合并到来源项目的项目改为指向 target，再删除来源项目
+	if err := tx.Unscoped().Model(&models.HealthItem{}).Where("merged_into_id IN ? OR id IN ?", sourceIDs, sourceIDs).
+		Update("merged_into_id", target.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("id IN ?", sourceIDs).Delete(&models.HealthItem{}).Error; err != nil {
+		return err
+	}
+	if _, err := SetSynonyms(tx, target, names); err != nil {
+		return err
+	}
+	return SnapshotPlansWithItem(tx, target.ID)
+}
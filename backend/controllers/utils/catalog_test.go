@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"HealthCare/backend/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 合并只改写检查结果与点评草稿的项目ID：已签署的修订版本与已出具报告的摘要须在合并后仍然有效
+func TestMergeKeepsSignedConclusionAndIssuedReport(t *testing.T) {
+	const source, target = 7, 3
+	pkg := models.UserPackage{Model: gorm.Model{ID: 11}, Status: 1}
+	before := []models.UserHealthItem{
+		{Model: gorm.Model{ID: 101}, RelationHealthItemId: target, ItemValue: "5.6"},
+		{Model: gorm.Model{ID: 102}, RelationHealthItemId: source, ItemValue: "阴性"},
+	}
+	revision := models.ConclusionRevision{
+		UserPackageID: pkg.ID,
+		Revision:      1,
+		Summary:       "未见明显异常",
+		Comments:      []models.ItemComment{{HealthItemID: source, Comment: "复查"}},
+		SignedAt:      time.Date(2026, 3, 15, 9, 0, 0, 0, time.UTC),
+	}
+	signed := ConclusionHash(revision)
+	issued := reportHash(pkg, before, revision.Revision, models.ReportHashByResult)
+	legacy := reportHash(pkg, before, revision.Revision, models.ReportHashByItem)
+
+	// 合并后结果记录改挂到 target，记录ID不变
+	after := []models.UserHealthItem{before[0], before[1]}
+	after[1].RelationHealthItemId = target
+
+	report := &CheckupReport{Package: pkg, Revision: revision.Revision, results: after}
+	if got := report.HashFor(models.ReportHashByResult); got != issued {
+		t.Errorf("report hash changed after merge: %s != %s", got, issued)
+	}
+	if got := report.HashFor(models.ReportHashByItem); got == legacy {
+		t.Errorf("legacy hash unexpectedly unchanged after merge")
+	}
+	if got := ConclusionHash(revision); got != signed {
+		t.Errorf("signed conclusion hash changed: %s != %s", got, signed)
+	}
+
+	comments := revisionComments(revision.Comments, map[uint]uint{source: target})
+	if comments[target] != "复查" {
+		t.Errorf("comment for merged item = %q, want %q", comments[target], "复查")
+	}
+	if _, ok := comments[source]; ok {
+		t.Errorf("comment still keyed by merged item %d", source)
+	}
+
+	// 结果被修改时仍能发现
+	after[1].ItemValue = "6.8"
+	if got := report.HashFor(models.ReportHashByResult); got == issued {
+		t.Errorf("modified result not detected")
+	}
+}
+
+func TestRevisionComments(t *testing.T) {
+	list := []models.ItemComment{
+		{HealthItemID: 1, Comment: "a"},
+		{HealthItemID: 2, Comment: "b"},
+		{HealthItemID: 4, Comment: "c"},
+	}
+	got := revisionComments(list, map[uint]uint{2: 1, 4: 5})
+	want := map[uint]string{1: "a\nb", 5: "c"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for id, comment := range want {
+		if got[id] != comment {
+			t.Errorf("comments[%d] = %q, want %q", id, got[id], comment)
+		}
+	}
+}
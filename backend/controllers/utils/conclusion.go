@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"HealthCare/backend/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LatestConclusions 返回各用户套餐最新签署的结论修订版本，未签署过的套餐不在结果中
+func LatestConclusions(db *gorm.DB, packageIDs []uint) (map[uint]models.ConclusionRevision, error) {
+	latest := make(map[uint]models.ConclusionRevision, len(packageIDs))
+	if len(packageIDs) == 0 {
+		return latest, nil
+	}
+	var revisions []models.ConclusionRevision
+	if err := db.Where("user_package_id IN ?", packageIDs).Order("revision").Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range revisions {
+		latest[r.UserPackageID] = r
+	}
+	return latest, nil
+}
+
+// LatestConclusion 返回用户套餐最新签署的结论，未签署过时返回 nil
+func LatestConclusion(db *gorm.DB, packageID uint) (*models.ConclusionRevision, error) {
+	latest, err := LatestConclusions(db, []uint{packageID})
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := latest[packageID]; ok {
+		return &r, nil
+	}
+	return nil, nil
+}
+
+// SignConclusion 签署结论草稿：生成新的修订版本并将结论标记为已签署，须在事务中调用；
+// conclusion 需预加载 Comments，reason 为修订原因
+func SignConclusion(tx *gorm.DB, conclusion *models.CheckupConclusion, signer models.User, reason string) (models.ConclusionRevision, error) {
+	comments := make([]models.ItemComment, 0, len(conclusion.Comments))
+	for _, c := range conclusion.Comments {
+		comments = append(comments, models.ItemComment{HealthItemID: c.HealthItemID, Comment: c.Comment})
+	}
+	revision := models.ConclusionRevision{
+		ConclusionID:   conclusion.ID,
+		UserPackageID:  conclusion.UserPackageID,
+		Revision:       conclusion.Revision + 1,
+		Summary:        conclusion.Summary,
+		Recommendation: conclusion.Recommendation,
+		Comments:       comments,
+		DoctorName:     signer.Name,
+		SignedBy:       signer.ID,
+		SignedAt:       time.Now().Truncate(time.Second),
+		Reason:         reason,
+	}
+	revision.ContentHash = ConclusionHash(revision)
+	if err := tx.Create(&revision).Error; err != nil {
+		return revision, err
+	}
+	if err := tx.Model(conclusion).Updates(map[string]interface{}{
+		"status":    models.ConclusionFinal,
+		"revision":  revision.Revision,
+		"signed_at": revision.SignedAt,
+	}).Error; err != nil {
+		return revision, err
+	}
+	return revision, nil
+}
+
+// ConclusionHash 计算签署内容的摘要：结论、建议、项目点评、签署医生与时间
+func ConclusionHash(r models.ConclusionRevision) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "package:%d\nrevision:%d\nsummary:%q\nrecommendation:%q\n", r.UserPackageID, r.Revision, r.Summary, r.Recommendation)
+	for _, c := range r.Comments {
+		fmt.Fprintf(h, "item:%d:%q\n", c.HealthItemID, c.Comment)
+	}
+	fmt.Fprintf(h, "doctor:%q\nsigned_by:%d\nsigned_at:%d\n", r.DoctorName, r.SignedBy, r.SignedAt.Unix())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ConclusionComments 将修订版本中的项目点评转换为按项目ID索引的映射；
+// 修订版本签署后不再修改，其中已合并项目的ID按 merged_into_id 换成合并后的项目
+func ConclusionComments(db *gorm.DB, r *models.ConclusionRevision) (map[uint]string, error) {
+	if r == nil {
+		return make(map[uint]string), nil
+	}
+	ids := make([]uint, 0, len(r.Comments))
+	for _, c := range r.Comments {
+		ids = append(ids, c.HealthItemID)
+	}
+	targets, err := MergedItemTargets(db, ids)
+	if err != nil {
+		return nil, err
+	}
+	return revisionComments(r.Comments, targets), nil
+}
+
+// revisionComments 按合并关系归并点评，合并前后的项目各有点评时按签署顺序拼接
+func revisionComments(list []models.ItemComment, targets map[uint]uint) map[uint]string {
+	comments := make(map[uint]string, len(list))
+	for _, c := range list {
+		id := c.HealthItemID
+		if target, ok := targets[id]; ok {
+			id = target
+		}
+		if prev, ok := comments[id]; ok && prev != "" {
+			comments[id] = prev + "\n" + c.Comment
+		} else {
+			comments[id] = c.Comment
+		}
+	}
+	return comments
+}
@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"HealthCare/backend/config"
+	"HealthCare/backend/global"
+	"HealthCare/backend/models"
+	"log"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RaiseCriticalAlerts 检查刚写入的结果：结果已修改的未确认告警作废，新出现的危急值生成告警，
+// 并立即通知机构值班人员、客户本人及接收通知的家人；须在写入结果的事务中调用，返回新生成的告警
+func RaiseCriticalAlerts(tx *gorm.DB, pkg models.UserPackage, itemIDs []uint) ([]models.CriticalAlert, error) {
+	if len(itemIDs) == 0 {
+		return nil, nil
+	}
+	var rows []models.UserHealthItem
+	if err := tx.Preload("ThisHeathItem").
+		Where("user_id = ? AND plan_id = ? AND health_item_id IN ?", pkg.UserID, pkg.PlanID, itemIDs).
+		Order("health_item_id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var created []models.CriticalAlert
+	for _, r := range rows {
+		index := criticalValueIndex(r.ItemValue)
+		if err := tx.Model(&models.CriticalAlert{}).
+			Where("user_package_id = ? AND health_item_id = ? AND status = ? AND value_bidx <> ?",
+				pkg.ID, r.RelationHealthItemId, models.AlertOpen, index).
+			Update("status", models.AlertSuperseded).Error; err != nil {
+			return nil, err
+		}
+		if !IsCritical(r.ItemValue, r.ThisHeathItem) {
+			continue
+		}
+		// 同一结果已告警过（含已确认）时不重复告警
+		var count int64
+		if err := tx.Model(&models.CriticalAlert{}).
+			Where("user_package_id = ? AND health_item_id = ? AND value_bidx = ? AND status <> ?",
+				pkg.ID, r.RelationHealthItemId, index, models.AlertSuperseded).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			continue
+		}
+		alert := models.CriticalAlert{
+			UserPackageID: pkg.ID,
+			HealthItemID:  r.RelationHealthItemId,
+			InstitutionID: pkg.InstitutionID,
+			ValueIndex:    index,
+			EnteredBy:     r.EnteredBy,
+			Status:        models.AlertOpen,
+			Level:         models.EscalationOnCall,
+			NotifiedAt:    now,
+		}
+		if err := tx.Create(&alert).Error; err != nil {
+			return nil, err
+		}
+		created = append(created, alert)
+	}
+	if len(created) == 0 {
+		return nil, nil
+	}
+
+	staff, err := escalationRecipients(tx, pkg.InstitutionID, models.EscalationOnCall)
+	if err != nil {
+		return nil, err
+	}
+	family, err := GranteesWithScope(tx, pkg.UserID, models.ScopeNotifications)
+	if err != nil {
+		return nil, err
+	}
+	for _, alert := range created {
+		if err := notify(tx, staff, models.NotifyCriticalValue, alert); err != nil {
+			return nil, err
+		}
+		if err := notify(tx, append([]uint{pkg.UserID}, family...), models.NotifyCriticalPatient, alert); err != nil {
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+// closeCriticalAlerts 审核人确认危急值后关闭对应结果的未确认告警
+func closeCriticalAlerts(tx *gorm.DB, ack models.CriticalAck) error {
+	return tx.Model(&models.CriticalAlert{}).
+		Where("user_package_id = ? AND health_item_id = ? AND value_bidx = ? AND status = ?",
+			ack.UserPackageID, ack.HealthItemID, ack.ValueIndex, models.AlertOpen).
+		Updates(map[string]interface{}{
+			"status":          models.AlertAcknowledged,
+			"ack_id":          ack.ID,
+			"acknowledged_by": ack.AcknowledgedBy,
+			"acknowledged_at": ack.CreatedAt,
+		}).Error
+}
+
+// escalationRecipients 各升级级别的通知对象：值班成员（未设置值班时为机构主账号）、
+// 机构主账号与全部审核人、平台管理员
+func escalationRecipients(db *gorm.DB, institutionID uint, level uint8) ([]uint, error) {
+	var ids []uint
+	switch level {
+	case models.EscalationOnCall:
+		if err := activeMembers(db, institutionID).Where("institution_members.on_call = ?", true).
+			Pluck("institution_members.user_id", &ids).Error; err != nil {
+			return nil, err
+		}
+		if len(ids) > 0 {
+			return ids, nil
+		}
+		return ids, db.Model(&models.Institution{}).Where("id = ?", institutionID).Pluck("user_id", &ids).Error
+	case models.EscalationInstitution:
+		if err := db.Model(&models.Institution{}).Where("id = ?", institutionID).Pluck("user_id", &ids).Error; err != nil {
+			return nil, err
+		}
+		var reviewers []uint
+		if err := activeMembers(db, institutionID).Where("institution_members.role = ?", models.MemberRoleReviewer).
+			Pluck("institution_members.user_id", &reviewers).Error; err != nil {
+			return nil, err
+		}
+		return append(ids, reviewers...), nil
+	default:
+		return ids, db.Model(&models.User{}).Where("user_type = ?", 2).Pluck("id", &ids).Error
+	}
+}
+
+// activeMembers 机构中账号未注销的成员
+func activeMembers(db *gorm.DB, institutionID uint) *gorm.DB {
+	return db.Model(&models.InstitutionMember{}).
+		Joins("JOIN users ON users.id = institution_members.user_id AND users.deleted_at IS NULL").
+		Where("institution_members.institution_id = ?", institutionID)
+}
+
+// notify 向一组用户发送关于告警的通知，重复的用户只发送一次
+func notify(tx *gorm.DB, userIDs []uint, kind string, alert models.CriticalAlert) error {
+	notifications := make([]models.Notification, 0, len(userIDs))
+	seen := make([]uint, 0, len(userIDs))
+	for _, id := range userIDs {
+		if slices.Contains(seen, id) {
+			continue
+		}
+		seen = append(seen, id)
+		notifications = append(notifications, models.Notification{
+			UserID:          id,
+			Kind:            kind,
+			UserPackageID:   &alert.UserPackageID,
+			HealthItemID:    &alert.HealthItemID,
+			CriticalAlertID: &alert.ID,
+		})
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+	return tx.Create(&notifications).Error
+}
+
+// escalateCriticalAlert 告警超时未确认时升级一级并通知对应人员；并发执行时只有一个成功
+func escalateCriticalAlert(db *gorm.DB, alert models.CriticalAlert) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		level := alert.Level + 1
+		result := tx.Model(&models.CriticalAlert{}).
+			Where("id = ? AND status = ? AND level = ?", alert.ID, models.AlertOpen, alert.Level).
+			Updates(map[string]interface{}{"level": level, "notified_at": time.Now()})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		recipients, err := escalationRecipients(tx, alert.InstitutionID, level)
+		if err != nil {
+			return err
+		}
+		if err := notify(tx, recipients, models.NotifyCriticalEscalation, alert); err != nil {
+			return err
+		}
+		return WriteSystemAudit(tx, AuditEntry{
+			Action:     models.AuditCriticalEscalate,
+			TargetType: "user_package",
+			TargetID:   alert.UserPackageID,
+			Before:     map[string]interface{}{"alert_id": alert.ID, "level": alert.Level},
+			After:      map[string]interface{}{"alert_id": alert.ID, "level": level, "recipients": len(recipients)},
+		})
+	})
+}
+
+// ProcessDueEscalations 升级超过 critical-alerts.escalate-after-minutes 仍未确认的告警
+func ProcessDueEscalations() {
+	cutoff := time.Now().Add(-time.Duration(config.AppConfig.CriticalAlerts.EscalateAfterMinutes) * time.Minute)
+	var alerts []models.CriticalAlert
+	if err := global.DB.Preload("UserPackage").
+		Where("status = ? AND level < ? AND notified_at <= ?", models.AlertOpen, models.EscalationAdmin, cutoff).
+		Order("id").Find(&alerts).Error; err != nil {
+		log.Printf("critical alerts: load due alerts failed: %v", err)
+		return
+	}
+	for _, alert := range alerts {
+		if alert.UserPackage.ID == 0 {
+			continue // 用户套餐已删除
+		}
+		if err := escalateCriticalAlert(global.DB, alert); err != nil {
+			log.Printf("critical alerts: escalate alert %d failed: %v", alert.ID, err)
+		}
+	}
+}
+
+// StartCriticalAlertWorker 启动时调用，每分钟检查一次需要升级的危急值告警
+func StartCriticalAlertWorker() {
+	go func() {
+		ProcessDueEscalations()
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			ProcessDueEscalations()
+		}
+	}()
+}
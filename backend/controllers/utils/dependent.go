@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+)
+
+// 认领码字符集，去掉易混淆的 0/O、1/I/L
+const claimCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+const claimCodeLength = 10
+
+// NewClaimCode 生成被监护人认领码，返回认领码及其摘要（数据库只保存摘要）
+func NewClaimCode() (string, string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(claimCodeAlphabet)))
+	for i := 0; i < claimCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", "", err
+		}
+		sb.WriteByte(claimCodeAlphabet[n.Int64()])
+	}
+	code := sb.String()
+	return code, HashClaimCode(code), nil
+}
+
+// HashClaimCode 计算认领码摘要，忽略大小写与首尾空白
+func HashClaimCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToUpper(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
+
+// RandomToken 生成 n 字节的随机十六进制串，用于被监护人档案的占位用户名与密码
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
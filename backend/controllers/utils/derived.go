@@ -0,0 +1,246 @@
+package utils
+
+import (
+	"HealthCare/backend/formula"
+	"HealthCare/backend/models"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFormulaUnknownItem = errors.New("formula references an unknown item")
+	ErrFormulaCycle       = errors.New("formula references itself")
+)
+
+// ValidateFormula 解析项目 itemID 的公式，并检查引用的项目存在且不会形成循环引用
+func ValidateFormula(db *gorm.DB, itemID uint, src string) (*formula.Expr, error) {
+	expr, err := formula.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	var items []models.HealthItem
+	if err := db.Select("id, formula").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	formulas := make(map[uint]string, len(items))
+	for _, item := range items {
+		formulas[item.ID] = item.Formula
+	}
+	if err := checkFormulaRefs(itemID, src, expr, formulas); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// checkFormulaRefs 检查 expr 引用的项目都在 formulas（项目ID -> 公式）中，且沿衍生项目的引用不会回到 itemID
+func checkFormulaRefs(itemID uint, src string, expr *formula.Expr, formulas map[uint]string) error {
+	for _, id := range expr.Refs() {
+		if _, ok := formulas[id]; !ok {
+			return fmt.Errorf("%w: {%d}", ErrFormulaUnknownItem, id)
+		}
+	}
+
+	// 沿引用的衍生项目向下查找，回到 itemID 即为循环
+	formulas[itemID] = src
+	visited := make(map[uint]bool)
+	var reaches func(id uint) bool
+	reaches = func(id uint) bool {
+		if visited[id] {
+			return false
+		}
+		visited[id] = true
+		if formulas[id] == "" {
+			return false
+		}
+		ref, err := formula.Parse(formulas[id])
+		if err != nil {
+			return false
+		}
+		for _, r := range ref.Refs() {
+			if r == itemID || reaches(r) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, id := range expr.Refs() {
+		if id == itemID || reaches(id) {
+			return ErrFormulaCycle
+		}
+	}
+	return nil
+}
+
+// derivedEnv 计算公式时的输入：套餐中已有的结果与客户的年龄、性别
+type derivedEnv struct {
+	values map[uint]float64
+	vars   map[string]float64
+}
+
+func (e derivedEnv) Item(id uint) (float64, bool) {
+	v, ok := e.values[id]
+	return v, ok
+}
+
+func (e derivedEnv) Var(name string) (float64, bool) {
+	v, ok := e.vars[name]
+	return v, ok
+}
+
+// formatDerived 衍生结果的文本，保留至多 6 位小数以消除浮点误差
+func formatDerived(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e6)/1e6, 'f', -1, 64)
+}
+
+// ComputeDerivedItems 按公式重新计算用户套餐中的衍生项目，须在写入结果的事务中、结果写入后调用：
+// 输入齐全时写入或更新标记为 derived 的结果，输入缺失或无法计算时删除原先计算的结果；
+// 返回结果有变化的项目及其新值（删除时为空字符串）
+func ComputeDerivedItems(tx *gorm.DB, pkg models.UserPackage, enteredBy uint) (map[uint]string, []uint, error) {
+	_, contents, err := PackageContents(tx, pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([]uint, 0, len(contents))
+	for _, c := range contents {
+		ids = append(ids, c.HealthItemID)
+	}
+	var derivedItems []models.HealthItem
+	if len(ids) > 0 {
+		if err := tx.Where("id IN ? AND formula <> ''", ids).Order("id").Find(&derivedItems).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(derivedItems) == 0 {
+		return nil, nil, nil
+	}
+
+	var rows []models.UserHealthItem
+	if err := tx.Where("user_id = ? AND plan_id = ?", pkg.UserID, pkg.PlanID).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	existing := make(map[uint]models.UserHealthItem, len(rows))
+	env := derivedEnv{values: make(map[uint]float64), vars: make(map[string]float64)}
+	isDerived := make(map[uint]bool, len(derivedItems))
+	for _, item := range derivedItems {
+		isDerived[item.ID] = true
+	}
+	for _, r := range rows {
+		existing[r.RelationHealthItemId] = r
+		if isDerived[r.RelationHealthItemId] {
+			continue
+		}
+		if v, ok := leadingNumber(strings.TrimSpace(r.ItemValue)); ok {
+			env.values[r.RelationHealthItemId] = v
+		}
+	}
+	var patient models.User
+	if err := tx.Select("id, gender, birthday").First(&patient, pkg.UserID).Error; err != nil {
+		return nil, nil, err
+	}
+	checkupAt := pkg.CreatedAt
+	if pkg.AppointmentAt != nil {
+		checkupAt = *pkg.AppointmentAt
+	}
+	if age, ok := Age(patient.Birthday, checkupAt); ok {
+		env.vars[formula.VarAge] = float64(age)
+	}
+	switch strings.ToUpper(patient.Gender) {
+	case "M":
+		env.vars[formula.VarMale], env.vars[formula.VarFemale] = 1, 0
+	case "F":
+		env.vars[formula.VarMale], env.vars[formula.VarFemale] = 0, 1
+	}
+
+	// 衍生项目可以引用其他衍生项目：每轮计算所引用衍生项目均已确定的项目，直到没有进展
+	exprs := make(map[uint]*formula.Expr, len(derivedItems))
+	for _, item := range derivedItems {
+		expr, err := formula.Parse(item.Formula)
+		if err != nil {
+			continue // 保存公式时已校验，解析失败视为无法计算
+		}
+		exprs[item.ID] = expr
+	}
+	computed := make(map[uint]string, len(derivedItems))
+	settled := make(map[uint]bool, len(derivedItems))
+	for progress := true; progress; {
+		progress = false
+		for _, item := range derivedItems {
+			if settled[item.ID] {
+				continue
+			}
+			expr := exprs[item.ID]
+			ready := true
+			for _, ref := range exprRefs(expr) {
+				if isDerived[ref] && !settled[ref] {
+					ready = false
+				}
+			}
+			if !ready {
+				continue
+			}
+			settled[item.ID], progress = true, true
+			if expr == nil {
+				continue
+			}
+			if v, err := expr.Eval(env); err == nil {
+				computed[item.ID] = formatDerived(v)
+				env.values[item.ID] = v
+			}
+		}
+	}
+
+	changes := make(map[uint]string)
+	var order []uint
+	for _, item := range derivedItems {
+		value, ok := computed[item.ID]
+		row, exists := existing[item.ID]
+		switch {
+		case !ok && exists && row.Derived:
+			if err := tx.Unscoped().Delete(&row).Error; err != nil {
+				return nil, nil, err
+			}
+		case !ok:
+			continue // 无法计算，且没有计算过的结果
+		case exists && row.Derived && row.ItemValue == value:
+			continue // 结果未变
+		default:
+			if !exists {
+				row = models.UserHealthItem{
+					RelationUserId:       pkg.UserID,
+					RelationPlanId:       pkg.PlanID,
+					RelationHealthItemId: item.ID,
+				}
+			}
+			row.ItemValue = value
+			row.PlanVersionID = pkg.PlanVersionID
+			row.EnteredBy = &enteredBy
+			row.ReviewPending = true
+			row.Derived = true
+			if err := tx.Save(&row).Error; err != nil {
+				return nil, nil, err
+			}
+		}
+		changes[item.ID] = value
+		order = append(order, item.ID)
+	}
+	return changes, order, nil
+}
+
+func exprRefs(expr *formula.Expr) []uint {
+	if expr == nil {
+		return nil
+	}
+	return expr.Refs()
+}
+
+// IsDerivedItem 项目是否为由公式计算的衍生项目
+func IsDerivedItem(db *gorm.DB, itemID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.HealthItem{}).Where("id = ? AND formula <> ''", itemID).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"HealthCare/backend/formula"
+	"errors"
+	"testing"
+)
+
+func TestCheckFormulaRefs(t *testing.T) {
+	// 1 身高、2 体重、3 BMI = f(1, 2)、4 = f(3)、5 尚未设置公式
+	catalog := map[uint]string{1: "", 2: "", 3: "{2} / pow({1} / 100, 2)", 4: "round({3}, 1)", 5: ""}
+	tests := []struct {
+		name   string
+		itemID uint
+		src    string
+		want   error
+	}{
+		{"plain inputs", 5, "{1} + {2}", nil},
+		{"derived input", 5, "{4} * 2", nil},
+		{"unknown item", 5, "{1} + {9}", ErrFormulaUnknownItem},
+		{"self reference", 5, "{5} + 1", ErrFormulaCycle},
+		{"direct cycle", 3, "{4} + 1", ErrFormulaCycle},
+		{"indirect cycle", 1, "{4} * 100", ErrFormulaCycle},
+		{"replacing own formula", 3, "{2} / {1}", nil},
+		{"existing item without formula", 2, "{1} * 0.5", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := formula.Parse(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			formulas := make(map[uint]string, len(catalog))
+			for id, src := range catalog {
+				formulas[id] = src
+			}
+			if err := checkFormulaRefs(tt.itemID, tt.src, expr, formulas); !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"HealthCare/backend/config"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidAgeRange   = errors.New("invalid age range")
+	ErrInvalidSex        = errors.New("sex must be M, F or empty")
+	ErrUnknownRiskFactor = errors.New("unknown risk factor")
+)
+
+// 结果中出现以下词语视为异常（不区分大小写），前面带否定词时（如 "未见异常"、"无明显异常"）不计
+var abnormalWords = []string{"异常", "阳性", "偏高", "偏低", "升高", "降低", "超标", "positive", "abnormal", "high", "low", "↑", "↓"}
+
+// 以下词语表示正常，前面带否定词时（如 "不正常"、"非阴性"）视为异常
+var normalWords = []string{"正常", "阴性", "normal", "negative"}
+
+// 否定词及否定词与被否定词之间可出现的词，如 "未见明显异常"
+var (
+	negationPrefixes      = []string{"不", "非", "未", "无", "没"}
+	negationFillers       = []string{"明显", "见", "太", "有"}
+	latinNegationPrefixes = []string{"not", "no", "non"}
+)
+
+// 化验单上的高低标记，作为独立的词出现，如 "6.8 H"、"3.1 (L)"
+var abnormalFlags = []string{"h", "l", "hh", "ll", "h*", "l*"}
+
+// Age 根据生日计算周岁，生日为空或格式无法识别时返回 false
+// 生日可能是 "2006-01-02" 或数据库返回的带时间格式
+func Age(birthday string, now time.Time) (int, bool) {
+	if len(birthday) < 10 {
+		return 0, false
+	}
+	t, err := time.Parse("2006-01-02", birthday[:10])
+	if err != nil {
+		return 0, false
+	}
+	age := now.Year() - t.Year()
+	if now.Month() < t.Month() || (now.Month() == t.Month() && now.Day() < t.Day()) {
+		age--
+	}
+	return age, age >= 0
+}
+
+// IsAbnormal 判断检查结果是否异常
+// 数值结果且项目设置了参考范围时按范围判断，否则按结果文字判断
+func IsAbnormal(value string, item models.HealthItem) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return false
+	}
+
+	if v, ok := leadingNumber(value); ok && (item.ReferenceLow != nil || item.ReferenceHigh != nil) {
+		return (item.ReferenceLow != nil && v < *item.ReferenceLow) ||
+			(item.ReferenceHigh != nil && v > *item.ReferenceHigh)
+	}
+
+	return textAbnormal(strings.ToLower(value), item.Unit)
+}
+
+// textAbnormal 按结果文字判断是否异常：出现未被否定的异常词、被否定的正常词或高低标记即为异常；
+// unit 为项目单位，单位为 L（升）时不把 "L" 当作偏低标记
+func textAbnormal(lower, unit string) bool {
+	for _, w := range abnormalWords {
+		for _, i := range wordIndexes(lower, w) {
+			if !negated(lower[:i]) {
+				return true
+			}
+		}
+	}
+	for _, w := range normalWords {
+		for _, i := range wordIndexes(lower, w) {
+			if negated(lower[:i]) {
+				return true
+			}
+		}
+	}
+	for _, token := range strings.FieldsFunc(lower, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '(' || r == ')' || r == '[' || r == ']' || r == ',' || r == '，'
+	}) {
+		if token == "l" && strings.EqualFold(unit, "L") {
+			continue
+		}
+		for _, flag := range abnormalFlags {
+			if token == flag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// negated 判断紧接在 prefix 之后的词是否被否定，如 prefix 为 "未见明显" 或 "not "
+func negated(prefix string) bool {
+	prefix = strings.TrimRight(prefix, " ")
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, filler := range negationFillers {
+			if strings.HasSuffix(prefix, filler) {
+				prefix, trimmed = strings.TrimSuffix(prefix, filler), true
+			}
+		}
+	}
+	for _, p := range negationPrefixes {
+		if strings.HasSuffix(prefix, p) {
+			return true
+		}
+	}
+	lastWord := prefix[strings.LastIndexFunc(prefix, func(r rune) bool { return !isLatinLetter(r) })+1:]
+	for _, p := range latinNegationPrefixes {
+		if lastWord == p {
+			return true
+		}
+	}
+	return false
+}
+
+// wordIndexes 返回 word 在 text 中出现的位置；拉丁字母词须为完整单词，"yellow" 不视为包含 "low"
+func wordIndexes(text, word string) []int {
+	var indexes []int
+	latin := isLatinLetter(rune(word[0]))
+	for start := 0; ; {
+		i := strings.Index(text[start:], word)
+		if i < 0 {
+			return indexes
+		}
+		i += start
+		end := i + len(word)
+		if !latin || ((i == 0 || !isLatinLetter(rune(text[i-1]))) && (end == len(text) || !isLatinLetter(rune(text[end])))) {
+			indexes = append(indexes, i)
+		}
+		start = i + 1
+	}
+}
+
+func isLatinLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// leadingNumber 解析结果开头的数值，如 "5.6 mmol/L" -> 5.6
+func leadingNumber(value string) (float64, bool) {
+	end := 0
+	for i, r := range value {
+		if (r >= '0' && r <= '9') || r == '.' || (i == 0 && (r == '-' || r == '+')) {
+			end = i + 1
+			continue
+		}
+		break
+	}
+	if end == 0 {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(value[:end], 64)
+	return v, err == nil
+}
+
+// RiskFactorsForItem 根据检查项目名称匹配相关的风险因素代码
+func RiskFactorsForItem(itemName string) []string {
+	name := strings.ToLower(itemName)
+	var codes []string
+	for _, factor := range config.AppConfig.RiskFactors {
+		for _, keyword := range factor.Keywords {
+			if keyword != "" && strings.Contains(name, strings.ToLower(keyword)) {
+				codes = append(codes, factor.Code)
+				break
+			}
+		}
+	}
+	return codes
+}
+
+// ParseRiskFactors 解析逗号分隔的风险因素代码，忽略空白
+func ParseRiskFactors(s string) []string {
+	var codes []string
+	for _, code := range strings.Split(s, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// RiskFactorName 返回风险因素在指定语言下的名称
+func RiskFactorName(lang, code string) string {
+	factor, ok := config.RiskFactorByCode[code]
+	if !ok {
+		return code
+	}
+	if lang == i18n.En && factor.NameEn != "" {
+		return factor.NameEn
+	}
+	return factor.Name
+}
+
+// NormalizeEligibility 校验并规范化套餐适用条件，返回规范化后的性别与风险因素字符串
+func NormalizeEligibility(minAge, maxAge *uint, sex string, riskFactors []string) (string, string, error) {
+	if minAge != nil && maxAge != nil && *minAge > *maxAge {
+		return "", "", ErrInvalidAgeRange
+	}
+
+	sex = strings.ToUpper(strings.TrimSpace(sex))
+	if sex != "" && sex != "M" && sex != "F" {
+		return "", "", ErrInvalidSex
+	}
+
+	seen := make(map[string]bool)
+	var codes []string
+	for _, code := range riskFactors {
+		code = strings.TrimSpace(code)
+		if code == "" || seen[code] {
+			continue
+		}
+		if _, ok := config.RiskFactorByCode[code]; !ok {
+			return "", "", ErrUnknownRiskFactor
+		}
+		seen[code] = true
+		codes = append(codes, code)
+	}
+	return sex, strings.Join(codes, ","), nil
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"HealthCare/backend/models"
+	"testing"
+	"time"
+)
+
+func TestIsAbnormal(t *testing.T) {
+	low, high := 3.9, 6.1
+	ranged := models.HealthItem{ReferenceLow: &low, ReferenceHigh: &high}
+	liters := models.HealthItem{Unit: "L"}
+
+	tests := []struct {
+		value string
+		item  models.HealthItem
+		want  bool
+	}{
+		{"", models.HealthItem{}, false},
+		{"5.6 mmol/L", ranged, false},
+		{"6.8 mmol/L", ranged, true},
+		{"3.1", ranged, true},
+		{"6.8 H", ranged, true},
+		{"5.0 H", ranged, false}, // 有参考范围时按范围判断
+
+		{"正常", models.HealthItem{}, false},
+		{"不正常", models.HealthItem{}, true},
+		{"非正常心电图", models.HealthItem{}, true},
+		{"异常", models.HealthItem{}, true},
+		{"未见异常", models.HealthItem{}, false},
+		{"未见明显异常", models.HealthItem{}, false},
+		{"无明显异常", models.HealthItem{}, false},
+		{"无异常", models.HealthItem{}, false},
+		{"没有异常", models.HealthItem{}, false},
+		{"未见异常，心电图异常", models.HealthItem{}, true},
+		{"阴性", models.HealthItem{}, false},
+		{"阳性", models.HealthItem{}, true},
+		{"非阴性", models.HealthItem{}, true},
+		{"血压偏高", models.HealthItem{}, true},
+		{"未升高", models.HealthItem{}, false},
+
+		{"Negative", models.HealthItem{}, false},
+		{"Positive", models.HealthItem{}, true},
+		{"Normal", models.HealthItem{}, false},
+		{"not normal", models.HealthItem{}, true},
+		{"Abnormal", models.HealthItem{}, true},
+		{"no abnormal findings", models.HealthItem{}, false},
+		{"High", models.HealthItem{}, true},
+		{"LOW.", models.HealthItem{}, true},
+		{"yellow", models.HealthItem{}, false},
+		{"highlighted", models.HealthItem{}, false},
+
+		{"6.8 H", models.HealthItem{}, true},
+		{"3.1 (L)", models.HealthItem{}, true},
+		{"12 HH", models.HealthItem{}, true},
+		{"6.8 ↑", models.HealthItem{}, true},
+		{"5.6 mmol/L", models.HealthItem{}, false},
+		{"1.5 L", liters, false},
+		{"5.6", models.HealthItem{}, false},
+	}
+	for _, tt := range tests {
+		if got := IsAbnormal(tt.value, tt.item); got != tt.want {
+			t.Errorf("IsAbnormal(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestAge(t *testing.T) {
+	now := mustTime(t, "2026-03-15")
+	tests := []struct {
+		birthday string
+		want     int
+		ok       bool
+	}{
+		{"2000-03-15", 26, true},
+		{"2000-03-16", 25, true},
+		{"2000-03-15T00:00:00Z", 26, true},
+		{"2027-01-01", -1, false},
+		{"", 0, false},
+		{"2000/03/15", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Age(tt.birthday, now)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("Age(%q) = %d, %v, want %d, %v", tt.birthday, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	v, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
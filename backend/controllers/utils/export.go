@@ -0,0 +1,483 @@
+package utils
+
+import (
+	"HealthCare/backend/config"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExportData 个人数据导出的内容，即归档中的 data.json
+type ExportData struct {
+	ExportedAt   time.Time          `json:"exported_at"`
+	Profile      exportProfile      `json:"profile"`
+	Family       []exportFamily     `json:"family"`
+	Packages     []exportPackage    `json:"packages"`
+	Results      []exportResult     `json:"results"`
+	Commentaries []exportCommentary `json:"commentaries"`
+	Files        []exportFile       `json:"files"`
+}
+
+type exportProfile struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	Gender    string    `json:"gender"`
+	Birthday  string    `json:"birthday"`
+	Phone     string    `json:"phone"`
+	Email     string    `json:"email"`
+	Address   string    `json:"address"`
+	Language  string    `json:"language"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type exportFamily struct {
+	UserID       uint      `json:"user_id"`
+	Username     string    `json:"username"`
+	Name         string    `json:"name"`
+	Relationship string    `json:"relationship"` // 对方是我的什么人
+	Confirmed    bool      `json:"confirmed"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type exportPackage struct {
+	ID              uint       `json:"id"`
+	PlanID          uint       `json:"plan_id"`
+	PlanName        string     `json:"plan_name"`
+	PlanVersion     uint       `json:"plan_version,omitempty"`
+	InstitutionName string     `json:"institution_name"`
+	Status          uint8      `json:"status"` // 0: pending, 1: completed
+	AppointmentAt   *time.Time `json:"appointment_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+type exportResult struct {
+	PlanID        uint      `json:"plan_id"`
+	PlanName      string    `json:"plan_name"`
+	ItemID        uint      `json:"item_id"`
+	ItemName      string    `json:"item_name"`
+	Value         string    `json:"value"`
+	Unit          string    `json:"unit"`
+	ReferenceLow  *float64  `json:"reference_low"`
+	ReferenceHigh *float64  `json:"reference_high"`
+	Abnormal      bool      `json:"abnormal"`
+	RecordedAt    time.Time `json:"recorded_at"`
+}
+
+type exportCommentary struct {
+	ID               uint      `json:"id"`
+	PlanID           uint      `json:"plan_id"`
+	PlanName         string    `json:"plan_name"`
+	Rating           uint8     `json:"rating"`
+	Commentary       string    `json:"commentary"`
+	Hidden           bool      `json:"hidden"`
+	InstitutionReply string    `json:"institution_reply,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type exportFile struct {
+	ID          uint      `json:"id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Archive     string    `json:"archive_path"` // 文件在归档中的路径，文件丢失时为空
+	UploadedAt  time.Time `json:"uploaded_at"`
+	path        string
+}
+
+// CollectExport 汇总用户的个人数据，lang 决定检查项目名称的语言
+func CollectExport(db *gorm.DB, userID uint, lang string) (*ExportData, error) {
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	data := &ExportData{
+		ExportedAt: time.Now(),
+		Profile: exportProfile{
+			ID:        user.ID,
+			Username:  user.Username,
+			Name:      user.Name,
+			Gender:    user.Gender,
+			Birthday:  user.Birthday,
+			Phone:     user.Phone,
+			Email:     user.Email,
+			Address:   user.Address,
+			Language:  user.Language,
+			CreatedAt: user.CreatedAt,
+		},
+		Family:       []exportFamily{},
+		Packages:     []exportPackage{},
+		Results:      []exportResult{},
+		Commentaries: []exportCommentary{},
+		Files:        []exportFile{},
+	}
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+
+	var families []models.Family
+	if err := db.Preload("ThisUser").Preload("Relative").
+		Where("user_id = ? OR relative_id = ?", userID, userID).
+		Order("id").Find(&families).Error; err != nil {
+		return nil, err
+	}
+	for _, f := range families {
+		relative := f.Relative
+		if f.RelativeID == userID {
+			relative = f.ThisUser
+		}
+		data.Family = append(data.Family, exportFamily{
+			UserID:       relative.ID,
+			Username:     relative.Username,
+			Name:         relative.Name,
+			Relationship: RelationshipFrom(f, userID),
+			Confirmed:    f.Status == 1,
+			CreatedAt:    f.CreatedAt,
+		})
+	}
+
+	var packages []models.UserPackage
+	if err := db.Preload("Plan", unscoped).Preload("Institution", unscoped).Preload("PlanVersion").
+		Where("user_id = ?", userID).Order("id").Find(&packages).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range packages {
+		pkg := exportPackage{
+			ID:              p.ID,
+			PlanID:          p.PlanID,
+			PlanName:        p.Plan.PlanName,
+			InstitutionName: p.Institution.InstitutionName,
+			Status:          p.Status,
+			AppointmentAt:   p.AppointmentAt,
+			CreatedAt:       p.CreatedAt,
+		}
+		if p.PlanVersion != nil {
+			pkg.PlanName = p.PlanVersion.PlanName
+			pkg.PlanVersion = p.PlanVersion.Version
+		}
+		data.Packages = append(data.Packages, pkg)
+	}
+
+	var results []models.UserHealthItem
+	if err := db.Preload("ThisHeathItem", unscoped).Preload("ThisPlan", unscoped).Scopes(ReleasedResults).
+		Where("user_id = ?", userID).Order("plan_id, id").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	itemIDs := make([]uint, 0, len(results))
+	for _, r := range results {
+		itemIDs = append(itemIDs, r.RelationHealthItemId)
+	}
+	itemNames := LocalizedItemNames(lang, itemIDs)
+	for _, r := range results {
+		data.Results = append(data.Results, exportResult{
+			PlanID:        r.RelationPlanId,
+			PlanName:      r.ThisPlan.PlanName,
+			ItemID:        r.RelationHealthItemId,
+			ItemName:      LocalizeItemName(itemNames, r.RelationHealthItemId, r.ThisHeathItem.ItemName),
+			Value:         r.ItemValue,
+			Unit:          r.ThisHeathItem.Unit,
+			ReferenceLow:  r.ThisHeathItem.ReferenceLow,
+			ReferenceHigh: r.ThisHeathItem.ReferenceHigh,
+			Abnormal:      IsAbnormal(r.ItemValue, r.ThisHeathItem),
+			RecordedAt:    r.UpdatedAt,
+		})
+	}
+
+	var commentaries []models.Commentary
+	if err := db.Preload("ThisPlan", unscoped).
+		Where("user_id = ?", userID).Order("id").Find(&commentaries).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range commentaries {
+		data.Commentaries = append(data.Commentaries, exportCommentary{
+			ID:               c.ID,
+			PlanID:           c.RelationPlanId,
+			PlanName:         c.ThisPlan.PlanName,
+			Rating:           c.Rating,
+			Commentary:       c.Commentary,
+			Hidden:           c.Status == models.CommentaryHidden,
+			InstitutionReply: c.InstitutionReply,
+			CreatedAt:        c.CreatedAt,
+		})
+	}
+
+	var files []models.ReportFile
+	if err := db.Where("user_id = ?", userID).Order("id").Find(&files).Error; err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		data.Files = append(data.Files, exportFile{
+			ID:          f.ID,
+			FileName:    f.FileName,
+			ContentType: f.ContentType,
+			Size:        f.Size,
+			Archive:     "files/" + strconv.FormatUint(uint64(f.ID), 10) + "-" + f.FileName,
+			UploadedAt:  f.CreatedAt,
+			path:        StoragePath(f.Path),
+		})
+	}
+	return data, nil
+}
+
+// WriteExportArchive 将导出数据写为 zip 归档：data.json、report.html 以及 files/ 下的上传文件
+func WriteExportArchive(w io.Writer, data *ExportData, lang string) error {
+	archive := zip.NewWriter(w)
+
+	// 先写入文件，丢失的文件在 data.json 中不给出归档路径
+	for i := range data.Files {
+		if err := copyToArchive(archive, data.Files[i].Archive, data.Files[i].path); err != nil {
+			if !os.IsNotExist(err) {
+				return err
+			}
+			log.Printf("export: report file %d missing: %v", data.Files[i].ID, err)
+			data.Files[i].Archive = ""
+		}
+	}
+
+	jsonFile, err := archive.Create("data.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(jsonFile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+
+	htmlFile, err := archive.Create("report.html")
+	if err != nil {
+		return err
+	}
+	if err := exportTemplate.Execute(htmlFile, exportView{Lang: lang, Data: data}); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func copyToArchive(archive *zip.Writer, name, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// RunExportJob 在后台生成导出归档，完成后更新任务状态；由 go 语句调用
+func RunExportJob(jobID uint) {
+	var job models.ExportJob
+	if err := global.DB.First(&job, jobID).Error; err != nil {
+		log.Printf("export: load job %d failed: %v", jobID, err)
+		return
+	}
+	if err := global.DB.Model(&job).Update("status", models.ExportRunning).Error; err != nil {
+		log.Printf("export: start job %d failed: %v", jobID, err)
+		return
+	}
+
+	rel, size, err := buildExport(job)
+	now := time.Now()
+	updates := map[string]interface{}{"finished_at": now}
+	if err != nil {
+		log.Printf("export: job %d failed: %v", jobID, err)
+		message := err.Error()
+		if len(message) > 255 {
+			message = message[:255]
+		}
+		updates["status"] = models.ExportFailed
+		updates["error"] = message
+	} else {
+		updates["status"] = models.ExportReady
+		updates["path"] = rel
+		updates["size"] = size
+		updates["expires_at"] = now.Add(exportRetention())
+	}
+	if err := global.DB.Model(&models.ExportJob{}).Where("id = ?", jobID).Updates(updates).Error; err != nil {
+		log.Printf("export: finish job %d failed: %v", jobID, err)
+	}
+	CleanupExpiredExports()
+}
+
+// buildExport 生成归档文件，先写入临时文件再改名，避免下载到不完整的归档
+func buildExport(job models.ExportJob) (rel string, size int64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	data, err := CollectExport(global.DB, job.RelationUserId, job.Language)
+	if err != nil {
+		return "", 0, err
+	}
+
+	rel = fmt.Sprintf("exports/%d/export-%d.zip", job.RelationUserId, job.ID)
+	path := StoragePath(rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", 0, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "export-*.tmp")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := WriteExportArchive(tmp, data, job.Language); err != nil {
+		tmp.Close()
+		return "", 0, err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return "", 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, err
+	}
+	return rel, info.Size(), nil
+}
+
+// ResumeExportJobs 启动时调用：重新执行因重启中断的导出任务，并清理过期的导出文件
+func ResumeExportJobs() {
+	var jobs []models.ExportJob
+	if err := global.DB.Where("status IN ?", []uint8{models.ExportPending, models.ExportRunning}).
+		Find(&jobs).Error; err != nil {
+		log.Printf("export: load pending jobs failed: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		go RunExportJob(job.ID)
+	}
+	CleanupExpiredExports()
+}
+
+// CleanupExpiredExports 删除超过保留期限的导出文件，任务标记为已过期
+func CleanupExpiredExports() {
+	var jobs []models.ExportJob
+	if err := global.DB.Where("status = ? AND expires_at < ?", models.ExportReady, time.Now()).
+		Find(&jobs).Error; err != nil {
+		log.Printf("export: load expired jobs failed: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		if err := os.Remove(StoragePath(job.Path)); err != nil && !os.IsNotExist(err) {
+			log.Printf("export: remove %s failed: %v", job.Path, err)
+			continue
+		}
+		global.DB.Model(&job).Updates(map[string]interface{}{"status": models.ExportExpired, "path": ""})
+	}
+}
+
+func exportRetention() time.Duration {
+	hours := config.AppConfig.Storage.ExportRetentionHours
+	if hours <= 0 {
+		hours = 72
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// exportView 可读报告的模板数据
+type exportView struct {
+	Lang string
+	Data *ExportData
+}
+
+// T 模板中按报告语言翻译
+func (v exportView) T(key string) string {
+	return i18n.Translate(v.Lang, key)
+}
+
+var exportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+	"ptrdate": func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04")
+	},
+	"num": func(f *float64) string {
+		if f == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*f, 'f', -1, 64)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{.T "export_title"}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { background: #f4f4f4; }
+.abnormal { color: #c00; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>{{.T "export_title"}}</h1>
+<p>{{.T "export_generated_at"}}: {{date .Data.ExportedAt}}</p>
+
+<h2>{{.T "export_profile"}}</h2>
+<table>
+{{with .Data.Profile}}
+<tr><th>{{$.T "export_username"}}</th><td>{{.Username}}</td></tr>
+<tr><th>{{$.T "export_name"}}</th><td>{{.Name}}</td></tr>
+<tr><th>{{$.T "export_gender"}}</th><td>{{.Gender}}</td></tr>
+<tr><th>{{$.T "export_birthday"}}</th><td>{{.Birthday}}</td></tr>
+<tr><th>{{$.T "export_phone"}}</th><td>{{.Phone}}</td></tr>
+<tr><th>{{$.T "export_email"}}</th><td>{{.Email}}</td></tr>
+<tr><th>{{$.T "export_address"}}</th><td>{{.Address}}</td></tr>
+{{end}}
+</table>
+
+<h2>{{.T "export_family"}}</h2>
+<table>
+<tr><th>{{.T "export_name"}}</th><th>{{.T "export_relationship"}}</th><th>{{.T "export_confirmed"}}</th></tr>
+{{range .Data.Family}}<tr><td>{{.Name}} ({{.Username}})</td><td>{{.Relationship}}</td><td>{{if .Confirmed}}✓{{end}}</td></tr>
+{{end}}</table>
+
+<h2>{{.T "export_packages"}}</h2>
+<table>
+<tr><th>{{.T "export_plan"}}</th><th>{{.T "export_institution"}}</th><th>{{.T "export_appointment"}}</th><th>{{.T "export_completed"}}</th><th>{{.T "export_created_at"}}</th></tr>
+{{range .Data.Packages}}<tr><td>{{.PlanName}}</td><td>{{.InstitutionName}}</td><td>{{ptrdate .AppointmentAt}}</td><td>{{if eq .Status 1}}✓{{end}}</td><td>{{date .CreatedAt}}</td></tr>
+{{end}}</table>
+
+<h2>{{.T "export_results"}}</h2>
+<table>
+<tr><th>{{.T "export_plan"}}</th><th>{{.T "export_item"}}</th><th>{{.T "export_value"}}</th><th>{{.T "export_reference"}}</th><th>{{.T "export_recorded_at"}}</th></tr>
+{{range .Data.Results}}<tr><td>{{.PlanName}}</td><td>{{.ItemName}}</td><td{{if .Abnormal}} class="abnormal"{{end}}>{{.Value}} {{.Unit}}</td><td>{{if or .ReferenceLow .ReferenceHigh}}{{num .ReferenceLow}} - {{num .ReferenceHigh}} {{.Unit}}{{end}}</td><td>{{date .RecordedAt}}</td></tr>
+{{end}}</table>
+
+<h2>{{.T "export_commentaries"}}</h2>
+<table>
+<tr><th>{{.T "export_plan"}}</th><th>{{.T "export_rating"}}</th><th>{{.T "export_commentary"}}</th><th>{{.T "export_reply"}}</th><th>{{.T "export_created_at"}}</th></tr>
+{{range .Data.Commentaries}}<tr><td>{{.PlanName}}</td><td>{{.Rating}}</td><td>{{.Commentary}}</td><td>{{.InstitutionReply}}</td><td>{{date .CreatedAt}}</td></tr>
+{{end}}</table>
+
+<h2>{{.T "export_files"}}</h2>
+<table>
+<tr><th>{{.T "export_file_name"}}</th><th>{{.T "export_uploaded_at"}}</th></tr>
+{{range .Data.Files}}<tr><td>{{if .Archive}}<a href="{{.Archive}}">{{.FileName}}</a>{{else}}{{.FileName}}{{end}}</td><td>{{date .UploadedAt}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"HealthCare/backend/global"
+	"HealthCare/backend/models"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ErrFamilyScopeDenied 数据所有者未向当前用户授予所需的授权范围
+var ErrFamilyScopeDenied = errors.New("family access not granted")
+
+// ValidFamilyScope 判断是否为可授予的授权范围
+func ValidFamilyScope(scope string) bool {
+	return slices.Contains(models.FamilyScopes, scope)
+}
+
+// activeGrants 有效授权查询：亲属关系仍为已确认状态，授权未撤销且未过期
+func activeGrants(db *gorm.DB, scope string) *gorm.DB {
+	return db.Model(&models.FamilyGrant{}).
+		Joins("JOIN families ON families.id = family_grants.family_id AND families.status = 1 AND families.deleted_at IS NULL").
+		Where("family_grants.scope = ? AND family_grants.revoked_at IS NULL", scope).
+		Where("family_grants.expires_at IS NULL OR family_grants.expires_at > ?", time.Now())
+}
+
+// HasFamilyScope 判断 owner 是否向 grantee 授予了有效的 scope 授权
+// 监护人对其托管的被监护人档案拥有全部授权
+func HasFamilyScope(db *gorm.DB, ownerID, granteeID uint, scope string) (bool, error) {
+	var count int64
+	if err := db.Model(&models.User{}).Where("id = ? AND guardian_id = ?", ownerID, granteeID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	err := activeGrants(db, scope).
+		Where("family_grants.owner_id = ? AND family_grants.grantee_id = ?", ownerID, granteeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GranteesWithScope 返回 owner 授予了有效 scope 授权的亲属ID，owner 为被监护人时包含其监护人
+func GranteesWithScope(db *gorm.DB, ownerID uint, scope string) ([]uint, error) {
+	var ids []uint
+	if err := activeGrants(db, scope).
+		Where("family_grants.owner_id = ?", ownerID).
+		Pluck("family_grants.grantee_id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	var owner models.User
+	if err := db.Select("id, guardian_id").First(&owner, ownerID).Error; err != nil {
+		return nil, err
+	}
+	if owner.GuardianID != nil && !slices.Contains(ids, *owner.GuardianID) {
+		ids = append(ids, *owner.GuardianID)
+	}
+	return ids, nil
+}
+
+// AuthorizeFamilyAccess 检查 actor 是否获得 owner 的 scope 授权，通过时写入访问日志
+// 日志写入失败时拒绝访问，保证每次代理访问都有记录
+func AuthorizeFamilyAccess(ctx *gin.Context, ownerID, actorID uint, scope string) error {
+	ok, err := HasFamilyScope(global.DB, ownerID, actorID, scope)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrFamilyScopeDenied
+	}
+	return global.DB.Create(&models.FamilyAccessLog{
+		OwnerID: ownerID,
+		ActorID: actorID,
+		Scope:   scope,
+		Action:  ctx.Request.Method + " " + ctx.Request.URL.Path,
+		IP:      ctx.ClientIP(),
+	}).Error
+}
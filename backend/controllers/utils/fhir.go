@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"HealthCare/backend/fhir"
+	"HealthCare/backend/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FHIRBundle 将用户套餐的检查结果转换为 FHIR R4 collection Bundle：
+// Patient、Organization（体检机构）、DiagnosticReport（套餐）以及每个检查项目一条 Observation
+// pkg 需预加载 User、Institution、Plan（及 PlanVersion），results 需预加载 ThisHeathItem；
+// mappings 为检查项目ID到 LOINC 映射，未映射的项目只使用本系统编码
+func FHIRBundle(pkg models.UserPackage, results []models.UserHealthItem, mappings map[uint]models.LoincMapping, lang string) fhir.Bundle {
+	now := time.Now()
+	patientID, organizationID, reportID := fhir.NewUUID(), fhir.NewUUID(), fhir.NewUUID()
+	patientRef := &fhir.Reference{Reference: fhir.URN(patientID), Display: pkg.User.Name}
+	organizationRef := fhir.Reference{Reference: fhir.URN(organizationID), Display: pkg.Institution.InstitutionName}
+
+	patient := fhir.Patient{
+		ResourceType: "Patient",
+		ID:           patientID,
+		Identifier:   []fhir.Identifier{fhir.LocalIdentifier("user", pkg.User.ID)},
+		Gender:       fhirGender(pkg.User.Gender),
+	}
+	if pkg.User.Name != "" {
+		patient.Name = []fhir.HumanName{{Text: pkg.User.Name}}
+	}
+	if len(pkg.User.Birthday) >= 10 {
+		patient.BirthDate = pkg.User.Birthday[:10]
+	}
+	if pkg.User.Phone != "" {
+		patient.Telecom = append(patient.Telecom, fhir.Contact{System: "phone", Value: pkg.User.Phone})
+	}
+	if pkg.User.Email != "" {
+		patient.Telecom = append(patient.Telecom, fhir.Contact{System: "email", Value: pkg.User.Email})
+	}
+	if pkg.User.Address != "" {
+		patient.Address = []fhir.Address{{Text: pkg.User.Address}}
+	}
+
+	organization := fhir.Organization{
+		ResourceType: "Organization",
+		ID:           organizationID,
+		Identifier:   []fhir.Identifier{fhir.LocalIdentifier("institution", pkg.Institution.ID)},
+		Name:         pkg.Institution.InstitutionName,
+	}
+	if pkg.Institution.InstitutionPhone != "" {
+		organization.Telecom = []fhir.Contact{{System: "phone", Value: pkg.Institution.InstitutionPhone}}
+	}
+	if pkg.Institution.InstitutionAddress != "" {
+		organization.Address = []fhir.Address{{Text: pkg.Institution.InstitutionAddress}}
+	}
+
+	itemIDs := make([]uint, 0, len(results))
+	for _, r := range results {
+		itemIDs = append(itemIDs, r.RelationHealthItemId)
+	}
+	itemNames := LocalizedItemNames(lang, itemIDs)
+
+	// 未发布的结果尚未经审核，报告与各项结果均为 preliminary
+	status := "preliminary"
+	if pkg.ReviewStatus == models.ReviewReleased {
+		status = "final"
+	}
+
+	var observations []fhir.BundleEntry
+	var resultRefs []fhir.Reference
+	var effective time.Time
+	for _, r := range results {
+		name := LocalizeItemName(itemNames, r.RelationHealthItemId, r.ThisHeathItem.ItemName)
+		observation := fhirObservation(r, name, mappings[r.RelationHealthItemId], status)
+		observation.Subject = patientRef
+		observation.Performer = []fhir.Reference{organizationRef}
+		observations = append(observations, fhir.BundleEntry{FullURL: fhir.URN(observation.ID), Resource: observation})
+		resultRefs = append(resultRefs, fhir.Reference{Reference: fhir.URN(observation.ID), Display: name})
+		if r.UpdatedAt.After(effective) {
+			effective = r.UpdatedAt
+		}
+	}
+
+	planName := pkg.Plan.PlanName
+	if pkg.PlanVersion != nil {
+		planName = pkg.PlanVersion.PlanName
+	}
+	report := fhir.DiagnosticReport{
+		ResourceType: "DiagnosticReport",
+		ID:           reportID,
+		Identifier:   []fhir.Identifier{fhir.LocalIdentifier("user-package", pkg.ID)},
+		Status:       status,
+		Category:     []fhir.CodeableConcept{fhir.Concept(fhir.SystemDiagnosticType, "LAB", "Laboratory")},
+		Code:         fhir.Concept("urn:healthcare:plan", strconv.FormatUint(uint64(pkg.PlanID), 10), planName),
+		Subject:      patientRef,
+		Issued:       fhir.DateTime(now),
+		Performer:    []fhir.Reference{organizationRef},
+		Result:       resultRefs,
+	}
+	if !effective.IsZero() {
+		report.EffectiveDateTime = fhir.DateTime(effective)
+	}
+
+	entries := []fhir.BundleEntry{
+		{FullURL: fhir.URN(patientID), Resource: patient},
+		{FullURL: fhir.URN(organizationID), Resource: organization},
+		{FullURL: fhir.URN(reportID), Resource: report},
+	}
+	return fhir.Bundle{
+		ResourceType: "Bundle",
+		ID:           fhir.NewUUID(),
+		Type:         "collection",
+		Timestamp:    fhir.DateTime(now),
+		Entry:        append(entries, observations...),
+	}
+}
+
+// fhirObservation 将一条检查结果转换为 Observation，status 为结果状态（preliminary 或 final）
+// 结果为数值（可带单位）时输出 valueQuantity 与参考范围，否则输出 valueString
+func fhirObservation(r models.UserHealthItem, name string, mapping models.LoincMapping, status string) fhir.Observation {
+	item := r.ThisHeathItem
+	code := fhir.CodeableConcept{Text: name}
+	if mapping.Code != "" {
+		code.Coding = append(code.Coding, fhir.Coding{System: fhir.SystemLOINC, Code: mapping.Code, Display: mapping.Display})
+	}
+	code.Coding = append(code.Coding, fhir.Coding{
+		System:  "urn:healthcare:health-item",
+		Code:    strconv.FormatUint(uint64(r.RelationHealthItemId), 10),
+		Display: name,
+	})
+
+	observation := fhir.Observation{
+		ResourceType:      "Observation",
+		ID:                fhir.NewUUID(),
+		Status:            status,
+		Category:          []fhir.CodeableConcept{fhir.Concept(fhir.SystemObsCategory, "laboratory", "Laboratory")},
+		Code:              code,
+		EffectiveDateTime: fhir.DateTime(r.UpdatedAt),
+	}
+
+	unit := item.Unit
+	ucum := mapping.Unit
+	if ucum == "" {
+		ucum = unit
+	}
+	quantity := func(v float64) *fhir.Quantity {
+		q := &fhir.Quantity{Value: v, Unit: unit}
+		if ucum != "" {
+			q.System, q.Code = fhir.SystemUCUM, ucum
+		}
+		return q
+	}
+
+	value := strings.TrimSpace(r.ItemValue)
+	v, ok := leadingNumber(value)
+	if rest := strings.TrimSpace(strings.TrimLeft(value, "+-.0123456789")); ok && (rest == "" || rest == unit) {
+		observation.ValueQuantity = quantity(v)
+		if item.ReferenceLow != nil || item.ReferenceHigh != nil {
+			var rng fhir.ReferenceRange
+			if item.ReferenceLow != nil {
+				rng.Low = quantity(*item.ReferenceLow)
+			}
+			if item.ReferenceHigh != nil {
+				rng.High = quantity(*item.ReferenceHigh)
+			}
+			observation.ReferenceRange = []fhir.ReferenceRange{rng}
+
+			switch {
+			case item.ReferenceLow != nil && v < *item.ReferenceLow:
+				observation.Interpretation = []fhir.CodeableConcept{fhir.Concept(fhir.SystemInterpretation, "L", "Low")}
+			case item.ReferenceHigh != nil && v > *item.ReferenceHigh:
+				observation.Interpretation = []fhir.CodeableConcept{fhir.Concept(fhir.SystemInterpretation, "H", "High")}
+			default:
+				observation.Interpretation = []fhir.CodeableConcept{fhir.Concept(fhir.SystemInterpretation, "N", "Normal")}
+			}
+		}
+		return observation
+	}
+
+	observation.ValueString = value
+	if IsAbnormal(value, item) {
+		observation.Interpretation = []fhir.CodeableConcept{fhir.Concept(fhir.SystemInterpretation, "A", "Abnormal")}
+	}
+	return observation
+}
+
+func fhirGender(gender string) string {
+	switch strings.ToUpper(gender) {
+	case "M":
+		return "male"
+	case "F":
+		return "female"
+	}
+	return "unknown"
+}
@@ -69,4 +69,4 @@ func ParseJSONToHealthItemString(jsonString string) (string, error) {
 		return "", err
 	}
 	return BuildHealthItemString(itemMap), nil
-}
\ No newline at end of file
+}
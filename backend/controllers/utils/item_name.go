@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+)
+
+// LocalizedItemNames 查询健康项目在指定语言下的显示名称
+// 返回 health_item_id -> 译名，没有译名的项目不在结果中，调用方应回退到 HealthItem.ItemName
+func LocalizedItemNames(lang string, itemIDs []uint) map[uint]string {
+	names := make(map[uint]string)
+	if lang == i18n.DefaultLanguage || len(itemIDs) == 0 {
+		return names
+	}
+
+	var translations []models.HealthItemTranslation
+	if err := global.DB.Where("health_item_id IN ? AND language = ?", itemIDs, lang).Find(&translations).Error; err != nil {
+		return names
+	}
+	for _, t := range translations {
+		names[t.RelationHealthItemId] = t.ItemName
+	}
+	return names
+}
+
+// LocalizeItemName 返回单个健康项目的显示名称，没有译名时返回默认名称
+func LocalizeItemName(names map[uint]string, itemID uint, defaultName string) string {
+	if name, ok := names[itemID]; ok && name != "" {
+		return name
+	}
+	return defaultName
+}
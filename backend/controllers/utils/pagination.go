@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Pagination 列表接口统一的分页与排序参数
+// 查询参数: limit, offset, cursor, sort（字段名，前缀 "-" 表示降序）
+// cursor 为上一页返回的 next_cursor，仅在按 id 排序时可用，此时忽略 offset
+type Pagination struct {
+	Limit  int
+	Offset int
+	Cursor uint
+	Sort   string // 排序使用的 SQL 列
+	Desc   bool
+
+	idColumn string
+}
+
+// PageInfo 列表接口响应中的分页信息
+type PageInfo struct {
+	Limit      int   `json:"limit"`
+	Offset     int   `json:"offset"`
+	Total      int64 `json:"total"`
+	NextCursor *uint `json:"next_cursor,omitempty"`
+	NextOffset *int  `json:"next_offset,omitempty"`
+}
+
+var (
+	ErrInvalidLimit  = errors.New("invalid limit")
+	ErrInvalidOffset = errors.New("invalid offset")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort field")
+	ErrCursorSort    = errors.New("cursor pagination requires sorting by id")
+)
+
+// ParsePagination 解析分页与排序参数
+// sortable 为允许排序的字段名到 SQL 列的映射，必须包含 "id"；defaultSort 形如 "-id"
+func ParsePagination(ctx *gin.Context, sortable map[string]string, defaultSort string) (Pagination, error) {
+	p := Pagination{Limit: DefaultPageLimit, idColumn: sortable["id"]}
+
+	if s := ctx.Query("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 {
+			return p, ErrInvalidLimit
+		}
+		p.Limit = min(limit, MaxPageLimit)
+	}
+
+	if s := ctx.Query("offset"); s != "" {
+		offset, err := strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			return p, ErrInvalidOffset
+		}
+		p.Offset = offset
+	}
+
+	sort := ctx.DefaultQuery("sort", defaultSort)
+	if strings.HasPrefix(sort, "-") {
+		p.Desc = true
+		sort = sort[1:]
+	}
+	column, ok := sortable[sort]
+	if !ok {
+		return p, ErrInvalidSort
+	}
+	p.Sort = column
+
+	if s := ctx.Query("cursor"); s != "" {
+		cursor, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return p, ErrInvalidCursor
+		}
+		if column != p.idColumn {
+			return p, ErrCursorSort
+		}
+		p.Cursor = uint(cursor)
+		p.Offset = 0
+	}
+
+	return p, nil
+}
+
+// Paginate 统计总数并查询一页数据到 dest（切片指针），返回分页信息
+// dest 的元素须有 ID 字段，用于生成下一页游标
+func Paginate(query *gorm.DB, p Pagination, dest interface{}) (PageInfo, error) {
+	info := PageInfo{Limit: p.Limit, Offset: p.Offset}
+
+	if err := query.Session(&gorm.Session{}).Count(&info.Total).Error; err != nil {
+		return info, err
+	}
+
+	page := query.Session(&gorm.Session{})
+	if p.Cursor > 0 {
+		if p.Desc {
+			page = page.Where(p.idColumn+" < ?", p.Cursor)
+		} else {
+			page = page.Where(p.idColumn+" > ?", p.Cursor)
+		}
+	}
+
+	order := p.Sort
+	if p.Desc {
+		order += " DESC"
+	}
+	if p.Sort != p.idColumn {
+		// 次级按 id 排序，保证相同值的记录分页稳定
+		order += ", " + p.idColumn
+	}
+
+	if err := page.Order(order).Limit(p.Limit).Offset(p.Offset).Find(dest).Error; err != nil {
+		return info, err
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	if rows.Len() < p.Limit {
+		return info, nil
+	}
+	if p.Sort == p.idColumn {
+		if next, ok := rowID(rows.Index(rows.Len() - 1)); ok {
+			info.NextCursor = &next
+		}
+	}
+	if p.Cursor == 0 {
+		next := p.Offset + p.Limit
+		info.NextOffset = &next
+	}
+	return info, nil
+}
+
+// rowID 取结构体的 ID 字段或 map 的 "id" 键
+func rowID(row reflect.Value) (uint, bool) {
+	switch row.Kind() {
+	case reflect.Struct:
+		if id := row.FieldByName("ID"); id.IsValid() && id.CanUint() {
+			return uint(id.Uint()), true
+		}
+	case reflect.Map:
+		if id := row.MapIndex(reflect.ValueOf("id")); id.IsValid() {
+			if u, err := strconv.ParseUint(fmt.Sprint(id.Interface()), 10, 32); err == nil {
+				return uint(u), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// ParseDateRange 解析 date_from / date_to 查询参数（YYYY-MM-DD 或 RFC3339），date_to 为日期时包含当天
+func ParseDateRange(ctx *gin.Context) (from, to *time.Time, err error) {
+	parse := func(s string, endOfDay bool) (*time.Time, error) {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return &t, nil
+		}
+		t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		if endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return &t, nil
+	}
+
+	if s := ctx.Query("date_from"); s != "" {
+		if from, err = parse(s, false); err != nil {
+			return nil, nil, err
+		}
+	}
+	if s := ctx.Query("date_to"); s != "" {
+		if to, err = parse(s, true); err != nil {
+			return nil, nil, err
+		}
+	}
+	return from, to, nil
+}
+
+// ParseFloatRange 解析一对数值范围查询参数，如 min_price / max_price
+func ParseFloatRange(ctx *gin.Context, minKey, maxKey string) (lower, upper *float64, err error) {
+	parse := func(key string) (*float64, error) {
+		s := ctx.Query(key)
+		if s == "" {
+			return nil, nil
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		return &v, nil
+	}
+
+	if lower, err = parse(minKey); err != nil {
+		return nil, nil, err
+	}
+	if upper, err = parse(maxKey); err != nil {
+		return nil, nil, err
+	}
+	return lower, upper, nil
+}
+
+// ParseUintQuery 解析可选的无符号整数查询参数，未提供时返回 nil
+func ParseUintQuery(ctx *gin.Context, key string) (*uint, error) {
+	s := ctx.Query(key)
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	u := uint(v)
+	return &u, nil
+}
+
+// WhereRange 为查询追加 column 的上下界条件，边界为 nil 时忽略
+func WhereRange[T any](query *gorm.DB, column string, lower, upper *T) *gorm.DB {
+	if lower != nil {
+		query = query.Where(column+" >= ?", *lower)
+	}
+	if upper != nil {
+		query = query.Where(column+" <= ?", *upper)
+	}
+	return query
+}
@@ -0,0 +1,230 @@
+package utils
+
+import (
+	"HealthCare/backend/global"
+	"HealthCare/backend/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// SnapshotPlan 按套餐当前内容生成新版本并更新 plans.current_version
+// 内容与最新版本一致时不生成新版本，直接返回最新版本
+// 套餐内容发生变更后，在同一事务（或变更成功后）调用
+func SnapshotPlan(db *gorm.DB, planID uint) (*models.PlanVersion, error) {
+	var plan models.Plan
+	if err := db.First(&plan, planID).Error; err != nil {
+		return nil, err
+	}
+
+	var items []models.PlanVersionItem
+	if err := db.Table("plan_heath_items").
+		Select("plan_heath_items.health_item_id, health_items.item_name, plan_heath_items.item_description, plan_heath_items.item_metrics").
+		Joins("JOIN health_items ON health_items.id = plan_heath_items.health_item_id").
+		Where("plan_heath_items.plan_id = ? AND plan_heath_items.deleted_at IS NULL", planID).
+		Order("plan_heath_items.health_item_id").
+		Scan(&items).Error; err != nil {
+		return nil, err
+	}
+
+	hash, err := planContentHash(plan, items)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest models.PlanVersion
+	err = db.Where("plan_id = ?", planID).Order("version DESC").First(&latest).Error
+	if err == nil && latest.ContentHash == hash {
+		if plan.CurrentVersion != latest.Version {
+			if err := db.Model(&models.Plan{}).Where("id = ?", planID).Update("current_version", latest.Version).Error; err != nil {
+				return nil, err
+			}
+		}
+		return &latest, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	version := models.PlanVersion{
+		RelationPlanId: plan.ID,
+		Version:        latest.Version + 1,
+		PlanName:       plan.PlanName,
+		PlanPrice:      plan.PlanPrice,
+		Description:    plan.Description,
+		SuitableFor:    plan.SuitableFor,
+		ContentHash:    hash,
+		Items:          items,
+	}
+	if err := db.Create(&version).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&models.Plan{}).Where("id = ?", planID).Update("current_version", version.Version).Error; err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// SnapshotPlansWithItem 健康项目名称变更后，为包含该项目的套餐生成新版本
+func SnapshotPlansWithItem(db *gorm.DB, itemID uint) error {
+	var planIDs []uint
+	if err := db.Model(&models.PlanHeathItem{}).
+		Where("health_item_id = ?", itemID).
+		Distinct().Pluck("plan_id", &planIDs).Error; err != nil {
+		return err
+	}
+	for _, planID := range planIDs {
+		if _, err := SnapshotPlan(db, planID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CurrentPlanVersion 返回套餐的当前版本，尚无版本时先生成
+func CurrentPlanVersion(db *gorm.DB, planID uint) (*models.PlanVersion, error) {
+	var plan models.Plan
+	if err := db.Select("id, current_version").First(&plan, planID).Error; err != nil {
+		return nil, err
+	}
+	if plan.CurrentVersion == 0 {
+		return SnapshotPlan(db, planID)
+	}
+
+	var version models.PlanVersion
+	if err := db.Where("plan_id = ? AND version = ?", planID, plan.CurrentVersion).First(&version).Error; err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// LoadPlanVersion 按ID加载版本及其项目，id 为 nil 时返回 nil
+func LoadPlanVersion(db *gorm.DB, id *uint) (*models.PlanVersion, error) {
+	if id == nil {
+		return nil, nil
+	}
+	var version models.PlanVersion
+	if err := db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("health_item_id")
+	}).First(&version, *id).Error; err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// EnsurePlanVersions 启动时调用，为没有版本的套餐生成首个版本，
+// 并将未记录版本的用户套餐指向该版本
+func EnsurePlanVersions() {
+	var planIDs []uint
+	if err := global.DB.Model(&models.Plan{}).Where("current_version = 0").Pluck("id", &planIDs).Error; err != nil {
+		log.Printf("plan versions: query plans failed: %v", err)
+		return
+	}
+
+	for _, planID := range planIDs {
+		err := global.DB.Transaction(func(tx *gorm.DB) error {
+			version, err := SnapshotPlan(tx, planID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&models.UserPackage{}).
+				Where("plan_id = ? AND plan_version_id IS NULL", planID).
+				Update("plan_version_id", version.ID).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.UserHealthItem{}).
+				Where("plan_id = ? AND plan_version_id IS NULL", planID).
+				Update("plan_version_id", version.ID).Error
+		})
+		if err != nil {
+			log.Printf("plan versions: snapshot plan %d failed: %v", planID, err)
+		}
+	}
+}
+
+func planContentHash(plan models.Plan, items []models.PlanVersionItem) (string, error) {
+	type content struct {
+		Name        string
+		Price       float64
+		Description string
+		SuitableFor string
+		Items       [][4]interface{}
+	}
+
+	c := content{
+		Name:        plan.PlanName,
+		Price:       plan.PlanPrice,
+		Description: plan.Description,
+		SuitableFor: plan.SuitableFor,
+	}
+	sorted := append([]models.PlanVersionItem(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].RelationHealthItemId < sorted[j].RelationHealthItemId })
+	for _, item := range sorted {
+		c.Items = append(c.Items, [4]interface{}{item.RelationHealthItemId, item.ItemName, item.ItemDescription, item.ItemMetrics})
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// PackageItem 用户套餐中的一个体检项目
+type PackageItem struct {
+	PlanItemID      uint // 当前套餐中对应的 PlanHeathItem ID，项目已移出当前套餐时为 0
+	HealthItemID    uint
+	ItemName        string
+	ItemDescription string
+}
+
+// PackageContents 返回用户套餐应展示的名称与项目：已记录版本时按所购版本，否则按套餐当前内容
+func PackageContents(db *gorm.DB, userPackage models.UserPackage) (string, []PackageItem, error) {
+	var current []models.PlanHeathItem
+	if err := db.Preload("ThisHeathItem").Where("plan_id = ?", userPackage.PlanID).Find(&current).Error; err != nil {
+		return "", nil, err
+	}
+
+	version, err := LoadPlanVersion(db, userPackage.PlanVersionID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if version == nil {
+		var plan models.Plan
+		if err := db.Select("id, plan_name").First(&plan, userPackage.PlanID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil, err
+		}
+		items := make([]PackageItem, 0, len(current))
+		for _, phi := range current {
+			items = append(items, PackageItem{
+				PlanItemID:      phi.ID,
+				HealthItemID:    phi.RelationHealthItemId,
+				ItemName:        phi.ThisHeathItem.ItemName,
+				ItemDescription: phi.ItemDescription,
+			})
+		}
+		return plan.PlanName, items, nil
+	}
+
+	planItemIDs := make(map[uint]uint, len(current))
+	for _, phi := range current {
+		planItemIDs[phi.RelationHealthItemId] = phi.ID
+	}
+	items := make([]PackageItem, 0, len(version.Items))
+	for _, vi := range version.Items {
+		items = append(items, PackageItem{
+			PlanItemID:      planItemIDs[vi.RelationHealthItemId],
+			HealthItemID:    vi.RelationHealthItemId,
+			ItemName:        vi.ItemName,
+			ItemDescription: vi.ItemDescription,
+		})
+	}
+	return version.PlanName, items, nil
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"HealthCare/backend/models"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+// RefreshRatings 重新计算套餐及其所属机构的评分汇总
+// 只统计可见且已评分的评论，评论发布、删除、隐藏或恢复后调用
+func RefreshRatings(db *gorm.DB, planID uint) error {
+	var plan models.Plan
+	if err := db.Unscoped().Select("id, institution_id").First(&plan, planID).Error; err != nil {
+		return err
+	}
+
+	var planAgg ratingAggregate
+	if err := db.Model(&models.Commentary{}).
+		Select("COALESCE(AVG(rating), 0) AS avg, COUNT(*) AS count").
+		Where("plan_id = ? AND status = ? AND rating > 0", planID, models.CommentaryVisible).
+		Scan(&planAgg).Error; err != nil {
+		return err
+	}
+	if err := db.Unscoped().Model(&models.Plan{}).Where("id = ?", planID).Updates(planAgg.columns()).Error; err != nil {
+		return err
+	}
+
+	var institutionAgg ratingAggregate
+	if err := db.Model(&models.Commentary{}).
+		Select("COALESCE(AVG(commentaries.rating), 0) AS avg, COUNT(*) AS count").
+		Joins("JOIN plans ON plans.id = commentaries.plan_id AND plans.deleted_at IS NULL").
+		Where("plans.institution_id = ? AND commentaries.status = ? AND commentaries.rating > 0",
+			plan.RelationInstitutionID, models.CommentaryVisible).
+		Scan(&institutionAgg).Error; err != nil {
+		return err
+	}
+	return db.Model(&models.Institution{}).Where("id = ?", plan.RelationInstitutionID).Updates(institutionAgg.columns()).Error
+}
+
+type ratingAggregate struct {
+	Avg   float64
+	Count uint
+}
+
+func (a ratingAggregate) columns() map[string]interface{} {
+	return map[string]interface{}{
+		"rating_avg":   math.Round(a.Avg*100) / 100,
+		"rating_count": a.Count,
+	}
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"HealthCare/backend/config"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrUnknownRelationship = errors.New("unknown relationship")
+	ErrRelationshipGender  = errors.New("relationship does not match gender")
+)
+
+// genderedName 按性别取称谓，性别未知时仅在两种称谓相同时返回
+func genderedName(names config.GenderedName, gender string) string {
+	switch strings.ToUpper(gender) {
+	case "M":
+		return names.Male
+	case "F":
+		return names.Female
+	}
+	if names.Male == names.Female {
+		return names.Male
+	}
+	return ""
+}
+
+// ValidateRelationship 校验称谓在关系图中存在且与双方性别相符
+// relativeGender 为对方性别，须与称谓要求的性别一致；selfGender 为我的性别，须有对应的反向称谓
+func ValidateRelationship(name, selfGender, relativeGender string) error {
+	rel, ok := config.RelationByName[name]
+	if !ok {
+		return ErrUnknownRelationship
+	}
+	if rel.Gender != "" && relativeGender != "" && !strings.EqualFold(rel.Gender, relativeGender) {
+		return ErrRelationshipGender
+	}
+	if selfGender != "" && genderedName(rel.Inverse, selfGender) == "" {
+		return ErrRelationshipGender
+	}
+	return nil
+}
+
+// InverseRelationship B 是 A 的 name，返回 A 是 B 的什么人，gender 为 A 的性别
+// A 的性别未知时返回 "儿子/女儿" 形式；关系图中未定义的历史称谓原样返回
+func InverseRelationship(name, gender string) string {
+	rel, ok := config.RelationByName[name]
+	if !ok {
+		return name
+	}
+	if inverse := genderedName(rel.Inverse, gender); inverse != "" {
+		return inverse
+	}
+	var names []string
+	for _, n := range []string{rel.Inverse.Male, rel.Inverse.Female} {
+		if n != "" {
+			names = append(names, n)
+		}
+	}
+	if len(names) == 0 {
+		return name
+	}
+	return strings.Join(names, "/")
+}
+
+// RelationshipFrom 返回家庭关系中对方相对 viewerID 的称谓
+// Relationship 字段记录的是 relative 相对 user 的称谓，viewer 为 relative 一方时按发起人性别反转，需预加载 ThisUser
+func RelationshipFrom(family models.Family, viewerID uint) string {
+	if family.UserID == viewerID {
+		return family.Relationship
+	}
+	return InverseRelationship(family.Relationship, family.ThisUser.Gender)
+}
+
+// normalizeRelationship 推导传递关系前规范化称谓：性别中立的称谓按对方性别细化，再取泛称
+func normalizeRelationship(name, gender string) string {
+	rel, ok := config.RelationByName[name]
+	if !ok {
+		return name
+	}
+	if specific := genderedName(rel.Specific, gender); specific != "" {
+		name = specific
+		rel = config.RelationByName[name]
+	}
+	if rel.Base != "" {
+		return rel.Base
+	}
+	return name
+}
+
+// DeriveRelationship 推导传递关系：A 是我的 via（A 的性别为 viaGender），B 是 A 的 then（B 的性别为 thenGender），
+// 返回 B 是我的什么人；没有匹配的推导规则时返回 false
+func DeriveRelationship(via, viaGender, then, thenGender string) (string, bool) {
+	result, ok := config.RelationRuleMap[[2]string{
+		normalizeRelationship(via, viaGender),
+		normalizeRelationship(then, thenGender),
+	}]
+	return result, ok
+}
+
+// RelationshipName 返回称谓在指定语言下的名称
+func RelationshipName(lang, name string) string {
+	if rel, ok := config.RelationByName[name]; ok && lang == i18n.En && rel.NameEn != "" {
+		return rel.NameEn
+	}
+	return name
+}
+
+// RelationshipDegree 返回称谓的血缘亲等，非血亲或未定义的称谓返回 0
+// 兼容 InverseRelationship 在性别未知时返回的 "儿子/女儿" 形式
+func RelationshipDegree(name string) int {
+	name, _, _ = strings.Cut(name, "/")
+	return config.RelationByName[name].Degree
+}
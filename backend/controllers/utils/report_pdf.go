@@ -0,0 +1,568 @@
+package utils
+
+import (
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"HealthCare/backend/pdf"
+	"HealthCare/backend/qrcode"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportItem 报告中的一个项目行
+type ReportItem struct {
+	HealthItemID uint
+	ItemName     string
+	Category     string
+	Value        string
+	Unit         string
+	Reference    string
+	Flag         string // 异常标记：↑、↓ 或文字结果异常
+	Abnormal     bool
+	Tested       bool
+	Comment      string // 医生对该项目的点评
+}
+
+// CheckupReport 生成 PDF 体检报告所需的数据
+type CheckupReport struct {
+	Package        models.UserPackage
+	PlanName       string
+	Items          []ReportItem
+	Categories     []string // 分类的展示顺序，未分类项目归入最后的空分类
+	Conclusion     string   // 最新签署的医生结论，未签署时报告中只展示自动生成的异常汇总
+	Recommendation string
+	Doctor         string
+	SignedAt       *time.Time
+	Revision       uint
+	Hash           string // 检查结果与结论修订号的摘要（models.ReportHashByResult），用于验证报告出具后内容是否被修改
+
+	results []models.UserHealthItem
+}
+
+// BuildCheckupReport 按用户套餐所购版本整理报告内容：每个项目的结果、单位、参考范围与异常标记，
+// 项目按分类分组，分类按在套餐中首次出现的顺序排列；pkg 需预加载 User、Institution
+func BuildCheckupReport(db *gorm.DB, pkg models.UserPackage, lang string) (*CheckupReport, error) {
+	planName, contents, err := PackageContents(db, pkg)
+	if err != nil {
+		return nil, err
+	}
+	report := &CheckupReport{Package: pkg, PlanName: planName}
+
+	ids := make([]uint, 0, len(contents))
+	for _, c := range contents {
+		ids = append(ids, c.HealthItemID)
+	}
+	items := make(map[uint]models.HealthItem, len(ids))
+	if len(ids) > 0 {
+		var list []models.HealthItem
+		if err := db.Where("id IN ?", ids).Find(&list).Error; err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			items[item.ID] = item
+		}
+	}
+
+	results, err := packageResults(db, pkg)
+	if err != nil {
+		return nil, err
+	}
+	report.results = results
+	values := make(map[uint]string, len(results))
+	for _, r := range results {
+		values[r.RelationHealthItemId] = r.ItemValue
+	}
+
+	conclusion, err := LatestConclusion(db, pkg.ID)
+	if err != nil {
+		return nil, err
+	}
+	if conclusion != nil {
+		report.Conclusion = conclusion.Summary
+		report.Recommendation = conclusion.Recommendation
+		report.Doctor = conclusion.DoctorName
+		report.SignedAt = &conclusion.SignedAt
+		report.Revision = conclusion.Revision
+	}
+	comments, err := ConclusionComments(db, conclusion)
+	if err != nil {
+		return nil, err
+	}
+
+	names := LocalizedItemNames(lang, ids)
+	seen := make(map[string]bool)
+	hasOther := false
+	for _, c := range contents {
+		item := items[c.HealthItemID]
+		row := ReportItem{
+			HealthItemID: c.HealthItemID,
+			ItemName:     LocalizeItemName(names, c.HealthItemID, c.ItemName),
+			Category:     item.Category,
+			Unit:         item.Unit,
+			Reference:    referenceRange(item),
+			Comment:      comments[c.HealthItemID],
+		}
+		if value, ok := values[c.HealthItemID]; ok && strings.TrimSpace(value) != "" {
+			row.Value = value
+			row.Tested = true
+			row.Abnormal = IsAbnormal(value, item)
+			if row.Abnormal {
+				row.Flag = abnormalFlag(value, item, lang)
+			}
+		}
+		report.Items = append(report.Items, row)
+		if row.Category == "" {
+			hasOther = true
+		} else if !seen[row.Category] {
+			seen[row.Category] = true
+			report.Categories = append(report.Categories, row.Category)
+		}
+	}
+	if hasOther {
+		report.Categories = append(report.Categories, "")
+	}
+	report.Hash = report.HashFor(models.ReportHashByResult)
+	return report, nil
+}
+
+// HashFor 按指定的摘要计算方式计算报告摘要，验证早期出具的报告时使用其记录的计算方式
+func (r *CheckupReport) HashFor(version uint8) string {
+	return reportHash(r.Package, r.results, r.Revision, version)
+}
+
+func packageResults(db *gorm.DB, pkg models.UserPackage) ([]models.UserHealthItem, error) {
+	var results []models.UserHealthItem
+	err := db.Where("user_id = ? AND plan_id = ?", pkg.UserID, pkg.PlanID).Order("id").Find(&results).Error
+	return results, err
+}
+
+// reportHash 对套餐状态、各项结果与结论修订号计算摘要，与语言和项目名称无关；
+// models.ReportHashByItem 按项目ID索引结果，models.ReportHashByResult 按结果记录ID索引，
+// 后者在项目合并、结果改挂到合并后的项目时保持不变
+func reportHash(pkg models.UserPackage, results []models.UserHealthItem, revision uint, version uint8) string {
+	values := make(map[uint]string, len(results))
+	for _, r := range results {
+		if version == models.ReportHashByItem {
+			values[r.RelationHealthItemId] = r.ItemValue
+		} else {
+			values[r.ID] = r.ItemValue
+		}
+	}
+	ids := make([]uint, 0, len(values))
+	for id := range values {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	h := sha256.New()
+	fmt.Fprintf(h, "package:%d\nstatus:%d\n", pkg.ID, pkg.Status)
+	for _, id := range ids {
+		if version == models.ReportHashByItem {
+			fmt.Fprintf(h, "%d=%s\n", id, values[id])
+		} else {
+			fmt.Fprintf(h, "result:%d=%s\n", id, values[id])
+		}
+	}
+	if revision > 0 {
+		fmt.Fprintf(h, "conclusion:%d\n", revision)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// upgradeReportIssues 在项目合并改写检查结果之前调用：涉及 itemIDs 结果的早期报告记录中，
+// 内容仍与当前结果一致的改为按结果记录ID计算的摘要，避免合并后验证时被误判为已修改
+func upgradeReportIssues(tx *gorm.DB, itemIDs []uint) error {
+	var issues []models.ReportIssue
+	err := tx.Preload("UserPackage").
+		Joins("JOIN user_packages ON user_packages.id = report_issues.user_package_id").
+		Where("report_issues.hash_version = ?", models.ReportHashByItem).
+		Where("EXISTS (?)", tx.Table("user_health_items").Select("1").
+			Where("user_health_items.user_id = user_packages.user_id AND user_health_items.plan_id = user_packages.plan_id").
+			Where("user_health_items.health_item_id IN ? AND user_health_items.deleted_at IS NULL", itemIDs)).
+		Find(&issues).Error
+	if err != nil {
+		return err
+	}
+	reports := make(map[uint]*CheckupReport)
+	for _, issue := range issues {
+		report, ok := reports[issue.UserPackageID]
+		if !ok {
+			results, err := packageResults(tx, issue.UserPackage)
+			if err != nil {
+				return err
+			}
+			report = &CheckupReport{Package: issue.UserPackage, results: results}
+			conclusion, err := LatestConclusion(tx, issue.UserPackageID)
+			if err != nil {
+				return err
+			}
+			if conclusion != nil {
+				report.Revision = conclusion.Revision
+			}
+			reports[issue.UserPackageID] = report
+		}
+		if report.HashFor(models.ReportHashByItem) != issue.ContentHash {
+			continue
+		}
+		if err := tx.Model(&issue).Updates(map[string]interface{}{
+			"content_hash": report.HashFor(models.ReportHashByResult),
+			"hash_version": models.ReportHashByResult,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IssueReport 记录一次报告出具并返回验证码；结果未变化时复用已有记录，避免重复下载产生多个验证码
+func IssueReport(db *gorm.DB, report *CheckupReport, issuedBy uint) (models.ReportIssue, error) {
+	var issue models.ReportIssue
+	err := db.Where("user_package_id = ? AND content_hash = ? AND hash_version = ?", report.Package.ID, report.Hash, models.ReportHashByResult).
+		Order("id DESC").Limit(1).Find(&issue).Error
+	if err != nil || issue.ID != 0 {
+		return issue, err
+	}
+	code := make([]byte, 16)
+	if _, err := rand.Read(code); err != nil {
+		return issue, err
+	}
+	issue = models.ReportIssue{
+		UserPackageID: report.Package.ID,
+		Code:          hex.EncodeToString(code),
+		ContentHash:   report.Hash,
+		HashVersion:   models.ReportHashByResult,
+		IssuedBy:      issuedBy,
+	}
+	return issue, db.Create(&issue).Error
+}
+
+// MaskName 验证页面只展示姓氏，其余以 * 代替
+func MaskName(name string) string {
+	runes := []rune(name)
+	if len(runes) <= 1 {
+		return name
+	}
+	return string(runes[0]) + strings.Repeat("*", len(runes)-1)
+}
+
+func referenceRange(item models.HealthItem) string {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	switch {
+	case item.ReferenceLow != nil && item.ReferenceHigh != nil:
+		return format(*item.ReferenceLow) + " - " + format(*item.ReferenceHigh)
+	case item.ReferenceLow != nil:
+		return "≥ " + format(*item.ReferenceLow)
+	case item.ReferenceHigh != nil:
+		return "≤ " + format(*item.ReferenceHigh)
+	}
+	return ""
+}
+
+func abnormalFlag(value string, item models.HealthItem, lang string) string {
+	if v, ok := leadingNumber(strings.TrimSpace(value)); ok {
+		if item.ReferenceHigh != nil && v > *item.ReferenceHigh {
+			return "↑"
+		}
+		if item.ReferenceLow != nil && v < *item.ReferenceLow {
+			return "↓"
+		}
+	}
+	return i18n.Translate(lang, "report_flag_abnormal")
+}
+
+// 报告版式（单位为点）
+const (
+	reportMargin   = 50.0
+	reportBottom   = pdf.PageHeight - 60
+	reportQRSize   = 76.0
+	reportRowSize  = 9.0
+	reportRowSpace = 14.0
+)
+
+var (
+	reportGray      = pdf.Color{R: 0.4, G: 0.4, B: 0.4}
+	reportRule      = pdf.Color{R: 0.75, G: 0.75, B: 0.75}
+	reportHeaderBg  = pdf.Color{R: 0.92, G: 0.94, B: 0.97}
+	reportSectionBg = pdf.Color{R: 0.97, G: 0.97, B: 0.97}
+	reportRed       = pdf.Color{R: 0.8, G: 0, B: 0}
+	reportRedBg     = pdf.Color{R: 1, G: 0.93, B: 0.93}
+)
+
+// 结果表格各列的起始位置与宽度
+var reportColumns = [5]struct{ x, w float64 }{
+	{reportMargin, 180},       // 项目
+	{reportMargin + 185, 95},  // 结果
+	{reportMargin + 285, 60},  // 单位
+	{reportMargin + 350, 100}, // 参考范围
+	{reportMargin + 455, 40},  // 提示
+}
+
+// reportWriter 逐行排版报告，空间不足时自动换页
+type reportWriter struct {
+	doc    *pdf.Document
+	page   *pdf.Page
+	y      float64
+	lang   string
+	report *CheckupReport
+}
+
+func (w *reportWriter) t(key string) string {
+	return i18n.Translate(w.lang, key)
+}
+
+// ensure 保证当前页还有 height 的空间，否则换页并重绘续页页眉与表头
+func (w *reportWriter) ensure(height float64, tableHeader bool) {
+	if w.y+height <= reportBottom {
+		return
+	}
+	w.page = w.doc.AddPage()
+	inst := w.report.Package.Institution
+	w.page.Text(reportMargin, 50, 10, reportGray, inst.InstitutionName+"  "+w.t("report_title"))
+	w.page.Text(pdf.PageWidth-reportMargin-pdf.TextWidth(w.report.Package.User.Name, 10), 50, 10, reportGray, w.report.Package.User.Name)
+	w.page.Line(reportMargin, 58, pdf.PageWidth-reportMargin, 58, 0.5, reportRule)
+	w.y = 75
+	if tableHeader {
+		w.tableHeader()
+	}
+}
+
+func (w *reportWriter) tableHeader() {
+	w.page.Rect(reportMargin, w.y, pdf.PageWidth-2*reportMargin, 18, reportHeaderBg)
+	for i, key := range []string{"report_col_item", "report_col_value", "report_col_unit", "report_col_reference", "report_col_flag"} {
+		w.page.Text(reportColumns[i].x+3, w.y+12.5, 9.5, pdf.Black, w.t(key))
+	}
+	w.y += 20
+}
+
+// RenderReportPDF 生成 PDF 体检报告：机构抬头、客户信息、按分类分组的结果表（异常结果红色标出）、
+// 结论与医生签名、指向验证地址的二维码；每页页脚带页码与验证码
+func RenderReportPDF(report *CheckupReport, issue models.ReportIssue, verifyURL, lang string) ([]byte, error) {
+	qr, err := qrcode.Encode(verifyURL)
+	if err != nil {
+		return nil, err
+	}
+	pkg := report.Package
+	doc := pdf.New()
+	doc.Title = report.PlanName
+	doc.Author = pkg.Institution.InstitutionName
+	w := &reportWriter{doc: doc, page: doc.AddPage(), lang: lang, report: report}
+	right := pdf.PageWidth - reportMargin
+
+	// 机构抬头与验证二维码
+	headerWidth := right - reportMargin - reportQRSize - 10
+	y := 58.0
+	for _, line := range pdf.Wrap(pkg.Institution.InstitutionName, headerWidth, 18) {
+		w.page.Text(reportMargin, y, 18, pdf.Black, line)
+		y += 22
+	}
+	contact := strings.TrimSpace(pkg.Institution.InstitutionAddress + "  " + pkg.Institution.InstitutionPhone)
+	for _, line := range pdf.Wrap(contact, headerWidth, 9) {
+		w.page.Text(reportMargin, y, 9, reportGray, line)
+		y += 12
+	}
+	drawQRCode(w.page, qr, right-reportQRSize, 20, reportQRSize)
+	codeLabel := w.t("report_verify_code") + " " + issue.Code[:8]
+	w.page.Text(right-reportQRSize+(reportQRSize-pdf.TextWidth(codeLabel, 7))/2, 20+reportQRSize+9, 7, reportGray, codeLabel)
+	y = max(y, 20+reportQRSize+16)
+	w.page.Line(reportMargin, y, right, y, 1, pdf.Black)
+
+	// 标题与客户信息
+	title := w.t("report_title")
+	w.page.Text((pdf.PageWidth-pdf.TextWidth(title, 16))/2, y+30, 16, pdf.Black, title)
+	w.y = y + 55
+	user := pkg.User
+	age := "-"
+	if a, ok := Age(user.Birthday, time.Now()); ok {
+		age = strconv.Itoa(a)
+	}
+	gender := map[string]string{"M": w.t("report_gender_male"), "F": w.t("report_gender_female")}[strings.ToUpper(user.Gender)]
+	checkedAt := pkg.UpdatedAt
+	if pkg.AppointmentAt != nil {
+		checkedAt = *pkg.AppointmentAt
+	}
+	info := [][2]string{
+		{w.t("report_name"), user.Name}, {w.t("report_gender"), gender},
+		{w.t("report_age"), age}, {w.t("report_package_no"), strconv.FormatUint(uint64(pkg.ID), 10)},
+		{w.t("report_plan"), report.PlanName}, {w.t("report_checked_at"), checkedAt.Format("2006-01-02")},
+		{w.t("report_issued_at"), issue.CreatedAt.Format("2006-01-02 15:04")}, {w.t("report_user_id"), strconv.FormatUint(uint64(user.ID), 10)},
+	}
+	half := (right - reportMargin) / 2
+	for i, field := range info {
+		x := reportMargin + float64(i%2)*half
+		w.page.Text(x, w.y, 10, reportGray, field[0]+":")
+		w.page.Text(x+pdf.TextWidth(field[0]+":", 10)+6, w.y, 10, pdf.Black, field[1])
+		if i%2 == 1 {
+			w.y += 17
+		}
+	}
+	w.y += 8
+
+	// 结果表
+	w.tableHeader()
+	for _, category := range report.Categories {
+		name := category
+		if name == "" {
+			name = w.t("report_category_other")
+		}
+		w.ensure(18+reportRowSpace+4, true)
+		w.page.Rect(reportMargin, w.y, right-reportMargin, 16, reportSectionBg)
+		w.page.Text(reportMargin+3, w.y+11.5, 10, pdf.Black, name)
+		w.y += 18
+		for _, item := range report.Items {
+			if item.Category == category {
+				w.itemRow(item)
+			}
+		}
+	}
+
+	// 结论
+	w.conclusion()
+
+	pages := doc.Pages()
+	for i, page := range pages {
+		page.Line(reportMargin, pdf.PageHeight-45, right, pdf.PageHeight-45, 0.5, reportRule)
+		page.Text(reportMargin, pdf.PageHeight-32, 8, reportGray, w.t("report_verify_code")+" "+issue.Code)
+		number := fmt.Sprintf("%d / %d", i+1, len(pages))
+		page.Text(right-pdf.TextWidth(number, 8), pdf.PageHeight-32, 8, reportGray, number)
+	}
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (w *reportWriter) itemRow(item ReportItem) {
+	cells := [5][]string{
+		pdf.Wrap(item.ItemName, reportColumns[0].w-6, reportRowSize),
+		pdf.Wrap(item.Value, reportColumns[1].w-6, reportRowSize),
+		pdf.Wrap(item.Unit, reportColumns[2].w-6, reportRowSize),
+		pdf.Wrap(item.Reference, reportColumns[3].w-6, reportRowSize),
+		{item.Flag},
+	}
+	if !item.Tested {
+		cells[1] = []string{w.t("report_not_tested")}
+	}
+	lines := 1
+	for _, c := range cells {
+		lines = max(lines, len(c))
+	}
+	var comment []string
+	if item.Comment != "" {
+		comment = pdf.Wrap(w.t("report_doctor_comment")+": "+item.Comment, pdf.PageWidth-2*reportMargin-16, reportRowSize-0.5)
+	}
+	height := float64(lines+len(comment))*reportRowSpace + 4
+	w.ensure(height, true)
+	if item.Abnormal {
+		w.page.Rect(reportMargin, w.y, pdf.PageWidth-2*reportMargin, height, reportRedBg)
+	}
+	for i, c := range cells {
+		color := pdf.Black
+		switch {
+		case item.Abnormal && (i == 1 || i == 4):
+			color = reportRed
+		case !item.Tested && i == 1:
+			color = reportGray
+		}
+		for j, line := range c {
+			w.page.Text(reportColumns[i].x+3, w.y+12+float64(j)*reportRowSpace, reportRowSize, color, line)
+		}
+	}
+	for j, line := range comment {
+		w.page.Text(reportMargin+13, w.y+12+float64(lines+j)*reportRowSpace, reportRowSize-0.5, reportGray, line)
+	}
+	w.y += height
+	w.page.Line(reportMargin, w.y, pdf.PageWidth-reportMargin, w.y, 0.3, reportRule)
+}
+
+// conclusion 结论部分：医生结论（若有），异常项目汇总，以及医生签名
+func (w *reportWriter) conclusion() {
+	width := pdf.PageWidth - 2*reportMargin
+	w.y += 20
+	w.ensure(60, false)
+	w.page.Text(reportMargin, w.y, 13, pdf.Black, w.t("report_conclusion"))
+	w.y += 8
+	w.page.Line(reportMargin, w.y, pdf.PageWidth-reportMargin, w.y, 0.5, reportRule)
+	w.y += 18
+
+	paragraph := func(text string, size float64, color pdf.Color) {
+		for _, line := range pdf.Wrap(text, width, size) {
+			w.ensure(size+6, false)
+			w.page.Text(reportMargin, w.y, size, color, line)
+			w.y += size + 6
+		}
+	}
+
+	tested, abnormal := 0, []ReportItem{}
+	for _, item := range w.report.Items {
+		if item.Tested {
+			tested++
+		}
+		if item.Abnormal {
+			abnormal = append(abnormal, item)
+		}
+	}
+	if w.report.Conclusion != "" {
+		paragraph(w.report.Conclusion, 10.5, pdf.Black)
+		w.y += 6
+	}
+	if w.report.Recommendation != "" {
+		paragraph(w.t("report_recommendation"), 11, pdf.Black)
+		paragraph(w.report.Recommendation, 10.5, pdf.Black)
+		w.y += 6
+	}
+	paragraph(i18n.Translate(w.lang, "report_summary", len(w.report.Items), tested, len(abnormal)), 10, pdf.Black)
+	for _, item := range abnormal {
+		line := "· " + item.ItemName + ": " + strings.TrimSpace(item.Value+" "+item.Unit)
+		if item.Reference != "" {
+			line += " (" + w.t("report_col_reference") + " " + item.Reference + ")"
+		}
+		paragraph(line+" "+item.Flag, 10, reportRed)
+	}
+	if w.report.Conclusion == "" {
+		paragraph(w.t("report_no_conclusion"), 9, reportGray)
+	}
+
+	w.y += 24
+	w.ensure(20, false)
+	doctor, signedAt := "________________", "________________"
+	if w.report.Doctor != "" {
+		doctor = w.report.Doctor
+	}
+	if w.report.SignedAt != nil {
+		signedAt = w.report.SignedAt.Format("2006-01-02")
+	}
+	w.page.Text(reportMargin+width/2, w.y, 10, pdf.Black, w.t("report_doctor")+": "+doctor)
+	w.y += 20
+	w.page.Text(reportMargin+width/2, w.y, 10, pdf.Black, w.t("report_signed_at")+": "+signedAt)
+	w.y += 20
+}
+
+// drawQRCode 以 (x, y) 为左上角、size 为边长绘制二维码（含 4 个模块的静区），横向相邻的深色模块合并为一个矩形
+func drawQRCode(page *pdf.Page, qr *qrcode.Code, x, y, size float64) {
+	module := size / float64(qr.Size+8)
+	page.Rect(x, y, size, size, pdf.White)
+	for row := 0; row < qr.Size; row++ {
+		for col := 0; col < qr.Size; {
+			if !qr.Modules[row][col] {
+				col++
+				continue
+			}
+			start := col
+			for col < qr.Size && qr.Modules[row][col] {
+				col++
+			}
+			// 略微加高避免相邻行之间出现缝隙
+			page.Rect(x+float64(start+4)*module, y+float64(row+4)*module, float64(col-start)*module, module+0.05, pdf.Black)
+		}
+	}
+}
@@ -0,0 +1,778 @@
+package utils
+
+import (
+	"HealthCare/backend/fhir"
+	"HealthCare/backend/hl7"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
+	"HealthCare/backend/security"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// HL7Authority 本系统在 HL7 v2 中的分配机构与编码系统名称：
+// PID-3 中该机构分配的标识为用户ID，OBR-2 中为用户套餐ID，OBX-3 中该编码系统的编码为体检项目ID
+const HL7Authority = "HEALTHCARE"
+
+// maxImportValueLength 导入结果值的最大长度
+const maxImportValueLength = 255
+
+// 导入结果的状态
+const (
+	importFinal       = "final"
+	importPreliminary = "preliminary"
+	importSkipped     = "skipped"
+)
+
+var (
+	errImportPatientNotFound  = errors.New("patient not found")
+	errImportPatientAmbiguous = errors.New("patient ambiguous")
+	errImportPatientMismatch  = errors.New("patient demographics mismatch")
+	errImportPackageNotFound  = errors.New("user package not found")
+	errImportPackageAmbiguous = errors.New("user package ambiguous")
+	errImportPackageForbidden = errors.New("user package belongs to another institution")
+)
+
+// importErrorKeys 匹配失败时写入导入报告的提示
+var importErrorKeys = map[error]string{
+	errImportPatientNotFound:  "import_patient_not_found",
+	errImportPatientAmbiguous: "import_patient_ambiguous",
+	errImportPatientMismatch:  "import_patient_mismatch",
+	errImportPackageNotFound:  "import_package_not_found",
+	errImportPackageAmbiguous: "import_package_ambiguous",
+	errImportPackageForbidden: "import_package_forbidden",
+}
+
+// ImportCode 检验结果的一个编码，System 为 loinc、local（本系统项目ID）或消息中的原始编码系统
+type ImportCode struct {
+	System string
+	Code   string
+}
+
+// ImportResult 消息中的一条检验结果
+type ImportResult struct {
+	Ref     string
+	Codes   []ImportCode
+	Text    string
+	Value   string
+	Numeric bool
+	Units   string
+	Status  string // final, preliminary, skipped
+}
+
+// ImportPatient 消息中的患者信息，UserID 为消息携带的本系统用户ID（0 表示未携带）
+type ImportPatient struct {
+	UserID    uint
+	Names     []string
+	BirthDate string
+	Phone     string
+}
+
+// ImportMessage 从 HL7 v2 或 FHIR 解析出的一条待导入消息，每条消息生成一份导入报告
+type ImportMessage struct {
+	Format        string
+	MessageID     string
+	Patient       ImportPatient
+	UserPackageID uint // 消息携带的本系统用户套餐ID
+	Results       []ImportResult
+	ParseError    string
+}
+
+// ImportOptions 导入选项，UserPackageID 为请求中指定的套餐，优先于消息中的标识
+type ImportOptions struct {
+	Importer      models.User
+	UserPackageID uint
+	Lang          string
+	Audit         func(*gorm.DB, AuditEntry) error
+}
+
+// HL7Messages 解析包含一条或多条 ORU^R01 消息的文本
+func HL7Messages(body string) []ImportMessage {
+	var messages []ImportMessage
+	for _, text := range hl7.Split(body) {
+		message := ImportMessage{Format: models.ImportFormatHL7v2}
+		parsed, err := hl7.Parse(text)
+		if err != nil {
+			message.ParseError = err.Error()
+			messages = append(messages, message)
+			continue
+		}
+		message.MessageID = parsed.ControlID()
+		oru, err := hl7.ParseORU(parsed)
+		if err != nil {
+			message.ParseError = err.Error()
+			messages = append(messages, message)
+			continue
+		}
+
+		for _, id := range oru.Patient.Identifiers {
+			if strings.EqualFold(id.Authority, HL7Authority) {
+				message.Patient.UserID = importID(id.ID)
+			}
+		}
+		message.Patient.Names = []string{oru.Patient.Family + oru.Patient.Given, oru.Patient.Given + oru.Patient.Family}
+		message.Patient.BirthDate = oru.Patient.BirthDate
+		message.Patient.Phone = oru.Patient.Phone
+
+		for _, order := range oru.Orders {
+			if message.UserPackageID == 0 && strings.EqualFold(order.PlacerOrder.Authority, HL7Authority) {
+				message.UserPackageID = importID(order.PlacerOrder.ID)
+			}
+			for i, obs := range order.Observations {
+				ref := "OBX-" + obs.SetID
+				if obs.SetID == "" {
+					ref = "OBX-" + strconv.Itoa(i+1)
+				}
+				result := ImportResult{
+					Ref:     ref,
+					Text:    obs.Text,
+					Value:   strings.TrimSpace(obs.Value),
+					Numeric: obs.ValueType == "NM",
+					Units:   obs.Units,
+					Status:  hl7ResultStatus(obs.Status),
+				}
+				if result.Text == "" {
+					result.Text = obs.AltText
+				}
+				for _, c := range [][2]string{{obs.CodingSystem, obs.Code}, {obs.AltSystem, obs.AltCode}} {
+					if c[1] != "" {
+						result.Codes = append(result.Codes, ImportCode{System: hl7CodingSystem(c[0]), Code: c[1]})
+					}
+				}
+				message.Results = append(message.Results, result)
+			}
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// hl7ResultStatus OBX-11：F/C 为最终结果，P/R/S 为初步结果，其余（X、D、W、I 等）不导入
+func hl7ResultStatus(status string) string {
+	switch strings.ToUpper(status) {
+	case "", "F", "C":
+		return importFinal
+	case "P", "R", "S":
+		return importPreliminary
+	}
+	return importSkipped
+}
+
+func hl7CodingSystem(system string) string {
+	switch {
+	case strings.EqualFold(system, "LN"):
+		return "loinc"
+	case strings.EqualFold(system, HL7Authority):
+		return "local"
+	}
+	return system
+}
+
+// FHIRMessages 解析 FHIR Bundle：每个 DiagnosticReport 及其结果为一条消息，
+// 未被报告引用的 Observation 按患者分组；Bundle 含多条消息时消息ID追加 #序号
+func FHIRMessages(data []byte) ([]ImportMessage, error) {
+	res, err := fhir.ParseBundle(data)
+	if err != nil {
+		return nil, err
+	}
+	baseID := res.Bundle.ID
+	if res.Bundle.Identifier != nil && res.Bundle.Identifier.Value != "" {
+		baseID = res.Bundle.Identifier.Value
+	}
+
+	type group struct {
+		subject      *fhir.Reference
+		packageID    uint
+		observations []int
+	}
+	var groups []*group
+	used := make(map[int]bool)
+	for _, report := range res.Reports {
+		g := &group{
+			subject:   report.Subject,
+			packageID: importID(fhir.IdentifierValue(report.Identifier, "urn:healthcare:user-package")),
+		}
+		for _, ref := range report.Result {
+			if i, ok := res.ObservationIndex(ref); ok && !used[i] {
+				used[i] = true
+				g.observations = append(g.observations, i)
+			}
+		}
+		groups = append(groups, g)
+	}
+	bySubject := make(map[string]*group)
+	for i, o := range res.Observations {
+		if used[i] {
+			continue
+		}
+		key := ""
+		if o.Subject != nil {
+			key = o.Subject.Reference
+		}
+		g, ok := bySubject[key]
+		if !ok {
+			g = &group{subject: o.Subject}
+			bySubject[key] = g
+			groups = append(groups, g)
+		}
+		g.observations = append(g.observations, i)
+	}
+
+	messages := make([]ImportMessage, 0, len(groups))
+	for n, g := range groups {
+		message := ImportMessage{Format: models.ImportFormatFHIR, MessageID: baseID, UserPackageID: g.packageID}
+		if baseID != "" && len(groups) > 1 {
+			message.MessageID = baseID + "#" + strconv.Itoa(n+1)
+		}
+		if patient, ok := res.Patient(g.subject); ok {
+			message.Patient = fhirImportPatient(*patient)
+		}
+		for _, i := range g.observations {
+			message.Results = append(message.Results, fhirImportResult(res.Observations[i], i))
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+func fhirImportPatient(p fhir.Patient) ImportPatient {
+	patient := ImportPatient{
+		UserID:    importID(fhir.IdentifierValue(p.Identifier, "urn:healthcare:user")),
+		BirthDate: p.BirthDate,
+	}
+	for _, name := range p.Name {
+		given := strings.Join(name.Given, "")
+		patient.Names = append(patient.Names, name.Text, name.Family+given, given+name.Family)
+	}
+	for _, t := range p.Telecom {
+		if t.System == "phone" && patient.Phone == "" {
+			patient.Phone = t.Value
+		}
+	}
+	return patient
+}
+
+// fhirImportResult Observation.status：final/amended/corrected 为最终结果，preliminary 为初步结果，其余不导入
+func fhirImportResult(o fhir.Observation, index int) ImportResult {
+	result := ImportResult{Ref: "Observation/" + o.ID, Text: o.Code.Text, Status: importSkipped}
+	if o.ID == "" {
+		result.Ref = "Observation[" + strconv.Itoa(index) + "]"
+	}
+	switch o.Status {
+	case "final", "amended", "corrected":
+		result.Status = importFinal
+	case "preliminary":
+		result.Status = importPreliminary
+	}
+	for _, c := range o.Code.Coding {
+		system := c.System
+		switch system {
+		case fhir.SystemLOINC:
+			system = "loinc"
+		case "urn:healthcare:health-item":
+			system = "local"
+		}
+		result.Codes = append(result.Codes, ImportCode{System: system, Code: c.Code})
+		if result.Text == "" {
+			result.Text = c.Display
+		}
+	}
+
+	switch {
+	case o.ValueQuantity != nil:
+		result.Value = strconv.FormatFloat(o.ValueQuantity.Value, 'f', -1, 64)
+		result.Numeric = true
+		result.Units = o.ValueQuantity.Unit
+		if result.Units == "" {
+			result.Units = o.ValueQuantity.Code
+		}
+	case o.ValueCodeable != nil:
+		result.Value = o.ValueCodeable.Text
+		for _, c := range o.ValueCodeable.Coding {
+			if result.Value == "" {
+				result.Value = c.Display
+			}
+			if result.Value == "" {
+				result.Value = c.Code
+			}
+		}
+	default:
+		result.Value = strings.TrimSpace(o.ValueString)
+	}
+	return result
+}
+
+// ImportResults 匹配患者、套餐与体检项目，校验后将一条消息的结果写入 UserHealthItem，并保存导入报告
+// 匹配失败或没有可写入的结果时报告状态为 failed；返回的错误仅表示报告本身保存失败
+func ImportResults(db *gorm.DB, message ImportMessage, opts ImportOptions) (models.ImportReport, error) {
+	report := models.ImportReport{
+		ImportedBy: opts.Importer.ID,
+		Format:     message.Format,
+		MessageID:  truncate(message.MessageID, 100),
+	}
+	fail := func(key string, args ...interface{}) (models.ImportReport, error) {
+		report.Model = gorm.Model{}
+		report.Status = models.ImportFailed
+		report.Error = truncate(i18n.Translate(opts.Lang, key, args...), 255)
+		return report, db.Create(&report).Error
+	}
+	failMatch := func(err error) (models.ImportReport, error) {
+		if key, ok := importErrorKeys[err]; ok {
+			return fail(key)
+		}
+		return fail("import_write_failed", err.Error())
+	}
+
+	if message.ParseError != "" {
+		return fail("import_parse_failed", message.ParseError)
+	}
+	if report.MessageID != "" {
+		var count int64
+		if err := db.Model(&models.ImportReport{}).
+			Where("imported_by = ? AND message_id = ? AND status IN ?", opts.Importer.ID, report.MessageID,
+				[]uint8{models.ImportSuccess, models.ImportPartial}).
+			Count(&count).Error; err != nil {
+			return fail("import_write_failed", err.Error())
+		}
+		if count > 0 {
+			return fail("import_duplicate_message", report.MessageID)
+		}
+	}
+
+	var institutionIDs []uint
+	if opts.Importer.UserType != 2 {
+		ids, err := EntryInstitutions(db, opts.Importer.ID)
+		if err != nil {
+			return fail("import_write_failed", err.Error())
+		}
+		institutionIDs = ids
+	}
+	user, err := matchImportPatient(db, message.Patient, institutionIDs)
+	if err != nil {
+		return failMatch(err)
+	}
+	report.UserID = &user.ID
+	pkg, err := matchImportPackage(db, user, message, opts)
+	if err != nil {
+		return failMatch(err)
+	}
+	report.UserPackageID = &pkg.ID
+
+	items, names, err := importPlanItems(db, pkg)
+	if err != nil {
+		return fail("import_write_failed", err.Error())
+	}
+	loincItems, loincUnits, err := importLoincItems(db, message, items)
+	if err != nil {
+		return fail("import_write_failed", err.Error())
+	}
+
+	values := make(map[uint]string)
+	var order []uint
+	for _, r := range message.Results {
+		line := models.ImportLine{Ref: r.Ref, Text: r.Text, Value: truncate(r.Value, maxImportValueLength)}
+		if len(r.Codes) > 0 {
+			line.Code = r.Codes[0].System + "|" + r.Codes[0].Code
+		}
+		reject := func(key string, args ...interface{}) {
+			line.Outcome = "rejected"
+			line.Message = i18n.Translate(opts.Lang, key, args...)
+			report.Rejected++
+		}
+
+		itemID := matchImportItem(r, items, names, loincItems)
+		line.HealthItemID = itemID
+		switch {
+		case r.Status == importSkipped:
+			line.Outcome = "skipped"
+			line.Message = i18n.Translate(opts.Lang, "import_line_skipped")
+		case itemID == 0:
+			reject("import_line_unmatched")
+		case items[itemID].Formula != "":
+			reject("import_line_derived")
+		case values[itemID] != "":
+			reject("import_line_duplicate", itemID)
+		case r.Value == "":
+			reject("import_line_empty")
+		case len(r.Value) > maxImportValueLength:
+			reject("import_line_too_long", maxImportValueLength)
+		case r.Numeric && !isNumber(r.Value):
+			reject("import_line_not_numeric", r.Value)
+		default:
+			line.Outcome = "accepted"
+			report.Accepted++
+			values[itemID] = r.Value
+			order = append(order, itemID)
+			unit := items[itemID].Unit
+			if u := loincUnits[itemID]; u != "" && !strings.EqualFold(u, r.Units) {
+				unit = u
+			}
+			if r.Units != "" && unit != "" && !strings.EqualFold(r.Units, unit) {
+				line.Message = i18n.Translate(opts.Lang, "import_line_unit_mismatch", r.Units, unit)
+			}
+			if r.Status == importPreliminary {
+				line.Message = strings.TrimSpace(line.Message + " " + i18n.Translate(opts.Lang, "import_line_preliminary"))
+			}
+		}
+		report.Lines = append(report.Lines, line)
+	}
+	if report.Accepted == 0 {
+		return fail("import_no_results")
+	}
+	if report.Rejected > 0 {
+		report.Status = models.ImportPartial
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&report).Error; err != nil {
+			return err
+		}
+		saved, err := SaveCheckupResults(tx, pkg, values, order, opts.Importer.ID)
+		if err != nil {
+			return err
+		}
+		saved.Audit.After["import_report_id"] = report.ID
+		return opts.Audit(tx, saved.Audit)
+	})
+	if err != nil {
+		return fail("import_write_failed", err.Error())
+	}
+	return report, nil
+}
+
+// matchImportPatient 按消息携带的用户ID匹配患者，否则按手机号盲索引加姓名匹配；
+// 消息带有出生日期时必须与档案一致。用户ID只在该用户已持有 institutionIDs 中机构的套餐，
+// 或姓名与手机号/出生日期同时与档案一致时采用，否则与用户不存在同样处理，避免按ID探查或写入任意账号
+func matchImportPatient(db *gorm.DB, p ImportPatient, institutionIDs []uint) (models.User, error) {
+	var user models.User
+	if p.UserID != 0 {
+		if err := db.First(&user, p.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return user, errImportPatientNotFound
+			}
+			return user, err
+		}
+		var held int64
+		if len(institutionIDs) > 0 {
+			if err := db.Model(&models.UserPackage{}).
+				Where("user_id = ? AND institution_id IN ?", user.ID, institutionIDs).
+				Count(&held).Error; err != nil {
+				return user, err
+			}
+		}
+		if held == 0 && !sameProfile(user, p) {
+			return models.User{}, errImportPatientNotFound
+		}
+		if !sameBirthDate(user, p.BirthDate) {
+			return user, errImportPatientMismatch
+		}
+		return user, nil
+	}
+
+	phone := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(p.Phone)
+	if phone == "" {
+		return user, errImportPatientNotFound
+	}
+	var users []models.User
+	if err := db.Where("phone_bidx = ?", security.BlindIndex("phone", phone)).Find(&users).Error; err != nil {
+		return user, err
+	}
+	var matched []models.User
+	for _, u := range users {
+		if sameName(u.Name, p.Names) && sameBirthDate(u, p.BirthDate) {
+			matched = append(matched, u)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return user, errImportPatientNotFound
+	case 1:
+		return matched[0], nil
+	}
+	return user, errImportPatientAmbiguous
+}
+
+// sameProfile 姓名与手机号或出生日期（至少一项）同时与档案一致
+func sameProfile(user models.User, p ImportPatient) bool {
+	if !sameName(user.Name, p.Names) {
+		return false
+	}
+	phone := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(p.Phone)
+	if phone != "" && security.BlindIndex("phone", phone) == user.PhoneIndex {
+		return true
+	}
+	return p.BirthDate != "" && sameBirthDate(user, p.BirthDate)
+}
+
+func sameName(name string, candidates []string) bool {
+	normalize := func(s string) string { return strings.ToLower(strings.ReplaceAll(s, " ", "")) }
+	for _, c := range candidates {
+		if c != "" && normalize(c) == normalize(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameBirthDate(user models.User, birthDate string) bool {
+	return birthDate == "" || len(user.Birthday) < 10 || user.Birthday[:10] == birthDate
+}
+
+// matchImportPackage 确定结果所属的用户套餐：请求指定或消息携带的套餐ID，
+// 否则为导入者可录入的机构下该用户唯一的待检测套餐；导入者须为套餐所属机构的主账号或录入成员，管理员不受限
+func matchImportPackage(db *gorm.DB, user models.User, message ImportMessage, opts ImportOptions) (models.UserPackage, error) {
+	var pkg models.UserPackage
+	id := opts.UserPackageID
+	if id == 0 {
+		id = message.UserPackageID
+	}
+	if id != 0 {
+		if err := db.Preload("Institution").First(&pkg, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg, errImportPackageNotFound
+			}
+			return pkg, err
+		}
+		if pkg.UserID != user.ID {
+			return pkg, errImportPackageNotFound
+		}
+	} else {
+		query := db.Preload("Institution").Where("user_id = ? AND status = 0", user.ID)
+		if opts.Importer.UserType != 2 {
+			institutionIDs, err := EntryInstitutions(db, opts.Importer.ID)
+			if err != nil {
+				return pkg, err
+			}
+			query = query.Where("institution_id IN ?", institutionIDs)
+		}
+		var pkgs []models.UserPackage
+		if err := query.Limit(2).Find(&pkgs).Error; err != nil {
+			return pkg, err
+		}
+		switch len(pkgs) {
+		case 0:
+			return pkg, errImportPackageNotFound
+		case 2:
+			return pkg, errImportPackageAmbiguous
+		}
+		pkg = pkgs[0]
+	}
+	if opts.Importer.UserType != 2 {
+		role, err := InstitutionRole(db, pkg.InstitutionID, opts.Importer.ID)
+		if err != nil {
+			return pkg, err
+		}
+		if !CanEnterResults(role) {
+			return pkg, errImportPackageForbidden
+		}
+	}
+	return pkg, nil
+}
+
+// importPlanItems 套餐（已记录版本时为所购版本）包含的体检项目，以及规范化的项目名称（含译名、同义名称与编码）到ID的映射
+func importPlanItems(db *gorm.DB, pkg models.UserPackage) (map[uint]models.HealthItem, map[string]uint, error) {
+	names := make(map[string]uint)
+	var ids []uint
+	pinned, err := LoadPlanVersion(db, pkg.PlanVersionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pinned != nil {
+		for _, vi := range pinned.Items {
+			ids = append(ids, vi.RelationHealthItemId)
+			names[NormalizeItemName(vi.ItemName)] = vi.RelationHealthItemId
+		}
+	} else if err := db.Model(&models.PlanHeathItem{}).Where("plan_id = ?", pkg.PlanID).
+		Pluck("health_item_id", &ids).Error; err != nil {
+		return nil, nil, err
+	}
+
+	items := make(map[uint]models.HealthItem, len(ids))
+	if len(ids) == 0 {
+		return items, names, nil
+	}
+	var list []models.HealthItem
+	if err := db.Where("id IN ?", ids).Find(&list).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, item := range list {
+		items[item.ID] = item
+		if _, ok := names[NormalizeItemName(item.ItemName)]; !ok {
+			names[NormalizeItemName(item.ItemName)] = item.ID
+		}
+	}
+	// 同义名称与编码优先级低于名称与译名
+	var synonyms []models.HealthItemSynonym
+	if err := db.Where("health_item_id IN ?", ids).Find(&synonyms).Error; err != nil {
+		return nil, nil, err
+	}
+	var translations []models.HealthItemTranslation
+	if err := db.Where("health_item_id IN ?", ids).Find(&translations).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, t := range translations {
+		if _, ok := names[NormalizeItemName(t.ItemName)]; !ok {
+			names[NormalizeItemName(t.ItemName)] = t.RelationHealthItemId
+		}
+	}
+	for _, syn := range synonyms {
+		if _, ok := names[syn.NameKey]; !ok {
+			names[syn.NameKey] = syn.RelationHealthItemId
+		}
+	}
+	for _, item := range list {
+		if key := NormalizeItemName(item.Code); key != "" {
+			if _, ok := names[key]; !ok {
+				names[key] = item.ID
+			}
+		}
+	}
+	return items, names, nil
+}
+
+// importLoincItems 消息中 LOINC 编码对应的套餐内项目，以及这些项目映射的 UCUM 单位
+func importLoincItems(db *gorm.DB, message ImportMessage, items map[uint]models.HealthItem) (map[string]uint, map[uint]string, error) {
+	var codes []string
+	for _, r := range message.Results {
+		for _, c := range r.Codes {
+			if c.System == "loinc" {
+				codes = append(codes, c.Code)
+			}
+		}
+	}
+	byCode := make(map[string]uint)
+	units := make(map[uint]string)
+	if len(codes) == 0 {
+		return byCode, units, nil
+	}
+	var mappings []models.LoincMapping
+	if err := db.Where("code IN ?", codes).Find(&mappings).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, m := range mappings {
+		// 同一编码可能映射到多个项目，只取本套餐包含的项目
+		if _, ok := items[m.RelationHealthItemId]; ok {
+			byCode[m.Code] = m.RelationHealthItemId
+			units[m.RelationHealthItemId] = m.Unit
+		}
+	}
+	return byCode, units, nil
+}
+
+// matchImportItem 按本系统项目ID、LOINC 映射、项目名称的顺序匹配套餐内的体检项目，未匹配返回 0
+func matchImportItem(r ImportResult, items map[uint]models.HealthItem, names map[string]uint, loinc map[string]uint) uint {
+	for _, c := range r.Codes {
+		if c.System == "local" {
+			if id := importID(c.Code); id != 0 {
+				if _, ok := items[id]; ok {
+					return id
+				}
+			}
+		}
+	}
+	for _, c := range r.Codes {
+		if c.System == "loinc" {
+			if id, ok := loinc[c.Code]; ok {
+				return id
+			}
+		}
+	}
+	if r.Text != "" {
+		return names[NormalizeItemName(r.Text)]
+	}
+	return 0
+}
+
+func isNumber(value string) bool {
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// importID 解析消息中的本系统ID，格式不符时返回 0
+func importID(s string) uint {
+	id, err := UnmarshalUint(strings.TrimSpace(s))
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// SavedResults SaveCheckupResults 的写入结果
+type SavedResults struct {
+	Audit   AuditEntry             // 记录变更前后内容的审计条目，由调用方补充来源后写入
+	Derived map[uint]string        // 按公式重新计算的衍生项目结果
+	Alerts  []models.CriticalAlert // 本次写入触发的危急值提醒
+}
+
+// SaveCheckupResults 写入用户套餐的检查结果（按 order 顺序，已有记录时覆盖）并将套餐标记为已完成，
+// 结果记为 enteredBy 录入并回到草稿，待审核人发布；随后重新计算衍生项目并对危急值发出提醒
+func SaveCheckupResults(tx *gorm.DB, pkg models.UserPackage, values map[uint]string, order []uint, enteredBy uint) (SavedResults, error) {
+	before := map[string]interface{}{"status": pkg.Status, "review_status": pkg.ReviewStatus}
+	after := map[string]interface{}{"status": 1, "review_status": models.ReviewDraft}
+	for _, itemID := range order {
+		key := "item_" + strconv.FormatUint(uint64(itemID), 10)
+		after[key] = AuditValue(values[itemID])
+
+		var row models.UserHealthItem
+		err := tx.Where("user_id = ? AND plan_id = ? AND health_item_id = ?", pkg.UserID, pkg.PlanID, itemID).First(&row).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			row = models.UserHealthItem{
+				RelationUserId:       pkg.UserID,
+				RelationPlanId:       pkg.PlanID,
+				RelationHealthItemId: itemID,
+			}
+		} else if err != nil {
+			return SavedResults{}, err
+		} else {
+			before[key] = AuditValue(row.ItemValue)
+		}
+		row.ItemValue = values[itemID]
+		row.PlanVersionID = pkg.PlanVersionID
+		row.EnteredBy = &enteredBy
+		row.ReviewPending = true
+		if err := tx.Save(&row).Error; err != nil {
+			return SavedResults{}, err
+		}
+	}
+	derived, derivedOrder, err := ComputeDerivedItems(tx, pkg, enteredBy)
+	if err != nil {
+		return SavedResults{}, err
+	}
+	for _, itemID := range derivedOrder {
+		after["item_"+strconv.FormatUint(uint64(itemID), 10)] = AuditValue(derived[itemID])
+	}
+	alerts, err := RaiseCriticalAlerts(tx, pkg, append(order, derivedOrder...))
+	if err != nil {
+		return SavedResults{}, err
+	}
+	after["critical_alerts"] = len(alerts)
+	if err := tx.Model(&models.UserPackage{}).Where("id = ?", pkg.ID).
+		Updates(map[string]interface{}{"status": 1, "review_status": models.ReviewDraft}).Error; err != nil {
+		return SavedResults{}, err
+	}
+	return SavedResults{
+		Audit: AuditEntry{
+			Action:     models.AuditCheckupResultsWrite,
+			TargetType: "user_package",
+			TargetID:   pkg.ID,
+			Before:     before,
+			After:      after,
+		},
+		Derived: derived,
+		Alerts:  alerts,
+	}, nil
+}
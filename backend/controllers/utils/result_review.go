@@ -0,0 +1,258 @@
+package utils
+
+import (
+	"HealthCare/backend/models"
+	"HealthCare/backend/security"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReviewNotDraft         = errors.New("results are not awaiting review")
+	ErrReviewNoResults        = errors.New("no results entered")
+	ErrReviewSelfRelease      = errors.New("reviewer entered some of the results")
+	ErrReviewUnacknowledged   = errors.New("critical values not acknowledged")
+	ErrReviewNotCritical      = errors.New("result is not a critical value")
+	ErrReviewResultNotEntered = errors.New("result not entered")
+)
+
+// ReleasedResults 只保留所属用户套餐已发布的检查结果，客户本人与家人可见的查询须使用
+func ReleasedResults(db *gorm.DB) *gorm.DB {
+	return db.Where("EXISTS (SELECT 1 FROM user_packages WHERE user_packages.user_id = user_health_items.user_id "+
+		"AND user_packages.plan_id = user_health_items.plan_id AND user_packages.review_status = ? AND user_packages.deleted_at IS NULL)",
+		models.ReviewReleased)
+}
+
+// InstitutionRole 返回用户在机构中的角色：主账号为 "owner"（拥有全部角色），成员为其角色，其他为空
+func InstitutionRole(db *gorm.DB, institutionID, userID uint) (string, error) {
+	var institution models.Institution
+	if err := db.Select("id, user_id").First(&institution, institutionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	if institution.UserID == userID {
+		return "owner", nil
+	}
+	var member models.InstitutionMember
+	err := db.Where("institution_id = ? AND user_id = ?", institutionID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	return member.Role, err
+}
+
+// EntryInstitutions 用户可以录入检查结果的机构：作为主账号的机构与角色为录入的成员机构
+func EntryInstitutions(db *gorm.DB, userID uint) ([]uint, error) {
+	var owned, member []uint
+	if err := db.Model(&models.Institution{}).Where("user_id = ?", userID).Pluck("id", &owned).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&models.InstitutionMember{}).Where("user_id = ? AND role = ?", userID, models.MemberRoleEntry).
+		Pluck("institution_id", &member).Error; err != nil {
+		return nil, err
+	}
+	return append(owned, member...), nil
+}
+
+// CanEnterResults 角色是否可以录入（含修改、导入）检查结果
+func CanEnterResults(role string) bool {
+	return role == "owner" || role == models.MemberRoleEntry
+}
+
+// CanReview 角色是否可以确认危急值并发布结果
+func CanReview(role string) bool {
+	return role == "owner" || role == models.MemberRoleReviewer
+}
+
+// CanWriteConclusion 角色是否可以填写并签署体检结论，录入人员不能
+func CanWriteConclusion(role string) bool {
+	return role == "owner" || role == models.MemberRoleReviewer
+}
+
+// InstitutionStaff 用户是否为管理员或机构的主账号、成员（任一角色）
+func InstitutionStaff(db *gorm.DB, institutionID uint, user models.User) (bool, error) {
+	if user.UserType == 2 {
+		return true, nil
+	}
+	if user.UserType != 3 {
+		return false, nil
+	}
+	role, err := InstitutionRole(db, institutionID, user.ID)
+	return role != "", err
+}
+
+// CanViewDraftResults 未发布的结果只有管理员与套餐所属机构的主账号、成员可见
+func CanViewDraftResults(db *gorm.DB, user models.User, pkg models.UserPackage) (bool, error) {
+	if pkg.ReviewStatus == models.ReviewReleased {
+		return true, nil
+	}
+	return InstitutionStaff(db, pkg.InstitutionID, user)
+}
+
+// IsCritical 判断数值结果是否超出项目的危急值界限
+func IsCritical(value string, item models.HealthItem) bool {
+	v, ok := leadingNumber(strings.TrimSpace(value))
+	if !ok {
+		return false
+	}
+	return (item.CriticalLow != nil && v < *item.CriticalLow) || (item.CriticalHigh != nil && v > *item.CriticalHigh)
+}
+
+// criticalValueIndex 危急值确认绑定的结果盲索引，结果修改后确认失效
+func criticalValueIndex(value string) string {
+	return security.BlindIndex("critical_value", value)
+}
+
+// ReviewResult 审核页中的一项结果
+type ReviewResult struct {
+	HealthItemID   uint                `json:"health_item_id"`
+	ItemName       string              `json:"item_name"`
+	Value          string              `json:"value"`
+	Unit           string              `json:"unit"`
+	Pending        bool                `json:"pending"` // 上次发布后新录入或修改
+	EnteredBy      *uint               `json:"entered_by"`
+	Abnormal       bool                `json:"abnormal"`
+	Critical       bool                `json:"critical"`
+	Acknowledgment *models.CriticalAck `json:"acknowledgment"` // 危急值对当前结果的确认，未确认为空
+}
+
+// ReviewState 用户套餐结果的审核状态
+type ReviewState struct {
+	UserPackageID  uint           `json:"user_package_id"`
+	ReviewStatus   uint8          `json:"review_status"`
+	ReleasedBy     *uint          `json:"released_by"`
+	ReleasedAt     *time.Time     `json:"released_at"`
+	Results        []ReviewResult `json:"results"`
+	Enterers       []uint         `json:"enterers"`       // 待审核结果的录入人，不能发布这些结果
+	Unacknowledged []uint         `json:"unacknowledged"` // 尚未确认的危急值项目
+}
+
+// LoadReviewState 汇总用户套餐的结果、危急值及其确认情况
+func LoadReviewState(db *gorm.DB, pkg models.UserPackage, lang string) (*ReviewState, error) {
+	state := &ReviewState{
+		UserPackageID:  pkg.ID,
+		ReviewStatus:   pkg.ReviewStatus,
+		ReleasedBy:     pkg.ReleasedBy,
+		ReleasedAt:     pkg.ReleasedAt,
+		Results:        []ReviewResult{},
+		Enterers:       []uint{},
+		Unacknowledged: []uint{},
+	}
+	var rows []models.UserHealthItem
+	if err := db.Preload("ThisHeathItem").Where("user_id = ? AND plan_id = ?", pkg.UserID, pkg.PlanID).
+		Order("health_item_id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	var acks []models.CriticalAck
+	if err := db.Where("user_package_id = ?", pkg.ID).Order("id").Find(&acks).Error; err != nil {
+		return nil, err
+	}
+	latestAck := make(map[uint]models.CriticalAck, len(acks))
+	for _, a := range acks {
+		latestAck[a.HealthItemID] = a
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, r.RelationHealthItemId)
+	}
+	names := LocalizedItemNames(lang, ids)
+	enterers := make(map[uint]bool)
+	for _, r := range rows {
+		if strings.TrimSpace(r.ItemValue) == "" {
+			continue
+		}
+		result := ReviewResult{
+			HealthItemID: r.RelationHealthItemId,
+			ItemName:     LocalizeItemName(names, r.RelationHealthItemId, r.ThisHeathItem.ItemName),
+			Value:        r.ItemValue,
+			Unit:         r.ThisHeathItem.Unit,
+			Pending:      r.ReviewPending,
+			EnteredBy:    r.EnteredBy,
+			Abnormal:     IsAbnormal(r.ItemValue, r.ThisHeathItem),
+			Critical:     IsCritical(r.ItemValue, r.ThisHeathItem),
+		}
+		if result.Critical {
+			if ack, ok := latestAck[r.RelationHealthItemId]; ok && ack.ValueIndex == criticalValueIndex(r.ItemValue) {
+				result.Acknowledgment = &ack
+			} else {
+				state.Unacknowledged = append(state.Unacknowledged, r.RelationHealthItemId)
+			}
+		}
+		if r.ReviewPending && r.EnteredBy != nil && !enterers[*r.EnteredBy] {
+			enterers[*r.EnteredBy] = true
+			state.Enterers = append(state.Enterers, *r.EnteredBy)
+		}
+		state.Results = append(state.Results, result)
+	}
+	sort.Slice(state.Enterers, func(i, j int) bool { return state.Enterers[i] < state.Enterers[j] })
+	return state, nil
+}
+
+// AcknowledgeCritical 记录审核人对当前危急值的确认，须在事务中调用
+func AcknowledgeCritical(tx *gorm.DB, pkg models.UserPackage, itemID uint, reviewer models.User, note string) (models.CriticalAck, error) {
+	var row models.UserHealthItem
+	err := tx.Preload("ThisHeathItem").
+		Where("user_id = ? AND plan_id = ? AND health_item_id = ?", pkg.UserID, pkg.PlanID, itemID).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && strings.TrimSpace(row.ItemValue) == "") {
+		return models.CriticalAck{}, ErrReviewResultNotEntered
+	}
+	if err != nil {
+		return models.CriticalAck{}, err
+	}
+	if !IsCritical(row.ItemValue, row.ThisHeathItem) {
+		return models.CriticalAck{}, ErrReviewNotCritical
+	}
+	ack := models.CriticalAck{
+		UserPackageID:  pkg.ID,
+		HealthItemID:   itemID,
+		ValueIndex:     criticalValueIndex(row.ItemValue),
+		Note:           note,
+		AcknowledgedBy: reviewer.ID,
+	}
+	if err := tx.Create(&ack).Error; err != nil {
+		return ack, err
+	}
+	return ack, closeCriticalAlerts(tx, ack)
+}
+
+// ReleaseResults 发布用户套餐的结果：须为草稿、已有结果、审核人不是待审核结果的录入人且危急值均已确认，
+// 须在事务中调用；并发发布时只有一个成功
+func ReleaseResults(tx *gorm.DB, pkg models.UserPackage, reviewer models.User, state *ReviewState) error {
+	switch {
+	case pkg.ReviewStatus != models.ReviewDraft:
+		return ErrReviewNotDraft
+	case len(state.Results) == 0:
+		return ErrReviewNoResults
+	case len(state.Unacknowledged) > 0:
+		return ErrReviewUnacknowledged
+	}
+	for _, id := range state.Enterers {
+		if id == reviewer.ID {
+			return ErrReviewSelfRelease
+		}
+	}
+	now := time.Now()
+	result := tx.Model(&models.UserPackage{}).
+		Where("id = ? AND review_status = ?", pkg.ID, models.ReviewDraft).
+		Updates(map[string]interface{}{
+			"review_status": models.ReviewReleased,
+			"released_by":   reviewer.ID,
+			"released_at":   now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrReviewNotDraft
+	}
+	return tx.Model(&models.UserHealthItem{}).
+		Where("user_id = ? AND plan_id = ? AND review_pending = ?", pkg.UserID, pkg.PlanID, true).
+		Update("review_pending", false).Error
+}
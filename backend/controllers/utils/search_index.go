@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"HealthCare/backend/global"
+	"HealthCare/backend/models"
+	"errors"
+	"log"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// 搜索索引维护
+// 套餐、机构、健康项目变更成功后调用对应的 Reindex*，索引更新失败只记录日志，不影响业务请求，
+// 可通过管理员重建接口修复
+
+// ReindexPlan 重建单个套餐的索引记录，套餐不存在或已删除时移除记录
+func ReindexPlan(planID uint) {
+	if err := reindexPlan(global.DB, planID); err != nil {
+		log.Printf("search: reindex plan %d failed: %v", planID, err)
+	}
+}
+
+// ReindexInstitution 重建机构及其全部套餐的索引记录
+func ReindexInstitution(institutionID uint) {
+	if err := reindexInstitution(global.DB, institutionID); err != nil {
+		log.Printf("search: reindex institution %d failed: %v", institutionID, err)
+	}
+}
+
+// ReindexHealthItem 健康项目名称或译名变更后，重建包含该项目的套餐索引
+func ReindexHealthItem(itemID uint) {
+	var planIDs []uint
+	if err := global.DB.Model(&models.PlanHeathItem{}).
+		Where("health_item_id = ?", itemID).
+		Distinct().Pluck("plan_id", &planIDs).Error; err != nil {
+		log.Printf("search: reindex health item %d failed: %v", itemID, err)
+		return
+	}
+	for _, planID := range planIDs {
+		ReindexPlan(planID)
+	}
+}
+
+// RebuildSearchIndex 清空并重建全部索引，返回写入的记录数
+func RebuildSearchIndex() (int, error) {
+	if err := global.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.SearchDocument{}).Error; err != nil {
+		return 0, err
+	}
+
+	var institutionIDs []uint
+	if err := global.DB.Model(&models.Institution{}).Pluck("id", &institutionIDs).Error; err != nil {
+		return 0, err
+	}
+	for _, id := range institutionIDs {
+		if err := reindexInstitution(global.DB, id); err != nil {
+			return 0, err
+		}
+	}
+
+	var count int64
+	err := global.DB.Model(&models.SearchDocument{}).Count(&count).Error
+	return int(count), err
+}
+
+// EnsureSearchIndex 启动时调用，索引为空而已有机构数据时自动重建
+func EnsureSearchIndex() {
+	var docs, institutions int64
+	global.DB.Model(&models.SearchDocument{}).Count(&docs)
+	global.DB.Model(&models.Institution{}).Count(&institutions)
+	if docs > 0 || institutions == 0 {
+		return
+	}
+	if n, err := RebuildSearchIndex(); err != nil {
+		log.Printf("search: build index failed: %v", err)
+	} else {
+		log.Printf("search: indexed %d documents", n)
+	}
+}
+
+func reindexInstitution(db *gorm.DB, institutionID uint) error {
+	var institution models.Institution
+	err := db.First(&institution, institutionID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return removeInstitutionDocuments(db, institutionID)
+	}
+	if err != nil {
+		return err
+	}
+
+	doc := models.SearchDocument{
+		EntityType:    models.SearchEntityInstitution,
+		EntityID:      institution.ID,
+		InstitutionID: institution.ID,
+		Title:         institution.InstitutionName,
+		Content:       institution.InstitutionAddress,
+	}
+	if err := saveDocument(db, &doc); err != nil {
+		return err
+	}
+
+	var planIDs []uint
+	if err := db.Model(&models.Plan{}).Where("institution_id = ?", institutionID).Pluck("id", &planIDs).Error; err != nil {
+		return err
+	}
+	for _, planID := range planIDs {
+		if err := reindexPlan(db, planID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reindexPlan(db *gorm.DB, planID uint) error {
+	var plan models.Plan
+	err := db.Preload("ThisInstitution").First(&plan, planID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return db.Where("entity_type = ? AND entity_id = ?", models.SearchEntityPlan, planID).
+			Delete(&models.SearchDocument{}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	// 健康项目名称及其译名都作为关键词，支持按英文名检索
+	var names []string
+	if err := db.Table("plan_heath_items").
+		Select("health_items.item_name").
+		Joins("JOIN health_items ON health_items.id = plan_heath_items.health_item_id").
+		Where("plan_heath_items.plan_id = ? AND plan_heath_items.deleted_at IS NULL", planID).
+		Pluck("health_items.item_name", &names).Error; err != nil {
+		return err
+	}
+	var translated []string
+	if err := db.Table("health_item_translations").
+		Joins("JOIN plan_heath_items ON plan_heath_items.health_item_id = health_item_translations.health_item_id").
+		Where("plan_heath_items.plan_id = ? AND plan_heath_items.deleted_at IS NULL AND health_item_translations.deleted_at IS NULL", planID).
+		Pluck("health_item_translations.item_name", &translated).Error; err != nil {
+		return err
+	}
+
+	price := plan.PlanPrice
+	doc := models.SearchDocument{
+		EntityType:    models.SearchEntityPlan,
+		EntityID:      plan.ID,
+		InstitutionID: plan.RelationInstitutionID,
+		Price:         &price,
+		Title:         plan.PlanName,
+		Keywords:      strings.Join(append(names, translated...), " "),
+		Content: strings.Join([]string{
+			plan.Description,
+			plan.SuitableFor,
+			plan.ThisInstitution.InstitutionName,
+			plan.ThisInstitution.InstitutionAddress,
+		}, " "),
+	}
+	return saveDocument(db, &doc)
+}
+
+func saveDocument(db *gorm.DB, doc *models.SearchDocument) error {
+	var existing models.SearchDocument
+	err := db.Where("entity_type = ? AND entity_id = ?", doc.EntityType, doc.EntityID).First(&existing).Error
+	if err == nil {
+		doc.ID = existing.ID
+		return db.Save(doc).Error
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return db.Create(doc).Error
+	}
+	return err
+}
+
+func removeInstitutionDocuments(db *gorm.DB, institutionID uint) error {
+	return db.Where("institution_id = ?", institutionID).Delete(&models.SearchDocument{}).Error
+}
+
+// SearchTerms 将查询语句按空白和标点切分为检索词，如 "B超, 肝功能" -> ["b超", "肝功能"]
+// 中文不需要再分词，ngram 全文索引会把每个检索词拆成连续的二元组按短语匹配
+func SearchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// BooleanQuery 将检索词转换为 MySQL BOOLEAN MODE 查询
+// ngram 分词下每个词以短语形式匹配；短于分词长度（单字）的词无法命中全文索引，
+// 作为 shortTerms 返回，由调用方改用 LIKE 匹配
+func BooleanQuery(terms []string) (query string, shortTerms []string) {
+	var parts []string
+	for _, term := range terms {
+		if len([]rune(term)) < 2 {
+			shortTerms = append(shortTerms, term)
+			continue
+		}
+		parts = append(parts, `+"`+term+`"`)
+	}
+	return strings.Join(parts, " "), shortTerms
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"HealthCare/backend/config"
+	"HealthCare/backend/global"
+	"HealthCare/backend/models"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// StoragePath 返回相对 storage.dir 的路径对应的文件路径
+func StoragePath(rel string) string {
+	dir := config.AppConfig.Storage.Dir
+	if dir == "" {
+		dir = "./data"
+	}
+	return filepath.Join(dir, filepath.FromSlash(rel))
+}
+
+// SaveReportFile 保存用户上传的体检报告文件并登记，文件名随机生成，原文件名仅用于展示与导出
+func SaveReportFile(userID uint, fileName, contentType string, data []byte) (models.ReportFile, error) {
+	name, err := randomName()
+	if err != nil {
+		return models.ReportFile{}, err
+	}
+	rel := "reports/" + strconv.FormatUint(uint64(userID), 10) + "/" + name + strings.ToLower(filepath.Ext(fileName))
+	path := StoragePath(rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return models.ReportFile{}, err
+	}
+	if err := os.WriteFile(path, data, 0o640); err != nil {
+		return models.ReportFile{}, err
+	}
+
+	file := models.ReportFile{
+		RelationUserId: userID,
+		FileName:       filepath.Base(fileName),
+		ContentType:    contentType,
+		Size:           int64(len(data)),
+		Path:           rel,
+	}
+	if err := global.DB.Create(&file).Error; err != nil {
+		os.Remove(path)
+		return models.ReportFile{}, err
+	}
+	return file, nil
+}
+
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,164 @@
+// Package fhir 提供本项目导出体检结果所需的 FHIR R4 资源类型（仅包含用到的字段）
+package fhir
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// 常用的编码系统
+const (
+	SystemLOINC          = "http://loinc.org"
+	SystemUCUM           = "http://unitsofmeasure.org"
+	SystemObsCategory    = "http://terminology.hl7.org/CodeSystem/observation-category"
+	SystemInterpretation = "http://terminology.hl7.org/CodeSystem/v3-ObservationInterpretation"
+	SystemDiagnosticType = "http://terminology.hl7.org/CodeSystem/v2-0074"
+)
+
+// MediaType FHIR JSON 的 MIME 类型
+const MediaType = "application/fhir+json"
+
+type Bundle struct {
+	ResourceType string        `json:"resourceType"`
+	ID           string        `json:"id,omitempty"`
+	Identifier   *Identifier   `json:"identifier,omitempty"`
+	Type         string        `json:"type"`
+	Timestamp    string        `json:"timestamp,omitempty"`
+	Entry        []BundleEntry `json:"entry"`
+}
+
+type BundleEntry struct {
+	FullURL  string      `json:"fullUrl"`
+	Resource interface{} `json:"resource"`
+}
+
+type Patient struct {
+	ResourceType string       `json:"resourceType"`
+	ID           string       `json:"id,omitempty"`
+	Identifier   []Identifier `json:"identifier,omitempty"`
+	Name         []HumanName  `json:"name,omitempty"`
+	Gender       string       `json:"gender,omitempty"` // male, female, other, unknown
+	BirthDate    string       `json:"birthDate,omitempty"`
+	Telecom      []Contact    `json:"telecom,omitempty"`
+	Address      []Address    `json:"address,omitempty"`
+}
+
+type Organization struct {
+	ResourceType string       `json:"resourceType"`
+	ID           string       `json:"id,omitempty"`
+	Identifier   []Identifier `json:"identifier,omitempty"`
+	Name         string       `json:"name"`
+	Telecom      []Contact    `json:"telecom,omitempty"`
+	Address      []Address    `json:"address,omitempty"`
+}
+
+type DiagnosticReport struct {
+	ResourceType      string            `json:"resourceType"`
+	ID                string            `json:"id,omitempty"`
+	Identifier        []Identifier      `json:"identifier,omitempty"`
+	Status            string            `json:"status"` // registered, partial, preliminary, final
+	Category          []CodeableConcept `json:"category,omitempty"`
+	Code              CodeableConcept   `json:"code"`
+	Subject           *Reference        `json:"subject,omitempty"`
+	EffectiveDateTime string            `json:"effectiveDateTime,omitempty"`
+	Issued            string            `json:"issued,omitempty"`
+	Performer         []Reference       `json:"performer,omitempty"`
+	Result            []Reference       `json:"result,omitempty"`
+}
+
+type Observation struct {
+	ResourceType      string            `json:"resourceType"`
+	ID                string            `json:"id,omitempty"`
+	Status            string            `json:"status"` // registered, preliminary, final, amended
+	Category          []CodeableConcept `json:"category,omitempty"`
+	Code              CodeableConcept   `json:"code"`
+	Subject           *Reference        `json:"subject,omitempty"`
+	EffectiveDateTime string            `json:"effectiveDateTime,omitempty"`
+	Performer         []Reference       `json:"performer,omitempty"`
+	ValueQuantity     *Quantity         `json:"valueQuantity,omitempty"`
+	ValueString       string            `json:"valueString,omitempty"`
+	ValueCodeable     *CodeableConcept  `json:"valueCodeableConcept,omitempty"`
+	Interpretation    []CodeableConcept `json:"interpretation,omitempty"`
+	ReferenceRange    []ReferenceRange  `json:"referenceRange,omitempty"`
+}
+
+type Identifier struct {
+	System string `json:"system,omitempty"`
+	Value  string `json:"value"`
+}
+
+type HumanName struct {
+	Text   string   `json:"text,omitempty"`
+	Family string   `json:"family,omitempty"`
+	Given  []string `json:"given,omitempty"`
+}
+
+type Contact struct {
+	System string `json:"system"` // phone, email
+	Value  string `json:"value"`
+}
+
+type Address struct {
+	Text string `json:"text"`
+}
+
+type Coding struct {
+	System  string `json:"system,omitempty"`
+	Code    string `json:"code"`
+	Display string `json:"display,omitempty"`
+}
+
+type CodeableConcept struct {
+	Coding []Coding `json:"coding,omitempty"`
+	Text   string   `json:"text,omitempty"`
+}
+
+type Reference struct {
+	Reference string `json:"reference"`
+	Display   string `json:"display,omitempty"`
+}
+
+type Quantity struct {
+	Value  float64 `json:"value"`
+	Unit   string  `json:"unit,omitempty"`
+	System string  `json:"system,omitempty"`
+	Code   string  `json:"code,omitempty"`
+}
+
+type ReferenceRange struct {
+	Low  *Quantity `json:"low,omitempty"`
+	High *Quantity `json:"high,omitempty"`
+}
+
+// NewUUID 生成随机 UUID（v4），用于 Bundle 内资源的 urn:uuid 引用
+func NewUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// URN 返回 Bundle 内资源的 fullUrl
+func URN(id string) string {
+	return "urn:uuid:" + id
+}
+
+// DateTime 按 FHIR dateTime 格式化时间
+func DateTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// Concept 由单个编码构造 CodeableConcept
+func Concept(system, code, display string) CodeableConcept {
+	return CodeableConcept{Coding: []Coding{{System: system, Code: code, Display: display}}, Text: display}
+}
+
+// LocalIdentifier 本系统内的标识，system 为 urn:healthcare:<kind>
+func LocalIdentifier(kind string, id uint) Identifier {
+	return Identifier{System: "urn:healthcare:" + kind, Value: strconv.FormatUint(uint64(id), 10)}
+}
@@ -0,0 +1,133 @@
+package fhir
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrNotBundle  = errors.New("resourceType is not Bundle")
+	ErrEmptyEntry = errors.New("bundle has no entries")
+)
+
+// Resources 从 Bundle 中解析出的资源，Patient 与 Observation 可按引用查找
+type Resources struct {
+	Bundle       Bundle
+	Patients     []Patient
+	Reports      []DiagnosticReport
+	Observations []Observation
+
+	patients     map[string]int
+	observations map[string]int
+}
+
+// ParseBundle 解析 FHIR R4 Bundle（collection、transaction、batch、message、searchset），
+// 只保留 Patient、DiagnosticReport 与 Observation，其余资源忽略
+func ParseBundle(data []byte) (*Resources, error) {
+	var raw struct {
+		Bundle
+		Entry []struct {
+			FullURL  string          `json:"fullUrl"`
+			Resource json.RawMessage `json:"resource"`
+		} `json:"entry"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if raw.ResourceType != "Bundle" {
+		return nil, ErrNotBundle
+	}
+	if len(raw.Entry) == 0 {
+		return nil, ErrEmptyEntry
+	}
+
+	res := &Resources{
+		Bundle:       raw.Bundle,
+		patients:     map[string]int{},
+		observations: map[string]int{},
+	}
+	for _, entry := range raw.Entry {
+		var head struct {
+			ResourceType string `json:"resourceType"`
+			ID           string `json:"id"`
+		}
+		if err := json.Unmarshal(entry.Resource, &head); err != nil {
+			return nil, err
+		}
+		var keys []string
+		if head.ID != "" {
+			keys = append(keys, head.ResourceType+"/"+head.ID)
+		}
+		if entry.FullURL != "" {
+			keys = append(keys, normalizeRef(entry.FullURL))
+		}
+		switch head.ResourceType {
+		case "Patient":
+			var p Patient
+			if err := json.Unmarshal(entry.Resource, &p); err != nil {
+				return nil, err
+			}
+			res.Patients = append(res.Patients, p)
+			for _, k := range keys {
+				res.patients[k] = len(res.Patients) - 1
+			}
+		case "DiagnosticReport":
+			var r DiagnosticReport
+			if err := json.Unmarshal(entry.Resource, &r); err != nil {
+				return nil, err
+			}
+			res.Reports = append(res.Reports, r)
+		case "Observation":
+			var o Observation
+			if err := json.Unmarshal(entry.Resource, &o); err != nil {
+				return nil, err
+			}
+			res.Observations = append(res.Observations, o)
+			for _, k := range keys {
+				res.observations[k] = len(res.Observations) - 1
+			}
+		}
+	}
+	return res, nil
+}
+
+// Patient 按引用查找 Bundle 内的患者
+func (r *Resources) Patient(ref *Reference) (*Patient, bool) {
+	if ref == nil {
+		return nil, false
+	}
+	i, ok := r.patients[normalizeRef(ref.Reference)]
+	if !ok {
+		return nil, false
+	}
+	return &r.Patients[i], true
+}
+
+// ObservationIndex 按引用查找 Bundle 内的检验结果，返回其在 Observations 中的下标
+func (r *Resources) ObservationIndex(ref Reference) (int, bool) {
+	i, ok := r.observations[normalizeRef(ref.Reference)]
+	return i, ok
+}
+
+// normalizeRef 绝对地址形式的引用（http://.../Patient/1）只保留 类型/ID 部分
+func normalizeRef(ref string) string {
+	if strings.HasPrefix(ref, "urn:") {
+		return ref
+	}
+	parts := strings.Split(strings.TrimRight(ref, "/"), "/")
+	if len(parts) >= 2 {
+		return parts[len(parts)-2] + "/" + parts[len(parts)-1]
+	}
+	return ref
+}
+
+// IdentifierValue 返回 system 匹配的第一个标识值
+func IdentifierValue(ids []Identifier, system string) string {
+	for _, id := range ids {
+		if id.System == system {
+			return id.Value
+		}
+	}
+	return ""
+}
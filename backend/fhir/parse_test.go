@@ -0,0 +1,102 @@
+package fhir
+
+import (
+	"errors"
+	"testing"
+)
+
+const sampleBundle = `{
+  "resourceType": "Bundle",
+  "type": "collection",
+  "entry": [
+    {"fullUrl": "urn:uuid:0a1b", "resource": {"resourceType": "Patient", "name": [{"text": "张三"}], "birthDate": "1980-01-02"}},
+    {"fullUrl": "https://lab.example.com/fhir/Patient/p2", "resource": {"resourceType": "Patient", "id": "p2", "gender": "female"}},
+    {"resource": {"resourceType": "DiagnosticReport", "id": "r1", "status": "final", "result": [{"reference": "Observation/o1"}]}},
+    {"resource": {"resourceType": "Observation", "id": "o1", "status": "final",
+      "code": {"coding": [{"system": "http://loinc.org", "code": "718-7"}]},
+      "subject": {"reference": "urn:uuid:0a1b"},
+      "valueQuantity": {"value": 135, "unit": "g/L"}}},
+    {"resource": {"resourceType": "Organization", "id": "org", "name": "LAB"}},
+    {"resource": null}
+  ]
+}`
+
+func TestParseBundle(t *testing.T) {
+	res, err := ParseBundle([]byte(sampleBundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Patients) != 2 || len(res.Reports) != 1 || len(res.Observations) != 1 {
+		t.Fatalf("got %d patients, %d reports, %d observations", len(res.Patients), len(res.Reports), len(res.Observations))
+	}
+
+	obs := res.Observations[0]
+	if obs.ValueQuantity == nil || obs.ValueQuantity.Value != 135 || obs.Code.Coding[0].Code != "718-7" {
+		t.Errorf("got observation %+v", obs)
+	}
+	tests := []struct {
+		ref  string
+		want string // 找到的患者的出生日期或性别，空表示找不到
+	}{
+		{"urn:uuid:0a1b", "1980-01-02"},
+		{"Patient/p2", "female"},
+		{"https://other.example.com/Patient/p2", "female"},
+		{"Patient/p2/", "female"},
+		{"Patient/", ""},
+		{"Patient/p3", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		p, ok := res.Patient(&Reference{Reference: tt.ref})
+		got := ""
+		if ok {
+			got = p.BirthDate + p.Gender
+		}
+		if got != tt.want {
+			t.Errorf("Patient(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+	if _, ok := res.Patient(nil); ok {
+		t.Error("Patient(nil) found a patient")
+	}
+	if i, ok := res.ObservationIndex(res.Reports[0].Result[0]); !ok || i != 0 {
+		t.Errorf("ObservationIndex = %d, %v, want 0, true", i, ok)
+	}
+}
+
+func TestParseBundleMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"not JSON", `<Bundle/>`, nil},
+		{"truncated", `{"resourceType": "Bundle", "entry": [`, nil},
+		{"not a bundle", `{"resourceType": "Patient", "id": "p1"}`, ErrNotBundle},
+		{"no entries", `{"resourceType": "Bundle", "type": "collection"}`, ErrEmptyEntry},
+		{"entry without resource", `{"resourceType": "Bundle", "entry": [{"fullUrl": "urn:uuid:1"}]}`, nil},
+		{"resource is not an object", `{"resourceType": "Bundle", "entry": [{"resource": "Patient"}]}`, nil},
+		{"wrong field type", `{"resourceType": "Bundle", "entry": [{"resource": {"resourceType": "Observation", "valueQuantity": {"value": "high"}}}]}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ParseBundle([]byte(tt.data))
+			if err == nil {
+				t.Fatalf("got %+v, want an error", res)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentifierValue(t *testing.T) {
+	ids := []Identifier{{System: "a", Value: "1"}, {System: "b", Value: "2"}, {System: "b", Value: "3"}}
+	if got := IdentifierValue(ids, "b"); got != "2" {
+		t.Errorf("got %q, want %q", got, "2")
+	}
+	if got := IdentifierValue(ids, "c"); got != "" {
+		t.Errorf("got %q for a missing system", got)
+	}
+}
@@ -0,0 +1,544 @@
+// Package formula 解析并计算衍生指标的表达式，如 BMI、eGFR、心血管风险评分
+//
+// 语法：
+//   - 数字：12、0.9938、1e-3
+//   - 检查项目结果：{项目ID}，如 {12}
+//   - 变量：age（体检时的年龄）、male、female（性别为男/女时为 1，否则为 0）
+//   - 运算符（优先级由低到高）：||；&&；== != < <= > >=；+ -；* /；一元 - !；^（右结合）
+//   - 函数：pow(x, y)、sqrt、ln、log10、exp、abs、min(...)、max(...)、round(x[, 小数位])、
+//     if(条件, 为真时的值, 为假时的值)，条件非 0 为真，未选中的分支不求值
+//
+// 例如 BMI：round({3} / pow({2} / 100, 2), 1)，其中 {2} 为身高(cm)，{3} 为体重(kg)
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 表达式中的变量
+const (
+	VarAge    = "age"
+	VarMale   = "male"
+	VarFemale = "female"
+)
+
+var (
+	ErrMissingInput = errors.New("formula input missing")
+	ErrNotFinite    = errors.New("formula result is not a finite number")
+)
+
+// SyntaxError 表达式的语法错误，Pos 为出错位置（从 0 开始的字符偏移）
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Pos, e.Msg)
+}
+
+// Env 计算时的输入：Item 返回项目结果，Var 返回变量值，取不到时返回 false
+type Env interface {
+	Item(id uint) (float64, bool)
+	Var(name string) (float64, bool)
+}
+
+// Expr 解析后的表达式
+type Expr struct {
+	root node
+	refs []uint
+	vars []string
+}
+
+// Refs 表达式引用的检查项目ID，按出现顺序去重
+func (e *Expr) Refs() []uint {
+	return e.refs
+}
+
+// Vars 表达式使用的变量
+func (e *Expr) Vars() []string {
+	return e.vars
+}
+
+// Eval 计算表达式，引用的项目或变量取不到时返回 ErrMissingInput，结果为 NaN 或无穷时返回 ErrNotFinite
+func (e *Expr) Eval(env Env) (float64, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, ErrNotFinite
+	}
+	return v, nil
+}
+
+// Parse 解析表达式
+func Parse(src string) (*Expr, error) {
+	p := &parser{src: []rune(src)}
+	p.next()
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+	return &Expr{root: root, refs: p.refs, vars: p.vars}, nil
+}
+
+type node interface {
+	eval(env Env) (float64, error)
+}
+
+type numberNode float64
+
+func (n numberNode) eval(Env) (float64, error) { return float64(n), nil }
+
+type itemNode uint
+
+func (n itemNode) eval(env Env) (float64, error) {
+	if v, ok := env.Item(uint(n)); ok {
+		return v, nil
+	}
+	return 0, ErrMissingInput
+}
+
+type varNode string
+
+func (n varNode) eval(env Env) (float64, error) {
+	if v, ok := env.Var(string(n)); ok {
+		return v, nil
+	}
+	return 0, ErrMissingInput
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n unaryNode) eval(env Env) (float64, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	if n.op == "!" {
+		return boolValue(v == 0), nil
+	}
+	return -v, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n binaryNode) eval(env Env) (float64, error) {
+	l, err := n.left.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	// 逻辑运算短路求值
+	switch {
+	case n.op == "&&" && l == 0:
+		return 0, nil
+	case n.op == "||" && l != 0:
+		return 1, nil
+	}
+	r, err := n.right.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		return l / r, nil
+	case "^":
+		return math.Pow(l, r), nil
+	case "==":
+		return boolValue(l == r), nil
+	case "!=":
+		return boolValue(l != r), nil
+	case "<":
+		return boolValue(l < r), nil
+	case "<=":
+		return boolValue(l <= r), nil
+	case ">":
+		return boolValue(l > r), nil
+	case ">=":
+		return boolValue(l >= r), nil
+	}
+	// && 与 || 的左值已在上面判断
+	return boolValue(r != 0), nil
+}
+
+type callNode struct {
+	fn   function
+	args []node
+}
+
+func (n callNode) eval(env Env) (float64, error) {
+	if n.fn.lazy != nil {
+		return n.fn.lazy(env, n.args)
+	}
+	values := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return 0, err
+		}
+		values[i] = v
+	}
+	return n.fn.call(values), nil
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// function 内置函数，maxArgs 为 -1 表示不限参数个数
+type function struct {
+	minArgs, maxArgs int
+	call             func(args []float64) float64
+	lazy             func(env Env, args []node) (float64, error)
+}
+
+func math1(f func(float64) float64) function {
+	return function{minArgs: 1, maxArgs: 1, call: func(a []float64) float64 { return f(a[0]) }}
+}
+
+var functions = map[string]function{
+	"pow":   {minArgs: 2, maxArgs: 2, call: func(a []float64) float64 { return math.Pow(a[0], a[1]) }},
+	"sqrt":  math1(math.Sqrt),
+	"ln":    math1(math.Log),
+	"log10": math1(math.Log10),
+	"exp":   math1(math.Exp),
+	"abs":   math1(math.Abs),
+	"min":   {minArgs: 1, maxArgs: -1, call: func(a []float64) float64 { return slices.Min(a) }},
+	"max":   {minArgs: 1, maxArgs: -1, call: func(a []float64) float64 { return slices.Max(a) }},
+	"round": {minArgs: 1, maxArgs: 2, call: func(a []float64) float64 {
+		scale := 1.0
+		if len(a) == 2 {
+			scale = math.Pow(10, math.Round(a[1]))
+		}
+		return math.Round(a[0]*scale) / scale
+	}},
+	"if": {minArgs: 3, maxArgs: 3, lazy: func(env Env, args []node) (float64, error) {
+		cond, err := args[0].eval(env)
+		if err != nil {
+			return 0, err
+		}
+		if cond != 0 {
+			return args[1].eval(env)
+		}
+		return args[2].eval(env)
+	}},
+}
+
+var variables = []string{VarAge, VarMale, VarFemale}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokItem
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type parser struct {
+	src  []rune
+	pos  int
+	tok  token
+	err  error
+	refs []uint
+	vars []string
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Pos: p.tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next 读取下一个记号，词法错误记录在 p.err 中并返回 EOF
+func (p *parser) next() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return
+	}
+	c := p.src[p.pos]
+	switch {
+	case unicode.IsDigit(c) || c == '.':
+		for p.pos < len(p.src) && (unicode.IsDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		// 科学计数法
+		if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+			end := p.pos + 1
+			if end < len(p.src) && (p.src[end] == '+' || p.src[end] == '-') {
+				end++
+			}
+			if end < len(p.src) && unicode.IsDigit(p.src[end]) {
+				for end < len(p.src) && unicode.IsDigit(p.src[end]) {
+					end++
+				}
+				p.pos = end
+			}
+		}
+		p.tok = token{kind: tokNumber, text: string(p.src[start:p.pos]), pos: start}
+	case unicode.IsLetter(c) || c == '_':
+		for p.pos < len(p.src) && (unicode.IsLetter(p.src[p.pos]) || unicode.IsDigit(p.src[p.pos]) || p.src[p.pos] == '_') {
+			p.pos++
+		}
+		p.tok = token{kind: tokIdent, text: string(p.src[start:p.pos]), pos: start}
+	case c == '{':
+		end := start + 1
+		for end < len(p.src) && p.src[end] != '}' {
+			end++
+		}
+		if end >= len(p.src) {
+			p.setError(start, "unterminated item reference")
+			return
+		}
+		p.pos = end + 1
+		p.tok = token{kind: tokItem, text: strings.TrimSpace(string(p.src[start+1 : end])), pos: start}
+	default:
+		p.pos++
+		if p.pos < len(p.src) {
+			two := string(p.src[start : p.pos+1])
+			switch two {
+			case "==", "!=", "<=", ">=", "&&", "||":
+				p.pos++
+				p.tok = token{kind: tokOp, text: two, pos: start}
+				return
+			}
+		}
+		if !strings.ContainsRune("+-*/^()<>!,", c) {
+			p.setError(start, fmt.Sprintf("unexpected character %q", c))
+			return
+		}
+		p.tok = token{kind: tokOp, text: string(c), pos: start}
+	}
+}
+
+func (p *parser) setError(pos int, msg string) {
+	if p.err == nil {
+		p.err = &SyntaxError{Pos: pos, Msg: msg}
+	}
+	p.pos = len(p.src)
+	p.tok = token{kind: tokEOF, pos: pos}
+}
+
+func (p *parser) isOp(ops ...string) bool {
+	return p.tok.kind == tokOp && slices.Contains(ops, p.tok.text)
+}
+
+// binary 解析左结合的二元运算
+func (p *parser) binary(operand func() (node, error), ops ...string) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp(ops...) {
+		op := p.tok.text
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.binary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.binary(p.parseCompare, "&&")
+}
+
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("==", "!=", "<", "<=", ">", ">=") {
+		op := p.tok.text
+		p.next()
+		right, err := p.parseAdd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdd() (node, error) {
+	return p.binary(p.parseMul, "+", "-")
+}
+
+func (p *parser) parseMul() (node, error) {
+	return p.binary(p.parseUnary, "*", "/")
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOp("-", "!") {
+		op := p.tok.text
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePow()
+}
+
+func (p *parser) parsePow() (node, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("^") {
+		p.next()
+		exp, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return binaryNode{op: "^", left: base, right: exp}, nil
+	}
+	return base, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", tok.text)
+		}
+		p.next()
+		return numberNode(v), nil
+	case tokItem:
+		id, err := strconv.ParseUint(tok.text, 10, 32)
+		if err != nil || id == 0 {
+			return nil, p.errorf("invalid item reference {%s}", tok.text)
+		}
+		if !slices.Contains(p.refs, uint(id)) {
+			p.refs = append(p.refs, uint(id))
+		}
+		p.next()
+		return itemNode(id), nil
+	case tokIdent:
+		p.next()
+		if p.isOp("(") {
+			return p.parseCall(tok)
+		}
+		if !slices.Contains(variables, tok.text) {
+			return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unknown variable %q", tok.text)}
+		}
+		if !slices.Contains(p.vars, tok.text) {
+			p.vars = append(p.vars, tok.text)
+		}
+		return varNode(tok.text), nil
+	case tokOp:
+		if tok.text == "(" {
+			p.next()
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.isOp(")") {
+				return nil, p.errorf("missing )")
+			}
+			p.next()
+			return inner, nil
+		}
+		return nil, p.errorf("unexpected %q", tok.text)
+	}
+	return nil, p.errorf("unexpected end of expression")
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("unknown function %q", name.text)}
+	}
+	p.next() // (
+	var args []node
+	if !p.isOp(")") {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.isOp(",") {
+				break
+			}
+			p.next()
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	if !p.isOp(")") {
+		return nil, p.errorf("missing ) after arguments of %s", name.text)
+	}
+	p.next()
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("wrong number of arguments for %s", name.text)}
+	}
+	return callNode{fn: fn, args: args}, nil
+}
+
+var itemRef = regexp.MustCompile(`\{\s*(\d+)\s*\}`)
+
+// RenameItems 将表达式中对项目的引用按 rename（旧ID -> 新ID）替换，其余内容保持原样
+func RenameItems(src string, rename map[uint]uint) string {
+	return itemRef.ReplaceAllStringFunc(src, func(ref string) string {
+		id, err := strconv.ParseUint(itemRef.FindStringSubmatch(ref)[1], 10, 32)
+		if err != nil {
+			return ref
+		}
+		if to, ok := rename[uint(id)]; ok {
+			return "{" + strconv.FormatUint(uint64(to), 10) + "}"
+		}
+		return ref
+	})
+}
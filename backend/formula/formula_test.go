@@ -0,0 +1,217 @@
+package formula
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// env 测试用的输入，calls 记录被读取的项目，用于检查短路求值
+type env struct {
+	items map[uint]float64
+	vars  map[string]float64
+	calls []uint
+}
+
+func (e *env) Item(id uint) (float64, bool) {
+	e.calls = append(e.calls, id)
+	v, ok := e.items[id]
+	return v, ok
+}
+
+func (e *env) Var(name string) (float64, bool) {
+	v, ok := e.vars[name]
+	return v, ok
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		// 优先级与结合性
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"24 / 4 / 2", 3},
+		{"2 ^ 3 ^ 2", 512},
+		{"(2 ^ 3) ^ 2", 64},
+		{"-2 ^ 2", -4},
+		{"2 ^ -1", 0.5},
+		{"2 * 3 ^ 2", 18},
+		{"--3", 3},
+		{"1 + 2 > 2", 1},
+		{"1 || 0 && 0", 1},
+		{"!0 + 1", 2},
+		{"!(1 > 2)", 1},
+		{"1e-3 * 1E3", 1},
+		{".5 + 1", 1.5},
+
+		// 函数
+		{"pow(2, 10)", 1024},
+		{"sqrt(16) + abs(-2)", 6},
+		{"min(3, 1, 2) + max(3, 1, 2)", 4},
+		{"ln(exp(2))", 2},
+		{"log10(1000)", 3},
+		{"round(2.5)", 3},
+		{"round(-2.5)", -3},
+		{"round(22.857, 1)", 22.9},
+		{"round(22.857, 2)", 22.86},
+		{"round(1234.5, -2)", 1200},
+		{"round(22.857, 1.6)", 22.86}, // 小数位先取整
+		{"round(22.857, 0)", 23},
+
+		// 项目与变量
+		{"round({3} / pow({2} / 100, 2), 1)", 22.9},
+		{"if(male, {2} - 100, {2} - 105)", 75},
+		{"age * female", 0},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.src)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.src, err)
+		}
+		got, err := expr.Eval(&env{
+			items: map[uint]float64{2: 175, 3: 70},
+			vars:  map[string]float64{VarAge: 40, VarMale: 1, VarFemale: 0},
+		})
+		if err != nil {
+			t.Errorf("Eval(%q): %v", tt.src, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Eval(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+// 比较运算不能连用
+func TestComparisonsDoNotChain(t *testing.T) {
+	var syntax *SyntaxError
+	if _, err := Parse("1 < 2 == 1"); !errors.As(err, &syntax) || syntax.Pos != 6 {
+		t.Fatalf("got error %v, want a syntax error at position 6", err)
+	}
+}
+
+// if 只计算选中的分支，&& 与 || 在左值确定结果时不计算右边
+func TestShortCircuit(t *testing.T) {
+	tests := []struct {
+		src   string
+		want  float64
+		calls []uint
+	}{
+		{"if({1} > 0, {2}, {9})", 2, []uint{1, 2}},
+		{"if({1} < 0, {9}, {2})", 2, []uint{1, 2}},
+		{"0 && {9}", 0, nil},
+		{"1 || {9}", 1, nil},
+		{"1 && {2}", 1, []uint{2}},
+		{"if(0, 1 / 0, 3)", 3, nil},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.src)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.src, err)
+		}
+		e := &env{items: map[uint]float64{1: 1, 2: 2}}
+		got, err := expr.Eval(e)
+		if err != nil {
+			t.Errorf("Eval(%q): %v", tt.src, err)
+			continue
+		}
+		if got != tt.want || !reflect.DeepEqual(e.calls, tt.calls) {
+			t.Errorf("Eval(%q) = %v reading %v, want %v reading %v", tt.src, got, e.calls, tt.want, tt.calls)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want error
+	}{
+		{"{9} + 1", ErrMissingInput},
+		{"age", ErrMissingInput},
+		{"if({9}, 1, 2)", ErrMissingInput},
+		{"1 / 0", ErrNotFinite},
+		{"sqrt(-1)", ErrNotFinite},
+		{"ln(0)", ErrNotFinite},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.src)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.src, err)
+		}
+		if _, err := expr.Eval(&env{}); !errors.Is(err, tt.want) {
+			t.Errorf("Eval(%q): got error %v, want %v", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		pos int
+	}{
+		{"", 0},
+		{"1 +", 3},
+		{"(1 + 2", 6},
+		{"1 2", 2},
+		{"{0}", 0},
+		{"{abc}", 0},
+		{"{12", 0},
+		{"bmi * 2", 0},
+		{"foo(1)", 0},
+		{"pow(1)", 0},
+		{"round(1, 2, 3)", 0},
+		{"if(1, 2)", 0},
+		{"min()", 0},
+		{"max(1, 2", 8},
+		{"1 $ 2", 2},
+		{"1..2", 0},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		var syntax *SyntaxError
+		if !errors.As(err, &syntax) {
+			t.Errorf("Parse(%q): got error %v, want a syntax error", tt.src, err)
+			continue
+		}
+		if syntax.Pos != tt.pos {
+			t.Errorf("Parse(%q): got error at position %d, want %d (%v)", tt.src, syntax.Pos, tt.pos, err)
+		}
+	}
+}
+
+func TestRefsAndVars(t *testing.T) {
+	expr, err := Parse("{3} / pow({2} / 100, 2) + { 3 } * age - male * age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := expr.Refs(), []uint{3, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Refs() = %v, want %v", got, want)
+	}
+	if got, want := expr.Vars(), []string{VarAge, VarMale}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Vars() = %v, want %v", got, want)
+	}
+}
+
+func TestRenameItems(t *testing.T) {
+	rename := map[uint]uint{2: 20, 3: 2}
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"{3} / pow({2} / 100, 2)", "{2} / pow({20} / 100, 2)"},
+		{"{ 3 } + {12} + {23}", "{2} + {12} + {23}"},
+		{"{03}", "{2}"},
+		{"round({4}, 2)", "round({4}, 2)"},
+		{"{99999999999} + {2}", "{99999999999} + {20}"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := RenameItems(tt.src, rename); got != tt.want {
+			t.Errorf("RenameItems(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
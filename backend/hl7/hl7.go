@@ -0,0 +1,311 @@
+// Package hl7 解析 HL7 v2 消息（ER7 管道符编码），提供检验结果 ORU^R01 所需的字段
+package hl7
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoMSH          = errors.New("message does not start with an MSH segment")
+	ErrBadEncoding    = errors.New("invalid MSH encoding characters")
+	ErrNotORU         = errors.New("message is not an ORU^R01 message")
+	ErrMissingPatient = errors.New("message has no PID segment")
+)
+
+// Delimiters 消息的分隔符，由 MSH-1、MSH-2 定义
+type Delimiters struct {
+	Field        byte
+	Component    byte
+	Repetition   byte
+	Escape       byte
+	Subcomponent byte
+}
+
+// Segment 一个段，Fields[0] 为段名；MSH 段按标准编号，Fields[1] 为字段分隔符本身
+type Segment struct {
+	Fields []string
+	delims Delimiters
+}
+
+// Name 段名，如 MSH、PID、OBX
+func (s Segment) Name() string {
+	return s.Fields[0]
+}
+
+// Field 返回第 n 个字段（从 1 开始）的原始内容，不存在时返回空字符串
+func (s Segment) Field(n int) string {
+	if n < 0 || n >= len(s.Fields) {
+		return ""
+	}
+	return s.Fields[n]
+}
+
+// Repetitions 返回字段的各个重复
+func (s Segment) Repetitions(n int) []string {
+	field := s.Field(n)
+	if field == "" {
+		return nil
+	}
+	return strings.Split(field, string(s.delims.Repetition))
+}
+
+// Component 返回第 n 个字段第一个重复中的第 c 个组件（从 1 开始），已解码转义序列
+func (s Segment) Component(n, c int) string {
+	reps := s.Repetitions(n)
+	if len(reps) == 0 {
+		return ""
+	}
+	return s.component(reps[0], c)
+}
+
+func (s Segment) component(value string, c int) string {
+	parts := strings.Split(value, string(s.delims.Component))
+	if c < 1 || c > len(parts) {
+		return ""
+	}
+	return s.unescape(parts[c-1])
+}
+
+// Value 返回字段第一个重复第一个组件的解码值
+func (s Segment) Value(n int) string {
+	return s.Component(n, 1)
+}
+
+// unescape 解码 \F\ \S\ \T\ \R\ \E\ 转义序列，其余转义原样保留
+func (s Segment) unescape(value string) string {
+	esc := string(s.delims.Escape)
+	if !strings.Contains(value, esc) {
+		return value
+	}
+	r := strings.NewReplacer(
+		esc+"F"+esc, string(s.delims.Field),
+		esc+"S"+esc, string(s.delims.Component),
+		esc+"T"+esc, string(s.delims.Subcomponent),
+		esc+"R"+esc, string(s.delims.Repetition),
+		esc+"E"+esc, esc,
+	)
+	return r.Replace(value)
+}
+
+// Message 一条 HL7 v2 消息
+type Message struct {
+	Segments   []Segment
+	Delimiters Delimiters
+}
+
+// Parse 解析一条消息，段之间以 \r、\n 或 \r\n 分隔
+func Parse(text string) (*Message, error) {
+	text = strings.TrimLeft(text, " \t\r\n\ufeff")
+	if !strings.HasPrefix(text, "MSH") || len(text) < 8 {
+		return nil, ErrNoMSH
+	}
+	d := Delimiters{
+		Field:        text[3],
+		Component:    text[4],
+		Repetition:   text[5],
+		Escape:       text[6],
+		Subcomponent: text[7],
+	}
+	seen := map[byte]bool{}
+	for _, c := range []byte{d.Field, d.Component, d.Repetition, d.Escape, d.Subcomponent} {
+		if seen[c] || c == '\r' || c == '\n' {
+			return nil, ErrBadEncoding
+		}
+		seen[c] = true
+	}
+
+	msg := &Message{Delimiters: d}
+	lines := strings.FieldsFunc(text, func(r rune) bool { return r == '\r' || r == '\n' })
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, string(d.Field))
+		if fields[0] == "MSH" {
+			// MSH-1 为字段分隔符本身，MSH-2 为编码字符，补齐编号
+			fields = append([]string{"MSH", string(d.Field)}, fields[1:]...)
+		}
+		msg.Segments = append(msg.Segments, Segment{Fields: fields, delims: d})
+	}
+	return msg, nil
+}
+
+// Split 将包含多条消息的文本按 MSH 段拆分
+func Split(text string) []string {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\r"), "\n", "\r")
+	var messages []string
+	var current []string
+	for _, line := range strings.Split(text, "\r") {
+		if strings.HasPrefix(line, "MSH") && len(current) > 0 {
+			messages = append(messages, strings.Join(current, "\r"))
+			current = nil
+		}
+		if strings.TrimSpace(line) != "" {
+			current = append(current, line)
+		}
+	}
+	if len(current) > 0 {
+		messages = append(messages, strings.Join(current, "\r"))
+	}
+	return messages
+}
+
+// Segment 返回第一个名为 name 的段
+func (m *Message) Segment(name string) (Segment, bool) {
+	for _, s := range m.Segments {
+		if s.Name() == name {
+			return s, true
+		}
+	}
+	return Segment{}, false
+}
+
+// ControlID 消息控制ID（MSH-10）
+func (m *Message) ControlID() string {
+	msh, _ := m.Segment("MSH")
+	return msh.Value(10)
+}
+
+// Type 消息类型（MSH-9），如 ORU^R01
+func (m *Message) Type() string {
+	msh, _ := m.Segment("MSH")
+	return msh.Component(9, 1) + "^" + msh.Component(9, 2)
+}
+
+// Identifier 患者或医嘱的标识（CX/EI），Authority 为分配机构
+type Identifier struct {
+	ID        string
+	Authority string
+}
+
+// Patient PID 段中的患者信息
+type Patient struct {
+	Identifiers []Identifier
+	Family      string // XPN 姓，中文姓名通常整体写在此处
+	Given       string
+	BirthDate   string // YYYY-MM-DD
+	Sex         string // M, F, O, U
+	Phone       string
+}
+
+// Observation OBX 段，一条检验结果
+type Observation struct {
+	SetID          string
+	ValueType      string // NM, ST, TX, CE, CWE ...
+	Code           string
+	Text           string
+	CodingSystem   string // LN 表示 LOINC
+	AltCode        string // OBX-3 的备用编码
+	AltText        string
+	AltSystem      string
+	Value          string
+	Units          string
+	ReferenceRange string
+	AbnormalFlags  string
+	Status         string // F: final, C: corrected, P: preliminary, X/D/W: 取消或删除
+}
+
+// Order OBR 段及其下的检验结果
+type Order struct {
+	PlacerOrder  Identifier
+	FillerOrder  Identifier
+	ServiceCode  string
+	ServiceText  string
+	Observations []Observation
+}
+
+// ORU 检验结果消息 ORU^R01
+type ORU struct {
+	ControlID string
+	Patient   Patient
+	Orders    []Order
+}
+
+// ParseORU 解析 ORU^R01 消息；OBX 出现在第一个 OBR 之前时归入一个空医嘱
+func ParseORU(m *Message) (*ORU, error) {
+	if m.Type() != "ORU^R01" {
+		return nil, ErrNotORU
+	}
+	oru := &ORU{ControlID: m.ControlID()}
+	pid, ok := m.Segment("PID")
+	if !ok {
+		return nil, ErrMissingPatient
+	}
+	for _, rep := range pid.Repetitions(3) {
+		id := Identifier{ID: pid.component(rep, 1), Authority: pid.component(rep, 4)}
+		if id.ID != "" {
+			oru.Patient.Identifiers = append(oru.Patient.Identifiers, id)
+		}
+	}
+	oru.Patient.Family = pid.Component(5, 1)
+	oru.Patient.Given = pid.Component(5, 2)
+	oru.Patient.BirthDate = Date(pid.Value(7))
+	oru.Patient.Sex = pid.Value(8)
+	oru.Patient.Phone = pid.Value(13)
+
+	var order *Order
+	for _, s := range m.Segments {
+		switch s.Name() {
+		case "OBR":
+			oru.Orders = append(oru.Orders, Order{
+				PlacerOrder: Identifier{ID: s.Component(2, 1), Authority: s.Component(2, 2)},
+				FillerOrder: Identifier{ID: s.Component(3, 1), Authority: s.Component(3, 2)},
+				ServiceCode: s.Component(4, 1),
+				ServiceText: s.Component(4, 2),
+			})
+			order = &oru.Orders[len(oru.Orders)-1]
+		case "OBX":
+			if order == nil {
+				oru.Orders = append(oru.Orders, Order{})
+				order = &oru.Orders[len(oru.Orders)-1]
+			}
+			obs := Observation{
+				SetID:          s.Value(1),
+				ValueType:      s.Value(2),
+				Code:           s.Component(3, 1),
+				Text:           s.Component(3, 2),
+				CodingSystem:   s.Component(3, 3),
+				AltCode:        s.Component(3, 4),
+				AltText:        s.Component(3, 5),
+				AltSystem:      s.Component(3, 6),
+				Units:          s.Component(6, 1),
+				ReferenceRange: s.Value(7),
+				AbnormalFlags:  s.Value(8),
+				Status:         s.Value(11),
+			}
+			switch obs.ValueType {
+			case "CE", "CWE", "CNE":
+				// 编码结果取显示文本，没有文本时取编码
+				obs.Value = s.Component(5, 2)
+				if obs.Value == "" {
+					obs.Value = s.Component(5, 1)
+				}
+			case "SN":
+				// 结构化数值，如 >^5 或 ^1^:^128
+				parts := make([]string, 0, 4)
+				for c := 1; c <= 4; c++ {
+					parts = append(parts, s.Component(5, c))
+				}
+				obs.Value = strings.Join(parts, "")
+			default:
+				obs.Value = s.Value(5)
+			}
+			order.Observations = append(order.Observations, obs)
+		}
+	}
+	return oru, nil
+}
+
+// Date 将 HL7 日期（YYYYMMDD[HHMM...]）转换为 YYYY-MM-DD，格式不符或日期无效时返回空字符串
+func Date(value string) string {
+	if len(value) < 8 {
+		return ""
+	}
+	t, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
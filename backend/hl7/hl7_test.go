@@ -0,0 +1,204 @@
+package hl7
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const sampleORU = "MSH|^~\\&|LIS|LAB|HC|HC|20260315093000||ORU^R01|MSG0001|P|2.5.1\r" +
+	"PID|1||1001^^^HC~A123^^^LAB||张^三||19800102|M|||||13800000000\r" +
+	"OBR|1|P100^HC|F200^LAB|CBC^血常规\r" +
+	"OBX|1|NM|718-7^Hemoglobin^LN^HGB^血红蛋白^L||135|g/L|130-175|N|||F\r" +
+	"OBX|2|CE|5778-6^Color^LN||YEL^黄色|||||F\r" +
+	"OBX|3|SN|2345-7^Glucose^LN||>^5|mmol/L||H|||F\r" +
+	"OBX|4|ST|NOTE^备注||A\\S\\B \\F\\ C\\E\\D|||||F"
+
+func TestParse(t *testing.T) {
+	for _, sep := range []string{"\r", "\n", "\r\n"} {
+		msg, err := Parse("\ufeff" + "MSH|^~\\&|LIS||||||ORU^R01|1|P|2.5" + sep + sep + "PID|1||1001" + sep)
+		if err != nil {
+			t.Fatalf("separator %q: %v", sep, err)
+		}
+		if len(msg.Segments) != 2 {
+			t.Fatalf("separator %q: got %d segments, want 2", sep, len(msg.Segments))
+		}
+		msh := msg.Segments[0]
+		if msh.Field(1) != "|" || msh.Field(2) != "^~\\&" || msh.Value(3) != "LIS" {
+			t.Errorf("separator %q: MSH fields not numbered by the standard: %q", sep, msh.Fields)
+		}
+		if msg.Type() != "ORU^R01" || msg.ControlID() != "1" {
+			t.Errorf("separator %q: got type %q, control ID %q", sep, msg.Type(), msg.ControlID())
+		}
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want error
+	}{
+		{"empty", "", ErrNoMSH},
+		{"no MSH", "PID|1||1001", ErrNoMSH},
+		{"MSH too short", "MSH|^~", ErrNoMSH},
+		{"duplicate encoding characters", "MSH|^^\\&|LIS", ErrBadEncoding},
+		{"line break in encoding characters", "MSH|^~\\\r&|LIS", ErrBadEncoding},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.text); !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// 字段、组件不足的段不会越界，缺失的部分按空值处理
+func TestSegmentMissingFields(t *testing.T) {
+	msg, err := Parse("MSH|^~\\&\rOBX\rOBX|1|NM|718-7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range msg.Segments[1:] {
+		if s.Name() != "OBX" {
+			t.Fatalf("got segment %q, want OBX", s.Name())
+		}
+		if s.Field(-1) != "" || s.Field(20) != "" || s.Component(3, 2) != "" || s.Component(3, 0) != "" || s.Repetitions(9) != nil {
+			t.Errorf("missing fields of %q are not empty", s.Fields)
+		}
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"MSH|^~\\&\rNTE|1||A\\F\\B", "A|B"},
+		{"MSH|^~\\&\rNTE|1||A\\S\\B\\T\\C\\R\\D", "A^B&C~D"},
+		{"MSH|^~\\&\rNTE|1||C:\\E\\temp", "C:\\temp"},
+		{"MSH|^~\\&\rNTE|1||\\E\\F\\", "\\F\\"},
+		{"MSH|^~\\&\rNTE|1||line\\X0D\\break", "line\\X0D\\break"},
+		{"MSH|^~\\&\rNTE|1||trailing\\", "trailing\\"},
+		{"MSH#$*!@\rNTE#1##a!F!b!S!c", "a#b$c"},
+	}
+	for _, tt := range tests {
+		msg, err := Parse(tt.text)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.text, err)
+		}
+		nte, _ := msg.Segment("NTE")
+		if got := nte.Value(3); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseORU(t *testing.T) {
+	msg, err := Parse(sampleORU)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oru, err := ParseORU(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPatient := Patient{
+		Identifiers: []Identifier{{ID: "1001", Authority: "HC"}, {ID: "A123", Authority: "LAB"}},
+		Family:      "张",
+		Given:       "三",
+		BirthDate:   "1980-01-02",
+		Sex:         "M",
+		Phone:       "13800000000",
+	}
+	if oru.ControlID != "MSG0001" || !reflect.DeepEqual(oru.Patient, wantPatient) {
+		t.Fatalf("got %q %+v, want MSG0001 %+v", oru.ControlID, oru.Patient, wantPatient)
+	}
+	if len(oru.Orders) != 1 || len(oru.Orders[0].Observations) != 4 {
+		t.Fatalf("got orders %+v", oru.Orders)
+	}
+	order := oru.Orders[0]
+	if order.PlacerOrder != (Identifier{ID: "P100", Authority: "HC"}) || order.ServiceText != "血常规" {
+		t.Errorf("got order %+v", order)
+	}
+	hgb := order.Observations[0]
+	if hgb.Code != "718-7" || hgb.CodingSystem != "LN" || hgb.AltText != "血红蛋白" || hgb.Value != "135" ||
+		hgb.Units != "g/L" || hgb.ReferenceRange != "130-175" || hgb.Status != "F" {
+		t.Errorf("got observation %+v", hgb)
+	}
+	values := make([]string, 0, len(order.Observations))
+	for _, obs := range order.Observations {
+		values = append(values, obs.Value)
+	}
+	if want := []string{"135", "黄色", ">5", "A^B | C\\D"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("got values %q, want %q", values, want)
+	}
+}
+
+func TestParseORUMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want error
+	}{
+		{"not ORU", "MSH|^~\\&|||||||ADT^A01|1\rPID|1||1001", ErrNotORU},
+		{"no message type", "MSH|^~\\&\rPID|1||1001", ErrNotORU},
+		{"no PID", "MSH|^~\\&|||||||ORU^R01|1\rOBX|1|NM|718-7||135", ErrMissingPatient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := Parse(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ParseORU(msg); !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// OBX 出现在 OBR 之前归入一个空医嘱；空值的编码结果取编码
+	msg, err := Parse("MSH|^~\\&|||||||ORU^R01|1\rPID|1||1001||||2026\rOBX|1|CWE|5778-6||YEL\rOBX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oru, err := ParseORU(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oru.Patient.BirthDate != "" {
+		t.Errorf("got birth date %q for a truncated date", oru.Patient.BirthDate)
+	}
+	if len(oru.Orders) != 1 || len(oru.Orders[0].Observations) != 2 || oru.Orders[0].Observations[0].Value != "YEL" {
+		t.Errorf("got orders %+v", oru.Orders)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	got := Split("MSH|a\r\nPID|1\n\nMSH|b\rPID|2\r")
+	want := []string{"MSH|a\rPID|1", "MSH|b\rPID|2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if got := Split(" \r\n"); got != nil {
+		t.Fatalf("got %q for blank input", got)
+	}
+}
+
+func TestDate(t *testing.T) {
+	tests := map[string]string{
+		"19800102":       "1980-01-02",
+		"198001021230+8": "1980-01-02",
+		"1980":           "",
+		"1980ab02":       "",
+		"-1980102":       "",
+		"19801302":       "",
+		"19800230":       "",
+	}
+	for value, want := range tests {
+		if got := Date(value); got != want {
+			t.Errorf("Date(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ZhCN = "zh-CN"
+	En   = "en"
+
+	// DefaultLanguage 未能识别请求语言时使用的默认语言
+	DefaultLanguage = ZhCN
+
+	// ContextKey gin.Context 中保存当前请求语言的键
+	ContextKey = "lang"
+)
+
+// Message 一条消息在各语言下的文本
+type Message struct {
+	ZhCN string
+	En   string
+}
+
+var catalog = make(map[string]Message)
+
+// register 将消息并入目录，供各消息文件在 init 中调用
+func register(messages map[string]Message) {
+	for key, msg := range messages {
+		catalog[key] = msg
+	}
+}
+
+// Supported 判断语言是否受支持
+func Supported(lang string) bool {
+	return lang == ZhCN || lang == En
+}
+
+// Normalize 将 "zh"、"zh_CN"、"en-US" 等写法归一为受支持的语言，无法识别时返回空字符串
+func Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(lang, "_", "-")))
+	switch {
+	case lang == "":
+		return ""
+	case lang == "zh" || strings.HasPrefix(lang, "zh-"):
+		return ZhCN
+	case lang == "en" || strings.HasPrefix(lang, "en-"):
+		return En
+	default:
+		return ""
+	}
+}
+
+// Negotiate 按 q 值解析 Accept-Language 请求头，返回首个受支持的语言
+func Negotiate(header string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := Normalize(fields[0])
+		if lang == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		candidates = append(candidates, candidate{lang: lang, q: q})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	for _, c := range candidates {
+		if c.q > 0 {
+			return c.lang
+		}
+	}
+	return DefaultLanguage
+}
+
+// Translate 返回指定语言的消息文本，带参数时按 fmt.Sprintf 格式化；未登记的键原样返回
+func Translate(lang, key string, args ...interface{}) string {
+	msg, ok := catalog[key]
+	if !ok {
+		return key
+	}
+
+	text := msg.ZhCN
+	if lang == En && msg.En != "" {
+		text = msg.En
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Lang 获取当前请求的语言，未经中间件设置时退回解析 Accept-Language
+func Lang(ctx *gin.Context) string {
+	if lang := ctx.GetString(ContextKey); lang != "" {
+		return lang
+	}
+	return Negotiate(ctx.GetHeader("Accept-Language"))
+}
+
+// T 按当前请求语言翻译消息
+func T(ctx *gin.Context, key string, args ...interface{}) string {
+	return Translate(Lang(ctx), key, args...)
+}
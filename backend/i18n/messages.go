@@ -0,0 +1,466 @@
+package i18n
+
+func init() {
+	register(map[string]Message{
+		"user_not_found":                    {"用户不存在", "User not found"},
+		"invalid_user":                      {"无效的用户", "Invalid user"},
+		"unauthorized":                      {"未授权", "Unauthorized"},
+		"invalid_user_id":                   {"无效的用户ID", "Invalid user ID"},
+		"invalid_plan_id":                   {"无效的套餐ID", "Invalid plan ID"},
+		"invalid_institution_id":            {"无效的机构ID", "Invalid institution ID"},
+		"invalid_customer_id":               {"无效的客户ID", "Invalid customer ID"},
+		"invalid_request":                   {"无效的请求数据", "Invalid request data"},
+		"invalid_request_detail":            {"无效的请求数据: ", "Invalid request data: "},
+		"bad_request_format":                {"请求格式错误: ", "Malformed request: "},
+		"invalid_input":                     {"输入格式不正确", "Invalid input"},
+		"internal_error":                    {"服务器内部错误", "Internal server error"},
+		"db_error":                          {"数据库错误: ", "Database error: "},
+		"commit_failed":                     {"提交事务失败: ", "Failed to commit transaction: "},
+		"delete_failed":                     {"删除失败: ", "Delete failed: "},
+		"update_failed":                     {"修改失败: ", "Update failed: "},
+		"update_success":                    {"更新成功", "Updated successfully"},
+		"permission_denied":                 {"您没有权限进行此操作", "You don't have permission to perform this operation"},
+		"permission_denied_view_data":       {"您没有权限查看此数据", "You don't have permission to view this data"},
+		"missing_auth_header":               {"缺少 Authorization 请求头", "Missing Authorization Header"},
+		"invalid_token":                     {"无效的令牌", "Invalid Token"},
+		"invalid_credentials":               {"用户名或密码错误", "Invalid Credentials"},
+		"invalid_user_type":                 {"无效的用户类型，必须为 1（普通用户）、2（管理员）或 3（机构用户）", "Invalid user type. Must be 1 (normal), 2 (admin), or 3 (institution)"},
+		"insufficient_privileges":           {"权限不足，无法访问", "Unauthorized access: insufficient privileges"},
+		"admin_required":                    {"需要管理员权限", "Admin privileges required"},
+		"password_mismatch":                 {"原密码不正确", "Invalid Credentials: password does not match"},
+		"new_password_mismatch":             {"两次输入的新密码不一致", "Invalid Credentials: new passwords do not match"},
+		"user_not_found_or_unchanged":       {"用户不存在或未作任何修改", "User not found or no changes made"},
+		"password_updated":                  {"密码修改成功，请重新登录", "Password updated successfully. Please login again."},
+		"no_institution_for_user":           {"该用户没有关联的机构", "No institution found for this user"},
+		"profile_updated":                   {"个人信息更新成功", "User profile updated successfully"},
+		"delete_user_forbidden":             {"您没有权限删除该用户", "You do not have permission to delete this user"},
+		"user_deleted":                      {"用户已删除", "User deleted successfully"},
+		"deletion_scheduled":                {"注销申请已提交，账号将于 %s 注销，此前可随时撤销", "Account deletion scheduled for %s; you can cancel it before then"},
+		"deletion_already_scheduled":        {"已有待执行的注销申请", "Account deletion is already scheduled"},
+		"deletion_not_found":                {"没有待执行的注销申请", "No pending account deletion"},
+		"deletion_cancelled":                {"注销申请已撤销", "Account deletion cancelled"},
+		"institution_account_not_deletable": {"该账号名下仍有机构，请先删除机构", "This account still owns an institution; delete the institution first"},
+		"user_permission_updated":           {"用户权限更新成功", "User permission updated successfully"},
+		"user_id_required":                  {"用户ID不能为空", "User ID is required"},
+		"personal_item_create_failed":       {"新建个人健康档案指标失败: ", "Failed to create personal health metric: "},
+		"personal_item_not_created":         {"未能创建健康档案指标", "Health metric was not created"},
+		"personal_item_created":             {"新建个人健康档案指标成功", "Personal health metric created successfully"},
+		"personal_item_delete_failed":       {"删除健康档案指标失败: ", "Failed to delete health metric: "},
+		"personal_item_not_found":           {"未找到对应的健康档案记录", "Health record not found"},
+		"personal_item_deleted":             {"删除个人健康档案指标成功", "Personal health metric deleted successfully"},
+		"personal_item_update_failed":       {"修改健康档案指标失败: ", "Failed to update health metric: "},
+		"personal_item_updated":             {"修改个人健康档案指标成功", "Personal health metric updated successfully"},
+		"query_original_failed":             {"查询原始数据失败: ", "Failed to query original data: "},
+		"family_request_not_found":          {"家庭关系请求不存在", "Request not found"},
+		"family_request_fetch_failed":       {"获取家庭关系请求失败", "Failed to fetch request"},
+		"family_pending_fetch_failed":       {"获取待处理请求失败", "Failed to fetch pending requests"},
+		"family_request_update_failed":      {"更新家庭关系请求失败", "Failed to update request"},
+		"family_request_delete_failed":      {"删除家庭关系请求失败", "Failed to delete request"},
+		"family_request_accepted":           {"已接受家庭关系请求", "Request accepted successfully"},
+		"family_request_rejected":           {"已拒绝家庭关系请求", "Request rejected successfully"},
+		"family_fetch_failed":               {"获取家庭关系失败", "Failed to fetch family relationships"},
+		"family_status_delete_failed":       {"删除授权状态失败: ", "Failed to delete authorization: "},
+		"family_status_not_found":           {"未找到对应的授权记录", "Authorization record not found"},
+		"family_status_deleted":             {"删除授权状态成功", "Authorization deleted successfully"},
+		"family_rename_failed":              {"修改亲友关系失败: ", "Failed to update relationship: "},
+		"family_member_not_found":           {"未找到对应的亲友记录", "Family member not found"},
+		"family_renamed":                    {"修改亲友关系成功", "Relationship updated successfully"},
+		"family_request_sent":               {"家庭关系请求已发送", "Family request sent"},
+		"unknown_relationship":              {"未定义的亲属称谓: %s", "Unknown relationship: %s"},
+		"relationship_gender_mismatch":      {"称谓 %s 与双方性别不符", "Relationship %s does not match the genders of the two users"},
+		"invalid_family_scope":              {"无效的授权范围: %s", "Invalid grant scope: %s"},
+		"family_grant_expiry_past":          {"授权到期时间必须晚于当前时间", "Grant expiry must be in the future"},
+		"family_relation_not_found":         {"与该用户没有已确认的亲属关系", "No confirmed family relationship with this user"},
+		"family_grant_failed":               {"保存授权失败: ", "Failed to save grant: "},
+		"family_grant_saved":                {"授权已保存", "Grant saved"},
+		"family_grants_fetch_failed":        {"获取授权失败: ", "Failed to retrieve grants: "},
+		"family_grant_not_found":            {"授权不存在", "Grant not found"},
+		"family_grant_revoked":              {"授权已撤销", "Grant revoked"},
+		"family_scope_required":             {"对方未授予您该项权限或授权已失效", "This family member has not granted you this access, or the grant has expired"},
+		"access_logs_fetch_failed":          {"获取访问记录失败: ", "Failed to retrieve access logs: "},
+		"audit_logs_fetch_failed":           {"获取审计日志失败: ", "Failed to retrieve audit logs: "},
+		"audit_write_failed":                {"写入审计日志失败: ", "Failed to write audit log: "},
+		"guardian_required":                 {"只有普通用户账号可以监护被监护人档案", "Only regular user accounts can manage dependent profiles"},
+		"dependent_create_failed":           {"创建被监护人档案失败: ", "Failed to create dependent profile: "},
+		"dependent_created":                 {"被监护人档案已创建", "Dependent profile created"},
+		"dependents_fetch_failed":           {"获取被监护人档案失败: ", "Failed to retrieve dependent profiles: "},
+		"dependent_not_found":               {"被监护人档案不存在", "Dependent profile not found"},
+		"dependent_update_failed":           {"修改被监护人档案失败: ", "Failed to update dependent profile: "},
+		"dependent_updated":                 {"被监护人档案已更新", "Dependent profile updated"},
+		"dependent_login_forbidden":         {"该账号为被监护人档案，请先使用认领码认领账号", "This is a managed dependent profile; claim it with a claim code first"},
+		"dependent_family_link_required":    {"监护人与被监护人档案的关系不能删除", "The link between a guardian and a managed dependent cannot be removed"},
+		"claim_code_create_failed":          {"生成认领码失败: ", "Failed to create claim code: "},
+		"claim_code_invalid":                {"认领码无效或已过期", "Claim code is invalid or expired"},
+		"claim_failed":                      {"认领账号失败: ", "Failed to claim account: "},
+		"dependent_claimed":                 {"账号认领成功", "Account claimed successfully"},
+		"username_taken":                    {"用户名已被使用", "Username is already taken"},
+		"institution_not_found":             {"机构不存在", "Institution not found"},
+		"institution_not_approved":          {"该机构尚未通过审核", "This institution is not approved"},
+		"only_institution_create":           {"只有机构用户可以创建机构信息", "Only institution users can create institution info"},
+		"institution_already_created":       {"该用户已经创建过机构信息", "This user has already created an institution"},
+		"institution_create_failed":         {"创建机构信息失败: ", "Failed to create institution: "},
+		"institution_submitted":             {"机构信息提交成功，等待管理员审核", "Institution submitted successfully, pending admin review"},
+		"only_admin_view_pending":           {"只有管理员可以查看待审核机构", "Only admin users can view pending institutions"},
+		"only_admin_review":                 {"只有管理员可以审核机构", "Only admin users can review institutions"},
+		"institution_approved":              {"机构审核通过", "Institution approved successfully"},
+		"institution_rejected":              {"机构审核未通过", "Institution rejected"},
+		"institution_view_forbidden":        {"您没有权限查看该机构", "You don't have permission to view this institution"},
+		"plan_create_forbidden":             {"您没有权限为该机构创建套餐", "You don't have permission to create plans for this institution"},
+		"health_item_name_required":         {"健康项目名称是必填的", "Health item name is required"},
+		"plan_name_exists":                  {"%v 已经存在，请更换名称或者是更新已有内容", "%v already exists, please choose another name or update the existing plan"},
+		"health_item_create_failed":         {"创建健康检查项目失败: ", "Failed to create health item: "},
+		"health_item_query_failed":          {"查询健康检查项目失败: ", "Failed to query health item: "},
+		"plan_item_exists":                  {"此套餐已包含健康项目: %s", "This plan already contains health item: %s"},
+		"plan_item_check_failed":            {"检查套餐项目关系时出错: ", "Failed to check plan item relation: "},
+		"plan_created":                      {"套餐创建成功", "Plan created successfully"},
+		"plan_item_created":                 {"体检项目创建成功", "Health item created successfully"},
+		"plan_item_not_found":               {"套餐内体检项目不存在", "Health item not found in plan"},
+		"plan_item_deleted":                 {"套餐内体检项目删除成功", "Health item removed from plan successfully"},
+		"user_package_check_failed":         {"检查用户套餐关联失败: ", "Failed to check user packages: "},
+		"user_package_delete_failed":        {"删除用户套餐关联失败: ", "Failed to delete user packages: "},
+		"user_health_item_delete_failed":    {"删除用户健康项目失败: ", "Failed to delete user health items: "},
+		"plan_commentary_delete_failed":     {"删除套餐评论失败: ", "Failed to delete plan commentaries: "},
+		"plan_item_relation_delete_failed":  {"删除套餐内体检项目关联失败: ", "Failed to delete plan item relation: "},
+		"plan_query_failed":                 {"查找套餐失败: ", "Failed to query plan: "},
+		"plan_delete_fk":                    {"删除套餐失败: 存在外键约束，请先删除套餐相关的", "Failed to delete plan: foreign key constraint, please delete related "},
+		"remaining_user_packages":           {"用户套餐关联(%d) ", "user packages (%d) "},
+		"remaining_user_health_items":       {"用户健康项目(%d) ", "user health items (%d) "},
+		"plan_delete_failed":                {"删除套餐失败: ", "Failed to delete plan: "},
+		"plan_deleted":                      {"套餐删除成功", "Plan deleted successfully"},
+		"institution_name_exists":           {"机构名称已存在", "Institution name already exists"},
+		"institution_update_forbidden":      {"您没有权限更新该机构", "You don't have permission to update this institution"},
+		"institution_update_failed":         {"更新机构信息失败: ", "Failed to update institution: "},
+		"institution_updated":               {"机构信息更新成功", "Institution updated successfully"},
+		"institution_delete_forbidden":      {"您没有权限删除该机构", "You don't have permission to delete this institution"},
+		"institution_plans_query_failed":    {"查询机构套餐失败: ", "Failed to query institution plans: "},
+		"plan_health_items_delete_failed":   {"删除套餐体检项目失败: ", "Failed to delete plan health items: "},
+		"institution_plans_delete_failed":   {"删除机构套餐失败: ", "Failed to delete institution plans: "},
+		"institution_delete_failed":         {"删除机构失败: ", "Failed to delete institution: "},
+		"institution_deleted":               {"机构及相关数据已成功删除", "Institution and related data deleted successfully"},
+		"institution_for_user_not_found":    {"未找到该用户的机构", "Institution not found for this user"},
+		"institution_id_required":           {"管理员需要指定机构ID", "Institution ID is required for admin users"},
+		"no_associated_institution":         {"您没有关联的机构", "You have no associated institution"},
+		"plan_not_found":                    {"套餐不存在", "Plan not found"},
+		"plan_not_found_for_institution":    {"该机构下不存在此套餐", "Plan not found for this institution"},
+		"plan_not_found_no_items":           {"套餐不存在且没有关联的项目", "Plan not found and no items associated"},
+		"plan_not_found_for_user":           {"套餐不存在或与当前用户无关", "Plan not found or not associated with this user"},
+		"plan_access_forbidden":             {"您无权访问该套餐", "You don't have access to this plan"},
+		"plan_operate_forbidden":            {"您没有权限操作此套餐", "You don't have permission to operate on this plan"},
+		"plan_version_create_failed":        {"生成套餐版本失败: ", "Failed to create plan version: "},
+		"plan_versions_fetch_failed":        {"获取套餐版本失败: ", "Failed to retrieve plan versions: "},
+		"plan_version_not_found":            {"套餐版本不存在", "Plan version not found"},
+		"invalid_plan_version":              {"无效的套餐版本号", "Invalid plan version"},
+		"compare_plan_count":                {"请选择 2 到 %d 个套餐进行对比", "Please select between 2 and %d plans to compare"},
+		"compare_plan_not_found":            {"ID为%d的套餐不存在", "Plan with ID %d does not exist"},
+		"plan_items_fetch_failed":           {"获取套餐项目失败: ", "Failed to retrieve plan items: "},
+		"plan_items_retrieve_failed":        {"获取套餐项目失败", "Failed to retrieve plan items"},
+		"no_plan_items":                     {"该套餐暂无体检项目", "No health items found for this plan"},
+		"plan_association_delete_failed":    {"删除现有关联失败: ", "Failed to delete existing associations: "},
+		"health_item_id_not_found":          {"ID为%d的健康项目不存在", "Health item with ID %d does not exist"},
+		"plan_association_create_failed":    {"创建健康项目关联失败: ", "Failed to associate health item: "},
+		"plan_items_associated":             {"套餐关联健康项目成功", "Health items associated with plan successfully"},
+		"health_item_not_found":             {"健康项目不存在", "Health item not found"},
+		"health_item_already_in_plan":       {"该健康项目已关联到此套餐", "This health item is already associated with the plan"},
+		"health_item_added_to_plan":         {"成功添加健康项目到套餐", "Health item added to plan successfully"},
+		"health_data_fetch_failed":          {"获取健康数据失败: ", "Failed to retrieve health data: "},
+		"user_packages_fetch_failed":        {"获取用户套餐失败: ", "Failed to retrieve user packages: "},
+		"user_packages_retrieve_failed":     {"获取用户套餐失败", "Failed to retrieve user packages"},
+		"user_has_no_institution_plan":      {"用户未选择该机构的套餐", "The user has not selected any plan of this institution"},
+		"user_plans_fetch_failed":           {"获取用户套餐列表失败: ", "Failed to retrieve user plans: "},
+		"deleted_plans_fetch_failed":        {"获取已删除套餐列表失败: ", "Failed to retrieve deleted plans: "},
+		"plan_not_found_unrecoverable":      {"套餐不存在，无法恢复", "Plan not found, cannot recover"},
+		"plan_not_deleted":                  {"套餐未被删除，无需恢复", "Plan is not deleted, no need to recover"},
+		"plan_recover_failed":               {"恢复套餐失败: ", "Failed to recover plan: "},
+		"plan_items_recover_failed":         {"恢复套餐项目失败: ", "Failed to recover plan items: "},
+		"plan_recovered":                    {"套餐恢复成功", "Plan recovered successfully"},
+		"only_admin_recover_plans":          {"权限不足：只有管理员可以恢复已删除的套餐", "Permission denied: only admins can recover deleted plans"},
+		"only_admin_view_deleted_plans":     {"权限不足：只有管理员可以查看已删除的套餐", "Permission denied: only admins can view deleted plans"},
+		"health_items_fetch_failed":         {"获取健康检查项目失败: ", "Failed to retrieve health items: "},
+		"health_check_item_not_found":       {"健康检查项目不存在", "Health item not found"},
+		"health_check_item_update_failed":   {"更新健康检查项目失败: ", "Failed to update health item: "},
+		"health_check_item_updated":         {"健康检查项目已更新", "Health item updated"},
+		"item_description_update_failed":    {"更新项目描述失败: ", "Failed to update item description: "},
+		"item_description_updated":          {"项目描述已更新", "Item description updated"},
+		"at_least_one_item":                 {"至少需要输入一个检查项目", "At least one health item is required"},
+		"check_item_not_found":              {"检查项目不存在", "Health item not found"},
+		"check_item_update_failed":          {"更新检查项目失败: ", "Failed to update health item: "},
+		"check_item_updated":                {"检查项目更新成功", "Health item updated successfully"},
+		"invalid_check_item_id":             {"无效的检查项目ID", "Invalid health item ID"},
+		"invalid_check_item_id_format":      {"无效的检查项目ID格式", "Invalid health item ID format"},
+		"health_item_save_failed":           {"创建健康项目失败: ", "Failed to create health item: "},
+		"health_item_saved":                 {"健康项目保存成功", "Health item saved successfully"},
+		"invalid_item_id":                   {"无效的项目ID", "Invalid item ID"},
+		"customer_not_found":                {"客户不存在", "Customer not found"},
+		"only_institution_add_data":         {"权限不足：只有机构用户可以添加体检数据", "Permission denied: only institution users can add health data"},
+		"user_package_create_failed":        {"创建用户套餐关联失败", "Failed to create user package association"},
+		"plan_item_validate_failed":         {"校验套餐体检项目失败", "Failed to validate health item in plan"},
+		"health_item_not_in_plan":           {"健康项目 %d 不属于套餐 %s", "Health item ID %d is not associated with plan ID %s"},
+		"user_health_item_create_failed":    {"创建用户体检数据失败: ", "Failed to create user health item: "},
+		"user_health_item_update_failed":    {"更新用户体检数据失败: ", "Failed to update user health item: "},
+		"user_package_status_update_failed": {"更新用户套餐状态失败", "Failed to update user package status"},
+		"user_health_items_updated":         {"用户体检数据更新成功", "User health items updated successfully"},
+		"user_health_items_fetch_failed":    {"获取用户体检数据失败", "Failed to retrieve user health items"},
+		"marshal_items_failed":              {"序列化体检项目失败", "Failed to marshal items"},
+		"only_institution_view_packages":    {"权限不足：只有机构用户可以查看用户套餐", "Permission denied: only institution users can view user packages"},
+		"package_already_selected":          {"您已经选择过该套餐", "You have already selected this package"},
+		"package_select_failed":             {"选择套餐失败: ", "Failed to select package: "},
+		"package_selected":                  {"套餐选择成功", "Package selected successfully"},
+		"view_packages_forbidden":           {"您没有权限查看其他用户的套餐", "You don't have permission to view other user's packages"},
+		"only_institution_update_status":    {"权限不足：只有机构用户或管理员可以更新套餐状态", "Permission denied: only institution users or admins can update package status"},
+		"status_required":                   {"无效的请求数据：缺少状态值", "Invalid request data: status is required"},
+		"invalid_status_value":              {"无效的状态值：必须为 0（待检）或 1（已完成）", "Invalid status value: must be 0 (pending) or 1 (completed)"},
+		"package_status_update_failed":      {"更新套餐状态失败: ", "Failed to update package status: "},
+		"package_not_found":                 {"未找到该用户的此套餐", "Package not found for this user and plan"},
+		"package_status_updated":            {"套餐状态更新成功", "Package status updated successfully"},
+		"appointment_in_past":               {"预约时间必须晚于当前时间", "Appointment time must be in the future"},
+		"appointment_saved":                 {"预约时间已更新", "Appointment updated"},
+		"package_not_pending":               {"该套餐已完成体检，不能修改预约", "This package has already been completed; its appointment cannot be changed"},
+		"commentary_add_failed":             {"发布评论失败", "Failed to add commentary"},
+		"commentary_added":                  {"评论发布成功", "Commentary added successfully"},
+		"commentary_not_found":              {"评论不存在", "Commentary not found"},
+		"commentary_delete_forbidden":       {"您没有权限删除该评论", "You do not have permission to delete this commentary"},
+		"commentary_delete_failed":          {"删除评论失败", "Failed to delete commentary"},
+		"commentary_deleted":                {"评论删除成功", "Commentary deleted successfully"},
+		"commentaries_fetch_failed":         {"获取评论失败", "Failed to retrieve commentaries"},
+		"commentary_purchase_required":      {"只有完成该套餐体检且已收到结果的用户才能评价", "Only users who completed this plan and received their results can review it"},
+		"commentary_already_reviewed":       {"您已评价过该套餐的全部已完成订单", "You have already reviewed every completed purchase of this plan"},
+		"rating_refresh_failed":             {"更新评分失败: ", "Failed to update rating: "},
+		"commentary_reply_forbidden":        {"只有套餐所属机构可以回复该评论", "Only the plan's institution can reply to this commentary"},
+		"commentary_replied":                {"回复成功", "Reply saved"},
+		"commentary_already_reported":       {"您已举报过该评论", "You have already reported this commentary"},
+		"commentary_report_failed":          {"举报失败: ", "Failed to report commentary: "},
+		"commentary_reported":               {"举报已提交，等待管理员处理", "Report submitted for review"},
+		"invalid_moderation_status":         {"无效的审核状态，可选 reported 或 hidden", "Invalid moderation status, expected reported or hidden"},
+		"commentary_hidden":                 {"评论已隐藏", "Commentary hidden"},
+		"commentary_restored":               {"评论已恢复", "Commentary restored"},
+		"invalid_query_params":              {"无效的查询参数: ", "Invalid query parameters: "},
+		"invalid_institution_status":        {"无效的机构状态", "Invalid institution status"},
+		"image_upload_failed":               {"图片上传失败", "Image upload failed"},
+		"image_read_failed":                 {"图片读取失败", "Failed to read image"},
+		"ocr_request_failed":                {"请求OCR服务失败", "Failed to build OCR request"},
+		"ocr_call_failed":                   {"调用OCR服务失败", "Failed to call OCR service"},
+		"ocr_read_failed":                   {"读取OCR结果失败", "Failed to read OCR result"},
+		"ocr_parse_failed":                  {"解析OCR结果失败", "Failed to parse OCR result"},
+
+		// 语言与译名
+		"unsupported_language":      {"不支持的语言: %s", "Unsupported language: %s"},
+		"language_updated":          {"语言偏好已更新", "Language preference updated"},
+		"translations_fetch_failed": {"获取译名失败: ", "Failed to retrieve translations: "},
+		"translation_save_failed":   {"保存译名失败: ", "Failed to save translation: "},
+		"translation_saved":         {"译名已保存", "Translation saved"},
+
+		// 搜索
+		"search_query_required":       {"请输入搜索关键词", "Search query is required"},
+		"invalid_search_type":         {"无效的搜索类型，可选 plan 或 institution", "Invalid search type, expected plan or institution"},
+		"search_failed":               {"搜索失败: ", "Search failed: "},
+		"search_index_rebuild_failed": {"重建搜索索引失败: ", "Failed to rebuild search index: "},
+		"search_index_rebuilt":        {"搜索索引已重建", "Search index rebuilt"},
+
+		// 数据导出
+		"report_file_save_failed": {"保存报告文件失败: ", "Failed to save report file: "},
+		"export_in_progress":      {"已有正在生成的导出任务，请稍后再试", "An export is already in progress"},
+		"export_started":          {"已开始生成导出文件，完成后可下载", "Export started; it can be downloaded once ready"},
+		"export_create_failed":    {"创建导出任务失败: ", "Failed to create export: "},
+		"exports_fetch_failed":    {"获取导出任务失败: ", "Failed to retrieve exports: "},
+		"export_not_found":        {"导出任务不存在", "Export not found"},
+		"invalid_export_id":       {"无效的导出任务ID", "Invalid export ID"},
+		"export_not_ready":        {"导出文件尚未生成", "Export is not ready yet"},
+		"export_expired":          {"导出文件已过期，请重新导出", "Export has expired; please start a new one"},
+		"export_title":            {"个人健康数据导出", "Personal Health Data Export"},
+		"export_generated_at":     {"生成时间", "Generated at"},
+		"export_profile":          {"个人信息", "Profile"},
+		"export_username":         {"用户名", "Username"},
+		"export_name":             {"姓名", "Name"},
+		"export_gender":           {"性别", "Gender"},
+		"export_birthday":         {"生日", "Birthday"},
+		"export_phone":            {"手机号", "Phone"},
+		"export_email":            {"邮箱", "Email"},
+		"export_address":          {"地址", "Address"},
+		"export_family":           {"家庭成员", "Family"},
+		"export_relationship":     {"关系", "Relationship"},
+		"export_confirmed":        {"已确认", "Confirmed"},
+		"export_packages":         {"体检套餐", "Checkup Packages"},
+		"export_plan":             {"套餐", "Plan"},
+		"export_institution":      {"机构", "Institution"},
+		"export_appointment":      {"预约时间", "Appointment"},
+		"export_completed":        {"已完成", "Completed"},
+		"export_created_at":       {"创建时间", "Created at"},
+		"export_results":          {"检查结果", "Results"},
+		"export_item":             {"项目", "Item"},
+		"export_value":            {"结果", "Value"},
+		"export_reference":        {"参考范围", "Reference range"},
+		"export_recorded_at":      {"记录时间", "Recorded at"},
+		"export_commentaries":     {"评价", "Reviews"},
+		"export_rating":           {"评分", "Rating"},
+		"export_commentary":       {"内容", "Review"},
+		"export_reply":            {"机构回复", "Institution reply"},
+		"export_files":            {"上传的报告文件", "Uploaded Reports"},
+		"export_file_name":        {"文件名", "File name"},
+		"export_uploaded_at":      {"上传时间", "Uploaded at"},
+
+		// FHIR 导出
+		"invalid_loinc_code":      {"无效的 LOINC 编码: %s", "Invalid LOINC code: %s"},
+		"loinc_mapping_saved":     {"LOINC 映射已保存", "LOINC mapping saved"},
+		"loinc_mapping_deleted":   {"LOINC 映射已删除", "LOINC mapping deleted"},
+		"loinc_mapping_not_found": {"该项目没有 LOINC 映射", "No LOINC mapping for this item"},
+
+		// 检验结果导入
+		"import_body_too_large":     {"导入内容不能超过 %dMB", "Import body must not exceed %dMB"},
+		"import_invalid_bundle":     {"无效的 FHIR Bundle: ", "Invalid FHIR Bundle: "},
+		"import_unknown_format":     {"无法识别导入格式，请指定 format 为 hl7v2 或 fhir", "Unknown import format; set format to hl7v2 or fhir"},
+		"import_no_messages":        {"请求中没有可导入的消息", "No messages found in the request"},
+		"import_finished":           {"已处理 %d 条消息，写入 %d 项结果，拒绝 %d 项", "Processed %d messages: %d results written, %d rejected"},
+		"import_report_not_found":   {"导入报告不存在", "Import report not found"},
+		"import_parse_failed":       {"消息解析失败: %s", "Failed to parse message: %s"},
+		"import_duplicate_message":  {"消息 %s 已导入，不能重复导入", "Message %s has already been imported"},
+		"import_write_failed":       {"写入检验结果失败: %s", "Failed to write results: %s"},
+		"import_patient_not_found":  {"未找到匹配的患者，请提供本系统用户ID或一致的手机号与姓名", "No matching patient; provide our user ID or a matching phone and name"},
+		"import_patient_ambiguous":  {"匹配到多个患者，请提供本系统用户ID", "Multiple patients match; provide our user ID"},
+		"import_patient_mismatch":   {"患者出生日期与档案不一致", "Patient birth date does not match our records"},
+		"import_package_not_found":  {"未找到该患者待导入的套餐", "No matching package found for this patient"},
+		"import_package_ambiguous":  {"该患者有多个待检测套餐，请指定 user_package_id", "The patient has several pending packages; specify user_package_id"},
+		"import_package_forbidden":  {"该套餐不属于您的机构", "The package does not belong to your institution"},
+		"import_no_results":         {"消息中没有可写入的检验结果", "No results in the message could be written"},
+		"import_line_skipped":       {"结果已取消或未完成，已跳过", "Result cancelled or not available; skipped"},
+		"import_line_unmatched":     {"未匹配到套餐中的体检项目", "No matching item in the package"},
+		"import_line_derived":       {"衍生项目由公式计算，不能导入", "Derived item is calculated by formula and cannot be imported"},
+		"import_line_duplicate":     {"项目 %d 在消息中重复", "Item %d appears more than once in the message"},
+		"import_line_empty":         {"结果值为空", "Result value is empty"},
+		"import_line_too_long":      {"结果值超过 %d 个字符", "Result value exceeds %d characters"},
+		"import_line_not_numeric":   {"数值型结果无效: %s", "Invalid numeric result: %s"},
+		"import_line_unit_mismatch": {"单位 %s 与项目单位 %s 不一致", "Unit %s differs from the item unit %s"},
+		"import_line_preliminary":   {"初步结果", "Preliminary result"},
+
+		// 批量上传
+		"batch_file_required":       {"请上传 CSV 或 XLSX 文件（file 字段）", "Upload a CSV or XLSX file in the file field"},
+		"batch_file_invalid":        {"无法读取文件: ", "Unable to read file: "},
+		"batch_empty":               {"文件中没有数据行", "The file has no data rows"},
+		"batch_too_many_rows":       {"一次最多上传 %d 位客户", "At most %d customers per upload"},
+		"batch_no_customer_column":  {"表头须包含 user_id 或 phone 列", "The header must include a user_id or phone column"},
+		"batch_unknown_column":      {"未匹配到套餐中的体检项目", "Column does not match any item in the plan"},
+		"batch_derived_column":      {"衍生项目由公式计算，不能上传", "Column is a derived item calculated by formula"},
+		"batch_invalid_user_id":     {"无效的用户ID: %s", "Invalid user ID: %s"},
+		"batch_invalid_birthday":    {"无效的出生日期: %s", "Invalid birthday: %s"},
+		"batch_duplicate_customer":  {"该客户已在第 %d 行出现", "This customer already appears on row %d"},
+		"batch_item_not_in_version": {"该项目不在客户所购的套餐版本中", "Item is not in the plan version the customer purchased"},
+		"batch_row_empty":           {"该行没有体检结果", "The row has no results"},
+		"batch_validation_failed":   {"校验未通过，共 %d 处错误，未写入任何数据", "Validation failed with %d errors; nothing was written"},
+		"batch_commit_failed":       {"批量写入失败: ", "Failed to write batch: "},
+		"batch_imported":            {"已为 %d 位客户写入 %d 项结果", "Wrote %[2]d results for %[1]d customers"},
+		"batch_already_imported":    {"该文件已导入（批次 %d），未重复写入", "This file was already imported (batch %d); nothing was written"},
+		"batch_not_found":           {"批次不存在", "Batch not found"},
+
+		// 体检报告
+		"report_package_not_completed": {"体检尚未完成，暂不能生成报告", "The checkup is not completed yet; the report is not available"},
+		"report_render_failed":         {"生成报告失败: ", "Failed to generate report: "},
+		"report_verify_not_found":      {"验证码无效，未找到对应的报告", "Invalid verification code; no matching report"},
+		"report_verified":              {"该报告由本平台出具，内容与出具时一致", "This report was issued by this platform and is unchanged"},
+		"report_verified_modified":     {"该报告由本平台出具，但出具后检查结果已被修改，请以最新报告为准", "This report was issued by this platform, but results have changed since; please refer to the latest report"},
+		"report_title":                 {"体检报告", "Checkup Report"},
+		"report_verify_code":           {"验证码", "Verification code"},
+		"report_name":                  {"姓名", "Name"},
+		"report_gender":                {"性别", "Gender"},
+		"report_gender_male":           {"男", "Male"},
+		"report_gender_female":         {"女", "Female"},
+		"report_age":                   {"年龄", "Age"},
+		"report_package_no":            {"体检编号", "Checkup No."},
+		"report_user_id":               {"用户ID", "User ID"},
+		"report_plan":                  {"套餐", "Plan"},
+		"report_checked_at":            {"体检日期", "Checkup date"},
+		"report_issued_at":             {"出具时间", "Issued at"},
+		"report_col_item":              {"项目", "Item"},
+		"report_col_value":             {"结果", "Result"},
+		"report_col_unit":              {"单位", "Unit"},
+		"report_col_reference":         {"参考范围", "Reference"},
+		"report_col_flag":              {"提示", "Flag"},
+		"report_category_other":        {"其他", "Other"},
+		"report_not_tested":            {"未检", "Not tested"},
+		"report_flag_abnormal":         {"异常", "Abnormal"},
+		"report_conclusion":            {"总检结论", "Conclusion"},
+		"report_summary":               {"本次体检共 %d 项，已出结果 %d 项，其中异常 %d 项。", "%d items in this checkup, %d with results, %d abnormal."},
+		"report_no_conclusion":         {"医生尚未填写结论，以上为系统根据参考范围生成的异常汇总。", "The doctor has not written a conclusion yet; the above is an automatic summary based on reference ranges."},
+		"report_doctor":                {"主检医师", "Physician"},
+		"report_signed_at":             {"日期", "Date"},
+		"report_recommendation":        {"健康建议", "Recommendations"},
+		"report_doctor_comment":        {"医生点评", "Doctor's comment"},
+
+		// 体检结论
+		"conclusion_forbidden":             {"只有套餐所属机构的主账号与审核医生可以填写体检结论", "Only the owner and reviewing doctors of this package's institution can write the conclusion"},
+		"conclusion_too_long":              {"内容不能超过 %d 个字符", "Content must not exceed %d characters"},
+		"conclusion_item_not_in_package":   {"项目 %d 不在该套餐中", "Item %d is not in this package"},
+		"conclusion_item_duplicate":        {"项目 %d 的点评重复", "Duplicate comment for item %d"},
+		"conclusion_save_failed":           {"保存体检结论失败: ", "Failed to save conclusion: "},
+		"conclusion_saved":                 {"体检结论草稿已保存", "Conclusion draft saved"},
+		"conclusion_package_not_completed": {"体检尚未完成，暂不能签署结论", "The checkup is not completed; the conclusion cannot be signed yet"},
+		"conclusion_not_found":             {"尚未填写体检结论", "No conclusion has been written"},
+		"conclusion_already_signed":        {"当前结论已签署，修改后才能重新签署", "The conclusion is already signed; edit it before signing again"},
+		"conclusion_incomplete":            {"签署前须填写总检结论", "A summary is required before signing"},
+		"conclusion_changed":               {"结论草稿已被修改，请刷新后重新确认再签署", "The conclusion draft has changed; reload and review it before signing"},
+		"conclusion_reason_required":       {"修订已签署的结论时须填写修订原因", "A reason is required when amending a signed conclusion"},
+		"conclusion_sign_failed":           {"签署体检结论失败: ", "Failed to sign conclusion: "},
+		"conclusion_signed":                {"体检结论已签署（第 %d 版）", "Conclusion signed (revision %d)"},
+
+		// 结果审核
+		"member_forbidden":               {"只有机构主账号可以管理成员", "Only the institution owner can manage members"},
+		"member_invalid_role":            {"角色必须为 entry 或 reviewer", "Role must be entry or reviewer"},
+		"member_is_owner":                {"机构主账号拥有全部角色，无需添加为成员", "The institution owner already has all roles"},
+		"member_not_institution_user":    {"成员必须为机构账号", "Members must be institution accounts"},
+		"member_not_found":               {"成员不存在", "Member not found"},
+		"member_saved":                   {"成员已保存", "Member saved"},
+		"member_removed":                 {"成员已移除", "Member removed"},
+		"review_forbidden":               {"只有机构审核人可以执行此操作", "Only an institution reviewer can do this"},
+		"result_entry_forbidden":         {"只有套餐所属机构的主账号或录入人员可以录入检查结果", "Only the owner or entry members of the plan's institution can enter results"},
+		"review_result_not_entered":      {"该项目尚未录入结果", "No result has been entered for this item"},
+		"review_not_critical":            {"该结果不是危急值", "This result is not a critical value"},
+		"critical_ack_note_required":     {"请填写处理说明（不超过 255 个字符）", "A note of up to 255 characters is required"},
+		"critical_acknowledged":          {"危急值已确认", "Critical value acknowledged"},
+		"review_not_draft":               {"结果已发布，没有待审核的结果", "Results are already released; nothing awaits review"},
+		"review_no_results":              {"尚未录入任何结果", "No results have been entered"},
+		"review_critical_unacknowledged": {"还有 %d 项危急值未确认", "%d critical values have not been acknowledged"},
+		"review_self_release":            {"不能发布自己录入的结果，须由其他审核人发布", "You cannot release results you entered; another reviewer must release them"},
+		"results_released":               {"结果已发布，客户现在可以查看", "Results released and now visible to the customer"},
+		"results_not_released":           {"结果正在审核，尚未发布", "Results are under review and not yet released"},
+
+		// 危急值告警与通知
+		"critical_alert_failed":            {"生成危急值告警失败: ", "Failed to raise critical value alerts: "},
+		"notification_critical_value":      {"%s 的 %s 结果为危急值，请尽快处理并确认", "Critical value recorded for %s: %s. Please act on and acknowledge it promptly"},
+		"notification_critical_escalation": {"%s 的 %s 危急值超时未确认，请立即处理", "Critical value for %s (%s) has not been acknowledged in time. Please act now"},
+		"notification_critical_patient":    {"%s 的 %s 检查结果需要尽快处理，体检机构会尽快与您联系", "A result for %s (%s) needs prompt attention. The institution will contact you shortly"},
+		"notification_not_found":           {"通知不存在", "Notification not found"},
+		"notification_marked_read":         {"通知已标记为已读", "Notification marked as read"},
+		"notifications_marked_read":        {"已将 %d 条通知标记为已读", "Marked %d notifications as read"},
+
+		// 衍生项目
+		"invalid_formula":           {"公式无效：", "Invalid formula: "},
+		"derived_item_not_editable": {"项目 %v 由公式计算，不能手工录入", "Item %v is calculated by formula and cannot be entered manually"},
+		"derived_compute_failed":    {"计算衍生项目失败：", "Failed to calculate derived items: "},
+
+		// 项目目录
+		"catalog_item_not_found":        {"项目目录中没有 %v，请从目录中选择项目或联系管理员添加", "%v is not in the item catalog; select an item from the catalog or ask an administrator to add it"},
+		"catalog_item_exists":           {"项目已在目录中", "Item is already in the catalog"},
+		"catalog_name_taken":            {"名称或编码已被其他项目使用：", "Name or code is already used by another item: "},
+		"catalog_category_not_found":    {"项目分类 %v 不存在", "Item category %v does not exist"},
+		"catalog_category_exists":       {"项目分类 %v 已存在", "Item category %v already exists"},
+		"catalog_category_saved":        {"项目分类已保存", "Item category saved"},
+		"catalog_category_in_use":       {"仍有 %v 个项目属于该分类，不能删除", "%v items still belong to this category"},
+		"catalog_category_deleted":      {"项目分类已删除", "Item category deleted"},
+		"catalog_synonyms_saved":        {"同义名称已保存", "Synonyms saved"},
+		"catalog_merge_invalid_sources": {"待合并的项目不存在或不在目录中", "Items to merge do not exist or are not in the catalog"},
+		"catalog_merge_conflict":        {"部分记录同时包含待合并的多个项目，请先处理后再合并", "Some records contain more than one of the items to merge; resolve them before merging"},
+		"catalog_merge_failed":          {"合并项目失败：", "Failed to merge items: "},
+		"catalog_items_merged":          {"已合并 %v 个项目", "%v items merged"},
+
+		// 推荐
+		"invalid_eligibility":          {"无效的适用条件: ", "Invalid eligibility: "},
+		"invalid_reference_range":      {"参考范围下限不能大于上限", "Reference low must not exceed reference high"},
+		"invalid_critical_range":       {"危急值下限不能大于上限", "Critical low must not exceed critical high"},
+		"invalid_recommendation_limit": {"limit 必须在 1 到 %d 之间", "limit must be between 1 and %d"},
+		"recommend_reason_own_risk":    {"您的%s结果异常，该套餐针对%s风险", "Your %s result was abnormal; this plan targets %s risk"},
+		"recommend_reason_family_risk": {"家人（%s）有%s相关异常，该套餐针对该风险", "Family members (%s) had abnormal results related to %s; this plan targets that risk"},
+		"recommend_reason_item_cover":  {"套餐包含%s，可复查您的%s相关异常", "Includes %s to follow up on your %s-related results"},
+		"recommend_reason_age":         {"适合您的年龄（%d 岁）", "Suitable for your age (%d)"},
+		"recommend_reason_sex_M":       {"专为男性设计", "Designed for men"},
+		"recommend_reason_sex_F":       {"专为女性设计", "Designed for women"},
+		"recommend_reason_general":     {"适合一般人群的常规体检", "General checkup suitable for most people"},
+	})
+}
@@ -2,13 +2,17 @@ package main
 
 import (
 	"HealthCare/backend/config"
+	"HealthCare/backend/controllers/utils"
 	"HealthCare/backend/routers"
-	"fmt"
 )
 
 func main() {
 	config.InitConfig()
-	fmt.Printf("Loaded config: %+v\n\n", config.AppConfig)
+	utils.EnsureSearchIndex()
+	utils.EnsurePlanVersions()
+	utils.ResumeExportJobs()
+	utils.StartDeletionWorker()
+	utils.StartCriticalAlertWorker()
 	router := routers.SetupRouter()
 	port := config.AppConfig.App.BackendPort
 	if port == "" {
@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
 	"net/http"
 
@@ -16,7 +17,7 @@ func AdminRequiredMiddleware() gin.HandlerFunc {
 		var user models.User
 		if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "无效的用户",
+				"error": i18n.T(c, "invalid_user"),
 			})
 			c.Abort()
 			return
@@ -25,7 +26,7 @@ func AdminRequiredMiddleware() gin.HandlerFunc {
 		// 检查是否为管理员 (UserType = 2)
 		if user.UserType != 2 {
 			c.JSON(http.StatusForbidden, gin.H{
-				"error": "需要管理员权限",
+				"error": i18n.T(c, "admin_required"),
 			})
 			c.Abort()
 			return
@@ -2,6 +2,9 @@ package middlewares
 
 import (
 	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
+	"HealthCare/backend/models"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,7 +16,7 @@ func AuthMiddleWare() gin.HandlerFunc {
 		token := ctx.GetHeader("Authorization")
 		if token == "" {
 			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Missing Authorization Header",
+				"error": i18n.T(ctx, "missing_auth_header"),
 			})
 			ctx.Abort()
 			return
@@ -21,13 +24,22 @@ func AuthMiddleWare() gin.HandlerFunc {
 		username, userType, err := utils.ParseJWT(token)
 		if err != nil {
 			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid Token",
+				"error": i18n.T(ctx, "invalid_token"),
 			})
 			ctx.Abort()
 			return
 		}
 		ctx.Set("username", username)
 		ctx.Set("user_type", userType)
+
+		// 请求未显式指定 ?lang= 时，优先使用用户保存的语言偏好
+		if i18n.Normalize(ctx.Query("lang")) == "" {
+			var language string
+			global.DB.Model(&models.User{}).Where("username = ?", username).Select("language").Scan(&language)
+			if i18n.Supported(language) {
+				ctx.Set(i18n.ContextKey, language)
+			}
+		}
 		ctx.Next()
 	}
 }
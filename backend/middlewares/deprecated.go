@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecatedMiddleware 为旧版路由添加 Deprecation 响应头，并通过 Link 头指向新版路由
+// aliases 为旧版相对路径到新版相对路径的映射，未登记的路由按相同相对路径处理
+func DeprecatedMiddleware(legacyPrefix, successorPrefix string, aliases map[string]string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		relative := strings.TrimPrefix(ctx.FullPath(), legacyPrefix)
+		successor := successorPrefix + relative
+		if target, ok := aliases[ctx.Request.Method+" "+relative]; ok {
+			successor = successorPrefix + target
+		}
+
+		ctx.Header("Deprecation", "true")
+		ctx.Header("Link", "<"+successor+`>; rel="successor-version"`)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"HealthCare/backend/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware 根据 ?lang= 参数或 Accept-Language 请求头确定本次请求的语言
+// 登录用户保存的语言偏好由 AuthMiddleWare 在鉴权后覆盖
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		lang := i18n.Normalize(ctx.Query("lang"))
+		if lang == "" {
+			lang = i18n.Negotiate(ctx.GetHeader("Accept-Language"))
+		}
+		ctx.Set(i18n.ContextKey, lang)
+		ctx.Next()
+	}
+}
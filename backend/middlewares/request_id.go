@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestIDHeader 请求ID的请求头与响应头
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey 请求ID在 gin.Context 中的键
+	RequestIDKey = "request_id"
+)
+
+// RequestIDMiddleware 为每个请求分配请求ID，写入上下文与响应头，用于审计日志与排查问题
+// 客户端传入的 X-Request-ID 合法时沿用，否则生成新的ID
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			buf := make([]byte, 16)
+			rand.Read(buf)
+			id = hex.EncodeToString(buf)
+		}
+		ctx.Set(RequestIDKey, id)
+		ctx.Header(RequestIDHeader, id)
+		ctx.Next()
+	}
+}
+
+// validRequestID 只接受不超过 64 个字符的字母、数字、- 和 _
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
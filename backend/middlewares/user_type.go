@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"HealthCare/backend/global"
+	"HealthCare/backend/i18n"
 	"HealthCare/backend/models"
 	"net/http"
 
@@ -16,7 +17,7 @@ func RequireUserType(allowedTypes ...uint8) gin.HandlerFunc {
 		var user models.User
 		if err := global.DB.Where("username = ?", username).First(&user).Error; err != nil {
 			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "User not found",
+				"error": i18n.T(ctx, "user_not_found"),
 			})
 			ctx.Abort()
 			return
@@ -26,7 +27,7 @@ func RequireUserType(allowedTypes ...uint8) gin.HandlerFunc {
 
 		if !allowed {
 			ctx.JSON(http.StatusForbidden, gin.H{
-				"error": "Unauthorized access: insufficient privileges",
+				"error": i18n.T(ctx, "insufficient_privileges"),
 			})
 			ctx.Abort()
 			return
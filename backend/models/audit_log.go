@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+// 审计动作
+const (
+	AuditUserPermissionUpdate = "user.permission_update"
+	AuditUserDelete           = "user.delete" // 账号匿名化
+	AuditUserDeletionRequest  = "user.deletion_request"
+	AuditUserDeletionCancel   = "user.deletion_cancel"
+	AuditInstitutionReview    = "institution.review"
+	AuditCheckupResultsWrite  = "checkup_results.write"
+	AuditPersonalItemUpdate   = "personal_health_item.update"
+	AuditConclusionSign       = "conclusion.sign" // 医生签署（含修订）体检结论
+	AuditCriticalAck          = "critical_value.acknowledge"
+	AuditResultsRelease       = "checkup_results.release"
+	AuditMemberUpdate         = "institution_member.update"
+	AuditCriticalEscalate     = "critical_value.escalate" // 危急值超时未确认，升级通知
+	AuditCatalogUpdate        = "catalog.update"          // 项目目录的项目、同义名称或分类变更
+	AuditCatalogMerge         = "catalog.merge"           // 合并重复项目
+	AuditFamilyGrant          = "family_grant.grant"      // 向亲属授予（或重新激活）授权
+	AuditFamilyRevoke         = "family_grant.revoke"
+	AuditUserProfileUpdate    = "user.profile_update" // 本人或监护人修改个人资料，取值只记录盲索引
+)
+
+// 审计日志表，只追加不修改，记录病历数据与权限的变更，与变更在同一事务中写入
+// Hash 由上一条记录的 Hash（PrevHash）与本条内容计算，构成哈希链，记录被篡改或删除都能被校验发现；
+// prev_hash 唯一，保证链不会分叉
+type AuditLog struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+	ActorID    *uint     `gorm:"index;column:actor_id" json:"actor_id"`
+	ActorName  string    `gorm:"type:varchar(50);column:actor_name" json:"actor_name"` // 操作时的用户名，用户删除后仍可追溯
+	Action     string    `gorm:"type:varchar(50);not null;index;column:action" json:"action"`
+	TargetType string    `gorm:"type:varchar(50);not null;index:idx_audit_target;column:target_type" json:"target_type"`
+	TargetID   uint      `gorm:"not null;index:idx_audit_target;column:target_id" json:"target_id"`
+	Before     string    `gorm:"type:text;column:before" json:"before"` // 变更前的字段（JSON），只包含发生变化的字段
+	After      string    `gorm:"type:text;column:after" json:"after"`   // 变更后的字段（JSON）
+	IP         string    `gorm:"type:varchar(45);column:ip" json:"ip"`
+	RequestID  string    `gorm:"type:varchar(64);index;column:request_id" json:"request_id"`
+	PrevHash   string    `gorm:"type:char(64);uniqueIndex;column:prev_hash" json:"prev_hash"`
+	Hash       string    `gorm:"type:char(64);not null;uniqueIndex;column:hash" json:"hash"`
+}
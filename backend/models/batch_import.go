@@ -0,0 +1,15 @@
+package models
+
+import "gorm.io/gorm"
+
+// BatchImport 机构批量上传（CSV/XLSX）体检结果的提交记录，只记录已写入的批次
+// 同一账号对同一套餐重复上传内容相同的文件时直接返回已有批次，不会重复写入
+type BatchImport struct {
+	gorm.Model
+	ImportedBy uint   `gorm:"not null;uniqueIndex:idx_batch_file;column:imported_by" json:"imported_by"`
+	PlanID     uint   `gorm:"not null;uniqueIndex:idx_batch_file;column:plan_id" json:"plan_id"`
+	FileHash   string `gorm:"type:char(64);not null;uniqueIndex:idx_batch_file;column:file_hash" json:"file_hash"` // 文件内容的 SHA-256
+	FileName   string `gorm:"type:varchar(255);column:file_name" json:"file_name"`
+	Rows       int    `gorm:"not null;default:0;column:row_count" json:"rows"`       // 客户行数
+	Results    int    `gorm:"not null;default:0;column:result_count" json:"results"` // 写入的结果数
+}
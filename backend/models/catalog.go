@@ -0,0 +1,24 @@
+package models
+
+import "gorm.io/gorm"
+
+// ItemCategory 体检项目分类（如 血常规、肝功能、影像），由管理员维护；
+// HealthItem.Category 只能取已定义的分类名称，SortOrder 越小越靠前
+type ItemCategory struct {
+	gorm.Model
+	Name      string `gorm:"type:varchar(50);not null;uniqueIndex;column:name" json:"name"`
+	SortOrder int    `gorm:"not null;default:0;column:sort_order" json:"sort_order"`
+}
+
+// HealthItemSynonym 项目的同义名称（如 "Blood Pressure" 之于 "血压"），
+// 机构按名称选择项目、导入结果匹配项目时与项目名称同等对待；
+// NameKey 为规范化后的名称，在全部同义名称中唯一
+type HealthItemSynonym struct {
+	gorm.Model
+	RelationHealthItemId uint   `gorm:"not null;index;column:health_item_id" json:"health_item_id"`
+	Name                 string `gorm:"type:varchar(512);not null;column:name" json:"name"`
+	NameKey              string `gorm:"type:varchar(512);not null;uniqueIndex;column:name_key" json:"-"`
+
+	// Relations
+	ThisHeathItem HealthItem `gorm:"foreignKey:RelationHealthItemId;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
@@ -1,14 +1,56 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 评论状态
+const (
+	CommentaryVisible uint8 = 0
+	CommentaryHidden  uint8 = 1 // 被管理员隐藏，不再对外展示，也不计入评分
+)
+
+// 举报处理状态
+const (
+	ReportPending   uint8 = 0
+	ReportResolved  uint8 = 1 // 评论已被隐藏
+	ReportDismissed uint8 = 2 // 评论被保留或恢复
+)
 
 type Commentary struct {
 	gorm.Model
 	RelationUserId uint   `gorm:"not null;index;column:user_id"`
 	RelationPlanId uint   `gorm:"not null;index;column:plan_id"`
 	Commentary     string `gorm:"type:varchar(255);not null;column:commentary"`
+	Rating         uint8  `gorm:"type:tinyint;not null;default:0;column:rating"` // 1-5 星，0 表示历史评论未评分
+	// 验证购买：评价对应的已完成用户套餐，每个用户套餐只能评价一次
+	UserPackageID *uint `gorm:"uniqueIndex;column:user_package_id"`
+
+	// 审核
+	Status       uint8  `gorm:"type:tinyint(1);not null;default:0;column:status"` // 0: visible, 1: hidden
+	ReportCount  uint   `gorm:"not null;default:0;column:report_count"`
+	HiddenReason string `gorm:"type:varchar(255);column:hidden_reason"`
+
+	// 机构回复
+	InstitutionReply string     `gorm:"type:varchar(500);column:institution_reply"`
+	RepliedAt        *time.Time `gorm:"column:replied_at"`
+
+	// Relations
+	ThisUser User               `gorm:"foreignKey:RelationUserId;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;"`
+	ThisPlan Plan               `gorm:"foreignKey:RelationPlanId;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;"`
+	Reports  []CommentaryReport `gorm:"foreignKey:CommentaryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"Reports,omitempty"`
+}
+
+// 评论举报，同一用户对同一评论只能举报一次
+type CommentaryReport struct {
+	gorm.Model
+	CommentaryID uint   `gorm:"not null;uniqueIndex:idx_commentary_reporter;column:commentary_id" json:"commentary_id"`
+	ReporterID   uint   `gorm:"not null;uniqueIndex:idx_commentary_reporter;column:reporter_id" json:"reporter_id"`
+	Reason       string `gorm:"type:varchar(255);not null;column:reason" json:"reason"`
+	Status       uint8  `gorm:"type:tinyint(1);not null;default:0;column:status" json:"status"` // 0: pending, 1: resolved, 2: dismissed
 
 	// Relations
-	ThisUser User `gorm:"foreignKey:RelationUserId;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;"`
-	ThisPlan Plan `gorm:"foreignKey:RelationPlanId;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;"`
+	Reporter User `gorm:"foreignKey:ReporterID;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;" json:"-"`
 }
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrConclusionRevisionImmutable 结论修订版本创建后不允许修改或删除
+var ErrConclusionRevisionImmutable = errors.New("conclusion revisions are immutable")
+
+// 体检结论状态
+const (
+	ConclusionDraft = 0 // 草稿，只有机构可见
+	ConclusionFinal = 1 // 已签署，客户可见
+)
+
+// CheckupConclusion 机构医生对一次体检（用户套餐）给出的总检结论与建议，每个用户套餐一条；
+// 保存时为草稿，签署后定稿并生成一个 ConclusionRevision；已签署的结论再次修改时回到草稿，
+// 重新签署后生成新的修订版本，客户始终看到最新签署的版本
+type CheckupConclusion struct {
+	gorm.Model
+	UserPackageID  uint       `gorm:"not null;uniqueIndex;column:user_package_id" json:"user_package_id"`
+	Status         uint8      `gorm:"type:tinyint(1);not null;default:0;column:status" json:"status"` // 0: draft, 1: final
+	Summary        string     `gorm:"type:text;column:summary" json:"summary"`                        // 总检结论
+	Recommendation string     `gorm:"type:text;column:recommendation" json:"recommendation"`          // 健康建议，如 "建议复查血脂"
+	UpdatedBy      uint       `gorm:"not null;column:updated_by" json:"updated_by"`
+	Version        uint       `gorm:"not null;default:0;column:version" json:"version"`   // 每次保存草稿加一，签署时须提交所看到的版本号
+	Revision       uint       `gorm:"not null;default:0;column:revision" json:"revision"` // 最近一次签署的修订号，0 表示从未签署
+	SignedAt       *time.Time `gorm:"column:signed_at" json:"signed_at"`
+
+	// Relations
+	Comments    []ConclusionComment `gorm:"foreignKey:ConclusionID" json:"comments"`
+	UserPackage UserPackage         `gorm:"foreignKey:UserPackageID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+// ConclusionComment 结论草稿中对单个体检项目的点评
+type ConclusionComment struct {
+	gorm.Model
+	ConclusionID uint   `gorm:"not null;uniqueIndex:idx_conclusion_item;column:conclusion_id" json:"conclusion_id"`
+	HealthItemID uint   `gorm:"not null;uniqueIndex:idx_conclusion_item;column:health_item_id" json:"health_item_id"`
+	Comment      string `gorm:"type:text;not null;column:comment" json:"comment"`
+
+	// Relations
+	ThisConclusion CheckupConclusion `gorm:"foreignKey:ConclusionID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+// ItemComment 修订版本中保存的项目点评
+type ItemComment struct {
+	HealthItemID uint   `json:"health_item_id"`
+	Comment      string `json:"comment"`
+}
+
+// ConclusionRevision 每次签署生成的结论快照，只追加不修改；SignedBy 为签署医生的账号，DoctorName 取自该账号的姓名，
+// Reason 为修订原因（首次签署为空），ContentHash 为签署内容的摘要，用于核对签署后内容未被改动
+type ConclusionRevision struct {
+	gorm.Model
+	ConclusionID   uint          `gorm:"not null;uniqueIndex:idx_conclusion_revision;column:conclusion_id" json:"conclusion_id"`
+	UserPackageID  uint          `gorm:"not null;index;column:user_package_id" json:"user_package_id"`
+	Revision       uint          `gorm:"not null;uniqueIndex:idx_conclusion_revision;column:revision" json:"revision"`
+	Summary        string        `gorm:"type:text;column:summary" json:"summary"`
+	Recommendation string        `gorm:"type:text;column:recommendation" json:"recommendation"`
+	Comments       []ItemComment `gorm:"type:text;serializer:json;column:comments" json:"comments"`
+	DoctorName     string        `gorm:"type:varchar(50);not null;column:doctor_name" json:"doctor_name"`
+	SignedBy       uint          `gorm:"not null;column:signed_by" json:"signed_by"`
+	SignedAt       time.Time     `gorm:"not null;column:signed_at" json:"signed_at"`
+	Reason         string        `gorm:"type:varchar(255);column:reason" json:"reason"`
+	ContentHash    string        `gorm:"type:char(64);not null;column:content_hash" json:"content_hash"`
+
+	// Relations
+	ThisConclusion CheckupConclusion `gorm:"foreignKey:ConclusionID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+func (ConclusionRevision) BeforeUpdate(*gorm.DB) error { return ErrConclusionRevisionImmutable }
+func (ConclusionRevision) BeforeDelete(*gorm.DB) error { return ErrConclusionRevisionImmutable }
@@ -0,0 +1,17 @@
+package models
+
+import "gorm.io/gorm"
+
+// CriticalAck 审核人对危急值的确认（如已通知客户或主治医生），结果发布前每个危急值都须确认；
+// ValueIndex 为确认时结果的盲索引，结果修改后原确认不再有效
+type CriticalAck struct {
+	gorm.Model
+	UserPackageID  uint   `gorm:"not null;index:idx_critical_ack;column:user_package_id" json:"user_package_id"`
+	HealthItemID   uint   `gorm:"not null;index:idx_critical_ack;column:health_item_id" json:"health_item_id"`
+	ValueIndex     string `gorm:"type:char(64);not null;column:value_bidx" json:"-"`
+	Note           string `gorm:"type:varchar(255);column:note" json:"note"`
+	AcknowledgedBy uint   `gorm:"not null;column:acknowledged_by" json:"acknowledged_by"`
+
+	// Relations
+	UserPackage UserPackage `gorm:"foreignKey:UserPackageID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 危急值告警状态
+const (
+	AlertOpen         uint8 = 0
+	AlertAcknowledged uint8 = 1
+	AlertSuperseded   uint8 = 2 // 结果已修改，告警不再适用
+)
+
+// 危急值告警的升级级别，超时未确认时逐级升级
+const (
+	EscalationOnCall      uint8 = 0 // 已通知值班人员（未设置值班时通知机构主账号）
+	EscalationInstitution uint8 = 1 // 已通知机构主账号与全部审核人
+	EscalationAdmin       uint8 = 2 // 已通知平台管理员
+)
+
+// CriticalAlert 录入危急值时生成的告警，审核人确认（CriticalAck）后关闭；
+// 同一结果只告警一次，ValueIndex 为结果的盲索引
+type CriticalAlert struct {
+	gorm.Model
+	UserPackageID  uint       `gorm:"not null;index:idx_critical_alert;column:user_package_id" json:"user_package_id"`
+	HealthItemID   uint       `gorm:"not null;index:idx_critical_alert;column:health_item_id" json:"health_item_id"`
+	InstitutionID  uint       `gorm:"not null;index;column:institution_id" json:"institution_id"`
+	ValueIndex     string     `gorm:"type:char(64);not null;column:value_bidx" json:"-"`
+	EnteredBy      *uint      `gorm:"column:entered_by" json:"entered_by"`
+	Status         uint8      `gorm:"type:tinyint(1);not null;default:0;index:idx_critical_alert_due;column:status" json:"status"` // 0: open, 1: acknowledged, 2: superseded
+	Level          uint8      `gorm:"type:tinyint(1);not null;default:0;column:level" json:"level"`                                // 升级级别
+	NotifiedAt     time.Time  `gorm:"not null;index:idx_critical_alert_due;column:notified_at" json:"notified_at"`                 // 最近一次发出通知的时间
+	AckID          *uint      `gorm:"column:ack_id" json:"ack_id"`
+	AcknowledgedBy *uint      `gorm:"column:acknowledged_by" json:"acknowledged_by"`
+	AcknowledgedAt *time.Time `gorm:"column:acknowledged_at" json:"acknowledged_at"`
+
+	// Relations
+	UserPackage UserPackage  `gorm:"foreignKey:UserPackageID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Ack         *CriticalAck `gorm:"foreignKey:AckID;references:ID" json:"acknowledgment,omitempty"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 账号注销申请状态
+const (
+	DeletionPending   uint8 = 0
+	DeletionCompleted uint8 = 1
+	DeletionCancelled uint8 = 2
+)
+
+// DeletionRequest 账号注销申请，冷静期（ScheduledAt 之前）内可撤销，到期后由后台任务匿名化账号
+// 体检记录依法保留，以假名化形式关联到匿名化后的账号
+type DeletionRequest struct {
+	gorm.Model
+	RelationUserId uint       `gorm:"not null;index;column:user_id" json:"user_id"`
+	RequestedBy    uint       `gorm:"not null;column:requested_by" json:"requested_by"`                     // 发起人，本人或管理员
+	Status         uint8      `gorm:"type:tinyint(1);not null;default:0;index;column:status" json:"status"` // 0: pending, 1: completed, 2: cancelled
+	Reason         string     `gorm:"type:varchar(255);column:reason" json:"reason"`
+	ScheduledAt    time.Time  `gorm:"not null;index;column:scheduled_at" json:"scheduled_at"`
+	CompletedAt    *time.Time `gorm:"column:completed_at" json:"completed_at"`
+}
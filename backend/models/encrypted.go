@@ -0,0 +1,63 @@
+package models
+
+import (
+	"HealthCare/backend/security"
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+func init() {
+	schema.RegisterSerializer("encrypted", EncryptedSerializer{})
+}
+
+// EncryptedSerializer 字段标注 serializer:encrypted 后写入时自动加密、读取时自动解密
+// 注意按 map 或原生 SQL 更新时不会经过序列化器，需先调用 SealFields
+type EncryptedSerializer struct{}
+
+// Scan 读取时解密，加密上线前写入的明文原样返回
+func (EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		value = string(v)
+	case string:
+		value = v
+	case time.Time:
+		value = v.Format("2006-01-02")
+	default:
+		return fmt.Errorf("unsupported value %T for encrypted field %s", dbValue, field.Name)
+	}
+	plaintext, err := security.Decrypt(value)
+	if err != nil {
+		return fmt.Errorf("decrypt %s: %w", field.Name, err)
+	}
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+// Value 写入时加密
+func (EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, _ := fieldValue.(string)
+	return security.Encrypt(value)
+}
+
+// SealFields 按 map 更新时加密其中的敏感字段，fields 为需要加密的列名
+func SealFields(values map[string]interface{}, fields ...string) error {
+	for _, field := range fields {
+		value, ok := values[field].(string)
+		if !ok {
+			continue
+		}
+		sealed, err := security.Encrypt(value)
+		if err != nil {
+			return err
+		}
+		values[field] = sealed
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 数据导出任务状态
+const (
+	ExportPending uint8 = 0
+	ExportRunning uint8 = 1
+	ExportReady   uint8 = 2
+	ExportFailed  uint8 = 3
+	ExportExpired uint8 = 4 // 超过保留期限，文件已删除
+)
+
+// ExportJob 个人数据导出任务，后台生成包含 JSON 数据、可读报告与上传文件的 zip 归档
+type ExportJob struct {
+	gorm.Model
+	RelationUserId uint       `gorm:"not null;index;column:user_id" json:"user_id"`
+	Status         uint8      `gorm:"type:tinyint(1);not null;default:0;index;column:status" json:"status"` // 0: pending, 1: running, 2: ready, 3: failed, 4: expired
+	Language       string     `gorm:"type:varchar(10);column:language" json:"language"`                     // 可读报告使用的语言
+	Path           string     `gorm:"type:varchar(512);column:path" json:"-"`                               // 相对 storage.dir 的路径
+	Size           int64      `gorm:"not null;default:0;column:size" json:"size"`
+	Error          string     `gorm:"type:varchar(255);column:error" json:"error,omitempty"`
+	FinishedAt     *time.Time `gorm:"column:finished_at" json:"finished_at"`
+	ExpiresAt      *time.Time `gorm:"index;column:expires_at" json:"expires_at"`
+
+	// Relations
+	ThisUser User `gorm:"foreignKey:RelationUserId;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;" json:"-"`
+}
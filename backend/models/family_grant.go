@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 家庭授权范围
+const (
+	ScopeViewRecords   = "view_records"  // 查看体检记录与结果
+	ScopeBookPackages  = "book_packages" // 代为选择（预约）体检套餐
+	ScopeNotifications = "notifications" // 接收数据所有者的通知
+)
+
+// FamilyScopes 全部可授予的授权范围
+var FamilyScopes = []string{ScopeViewRecords, ScopeBookPackages, ScopeNotifications}
+
+// 家庭授权表，由数据所有者（OwnerID）授予已确认关系的亲属（GranteeID），每个授权范围一条记录
+// 撤销或到期后失效；亲属关系删除时级联删除
+type FamilyGrant struct {
+	gorm.Model
+	FamilyID  uint       `gorm:"not null;index;column:family_id" json:"family_id"`
+	OwnerID   uint       `gorm:"not null;uniqueIndex:idx_grant_owner_grantee_scope;column:owner_id" json:"owner_id"`
+	GranteeID uint       `gorm:"not null;uniqueIndex:idx_grant_owner_grantee_scope;index;column:grantee_id" json:"grantee_id"`
+	Scope     string     `gorm:"type:varchar(32);not null;uniqueIndex:idx_grant_owner_grantee_scope;column:scope" json:"scope"`
+	ExpiresAt *time.Time `gorm:"column:expires_at" json:"expires_at"` // 为空表示长期有效
+	RevokedAt *time.Time `gorm:"column:revoked_at" json:"revoked_at"`
+
+	// Relations
+	Family  Family `gorm:"foreignKey:FamilyID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Owner   User   `gorm:"foreignKey:OwnerID;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;" json:"-"`
+	Grantee User   `gorm:"foreignKey:GranteeID;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;" json:"-"`
+}
+
+// Active 授权在 now 时刻是否有效
+func (g FamilyGrant) Active(now time.Time) bool {
+	return g.RevokedAt == nil && (g.ExpiresAt == nil || g.ExpiresAt.After(now))
+}
+
+// 亲属通过授权访问数据的记录，数据所有者可查看
+type FamilyAccessLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	OwnerID   uint      `gorm:"not null;index;column:owner_id" json:"owner_id"`
+	ActorID   uint      `gorm:"not null;index;column:actor_id" json:"actor_id"`
+	Scope     string    `gorm:"type:varchar(32);not null;column:scope" json:"scope"`
+	Action    string    `gorm:"type:varchar(255);not null;column:action" json:"action"` // 请求方法与路径
+	IP        string    `gorm:"type:varchar(45);column:ip" json:"ip"`
+
+	// Relations
+	Actor User `gorm:"foreignKey:ActorID;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;" json:"-"`
+}
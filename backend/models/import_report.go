@@ -0,0 +1,43 @@
+package models
+
+import "gorm.io/gorm"
+
+// 检验结果导入状态
+const (
+	ImportSuccess uint8 = 0 // 全部结果已写入
+	ImportPartial uint8 = 1 // 部分结果被拒绝
+	ImportFailed  uint8 = 2 // 没有写入任何结果
+)
+
+// 导入的消息格式
+const (
+	ImportFormatHL7v2 = "hl7v2"
+	ImportFormatFHIR  = "fhir"
+)
+
+// ImportLine 导入报告中每条检验结果的处理情况
+type ImportLine struct {
+	Ref          string `json:"ref"`            // 在消息中的位置，如 OBX-3 或 Observation/xx
+	Code         string `json:"code,omitempty"` // 消息中的编码（system|code）
+	Text         string `json:"text,omitempty"`
+	HealthItemID uint   `json:"health_item_id,omitempty"`
+	Value        string `json:"value,omitempty"`
+	Outcome      string `json:"outcome"` // accepted, rejected, skipped
+	Message      string `json:"message,omitempty"`
+}
+
+// ImportReport 外部系统（HL7 v2 ORU^R01、FHIR Bundle）检验结果导入的逐条消息报告
+// MessageID 为 MSH-10 或 Bundle 标识，同一导入账号重复发送已成功的消息会被拒绝
+type ImportReport struct {
+	gorm.Model
+	ImportedBy    uint         `gorm:"not null;index;column:imported_by" json:"imported_by"`
+	Format        string       `gorm:"type:varchar(10);not null;column:format" json:"format"`
+	MessageID     string       `gorm:"type:varchar(100);index;column:message_id" json:"message_id"`
+	UserID        *uint        `gorm:"index;column:user_id" json:"user_id"`
+	UserPackageID *uint        `gorm:"index;column:user_package_id" json:"user_package_id"`
+	Status        uint8        `gorm:"type:tinyint(1);not null;default:0;index;column:status" json:"status"` // 0: success, 1: partial, 2: failed
+	Accepted      int          `gorm:"not null;default:0;column:accepted" json:"accepted"`
+	Rejected      int          `gorm:"not null;default:0;column:rejected" json:"rejected"`
+	Error         string       `gorm:"type:varchar(255);column:error" json:"error,omitempty"`
+	Lines         []ImportLine `gorm:"type:text;serializer:json;column:lines" json:"lines"`
+}
@@ -13,6 +13,10 @@ type Institution struct {
 	UserID                   uint   `json:"user_id" gorm:"not null;index;column:user_id"`
 	Status                   uint8  `json:"status" gorm:"type:tinyint(1);not null;default:0;column:status"` // 0: pending, 1: approved, 2: rejected
 
+	// 评分汇总，由旗下套餐可见评论的星级计算
+	RatingAvg   float64 `json:"rating_avg" gorm:"type:decimal(3,2);default:0;column:rating_avg"`
+	RatingCount uint    `json:"rating_count" gorm:"default:0;column:rating_count"`
+
 	// Relations
 	User User `json:"user" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;"`
 }
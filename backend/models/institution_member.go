@@ -0,0 +1,24 @@
+package models
+
+import "gorm.io/gorm"
+
+// 机构成员角色
+const (
+	MemberRoleEntry    = "entry"    // 录入检查结果
+	MemberRoleReviewer = "reviewer" // 审核并向客户发布检查结果
+)
+
+// InstitutionMember 机构的工作人员账号，由机构主账号（Institution.UserID）添加；
+// 主账号本身拥有全部角色，不需要添加为成员
+type InstitutionMember struct {
+	gorm.Model
+	InstitutionID uint   `gorm:"not null;uniqueIndex:idx_institution_member;column:institution_id" json:"institution_id"`
+	UserID        uint   `gorm:"not null;uniqueIndex:idx_institution_member;index;column:user_id" json:"user_id"`
+	Role          string `gorm:"type:varchar(20);not null;column:role" json:"role"`
+	OnCall        bool   `gorm:"not null;default:false;column:on_call" json:"on_call"` // 值班，第一时间接收危急值通知
+	AddedBy       uint   `gorm:"not null;column:added_by" json:"added_by"`
+
+	// Relations
+	Institution Institution `gorm:"foreignKey:InstitutionID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	User        User        `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;" json:"-"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "gorm.io/gorm"
+
+// LoincMapping 体检项目到 LOINC 编码的映射，由管理员维护，用于 FHIR 导出
+// Unit 为 UCUM 单位编码（如 mmol/L），为空时使用项目的单位
+type LoincMapping struct {
+	gorm.Model
+	RelationHealthItemId uint   `gorm:"not null;uniqueIndex;column:health_item_id" json:"health_item_id"`
+	Code                 string `gorm:"type:varchar(20);not null;index;column:code" json:"code"`
+	Display              string `gorm:"type:varchar(255);column:display" json:"display"`
+	Unit                 string `gorm:"type:varchar(30);column:unit" json:"unit"`
+
+	// Relations
+	ThisHeathItem HealthItem `gorm:"foreignKey:RelationHealthItemId;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 通知类型，消息文本在读取时按读者语言生成
+const (
+	NotifyCriticalValue      = "critical_value"      // 机构值班人员：录入了危急值
+	NotifyCriticalEscalation = "critical_escalation" // 危急值超时未确认，升级通知机构主账号、审核人及管理员
+	NotifyCriticalPatient    = "critical_patient"    // 客户本人及接收通知的家人：有需要尽快处理的结果
+)
+
+// Notification 站内通知，涉及的客户由 UserPackageID 确定，不保存客户姓名，读取时按套餐查找
+type Notification struct {
+	gorm.Model
+	UserID          uint       `gorm:"not null;index:idx_notification_user;column:user_id" json:"user_id"`
+	Kind            string     `gorm:"type:varchar(40);not null;column:kind" json:"kind"`
+	UserPackageID   *uint      `gorm:"column:user_package_id" json:"user_package_id"`
+	HealthItemID    *uint      `gorm:"column:health_item_id" json:"health_item_id"`
+	CriticalAlertID *uint      `gorm:"index;column:critical_alert_id" json:"critical_alert_id"`
+	ReadAt          *time.Time `gorm:"index:idx_notification_user;column:read_at" json:"read_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
@@ -10,17 +10,59 @@ type Plan struct {
 	PlanPrice             float64 `gorm:"type:decimal(10,2);default:0;column:plan_price" json:"plan_price"`
 	Description           string  `gorm:"type:text;column:description" json:"description"`
 	SuitableFor           string  `gorm:"type:varchar(255);column:suitable_for" json:"suitable_for"`
+	CurrentVersion        uint    `gorm:"default:0;column:current_version" json:"current_version"` // 当前版本号，见 PlanVersion
+
+	// 适用条件，用于个性化推荐；为空表示不限
+	MinAge      *uint  `gorm:"column:min_age" json:"min_age"`
+	MaxAge      *uint  `gorm:"column:max_age" json:"max_age"`
+	Sex         string `gorm:"type:char(1);default:'';column:sex" json:"sex"`                        // M, F
+	RiskFactors string `gorm:"type:varchar(255);default:'';column:risk_factors" json:"risk_factors"` // 逗号分隔的风险因素代码，见 config risk-factors
+
+	// 评分汇总，由可见评论的星级计算
+	RatingAvg   float64 `gorm:"type:decimal(3,2);default:0;column:rating_avg" json:"rating_avg"`
+	RatingCount uint    `gorm:"default:0;column:rating_count" json:"rating_count"`
 
 	// Relations
 	ThisInstitution Institution `gorm:"foreignKey:RelationInstitutionID;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;" json:"-"`
 }
 
 // 体检套餐指标表，存储具体套餐的指标信息，item_id，item_name(主键),只保存信息，便于添加新的指标内容
+// UserID 不为 0 的行是旧版的个人健康指标，不属于项目目录（见 utils.CatalogItems）
 type HealthItem struct {
 	gorm.Model
 	ItemName       string `gorm:"type:varchar(512);index;column:item_name"`
 	UserID         uint   `gorm:"index;column:user_id"`
 	UserHealthInfo string `gorm:"type:varchar(512);index;column:user_health_info"`
+
+	// 参考范围，数值型结果超出范围视为异常
+	Unit          string   `gorm:"type:varchar(20);column:unit" json:"unit"`
+	ReferenceLow  *float64 `gorm:"column:reference_low" json:"reference_low"`
+	ReferenceHigh *float64 `gorm:"column:reference_high" json:"reference_high"`
+	// 危急值界限，结果超出时发布前须由审核人确认
+	CriticalLow  *float64 `gorm:"column:critical_low" json:"critical_low"`
+	CriticalHigh *float64 `gorm:"column:critical_high" json:"critical_high"`
+
+	// 项目分类（如 血常规、肝功能），报告中按分类分组展示，为空归入 "其他"
+	Category string `gorm:"type:varchar(50);default:'';index;column:category" json:"category"`
+
+	// 标准编码（机构内部或行业编码，如 WBC），在目录中唯一，为空表示未编码
+	Code string `gorm:"type:varchar(50);default:'';index;column:code" json:"code"`
+	// 合并重复项目后被合并项目指向保留的项目，引用已迁移，仅用于解析历史记录中的旧ID
+	MergedIntoID *uint `gorm:"index;column:merged_into_id" json:"merged_into_id"`
+
+	// 衍生指标的计算公式（语法见 formula 包），不为空时结果由引用的项目自动计算，不能手工录入
+	Formula string `gorm:"type:varchar(1024);default:'';column:formula" json:"formula"`
+}
+
+// 健康项目译名表，ItemName 为默认(中文)名称，其余语言的显示名称存于此表
+type HealthItemTranslation struct {
+	gorm.Model
+	RelationHealthItemId uint   `gorm:"not null;uniqueIndex:idx_item_language;column:health_item_id" json:"health_item_id"`
+	Language             string `gorm:"type:varchar(10);not null;uniqueIndex:idx_item_language;column:language" json:"language"`
+	ItemName             string `gorm:"type:varchar(512);not null;column:item_name" json:"item_name"`
+
+	// Relations
+	ThisHeathItem HealthItem `gorm:"foreignKey:RelationHealthItemId;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
 }
 
 // plan-item对应表, 展示套餐信息
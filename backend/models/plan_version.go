@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrPlanVersionImmutable 套餐版本创建后不允许修改或删除
+var ErrPlanVersionImmutable = errors.New("plan versions are immutable")
+
+// 套餐版本表，套餐内容（名称、价格、描述、适用人群、项目）每次变更都生成一个新版本
+// 用户购买时在 UserPackage 中记录所购版本，历史记录按该版本展示
+type PlanVersion struct {
+	gorm.Model
+	RelationPlanId uint    `gorm:"not null;uniqueIndex:idx_plan_version;column:plan_id" json:"plan_id"`
+	Version        uint    `gorm:"not null;uniqueIndex:idx_plan_version;column:version" json:"version"`
+	PlanName       string  `gorm:"type:varchar(100);not null;column:plan_name" json:"plan_name"`
+	PlanPrice      float64 `gorm:"type:decimal(10,2);default:0;column:plan_price" json:"plan_price"`
+	Description    string  `gorm:"type:text;column:description" json:"description"`
+	SuitableFor    string  `gorm:"type:varchar(255);column:suitable_for" json:"suitable_for"`
+	ContentHash    string  `gorm:"type:char(64);not null;column:content_hash" json:"-"` // 内容摘要，内容未变化时不生成新版本
+
+	// Relations
+	Items    []PlanVersionItem `gorm:"foreignKey:RelationPlanVersionId" json:"items,omitempty"`
+	ThisPlan Plan              `gorm:"foreignKey:RelationPlanId;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+// 套餐版本包含的项目，保存生成版本时的项目名称与描述
+type PlanVersionItem struct {
+	gorm.Model
+	RelationPlanVersionId uint   `gorm:"not null;index;column:plan_version_id" json:"plan_version_id"`
+	RelationHealthItemId  uint   `gorm:"not null;index;column:health_item_id" json:"health_item_id"`
+	ItemName              string `gorm:"type:varchar(512);not null;column:item_name" json:"item_name"`
+	ItemDescription       string `gorm:"type:varchar(100);column:item_description" json:"item_description"`
+	ItemMetrics           string `gorm:"type:text;column:item_metrics" json:"item_metrics"`
+
+	// Relations
+	ThisPlanVersion PlanVersion `gorm:"foreignKey:RelationPlanVersionId;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+func (PlanVersion) BeforeUpdate(*gorm.DB) error     { return ErrPlanVersionImmutable }
+func (PlanVersion) BeforeDelete(*gorm.DB) error     { return ErrPlanVersionImmutable }
+func (PlanVersionItem) BeforeUpdate(*gorm.DB) error { return ErrPlanVersionImmutable }
+func (PlanVersionItem) BeforeDelete(*gorm.DB) error { return ErrPlanVersionImmutable }
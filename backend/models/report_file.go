@@ -0,0 +1,16 @@
+package models
+
+import "gorm.io/gorm"
+
+// ReportFile 用户上传的体检报告文件，文件保存在 storage.dir 下，Path 为相对路径
+type ReportFile struct {
+	gorm.Model
+	RelationUserId uint   `gorm:"not null;index;column:user_id" json:"user_id"`
+	FileName       string `gorm:"type:varchar(255);not null;column:file_name" json:"file_name"`
+	ContentType    string `gorm:"type:varchar(100);column:content_type" json:"content_type"`
+	Size           int64  `gorm:"not null;default:0;column:size" json:"size"`
+	Path           string `gorm:"type:varchar(512);not null;column:path" json:"-"`
+
+	// Relations
+	ThisUser User `gorm:"foreignKey:RelationUserId;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;" json:"-"`
+}
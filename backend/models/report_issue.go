@@ -0,0 +1,23 @@
+package models
+
+import "gorm.io/gorm"
+
+// 报告摘要的计算方式
+const (
+	ReportHashByItem   = 1 // 按健康项目ID索引结果，项目合并后ID改变，仅用于验证早期出具的报告
+	ReportHashByResult = 2 // 按检查结果记录ID索引，不受项目合并影响
+)
+
+// ReportIssue 每次生成 PDF 体检报告时记录一条，报告上的二维码携带 Code 用于真伪验证；
+// ContentHash 为生成时检查结果的摘要，验证时按 HashVersion 与当前结果比较以发现报告出具后结果被修改
+type ReportIssue struct {
+	gorm.Model
+	UserPackageID uint   `gorm:"not null;index;column:user_package_id" json:"user_package_id"`
+	Code          string `gorm:"type:char(32);not null;uniqueIndex;column:code" json:"code"`
+	ContentHash   string `gorm:"type:char(64);not null;column:content_hash" json:"-"`
+	HashVersion   uint8  `gorm:"not null;default:1;column:hash_version" json:"-"`
+	IssuedBy      uint   `gorm:"not null;index;column:issued_by" json:"issued_by"`
+
+	// Relations
+	UserPackage UserPackage `gorm:"foreignKey:UserPackageID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
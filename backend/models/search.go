@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// 搜索类型
+const (
+	SearchEntityPlan        = "plan"
+	SearchEntityInstitution = "institution"
+)
+
+// 全文搜索索引表，每个套餐、机构对应一条记录，由 utils.Reindex* 在数据变更后维护
+// Title/Keywords/Content 使用 MySQL ngram 分词的 FULLTEXT 索引，支持中文检索
+type SearchDocument struct {
+	ID            uint     `gorm:"primarykey" json:"id"`
+	EntityType    string   `gorm:"type:varchar(20);not null;uniqueIndex:idx_search_entity;column:entity_type" json:"entity_type"`
+	EntityID      uint     `gorm:"not null;uniqueIndex:idx_search_entity;column:entity_id" json:"entity_id"`
+	InstitutionID uint     `gorm:"not null;index;column:institution_id" json:"institution_id"`
+	Price         *float64 `gorm:"type:decimal(10,2);index;column:price" json:"price"` // 仅套餐有价格
+	// 套餐名或机构名
+	Title string `gorm:"type:varchar(255);not null;column:title;index:idx_search_title,class:FULLTEXT,option:WITH PARSER ngram;index:idx_search_all,class:FULLTEXT,option:WITH PARSER ngram,priority:1" json:"title"`
+	// 套餐内健康项目名称（含各语言译名），以空格分隔
+	Keywords string `gorm:"type:text;column:keywords;index:idx_search_keywords,class:FULLTEXT,option:WITH PARSER ngram;index:idx_search_all,class:FULLTEXT,option:WITH PARSER ngram,priority:2" json:"keywords"`
+	// 套餐描述、适用人群、机构名称与地址
+	Content   string    `gorm:"type:text;column:content;index:idx_search_content,class:FULLTEXT,option:WITH PARSER ngram;index:idx_search_all,class:FULLTEXT,option:WITH PARSER ngram,priority:3" json:"content"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
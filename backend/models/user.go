@@ -1,6 +1,9 @@
 package models
 
 import (
+	"HealthCare/backend/security"
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -10,11 +13,31 @@ type User struct {
 	Password string `gorm:"type:varchar(255);not null;column:password"`
 	Name     string `gorm:"type:varchar(50);not null;column:name"`
 	Gender   string `gorm:"type:char;not null;column:gender"`
-	Birthday string `gorm:"type:date;column:birthday"`
-	Phone    string `gorm:"type:varchar(11);not null;column:phone"`
+	// 生日、手机号、地址加密保存
+	Birthday string `gorm:"type:varchar(255);serializer:encrypted;column:birthday"`
+	Phone    string `gorm:"type:varchar(255);not null;serializer:encrypted;column:phone"`
 	Email    string `gorm:"type:varchar(100);column:email"`
-	Address  string `gorm:"type:varchar(255);column:address"`
+	Address  string `gorm:"type:text;serializer:encrypted;column:address"`
 	UserType uint8  `gorm:"type:tinyint(1);not null;default:1;column:user_type" json:"user_type"` // 1: normal, 2: admin, 3: institution
+	Language string `gorm:"type:varchar(10);column:language" json:"language"`                     // zh-CN, en; 为空时按 Accept-Language
+	// 监护人用户ID，不为空表示由监护人托管的被监护人档案（儿童、老人等），不能登录，认领后清空
+	GuardianID *uint `gorm:"index;column:guardian_id" json:"guardian_id"`
+	// 手机号盲索引，用于按手机号查找用户
+	PhoneIndex string `gorm:"type:char(64);index;column:phone_bidx" json:"-"`
+}
+
+// BeforeSave 按结构体保存时同步手机号盲索引，按 map 更新时需调用 SealUserFields
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	u.PhoneIndex = security.BlindIndex("phone", u.Phone)
+	return nil
+}
+
+// SealUserFields 按 map 更新用户时加密敏感字段，并同步手机号盲索引
+func SealUserFields(values map[string]interface{}) error {
+	if phone, ok := values["phone"].(string); ok {
+		values["phone_bidx"] = security.BlindIndex("phone", phone)
+	}
+	return SealFields(values, "birthday", "phone", "address")
 }
 
 type RolePermission struct {
@@ -25,3 +48,15 @@ type RolePermission struct {
 	// Relations
 	ThisUser User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;"`
 }
+
+// 被监护人认领码，监护人生成后交给被监护人，用于将托管档案转换为可登录的账号
+// 每个被监护人同时只有一个有效认领码，只保存认领码的摘要
+type DependentClaim struct {
+	gorm.Model
+	UserID    uint      `gorm:"not null;uniqueIndex;column:user_id" json:"user_id"`
+	CodeHash  string    `gorm:"type:char(64);not null;uniqueIndex;column:code_hash" json:"-"`
+	ExpiresAt time.Time `gorm:"not null;column:expires_at" json:"expires_at"`
+
+	// Relations
+	ThisUser User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
@@ -1,9 +1,17 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// 用户套餐的结果审核状态
+const (
+	ReviewDraft    = 0 // 结果尚未发布，只有机构可见
+	ReviewReleased = 1 // 已由审核人发布，客户可见
+)
+
 // UserPackage represents a package selected by a user
 type UserPackage struct {
 	gorm.Model
@@ -11,9 +19,18 @@ type UserPackage struct {
 	InstitutionID uint  `json:"institution_id" gorm:"not null;index;column:institution_id"`
 	PlanID        uint  `json:"plan_id" gorm:"not null;index;column:plan_id"`
 	Status        uint8 `json:"status" gorm:"type:tinyint(1);not null;default:0;column:status"` // 0: pending, 1: completed
+	// 购买时的套餐版本，历史记录按此版本展示
+	PlanVersionID *uint `json:"plan_version_id" gorm:"index;column:plan_version_id"`
+	// 预约的体检时间，为空表示尚未预约
+	AppointmentAt *time.Time `json:"appointment_at" gorm:"index;column:appointment_at"`
+	// 结果审核：录入或修改结果后回到草稿，由机构审核人（非录入人）发布后客户才能看到
+	ReviewStatus uint8      `json:"review_status" gorm:"type:tinyint(1);not null;default:0;index;column:review_status"` // 0: draft, 1: released
+	ReleasedBy   *uint      `json:"released_by" gorm:"column:released_by"`
+	ReleasedAt   *time.Time `json:"released_at" gorm:"column:released_at"`
 
 	// Relations
-	User        User        `json:"user" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;"`
-	Institution Institution `json:"institution" gorm:"foreignKey:InstitutionID;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;"`
-	Plan        Plan        `json:"plan" gorm:"foreignKey:PlanID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	User        User         `json:"user" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;"`
+	Institution Institution  `json:"institution" gorm:"foreignKey:InstitutionID;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;"`
+	Plan        Plan         `json:"plan" gorm:"foreignKey:PlanID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PlanVersion *PlanVersion `json:"plan_version,omitempty" gorm:"foreignKey:PlanVersionID;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:SET NULL;"`
 }
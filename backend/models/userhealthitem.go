@@ -16,7 +16,11 @@ type UserHealthItem struct {
 	RelationUserId       uint   `gorm:"not null;index;column:user_id"`
 	RelationPlanId       uint   `gorm:"not null;index;column:plan_id"`
 	RelationHealthItemId uint   `gorm:"not null;index;column:health_item_id"`
-	ItemValue            string `gorm:"type:varchar(100);not null;index;column:item_value"`
+	ItemValue            string `gorm:"type:varchar(1024);not null;serializer:encrypted;column:item_value"`
+	PlanVersionID        *uint  `gorm:"index;column:plan_version_id"`                 // 录入时对应的套餐版本
+	EnteredBy            *uint  `gorm:"index;column:entered_by"`                      // 最近一次录入或修改的账号
+	ReviewPending        bool   `gorm:"not null;default:false;column:review_pending"` // 上次发布后新录入或修改，待审核
+	Derived              bool   `gorm:"not null;default:false;column:derived"`        // 由项目公式根据其他结果自动计算
 
 	// Relations
 	ThisUser      User       `gorm:"foreignKey:RelationUserId;references:ID;constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;"`
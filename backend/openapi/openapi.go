@@ -0,0 +1,272 @@
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Document OpenAPI 3 文档根对象（仅包含本项目用到的字段）
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Paths      map[string]*PathItem  `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// PathItem 同一路径下各 HTTP 方法的操作
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Param 查询参数说明
+type Param struct {
+	Name        string
+	Type        string // string, integer, number, boolean
+	Required    bool
+	Description string
+}
+
+// Operation 路由的文档说明，Request/Response 为示例类型的零值，用于反射生成 schema
+type Operation struct {
+	Tag      string
+	Summary  string
+	Public   bool // 无需 Authorization 头
+	Query    []Param
+	Request  interface{}
+	Response interface{}
+	// Upload 为 true 时请求体为 multipart/form-data，Request 描述表单字段
+	Upload bool
+	// Produces 非 JSON 响应的 MIME 类型，如 application/pdf
+	Produces string
+	// Consumes 请求体可接受的非 JSON MIME 类型，如 HL7 v2 文本，请求体按字符串描述
+	Consumes []string
+}
+
+var pathParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// Build 根据 gin 已注册的路由生成 OpenAPI 文档
+// docs 的键为 "METHOD 相对路径"（相对于 prefix），如 "POST /auth/login"
+// 不在 prefix 下的路由视为旧版别名，标记为 deprecated，并复用其对应新版路由的说明
+func Build(routes gin.RoutesInfo, prefix string, info Info, docs map[string]Operation, aliases map[string]string) *Document {
+	gen := newGenerator()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas: gen.schemas,
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+		Security: []map[string][]string{{"bearerAuth": {}}},
+	}
+
+	tags := make(map[string]bool)
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, "/api") {
+			continue
+		}
+
+		relative, deprecated := strings.TrimPrefix(route.Path, prefix), false
+		if !strings.HasPrefix(route.Path, prefix+"/") {
+			relative, deprecated = strings.TrimPrefix(route.Path, "/api"), true
+			if target, ok := aliases[route.Method+" "+relative]; ok {
+				relative = target
+			}
+		}
+
+		key := route.Method + " " + relative
+		op, ok := docs[key]
+		if !ok {
+			op = Operation{Tag: "misc"}
+		}
+
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+		item, exists := doc.Paths[path]
+		if !exists {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+
+		operation := &OperationObject{
+			Tags:        []string{op.Tag},
+			Summary:     op.Summary,
+			OperationID: operationID(route.Method, route.Path),
+			Deprecated:  deprecated,
+			Responses: map[string]*Response{
+				"default": {
+					Description: "错误",
+					Content:     jsonContent(gen.schemaOf(ErrorResponse{})),
+				},
+			},
+		}
+		if op.Public {
+			operation.Security = []map[string][]string{}
+		}
+
+		for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+			operation.Parameters = append(operation.Parameters, Parameter{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+		for _, q := range op.Query {
+			typ := q.Type
+			if typ == "" {
+				typ = "string"
+			}
+			operation.Parameters = append(operation.Parameters, Parameter{
+				Name:        q.Name,
+				In:          "query",
+				Required:    q.Required,
+				Description: q.Description,
+				Schema:      &Schema{Type: typ},
+			})
+		}
+
+		if op.Request != nil {
+			contentType := "application/json"
+			if op.Upload {
+				contentType = "multipart/form-data"
+			}
+			operation.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]*MediaType{contentType: {Schema: gen.schemaOf(op.Request)}},
+			}
+		}
+		if len(op.Consumes) > 0 {
+			content := make(map[string]*MediaType, len(op.Consumes))
+			for _, contentType := range op.Consumes {
+				content[contentType] = &MediaType{Schema: &Schema{Type: "string"}}
+			}
+			operation.RequestBody = &RequestBody{Required: true, Content: content}
+		}
+
+		success := &Response{Description: "成功"}
+		switch {
+		case op.Produces != "":
+			success.Content = map[string]*MediaType{op.Produces: {Schema: &Schema{Type: "string", Format: "binary"}}}
+		case op.Response != nil:
+			success.Content = jsonContent(gen.schemaOf(op.Response))
+		}
+		operation.Responses["200"] = success
+
+		(*item)[strings.ToLower(route.Method)] = operation
+		tags[op.Tag] = true
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	return doc
+}
+
+// ErrorResponse 所有接口失败时的响应体
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// MessageResponse 只返回提示信息的响应体
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
+
+func operationID(method, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, seg := range strings.Split(path, "/") {
+		seg = strings.Trim(seg, ":*")
+		seg = strings.NewReplacer("-", "_", ".", "_").Replace(seg)
+		if seg != "" && seg != "api" {
+			parts = append(parts, seg)
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// Handler 返回输出 OpenAPI 文档的处理函数，文档在首次请求时根据 engine 的路由生成
+func Handler(engine *gin.Engine, prefix string, info Info, docs map[string]Operation, aliases map[string]string) gin.HandlerFunc {
+	var (
+		once sync.Once
+		doc  *Document
+	)
+	return func(ctx *gin.Context) {
+		once.Do(func() {
+			doc = Build(engine.Routes(), prefix, info, docs, aliases)
+		})
+		ctx.JSON(http.StatusOK, doc)
+	}
+}
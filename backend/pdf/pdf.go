@@ -0,0 +1,199 @@
+// Package pdf 生成简单的 A4 PDF 文档：文本、矩形与线条
+// 文本使用 Adobe 预置的 CJK 字体 STSong-Light（UniGB-UTF16-H 编码），不嵌入字体文件，
+// 阅读器按系统的中文字体显示；坐标以页面左上角为原点，单位为点（1/72 英寸）
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// A4 页面尺寸
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Color RGB 颜色，分量取值 0-1
+type Color struct{ R, G, B float64 }
+
+var (
+	Black = Color{0, 0, 0}
+	White = Color{1, 1, 1}
+)
+
+// Document 一个 PDF 文档
+type Document struct {
+	Title   string
+	Author  string
+	Created time.Time
+	pages   []*Page
+}
+
+// Page 一页，绘图指令写入内容流
+type Page struct {
+	content bytes.Buffer
+}
+
+// New 创建空文档
+func New() *Document {
+	return &Document{Created: time.Now()}
+}
+
+// AddPage 追加一页并返回
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Pages 已添加的页面
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// TextWidth 估算文本宽度：ASCII 字符按半角，其余按全角
+func TextWidth(text string, size float64) float64 {
+	w := 0.0
+	for _, r := range text {
+		if r < 0x80 {
+			w += 0.5
+		} else {
+			w++
+		}
+	}
+	return w * size
+}
+
+// Wrap 按宽度折行，优先在空格处断开，中文按字断开
+func Wrap(text string, width, size float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := []rune{}
+		lastSpace := -1
+		for _, r := range paragraph {
+			line = append(line, r)
+			if r == ' ' {
+				lastSpace = len(line) - 1
+			}
+			if TextWidth(string(line), size) <= width || len(line) == 1 {
+				continue
+			}
+			cut := len(line) - 1
+			if lastSpace > 0 {
+				cut = lastSpace + 1
+			}
+			lines = append(lines, strings.TrimRight(string(line[:cut]), " "))
+			line = append([]rune{}, line[cut:]...)
+			lastSpace = -1
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// Text 在 (x, y) 处绘制文本，y 为基线位置
+func (p *Page) Text(x, y, size float64, color Color, text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(&p.content, "BT %s rg /F1 %s Tf %s %s Td <%s> Tj ET\n",
+		color.ops(), num(size), num(x), num(PageHeight-y), encodeText(text))
+}
+
+// Rect 填充矩形，(x, y) 为左上角
+func (p *Page) Rect(x, y, w, h float64, color Color) {
+	fmt.Fprintf(&p.content, "%s rg %s %s %s %s re f\n", color.ops(), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// StrokeRect 绘制矩形边框
+func (p *Page) StrokeRect(x, y, w, h, lineWidth float64, color Color) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s %s %s re S\n", color.ops(), num(lineWidth), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// Line 绘制直线
+func (p *Page) Line(x1, y1, x2, y2, lineWidth float64, color Color) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s m %s %s l S\n", color.ops(), num(lineWidth), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+func (c Color) ops() string {
+	return num(c.R) + " " + num(c.G) + " " + num(c.B)
+}
+
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-" {
+		return "0"
+	}
+	return s
+}
+
+// encodeText 将文本编码为 UTF-16BE 十六进制串
+func encodeText(text string) string {
+	var b strings.Builder
+	for _, u := range utf16.Encode([]rune(text)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	return b.String()
+}
+
+// WriteTo 输出 PDF 文件
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) int {
+		offsets = append(offsets, buf.Len())
+		id := len(offsets)
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", id, body)
+		return id
+	}
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 对象编号：1 目录，2 页面树，3-5 字体，6 文档信息，之后每页为页面与内容流
+	pageIDs := make([]string, len(d.pages))
+	for i := range d.pages {
+		pageIDs[i] = fmt.Sprintf("%d 0 R", 7+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pageIDs, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UTF16-H /DescendantFonts [4 0 R] >>")
+	object("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> " +
+		"/FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>")
+	object("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] " +
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	object(fmt.Sprintf("<< /Title <FEFF%s> /Author <FEFF%s> /Producer (HealthCare) /CreationDate (D:%s) >>",
+		encodeText(d.Title), encodeText(d.Author), d.Created.UTC().Format("20060102150405Z")))
+
+	for i, p := range d.pages {
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		if _, err := zw.Write(p.content.Bytes()); err != nil {
+			return 0, err
+		}
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", num(PageWidth), num(PageHeight), 8+2*i))
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", len(offsets), z.Len())
+		buf.Write(z.Bytes())
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 6 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
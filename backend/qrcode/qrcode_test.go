@@ -0,0 +1,254 @@
+package qrcode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		version int
+	}{
+		{"empty", "", 1},
+		{"version 1 full", strings.Repeat("a", 14), 1},
+		{"version 2", strings.Repeat("a", 15), 2},
+		{"verify URL", "https://health.example.com/api/v1/reports/verify/0123456789abcdef0123456789abcdef", 5},
+		{"version 7 with version information", strings.Repeat("b", 120), 7},
+		{"version 10 with 16-bit count", strings.Repeat("c", 200), 10},
+		{"version 10 full", strings.Repeat("d", 213), 10},
+		{"UTF-8", "体检报告验证", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Encode(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code.Version != tt.version || code.Size != 17+4*tt.version || len(code.Modules) != code.Size {
+				t.Fatalf("got version %d size %d, want version %d", code.Version, code.Size, tt.version)
+			}
+			checkFinders(t, code)
+			if got := decode(t, code); got != tt.data {
+				t.Fatalf("decoded %q, want %q", got, tt.data)
+			}
+		})
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(strings.Repeat("x", 214)); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("got error %v, want %v", err, ErrTooLong)
+	}
+}
+
+func checkFinders(t *testing.T, code *Code) {
+	t.Helper()
+	for _, corner := range [][2]int{{0, 0}, {code.Size - 7, 0}, {0, code.Size - 7}} {
+		for dy := 0; dy < 7; dy++ {
+			for dx := 0; dx < 7; dx++ {
+				d := max(abs(dx-3), abs(dy-3))
+				if want := d != 2; code.Modules[corner[1]+dy][corner[0]+dx] != want {
+					t.Fatalf("finder pattern at %v is damaged", corner)
+				}
+			}
+		}
+	}
+	for i := 8; i < code.Size-8; i++ {
+		if code.Modules[6][i] != (i%2 == 0) || code.Modules[i][6] != (i%2 == 0) {
+			t.Fatalf("timing pattern is damaged at %d", i)
+		}
+	}
+}
+
+// decode 按标准读出 QR 码内容：读取并校验格式信息，去掉掩码，按之字形读出码字，
+// 拆分交错的分块并以校验子确认每块纠错码正确，最后解析字节模式数据
+func decode(t *testing.T, code *Code) string {
+	t.Helper()
+	at := func(x, y int) int {
+		if code.Modules[y][x] {
+			return 1
+		}
+		return 0
+	}
+
+	// 两份格式信息须一致，且为纠错等级 M 的有效 BCH 码
+	var first, second int
+	for i := 0; i <= 5; i++ {
+		first |= at(8, i) << i
+	}
+	first |= at(8, 7)<<6 | at(8, 8)<<7 | at(7, 8)<<8
+	for i := 9; i < 15; i++ {
+		first |= at(14-i, 8) << i
+	}
+	for i := 0; i < 8; i++ {
+		second |= at(code.Size-1-i, 8) << i
+	}
+	for i := 8; i < 15; i++ {
+		second |= at(8, code.Size-15+i) << i
+	}
+	if first != second {
+		t.Fatalf("format information copies differ: %015b %015b", first, second)
+	}
+	format := first ^ 0x5412
+	rem := format
+	for i := 14; i >= 10; i-- {
+		if rem>>i&1 == 1 {
+			rem ^= 0x537 << (i - 10)
+		}
+	}
+	if rem != 0 || format>>13 != 0 {
+		t.Fatalf("invalid format information %015b", format)
+	}
+	mask := format >> 10 & 7
+
+	// 版本 7 起两份版本信息须一致，且为有效的 BCH 码
+	if code.Version >= 7 {
+		var info, transposed int
+		for i := 0; i < 18; i++ {
+			info |= at(code.Size-11+i%3, i/3) << i
+			transposed |= at(i/3, code.Size-11+i%3) << i
+		}
+		rem := info
+		for i := 17; i >= 12; i-- {
+			if rem>>i&1 == 1 {
+				rem ^= 0x1F25 << (i - 12)
+			}
+		}
+		if info != transposed || rem != 0 || info>>12 != code.Version {
+			t.Fatalf("invalid version information %018b", info)
+		}
+	}
+
+	function := newCode(code.Version).function
+	masked := func(x, y int) bool {
+		switch mask {
+		case 0:
+			return (y+x)%2 == 0
+		case 1:
+			return y%2 == 0
+		case 2:
+			return x%3 == 0
+		case 3:
+			return (y+x)%3 == 0
+		case 4:
+			return (y/2+x/3)%2 == 0
+		case 5:
+			return y*x%2+y*x%3 == 0
+		case 6:
+			return (y*x%2+y*x%3)%2 == 0
+		}
+		return ((y+x)%2+y*x%3)%2 == 0
+	}
+
+	var bits []byte
+	for right := code.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < code.Size; vert++ {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = code.Size - 1 - vert
+			}
+			for x := right; x > right-2; x-- {
+				if function[y][x] {
+					continue
+				}
+				bit := code.Modules[y][x] != masked(x, y)
+				if bit {
+					bits = append(bits, 1)
+				} else {
+					bits = append(bits, 0)
+				}
+			}
+		}
+	}
+
+	b := blocksM[code.Version]
+	blocks := make([][]byte, b.g1+b.g2)
+	dataLen := func(i int) int {
+		if i >= b.g1 {
+			return b.d1 + 1
+		}
+		return b.d1
+	}
+	next := func() byte {
+		var v byte
+		for i := 0; i < 8; i++ {
+			v = v<<1 | bits[i]
+		}
+		bits = bits[8:]
+		return v
+	}
+	for col := 0; col <= b.d1; col++ {
+		for i := range blocks {
+			if col < dataLen(i) {
+				blocks[i] = append(blocks[i], next())
+			}
+		}
+	}
+	for col := 0; col < b.ec; col++ {
+		for i := range blocks {
+			blocks[i] = append(blocks[i], next())
+		}
+	}
+
+	var data []byte
+	for i, block := range blocks {
+		// 生成多项式的根为 α^0 ... α^(ec-1)，正确的码字在这些点上的取值为 0
+		for root := 0; root < b.ec; root++ {
+			var s byte
+			for _, c := range block {
+				s = gfMul(s, expTable[root]) ^ c
+			}
+			if s != 0 {
+				t.Fatalf("block %d: syndrome %d is %d", i, root, s)
+			}
+		}
+		data = append(data, block[:dataLen(i)]...)
+	}
+
+	var stream bitBuffer
+	for _, d := range data {
+		stream.append(int(d), 8)
+	}
+	read := func(n int) int {
+		v := 0
+		for i := 0; i < n; i++ {
+			v = v<<1 | int(stream[i])
+		}
+		stream = stream[n:]
+		return v
+	}
+	if m := read(4); m != 0b0100 {
+		t.Fatalf("got mode %04b, want byte mode", m)
+	}
+	countBits := 8
+	if code.Version >= 10 {
+		countBits = 16
+	}
+	out := make([]byte, read(countBits))
+	for i := range out {
+		out[i] = byte(read(8))
+	}
+	return string(out)
+}
+
+func TestGaloisField(t *testing.T) {
+	// 本原多项式 x^8 + x^4 + x^3 + x^2 + 1
+	if expTable[8] != 0x1d || expTable[255] != 1 {
+		t.Fatalf("got α^8 = %#x, α^255 = %d", expTable[8], expTable[255])
+	}
+	for a := 1; a < 256; a++ {
+		if gfMul(byte(a), 1) != byte(a) || gfMul(byte(a), 0) != 0 {
+			t.Fatalf("multiplying %d by 1 or 0 is wrong", a)
+		}
+		inverse := expTable[255-int(logTable[a])]
+		if gfMul(byte(a), inverse) != 1 {
+			t.Fatalf("%d has no inverse", a)
+		}
+	}
+}
@@ -7,8 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupAddUserDataRouter(r *gin.Engine) {
-	adduserdata := r.Group("/api/adduserdata")
+func SetupAddUserDataRouter(r *gin.RouterGroup) {
+	adduserdata := r.Group("/adduserdata")
 	adduserdata.Use(middlewares.AuthMiddleWare())
 	{
 		// 机构用户或者管理员为用户添加体检数据
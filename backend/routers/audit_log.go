@@ -0,0 +1,18 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAuditLogRouter 审计日志查询与校验，仅管理员可用
+func SetupAuditLogRouter(r *gin.RouterGroup) {
+	audit := r.Group("/audit-logs")
+	audit.Use(middlewares.AuthMiddleWare(), middlewares.RequireUserType(2))
+	{
+		audit.GET("", controllers.GetAuditLogs)
+		audit.GET("/verify", controllers.VerifyAuditLogs)
+	}
+}
@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupBatchImportRouter 机构批量上传（CSV/XLSX）体检结果，仅机构与管理员可用
+func SetupBatchImportRouter(r *gin.RouterGroup) {
+	batches := r.Group("/imports/batches")
+	batches.Use(middlewares.AuthMiddleWare(), middlewares.RequireUserType(3, 2))
+	{
+		batches.POST("", controllers.UploadBatchResults)
+		batches.GET("", controllers.GetBatchImports)
+		batches.GET("/template", controllers.GetBatchTemplate)
+		batches.GET("/:id", controllers.GetBatchImport)
+	}
+}
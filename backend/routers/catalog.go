@@ -0,0 +1,35 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCatalogRouter 设置项目目录（分类、同义名称、重复项目合并）相关路由，项目查询见 /healthitems
+func SetupCatalogRouter(r *gin.RouterGroup) {
+	catalog := r.Group("/catalog")
+	catalog.Use(middlewares.AuthMiddleWare())
+	{
+		// 查看项目分类
+		catalog.GET("/categories", controllers.GetItemCategories)
+
+		// 管理员维护项目分类
+		catalog.POST("/categories", middlewares.RequireUserType(2), controllers.CreateItemCategory)
+		catalog.PUT("/categories/:id", middlewares.RequireUserType(2), controllers.UpdateItemCategory)
+		catalog.DELETE("/categories/:id", middlewares.RequireUserType(2), controllers.DeleteItemCategory)
+
+		// 管理员向目录添加项目
+		catalog.POST("/items", middlewares.RequireUserType(2), controllers.CreateCatalogItem)
+
+		// 管理员设置项目的同义名称
+		catalog.PUT("/items/:id/synonyms", middlewares.RequireUserType(2), controllers.SetItemSynonyms)
+
+		// 管理员将重复项目合并到该项目
+		catalog.POST("/items/:id/merge", middlewares.RequireUserType(2), controllers.MergeCatalogItems)
+
+		// 管理员查看疑似重复的项目
+		catalog.GET("/duplicates", middlewares.RequireUserType(2), controllers.GetDuplicateItems)
+	}
+}
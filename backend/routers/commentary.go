@@ -7,8 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupCommentaryRouter(routers *gin.Engine) {
-	commentary := routers.Group("/api/commentary")
+func SetupCommentaryRouter(routers *gin.RouterGroup) {
+	commentary := routers.Group("/commentary")
 	commentary.Use(middlewares.AuthMiddleWare())
 	{
 		// oy
@@ -21,6 +21,22 @@ func SetupCommentaryRouter(routers *gin.Engine) {
 		commentary.GET("/get/plan/:id", controllers.GetCommentaryByPlanID)
 		// 查看评论(用户id)
 		commentary.GET("/get/user", controllers.GetCommentaryByUserID)
+	}
+}
+
+// SetupCommentaryModerationRouter 评论回复、举报与审核，仅在 /api/v1 下提供
+func SetupCommentaryModerationRouter(routers *gin.RouterGroup) {
+	commentary := routers.Group("/commentary")
+	commentary.Use(middlewares.AuthMiddleWare())
+	{
+		// 机构回复评论
+		commentary.POST("/:id/reply", controllers.ReplyCommentary)
+		// 举报评论
+		commentary.POST("/:id/report", controllers.ReportCommentary)
 
+		// 审核（仅限管理员）
+		commentary.GET("/moderation", middlewares.AdminRequiredMiddleware(), controllers.GetModerationQueue)
+		commentary.POST("/:id/hide", middlewares.AdminRequiredMiddleware(), controllers.HideCommentary)
+		commentary.POST("/:id/restore", middlewares.AdminRequiredMiddleware(), controllers.RestoreCommentary)
 	}
 }
@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupConclusionRouter 体检结论：机构医生填写草稿并签署，客户查看已签署的结论及修订历史
+func SetupConclusionRouter(r *gin.RouterGroup) {
+	conclusion := r.Group("/user-packages/:id/conclusion")
+	conclusion.Use(middlewares.AuthMiddleWare())
+	{
+		conclusion.GET("", controllers.GetConclusion)
+		conclusion.GET("/revisions", controllers.GetConclusionRevisions)
+		conclusion.PUT("", middlewares.RequireUserType(3, 2), controllers.SaveConclusion)
+		conclusion.POST("/sign", middlewares.RequireUserType(3, 2), controllers.SignConclusion)
+	}
+}
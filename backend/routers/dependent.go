@@ -0,0 +1,26 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupDependentRouter 设置被监护人档案相关路由
+func SetupDependentRouter(r *gin.RouterGroup) {
+	dependents := r.Group("/dependents")
+	dependents.Use(middlewares.AuthMiddleWare())
+	{
+		// 创建与查看被监护人档案
+		dependents.POST("", controllers.CreateDependent)
+		dependents.GET("", controllers.GetDependents)
+		// 修改被监护人档案
+		dependents.PATCH("/:id", controllers.UpdateDependent)
+		// 生成认领码
+		dependents.POST("/:id/claim-code", controllers.CreateDependentClaimCode)
+	}
+
+	// 被监护人使用认领码认领账号
+	r.POST("/auth/claim", controllers.ClaimDependentAccount)
+}
@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupExportRouter 个人数据导出，只能导出和下载本人的数据
+func SetupExportRouter(r *gin.RouterGroup) {
+	exports := r.Group("/exports")
+	exports.Use(middlewares.AuthMiddleWare())
+	{
+		exports.POST("", controllers.CreateExport)
+		exports.GET("", controllers.GetExports)
+		exports.GET("/:id", controllers.GetExport)
+		exports.GET("/:id/download", controllers.DownloadExport)
+	}
+}
@@ -7,8 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupFamilyRouter(r *gin.Engine) {
-	family := r.Group("/api/family")
+func SetupFamilyRouter(r *gin.RouterGroup) {
+	family := r.Group("/family")
 	family.Use(middlewares.AuthMiddleWare())
 	{
 		family.POST("/request/:id", controllers.CreatFamily)
@@ -17,6 +17,24 @@ func SetupFamilyRouter(r *gin.Engine) {
 		family.GET("/confirmed/:id", controllers.GetConfirmedFamilyMembers) // 新增获取已确认家庭关系的路由
 		family.GET("/del_confirmed/:id", controllers.DelFamilyStatus)       // oy
 		family.POST("/update_family_name", controllers.UpdateFamilyName)    //oy
+	}
+}
+
+// SetupFamilyAccessRouter 亲属关系推导、家庭看板与授权，仅在 /api/v1 下提供
+func SetupFamilyAccessRouter(r *gin.RouterGroup) {
+	family := r.Group("/family")
+	family.Use(middlewares.AuthMiddleWare())
+	{
+		family.GET("/relations", controllers.GetRelations)
+		family.GET("/derived/:id", controllers.GetDerivedFamilyMembers)
+		// 家庭健康看板
+		family.GET("/dashboard", controllers.GetFamilyDashboard)
 
+		// 授权：数据所有者向亲属授予查看记录、代为预约、接收通知等权限
+		family.POST("/grants", controllers.GrantFamilyAccess)
+		family.GET("/grants", controllers.GetFamilyGrants)
+		family.DELETE("/grants/:id", controllers.RevokeFamilyGrant)
+		// 亲属通过授权访问我的数据的记录
+		family.GET("/access-logs", controllers.GetFamilyAccessLogs)
 	}
 }
@@ -0,0 +1,26 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupFHIRRouter FHIR R4 导出及体检项目的 LOINC 映射维护
+func SetupFHIRRouter(r *gin.RouterGroup) {
+	fhir := r.Group("/fhir")
+	fhir.Use(middlewares.AuthMiddleWare())
+	{
+		// 以 FHIR Bundle 导出用户套餐的检查结果
+		fhir.GET("/user-packages/:id", controllers.GetUserPackageFHIR)
+	}
+
+	loinc := r.Group("/loinc-mappings")
+	loinc.Use(middlewares.AuthMiddleWare())
+	{
+		loinc.GET("", controllers.GetLoincMappings)
+		loinc.PUT("/:item_id", middlewares.RequireUserType(2), controllers.SaveLoincMapping)
+		loinc.DELETE("/:item_id", middlewares.RequireUserType(2), controllers.DeleteLoincMapping)
+	}
+}
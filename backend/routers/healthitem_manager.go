@@ -8,8 +8,8 @@ import (
 )
 
 // SetupHealthItemManagerRouter 设置健康项目管理相关路由
-func SetupHealthItemManagerRouter(r *gin.Engine) {
-	healthItemManager := r.Group("api/healthitem-manager")
+func SetupHealthItemManagerRouter(r *gin.RouterGroup) {
+	healthItemManager := r.Group("/healthitem-manager")
 	healthItemManager.Use(middlewares.AuthMiddleWare())
 	{
 		// 创建健康项目模板
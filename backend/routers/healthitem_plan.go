@@ -8,8 +8,8 @@ import (
 )
 
 // SetupHealthItemPlanRouter 设置健康项目与套餐关联的路由
-func SetupHealthItemPlanRouter(r *gin.Engine) {
-	healthItemPlan := r.Group("api/healthitem-plan")
+func SetupHealthItemPlanRouter(r *gin.RouterGroup) {
+	healthItemPlan := r.Group("/healthitem-plan")
 	healthItemPlan.Use(middlewares.AuthMiddleWare())
 	{
 		// 获取套餐关联的健康项目
@@ -8,8 +8,8 @@ import (
 )
 
 // SetupHealthItemsRouter 设置健康检查项目相关路由
-func SetupHealthItemsRouter(r *gin.Engine) {
-	healthItems := r.Group("api/healthitems")
+func SetupHealthItemsRouter(r *gin.RouterGroup) {
+	healthItems := r.Group("/healthitems")
 	healthItems.Use(middlewares.AuthMiddleWare())
 	{
 		// 获取所有健康检查项目
@@ -22,8 +22,14 @@ func SetupHealthItemsRouter(r *gin.Engine) {
 		// 获取指定ID的健康检查项目
 		healthItems.GET("/:id", controllers.GetHealthItemByID)
 
-		// 更新健康检查项目
-		healthItems.PATCH("/:id", controllers.UpdateHealthItem)
+		// 管理员更新项目目录中的健康检查项目
+		healthItems.PATCH("/:id", middlewares.RequireUserType(2), controllers.UpdateHealthItem)
+
+		// 获取健康检查项目的各语言译名
+		healthItems.GET("/:id/translations", controllers.GetHealthItemTranslations)
+
+		// 设置健康检查项目的译名
+		healthItems.PUT("/:id/translations", middlewares.RequireUserType(3, 2), controllers.SetHealthItemTranslation)
 
 		// 更新套餐中项目的描述
 		healthItems.PATCH("/plan-item", controllers.UpdatePlanItemDescription)
@@ -7,8 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupImageOcrRouter(routers *gin.Engine) {
-	imageocr := routers.Group("/api/imageocr")
+func SetupImageOcrRouter(routers *gin.RouterGroup) {
+	imageocr := routers.Group("/imageocr")
 	imageocr.Use(middlewares.AuthMiddleWare())
 	{
 		imageocr.POST("/solve", controllers.ImageOcr)
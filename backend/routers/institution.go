@@ -7,8 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupInstitutionRouter(r *gin.Engine) {
-	institution := r.Group("/api/institutions")
+func SetupInstitutionRouter(r *gin.RouterGroup) {
+	institution := r.Group("/institutions")
 	institution.Use(middlewares.AuthMiddleWare())
 	{
 		// Only institution users can create institution info
@@ -42,3 +42,14 @@ func SetupInstitutionRouter(r *gin.Engine) {
 		institution.DELETE("/:id", middlewares.RequireUserType(3, 2), controllers.DeleteInstitution)
 	}
 }
+
+// SetupInstitutionMemberRouter 机构成员：录入人与审核人，仅在 /api/v1 下提供
+func SetupInstitutionMemberRouter(r *gin.RouterGroup) {
+	institution := r.Group("/institutions")
+	institution.Use(middlewares.AuthMiddleWare(), middlewares.RequireUserType(3, 2))
+	{
+		institution.GET("/:id/members", controllers.GetInstitutionMembers)
+		institution.POST("/:id/members", controllers.SetInstitutionMember)
+		institution.DELETE("/:id/members/:user_id", controllers.DeleteInstitutionMember)
+	}
+}
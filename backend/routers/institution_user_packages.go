@@ -8,9 +8,9 @@ import (
 )
 
 // SetupInstitutionUserPackagesRouter 设置机构用户套餐相关路由
-func SetupInstitutionUserPackagesRouter(r *gin.Engine) {
+func SetupInstitutionUserPackagesRouter(r *gin.RouterGroup) {
 	// 获取机构下的用户套餐列表
-	institutionUserPackages := r.Group("/api/institution/user-packages")
+	institutionUserPackages := r.Group("/institution/user-packages")
 	institutionUserPackages.Use(middlewares.AuthMiddleWare())
 	{
 		institutionUserPackages.GET("", controllers.GetUserPackagesByInstitution)
@@ -0,0 +1,26 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// legacyAliases 仅旧版 /api 下存在的路由及其在 /api/v1 下的替代路由
+var legacyAliases = map[string]string{
+	"POST /users/packages":    "/user-packages",
+	"GET /users/:id/packages": "/user-packages/:id",
+}
+
+// SetupLegacyRouter 注册旧版 /api 下与其他路由重复的接口，新版中已统一到 /user-packages
+func SetupLegacyRouter(r *gin.RouterGroup) {
+	user := r.Group("/users")
+	user.Use(middlewares.AuthMiddleWare())
+	{
+		// 用户选择套餐，同 POST /user-packages
+		user.POST("/packages", controllers.SelectPackage)
+		// 用户查看已经选择的套餐，同 GET /user-packages/:id
+		user.GET("/:id/packages", controllers.GetUserPackages)
+	}
+}
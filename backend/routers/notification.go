@@ -0,0 +1,19 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupNotificationRouter 站内通知：危急值告警等
+func SetupNotificationRouter(r *gin.RouterGroup) {
+	notification := r.Group("/notifications")
+	notification.Use(middlewares.AuthMiddleWare())
+	{
+		notification.GET("", controllers.GetNotifications)
+		notification.POST("/read-all", controllers.MarkAllNotificationsRead)
+		notification.POST("/:id/read", controllers.MarkNotificationRead)
+	}
+}
@@ -0,0 +1,1025 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/controllers/utils"
+	"HealthCare/backend/fhir"
+	"HealthCare/backend/models"
+	"HealthCare/backend/openapi"
+	"time"
+)
+
+// 以下类型仅用于生成 OpenAPI 文档，字段与对应处理函数的请求/响应保持一致
+
+// pageQuery 分页列表接口的通用查询参数，extra 为各接口的筛选条件
+func pageQuery(sortFields string, extra ...openapi.Param) []openapi.Param {
+	return append([]openapi.Param{
+		{Name: "limit", Type: "integer", Description: "每页数量，默认 20，最大 100"},
+		{Name: "offset", Type: "integer"},
+		{Name: "cursor", Type: "integer", Description: "上一页返回的 next_cursor，仅按 id 排序时可用"},
+		{Name: "sort", Description: "排序字段（" + sortFields + "），前缀 - 表示降序"},
+	}, extra...)
+}
+
+var dateRangeQuery = []openapi.Param{
+	{Name: "date_from", Description: "YYYY-MM-DD 或 RFC3339"},
+	{Name: "date_to", Description: "YYYY-MM-DD 或 RFC3339"},
+}
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type tokenResponse struct {
+	Token    string `json:"token"`
+	UID      uint   `json:"uid"`
+	UserType uint8  `json:"user_type"`
+}
+
+type userProfile struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Gender   string `json:"gender"`
+	Birthday string `json:"birthday"`
+	Address  string `json:"address"`
+	UserType uint8  `json:"user_type"`
+	Language string `json:"language"`
+}
+
+type userProfileResponse struct {
+	Data userProfile `json:"data"`
+}
+
+type updateProfileRequest struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Gender   string `json:"gender"`
+	Birthday string `json:"birthday"`
+	Phone    string `json:"phone"`
+	Email    string `json:"email"`
+	Address  string `json:"address"`
+}
+
+type resetPasswordRequest struct {
+	PrevPassword       string `json:"prev_password"`
+	NewPassword        string `json:"new_password"`
+	NewPasswordConfirm string `json:"new_password_confirm"`
+}
+
+type languageRequest struct {
+	Language string `json:"language"`
+}
+
+type userTypeRequest struct {
+	UserType uint8 `json:"user_type"`
+}
+
+type personalHealthItemRequest struct {
+	UserID         uint   `json:"user_id"`
+	UserHealthInfo string `json:"user_health_info"`
+}
+
+type updatePersonalHealthItemRequest struct {
+	ID             uint   `json:"id"`
+	UserHealthInfo string `json:"user_health_info"`
+}
+
+type familyRequest struct {
+	RelativeUsername string `json:"relative_username"`
+	Relationship     string `json:"relationship"`
+}
+
+type handleFamilyRequest struct {
+	Accept bool `json:"accept"`
+}
+
+type renameFamilyRequest struct {
+	ID           uint   `json:"id"`
+	Relationship string `json:"relationship"`
+}
+
+type familyMember struct {
+	ID           uint   `json:"id"`
+	RID          uint   `json:"rid"`
+	UserID       uint   `json:"user_id"`
+	Username     string `json:"username"`
+	Name         string `json:"name"`
+	Relationship string `json:"relationship"`
+}
+
+type relationOption struct {
+	Name    string `json:"name"`
+	Label   string `json:"label"`
+	Gender  string `json:"gender"`
+	Inverse struct {
+		Male   string `json:"male"`
+		Female string `json:"female"`
+	} `json:"inverse"`
+}
+
+type relationsResponse struct {
+	Relations []relationOption `json:"relations"`
+}
+
+type derivedFamilyMember struct {
+	UserID            uint   `json:"user_id"`
+	Username          string `json:"username"`
+	Name              string `json:"name"`
+	Relationship      string `json:"relationship"`
+	RelationshipLabel string `json:"relationship_label"`
+	ViaUserID         uint   `json:"via_user_id"`
+	ViaName           string `json:"via_name"`
+	ViaRelationship   string `json:"via_relationship"`
+}
+
+type dashboardPackage struct {
+	ID              uint       `json:"id"`
+	PlanID          uint       `json:"plan_id"`
+	PlanName        string     `json:"plan_name"`
+	InstitutionName string     `json:"institution_name"`
+	AppointmentAt   *time.Time `json:"appointment_at"`
+}
+
+type dashboardMember struct {
+	UserID               uint               `json:"user_id"`
+	Name                 string             `json:"name"`
+	Relationship         string             `json:"relationship"`
+	Degree               int                `json:"degree"`
+	Consented            bool               `json:"consented"`
+	LatestCheckupAt      *time.Time         `json:"latest_checkup_at"`
+	PendingPackages      []dashboardPackage `json:"pending_packages"`
+	UpcomingAppointments []dashboardPackage `json:"upcoming_appointments"`
+	Abnormal             []struct {
+		ItemID   uint      `json:"item_id"`
+		ItemName string    `json:"item_name"`
+		Value    string    `json:"value"`
+		Date     time.Time `json:"date"`
+	} `json:"abnormal"`
+}
+
+type familyDashboardResponse struct {
+	Members       []dashboardMember `json:"members"`
+	FamilyHistory []struct {
+		Code         string   `json:"code"`
+		Name         string   `json:"name"`
+		Relatives    []string `json:"relatives"`
+		FirstDegree  int      `json:"first_degree"`
+		SecondDegree int      `json:"second_degree"`
+		Level        string   `json:"level"`
+	} `json:"family_history"`
+}
+
+type dependentRequest struct {
+	Name         string `json:"name"`
+	Gender       string `json:"gender"`
+	Birthday     string `json:"birthday"`
+	Phone        string `json:"phone"`
+	Address      string `json:"address"`
+	Relationship string `json:"relationship"`
+}
+
+type dependentUpdateRequest struct {
+	Name     *string `json:"name"`
+	Gender   *string `json:"gender"`
+	Birthday *string `json:"birthday"`
+	Phone    *string `json:"phone"`
+	Address  *string `json:"address"`
+}
+
+type dependent struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Gender       string `json:"gender"`
+	Birthday     string `json:"birthday"`
+	Phone        string `json:"phone"`
+	Address      string `json:"address"`
+	FamilyID     uint   `json:"family_id"`
+	Relationship string `json:"relationship"`
+}
+
+type dependentResponse struct {
+	Message   string    `json:"message"`
+	Dependent dependent `json:"dependent"`
+}
+
+type dependentsResponse struct {
+	Dependents []dependent `json:"dependents"`
+}
+
+type claimCodeResponse struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type claimRequest struct {
+	Code     string `json:"code"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email"`
+}
+
+type claimResponse struct {
+	Message  string `json:"message"`
+	Token    string `json:"token"`
+	UID      uint   `json:"uid"`
+	UserType uint8  `json:"user_type"`
+}
+
+type familyGrantRequest struct {
+	RelativeID uint       `json:"relative_id"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+type familyGrantView struct {
+	models.FamilyGrant
+	OwnerName   string `json:"owner_name"`
+	GranteeName string `json:"grantee_name"`
+	Active      bool   `json:"active"`
+}
+
+type familyGrantsResponse struct {
+	Granted  []familyGrantView `json:"granted"`
+	Received []familyGrantView `json:"received"`
+}
+
+type familyAccessLogsResponse struct {
+	Logs       []models.FamilyAccessLog `json:"logs"`
+	Pagination utils.PageInfo           `json:"pagination"`
+}
+
+type auditLogsResponse struct {
+	Logs       []models.AuditLog `json:"logs"`
+	Pagination utils.PageInfo    `json:"pagination"`
+}
+
+type deletionRequest struct {
+	Reason string `json:"reason"`
+}
+
+type deletionResponse struct {
+	Message  string                 `json:"message"`
+	Deletion models.DeletionRequest `json:"deletion"`
+}
+
+type loincMappingRequest struct {
+	Code    string `json:"code"`
+	Display string `json:"display"`
+	Unit    string `json:"unit"`
+}
+
+type loincMappingView struct {
+	models.LoincMapping
+	ItemName string `json:"item_name"`
+	ItemUnit string `json:"item_unit"`
+}
+
+type loincMappingsResponse struct {
+	Mappings []loincMappingView `json:"mappings"`
+}
+
+type loincMappingResponse struct {
+	Message string              `json:"message"`
+	Mapping models.LoincMapping `json:"mapping"`
+}
+
+type exportResponse struct {
+	Message string           `json:"message"`
+	Export  models.ExportJob `json:"export"`
+}
+
+type exportsResponse struct {
+	Exports []models.ExportJob `json:"exports"`
+}
+
+type importResultsResponse struct {
+	Message string                `json:"message"`
+	Reports []models.ImportReport `json:"reports"`
+}
+
+type importReportsResponse struct {
+	Reports    []models.ImportReport `json:"reports"`
+	Pagination utils.PageInfo        `json:"pagination"`
+}
+
+type batchUpload struct {
+	File   []byte `json:"file"`    // .csv 或 .xlsx，首行为表头：user_id、name、phone、birthday 及项目ID或名称
+	PlanID uint   `json:"plan_id"` // 套餐ID
+	DryRun bool   `json:"dry_run"` // 为 true 时只校验不写入
+}
+
+type batchUploadResponse struct {
+	Message   string             `json:"message"`
+	Duplicate bool               `json:"duplicate"`
+	Batch     models.BatchImport `json:"batch"`
+	Report    utils.BatchReport  `json:"report"`
+}
+
+type batchImportsResponse struct {
+	Batches    []models.BatchImport `json:"batches"`
+	Pagination utils.PageInfo       `json:"pagination"`
+}
+
+type reportVerifyResponse struct {
+	Valid           bool      `json:"valid"`
+	Modified        bool      `json:"modified"`
+	Message         string    `json:"message"`
+	Code            string    `json:"code"`
+	IssuedAt        time.Time `json:"issued_at"`
+	InstitutionName string    `json:"institution_name"`
+	PlanName        string    `json:"plan_name"`
+	PatientName     string    `json:"patient_name"`
+}
+
+type conclusionResponse struct {
+	UserPackageID uint                       `json:"user_package_id"`
+	Conclusion    *models.ConclusionRevision `json:"conclusion"`
+	Draft         *models.CheckupConclusion  `json:"draft,omitempty"`
+}
+
+type conclusionRevisionsResponse struct {
+	Revisions []models.ConclusionRevision `json:"revisions"`
+}
+
+type conclusionRequest struct {
+	Summary        string               `json:"summary"`
+	Recommendation string               `json:"recommendation"`
+	Comments       []models.ItemComment `json:"comments"`
+}
+
+type conclusionSaveResponse struct {
+	Message string                   `json:"message"`
+	Draft   models.CheckupConclusion `json:"draft"`
+}
+
+type conclusionSignRequest struct {
+	Version uint   `json:"version"` // 所看到的草稿版本号
+	Reason  string `json:"reason"`
+}
+
+type conclusionSignResponse struct {
+	Message    string                    `json:"message"`
+	Conclusion models.ConclusionRevision `json:"conclusion"`
+}
+
+type institutionMembersResponse struct {
+	OwnerID uint                       `json:"owner_id"`
+	Members []models.InstitutionMember `json:"members"`
+}
+
+type institutionMemberRequest struct {
+	UserID uint   `json:"user_id"`
+	Role   string `json:"role"`
+	OnCall *bool  `json:"on_call"`
+}
+
+type institutionMemberResponse struct {
+	Message string                   `json:"message"`
+	Member  models.InstitutionMember `json:"member"`
+}
+
+type pendingReviewsResponse struct {
+	Packages   []models.UserPackage `json:"packages"`
+	Pagination utils.PageInfo       `json:"pagination"`
+}
+
+type resultReviewResponse struct {
+	Review     utils.ReviewState `json:"review"`
+	CanRelease bool              `json:"can_release"`
+}
+
+type criticalAckRequest struct {
+	Note string `json:"note"`
+}
+
+type criticalAckResponse struct {
+	Message        string             `json:"message"`
+	Acknowledgment models.CriticalAck `json:"acknowledgment"`
+}
+
+type criticalAlertsResponse struct {
+	Alerts []struct {
+		models.CriticalAlert
+		ItemName        string `json:"item_name"`
+		PatientName     string `json:"patient_name"`
+		ResponseSeconds *int64 `json:"response_seconds"`
+	} `json:"alerts"`
+	Pagination utils.PageInfo `json:"pagination"`
+}
+
+type notificationsResponse struct {
+	Notifications []struct {
+		models.Notification
+		Message string `json:"message"`
+	} `json:"notifications"`
+	Unread     int64          `json:"unread"`
+	Pagination utils.PageInfo `json:"pagination"`
+}
+
+type itemCategoryRequest struct {
+	Name      string `json:"name"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type itemCategoriesResponse struct {
+	Categories []struct {
+		models.ItemCategory
+		ItemCount int64 `json:"item_count"`
+	} `json:"categories"`
+	Uncategorized int64 `json:"uncategorized"`
+}
+
+type itemCategoryResponse struct {
+	Message  string              `json:"message"`
+	Category models.ItemCategory `json:"category"`
+}
+
+type catalogItemRequest struct {
+	ItemName      string   `json:"item_name"`
+	Code          string   `json:"code"`
+	Category      string   `json:"category"`
+	Unit          string   `json:"unit"`
+	ReferenceLow  *float64 `json:"reference_low"`
+	ReferenceHigh *float64 `json:"reference_high"`
+	CriticalLow   *float64 `json:"critical_low"`
+	CriticalHigh  *float64 `json:"critical_high"`
+	Synonyms      []string `json:"synonyms"`
+}
+
+type catalogItemResponse struct {
+	Message  string                     `json:"message"`
+	Item     models.HealthItem          `json:"item"`
+	Synonyms []models.HealthItemSynonym `json:"synonyms"`
+}
+
+type synonymsRequest struct {
+	Synonyms []string `json:"synonyms"`
+}
+
+type synonymsResponse struct {
+	Message  string                     `json:"message"`
+	Synonyms []models.HealthItemSynonym `json:"synonyms"`
+}
+
+type duplicateItemsResponse struct {
+	Groups [][]models.HealthItem `json:"groups"`
+}
+
+type mergeItemsRequest struct {
+	SourceIDs []uint `json:"source_ids"`
+}
+
+type institutionRequest struct {
+	InstitutionName          string `json:"institution_name"`
+	InstitutionAddress       string `json:"institution_address"`
+	InstitutionPhone         string `json:"institution_phone"`
+	InstitutionQualification string `json:"institution_qualification"`
+}
+
+type reviewRequest struct {
+	Approved bool `json:"approved"`
+}
+
+type createPlanRequest struct {
+	PlanName        *string  `json:"plan_name"`
+	HealthItem      string   `json:"health_item"`
+	HealthItemID    uint     `json:"health_item_id"`
+	ItemDescription *string  `json:"item_description"`
+	PlanPrice       *float64 `json:"plan_price"`
+	Description     *string  `json:"description"`
+	SuitableFor     *string  `json:"suitable_for"`
+	MinAge          *uint    `json:"min_age"`
+	MaxAge          *uint    `json:"max_age"`
+	Sex             string   `json:"sex"`
+	RiskFactors     []string `json:"risk_factors"`
+}
+
+type updatePlanRequest struct {
+	PlanID          uint     `json:"plan_id"`
+	ItemID          *uint    `json:"item_id"`
+	ItemDescription *string  `json:"item_description"`
+	PlanName        *string  `json:"plan_name"`
+	PlanPrice       *float64 `json:"plan_price"`
+	PlanDescription *string  `json:"description"`
+	PlanSuitableFor *string  `json:"suitable_for"`
+	MinAge          *uint    `json:"min_age"`
+	MaxAge          *uint    `json:"max_age"`
+	Sex             *string  `json:"sex"`
+	RiskFactors     []string `json:"risk_factors"`
+}
+
+type planItemRequest struct {
+	PlanID uint `json:"plan_id"`
+	ItemID uint `json:"item_id"`
+}
+
+type planRequest struct {
+	PlanID uint `json:"plan_id"`
+}
+
+type planDetail struct {
+	ID          uint    `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	SuitableFor string  `json:"suitable_for"`
+	Items       string  `json:"items"`
+	Price       float64 `json:"price"`
+}
+
+type institutionPlansResponse struct {
+	Institution models.Institution     `json:"institution"`
+	Plans       []planDetail           `json:"plans"`
+	Items       []models.PlanHeathItem `json:"items"`
+}
+
+type commentaryRequest struct {
+	RelationPlanId uint   `json:"plan_id"`
+	Rating         uint8  `json:"rating"`
+	Commentary     string `json:"commentary"`
+}
+
+type commentaryAddedResponse struct {
+	Message    string            `json:"message"`
+	Commentary models.Commentary `json:"commentary"`
+}
+
+type commentariesResponse struct {
+	Commentaries []models.Commentary `json:"commentaries"`
+}
+
+type planCommentariesResponse struct {
+	Commentaries []models.Commentary `json:"commentaries"`
+	Rating       float64             `json:"rating"`
+	RatingCount  uint                `json:"rating_count"`
+}
+
+type commentaryReplyRequest struct {
+	Reply string `json:"reply"`
+}
+
+type commentaryReasonRequest struct {
+	Reason string `json:"reason"`
+}
+
+type commentaryPageResponse struct {
+	Commentaries []models.Commentary `json:"commentaries"`
+	Pagination   utils.PageInfo      `json:"pagination"`
+}
+
+type institutionPageResponse struct {
+	Institutions []models.Institution `json:"institutions"`
+	Pagination   utils.PageInfo       `json:"pagination"`
+}
+
+type healthItemPageResponse struct {
+	Items      []models.HealthItem `json:"items"`
+	Pagination utils.PageInfo      `json:"pagination"`
+}
+
+type deletedPlan struct {
+	ID            uint    `json:"id"`
+	PlanName      string  `json:"plan_name"`
+	InstitutionID uint    `json:"institution_id"`
+	InstName      string  `json:"institution_name"`
+	PlanPrice     float64 `json:"plan_price"`
+	DeletedAt     string  `json:"deleted_at"`
+	ItemCount     int64   `json:"item_count"`
+}
+
+type deletedPlansResponse struct {
+	DeletedPlans []deletedPlan  `json:"deleted_plans"`
+	Pagination   utils.PageInfo `json:"pagination"`
+}
+
+type institutionUserPackage struct {
+	ID             uint   `json:"id"`
+	UserID         uint   `json:"user_id"`
+	PlanID         uint   `json:"plan_id"`
+	InstitutionID  uint   `json:"institution_id"`
+	Status         uint8  `json:"status"`
+	PlanVersionID  *uint  `json:"plan_version_id"`
+	UserName       string `json:"user_name"`
+	PlanName       string `json:"plan_name"`
+	CompletedItems int    `json:"completed_items"`
+	TotalItems     int    `json:"total_items"`
+}
+
+type institutionUserPackagesResponse struct {
+	Packages   []institutionUserPackage `json:"packages"`
+	Pagination utils.PageInfo           `json:"pagination"`
+}
+
+type itemValueInput struct {
+	ItemID    int     `json:"item_id"`
+	ItemValue *string `json:"item_value"`
+}
+
+type healthRecord struct {
+	PlanID          uint   `json:"plan_id"`
+	InstitutionID   uint   `json:"institution_id"`
+	InstitutionName string `json:"institution_name"`
+	PlanName        string `json:"plan_name"`
+	Status          uint8  `json:"status"`
+	Items           string `json:"items"`
+	ItemCount       int    `json:"item_count"`
+	CompletedCount  int    `json:"completed_count"`
+}
+
+type healthRecordsResponse struct {
+	Records []healthRecord `json:"records"`
+}
+
+type ocrItem struct {
+	ItemName  string `json:"item_name"`
+	ItemValue string `json:"item_value"`
+}
+
+type ocrUpload struct {
+	Image []byte `json:"image"`
+}
+
+type ocrResponse struct {
+	Result       []ocrItem `json:"result"`
+	ReportFileID uint      `json:"report_file_id"`
+}
+
+type planItem struct {
+	ID       uint   `json:"id"`
+	ItemName string `json:"item_name"`
+}
+
+type planItemsResponse struct {
+	PlanID   uint       `json:"plan_id"`
+	PlanName string     `json:"plan_name"`
+	Items    []planItem `json:"items"`
+}
+
+type healthItemsResponse struct {
+	Items []models.HealthItem `json:"items"`
+}
+
+type healthItemNameRequest struct {
+	ItemName string `json:"item_name"`
+}
+
+type recommendationProfile struct {
+	Age         *int                           `json:"age"`
+	Gender      string                         `json:"gender"`
+	RiskFactors []controllers.RiskFactorSource `json:"risk_factors"`
+}
+
+type recommendationsResponse struct {
+	Profile         recommendationProfile        `json:"profile"`
+	Recommendations []controllers.Recommendation `json:"recommendations"`
+}
+
+type riskFactor struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type riskFactorsResponse struct {
+	RiskFactors []riskFactor `json:"risk_factors"`
+}
+
+type healthItemUpdateRequest struct {
+	ItemName      string   `json:"item_name"`
+	Code          *string  `json:"code"`
+	Unit          *string  `json:"unit"`
+	ReferenceLow  *float64 `json:"reference_low"`
+	ReferenceHigh *float64 `json:"reference_high"`
+	Category      *string  `json:"category"`
+	CriticalLow   *float64 `json:"critical_low"`
+	CriticalHigh  *float64 `json:"critical_high"`
+	Formula       *string  `json:"formula"`
+}
+
+type translationRequest struct {
+	Language string `json:"language"`
+	ItemName string `json:"item_name"`
+}
+
+type planItemDescriptionRequest struct {
+	PlanID          uint   `json:"plan_id"`
+	ItemID          uint   `json:"item_id"`
+	ItemDescription string `json:"item_description"`
+}
+
+type templateRequest struct {
+	HealthItems string `json:"health_items"`
+}
+
+type templateValuesRequest struct {
+	ItemID     uint              `json:"item_id"`
+	ItemString string            `json:"item_string"`
+	Updates    map[string]string `json:"updates"`
+	DeleteKeys []string          `json:"delete_keys"`
+}
+
+type associateItem struct {
+	HealthItemID    uint   `json:"health_item_id"`
+	ItemDescription string `json:"item_description"`
+}
+
+type selectPackageRequest struct {
+	InstitutionID uint       `json:"institution_id"`
+	PlanID        uint       `json:"plan_id"`
+	UserID        uint       `json:"user_id"`
+	AppointmentAt *time.Time `json:"appointment_at"`
+}
+
+type appointmentRequest struct {
+	AppointmentAt *time.Time `json:"appointment_at"`
+}
+
+type appointmentResponse struct {
+	Message     string             `json:"message"`
+	UserPackage models.UserPackage `json:"user_package"`
+}
+
+type userPackagesResponse struct {
+	UserPackages []models.UserPackage `json:"user_packages"`
+}
+
+type packageStatusRequest struct {
+	Status uint8 `json:"status"`
+}
+
+type planVersionsResponse struct {
+	PlanID         uint                 `json:"plan_id"`
+	CurrentVersion uint                 `json:"current_version"`
+	Versions       []models.PlanVersion `json:"versions"`
+}
+
+type compareResponse struct {
+	Plans []controllers.ComparePlan `json:"plans"`
+	Items []controllers.CompareItem `json:"items"`
+}
+
+type searchResponse struct {
+	Query      string                     `json:"query"`
+	Terms      []string                   `json:"terms"`
+	Results    []controllers.SearchResult `json:"results"`
+	Pagination utils.PageInfo             `json:"pagination"`
+}
+
+type reindexResponse struct {
+	Message   string `json:"message"`
+	Documents int    `json:"documents"`
+}
+
+// apiDocs 各路由的文档说明，键为 "METHOD 相对于 /api/v1 的路径"
+var apiDocs = map[string]openapi.Operation{
+	"GET /openapi.json": {Tag: "docs", Summary: "获取 OpenAPI 文档", Public: true},
+
+	// auth
+	"POST /auth/login":    {Tag: "auth", Summary: "用户登录", Public: true, Request: loginRequest{}, Response: tokenResponse{}},
+	"POST /auth/register": {Tag: "auth", Summary: "用户注册", Public: true, Request: models.User{}, Response: tokenResponse{}},
+	"POST /auth/claim":    {Tag: "auth", Summary: "使用认领码认领被监护人账号", Public: true, Request: claimRequest{}, Response: claimResponse{}},
+
+	// users
+	"GET /users/:id/profile":             {Tag: "users", Summary: "查看用户个人信息", Response: userProfileResponse{}},
+	"POST /users/:id/profile":            {Tag: "users", Summary: "用户更新个人信息", Request: updateProfileRequest{}, Response: openapi.MessageResponse{}},
+	"PUT /users/:id/reset_pwd":           {Tag: "users", Summary: "用户重设密码", Request: resetPasswordRequest{}, Response: openapi.MessageResponse{}},
+	"PUT /users/:id/language":            {Tag: "users", Summary: "设置语言偏好", Request: languageRequest{}, Response: openapi.MessageResponse{}},
+	"GET /users/:id/institution":         {Tag: "users", Summary: "查找用户管理的机构", Response: models.Institution{}},
+	"GET /users/:id/deletion":            {Tag: "users", Summary: "查看最近一次注销申请", Response: models.DeletionRequest{}},
+	"DELETE /users/:id/deletion":         {Tag: "users", Summary: "撤销注销申请", Response: openapi.MessageResponse{}},
+	"PATCH /users/:id/permission":        {Tag: "users", Summary: "管理员更改用户权限", Request: userTypeRequest{}, Response: openapi.MessageResponse{}},
+	"POST /users/create_health_item":     {Tag: "users", Summary: "新建个人健康指标", Request: personalHealthItemRequest{}, Response: openapi.MessageResponse{}},
+	"GET /users/:id/del_health_item":     {Tag: "users", Summary: "删除个人健康指标", Response: openapi.MessageResponse{}},
+	"POST /users/update_use_health_item": {Tag: "users", Summary: "修改个人健康指标", Request: updatePersonalHealthItemRequest{}, Response: openapi.MessageResponse{}},
+	"GET /users/:id/plans":               {Tag: "users", Summary: "获取用户的套餐列表"},
+	"DELETE /users/:id": {Tag: "users", Summary: "申请注销账号（冷静期后匿名化，体检记录假名化保留）", Query: []openapi.Param{
+		{Name: "immediate", Type: "boolean", Description: "管理员跳过冷静期立即执行"},
+	}, Request: deletionRequest{}, Response: deletionResponse{}},
+
+	// family
+	"POST /family/request/:id":           {Tag: "family", Summary: "发起家庭关系请求（称谓须与双方性别相符）", Request: familyRequest{}, Response: openapi.MessageResponse{}},
+	"GET /family/pending/:id":            {Tag: "family", Summary: "获取待处理的家庭关系请求"},
+	"POST /family/handle/:id/:requestId": {Tag: "family", Summary: "处理家庭关系请求", Request: handleFamilyRequest{}, Response: openapi.MessageResponse{}},
+	"GET /family/confirmed/:id":          {Tag: "family", Summary: "获取已确认的家庭成员", Response: []familyMember{}},
+	"GET /family/del_confirmed/:id":      {Tag: "family", Summary: "删除家庭关系", Response: openapi.MessageResponse{}},
+	"POST /family/update_family_name":    {Tag: "family", Summary: "修改亲友关系名称", Request: renameFamilyRequest{}, Response: openapi.MessageResponse{}},
+	"GET /family/relations":              {Tag: "family", Summary: "获取可选的亲属称谓", Response: relationsResponse{}},
+	"GET /family/derived/:id":            {Tag: "family", Summary: "推导间接亲属（祖辈、兄弟姐妹、姻亲等），仅本人与管理员", Response: []derivedFamilyMember{}},
+	"GET /family/dashboard":              {Tag: "family", Summary: "家庭健康看板（家人的健康数据需要 view_records 授权）及家族病史摘要", Response: familyDashboardResponse{}},
+	"POST /family/grants":                {Tag: "family", Summary: "向亲属授予授权（view_records, book_packages, notifications）", Request: familyGrantRequest{}, Response: openapi.MessageResponse{}},
+	"GET /family/grants":                 {Tag: "family", Summary: "查看授予和获得的授权", Response: familyGrantsResponse{}},
+	"DELETE /family/grants/:id":          {Tag: "family", Summary: "撤销授权", Response: openapi.MessageResponse{}},
+	"GET /family/access-logs": {Tag: "family", Summary: "查看亲属访问我的数据的记录", Query: pageQuery("id, created_at", append([]openapi.Param{
+		{Name: "actor_id", Type: "integer"},
+		{Name: "scope"},
+	}, dateRangeQuery...)...), Response: familyAccessLogsResponse{}},
+
+	// dependents
+	"POST /dependents":                {Tag: "family", Summary: "创建被监护人档案", Request: dependentRequest{}, Response: dependentResponse{}},
+	"GET /dependents":                 {Tag: "family", Summary: "查看我监护的被监护人档案", Response: dependentsResponse{}},
+	"PATCH /dependents/:id":           {Tag: "family", Summary: "修改被监护人档案", Request: dependentUpdateRequest{}, Response: openapi.MessageResponse{}},
+	"POST /dependents/:id/claim-code": {Tag: "family", Summary: "生成被监护人认领码（7 天内有效）", Response: claimCodeResponse{}},
+
+	// institutions
+	"POST /institutions/:id":        {Tag: "institutions", Summary: "创建机构", Request: institutionRequest{}},
+	"GET /institutions/pending":     {Tag: "institutions", Summary: "获取待审核机构", Response: []models.Institution{}},
+	"POST /institutions/:id/review": {Tag: "institutions", Summary: "审核机构", Request: reviewRequest{}, Response: openapi.MessageResponse{}},
+	"GET /institutions": {Tag: "institutions", Summary: "获取已审核通过的机构列表", Query: pageQuery("id, institution_name, created_at", append([]openapi.Param{
+		{Name: "keyword", Description: "按名称或地址模糊匹配"},
+		{Name: "status", Type: "integer", Description: "仅管理员可用，默认 1"},
+	}, dateRangeQuery...)...), Response: institutionPageResponse{}},
+	"GET /institutions/:id":                {Tag: "institutions", Summary: "获取机构详情"},
+	"POST /institutions/:id/plans":         {Tag: "institutions", Summary: "创建机构套餐及体检项目", Request: createPlanRequest{}},
+	"GET /institutions/:id/plans":          {Tag: "institutions", Summary: "获取机构的套餐列表", Response: institutionPlansResponse{}},
+	"POST /institutions/:id/:plan_id/item": {Tag: "institutions", Summary: "为套餐新增体检项目", Request: createPlanRequest{}},
+	"PATCH /institutions/:id/update":       {Tag: "institutions", Summary: "更新机构信息", Request: institutionRequest{}},
+	"PATCH /institutions/:id/item":         {Tag: "institutions", Summary: "更新套餐或体检项目信息", Request: updatePlanRequest{}, Response: openapi.MessageResponse{}},
+	"DELETE /institutions/plan/item":       {Tag: "institutions", Summary: "删除套餐内体检项目", Request: planItemRequest{}, Response: openapi.MessageResponse{}},
+	"DELETE /institutions/plan":            {Tag: "institutions", Summary: "删除套餐", Request: planRequest{}, Response: openapi.MessageResponse{}},
+	"DELETE /institutions/:id":             {Tag: "institutions", Summary: "删除机构", Response: openapi.MessageResponse{}},
+	"GET /institution/user-packages": {Tag: "institutions", Summary: "获取机构的用户套餐列表", Query: pageQuery("id, status, created_at", append([]openapi.Param{
+		{Name: "institution_id", Type: "integer", Description: "管理员必填"},
+		{Name: "user_name"},
+		{Name: "phone", Description: "手机号，精确匹配"},
+		{Name: "plan_name"},
+		{Name: "status", Type: "integer"},
+	}, dateRangeQuery...)...), Response: institutionUserPackagesResponse{}},
+
+	// commentary
+	"GET /commentary/get/user_list": {Tag: "commentary", Summary: "查看所有评论", Query: pageQuery("id, created_at, rating, report_count", append([]openapi.Param{
+		{Name: "plan_id", Type: "integer"},
+		{Name: "user_id", Type: "integer"},
+		{Name: "rating", Type: "integer"},
+	}, dateRangeQuery...)...), Response: commentaryPageResponse{}},
+	"POST /commentary/add":          {Tag: "commentary", Summary: "发布评分评论（需已完成该套餐）", Request: commentaryRequest{}, Response: commentaryAddedResponse{}},
+	"DELETE /commentary/delete/:id": {Tag: "commentary", Summary: "删除评论", Response: openapi.MessageResponse{}},
+	"GET /commentary/get/plan/:id":  {Tag: "commentary", Summary: "按套餐查看评论及评分", Response: planCommentariesResponse{}},
+	"GET /commentary/get/user":      {Tag: "commentary", Summary: "查看当前用户的评论", Response: commentariesResponse{}},
+	"POST /commentary/:id/reply":    {Tag: "commentary", Summary: "机构回复评论", Request: commentaryReplyRequest{}, Response: openapi.MessageResponse{}},
+	"POST /commentary/:id/report":   {Tag: "commentary", Summary: "举报评论", Request: commentaryReasonRequest{}, Response: openapi.MessageResponse{}},
+	"GET /commentary/moderation": {Tag: "commentary", Summary: "评论审核队列（管理员）", Query: pageQuery("report_count, created_at, id",
+		openapi.Param{Name: "status", Description: "reported（默认）或 hidden"},
+	), Response: commentaryPageResponse{}},
+	"POST /commentary/:id/hide":    {Tag: "commentary", Summary: "隐藏评论（管理员）", Request: commentaryReasonRequest{}, Response: openapi.MessageResponse{}},
+	"POST /commentary/:id/restore": {Tag: "commentary", Summary: "恢复评论或驳回举报（管理员）", Response: openapi.MessageResponse{}},
+
+	// health records
+	"GET /userview/": {Tag: "records", Summary: "查看当前用户（或授权亲属）的全部体检记录", Query: []openapi.Param{
+		{Name: "user_id", Type: "integer", Description: "查看亲属的记录，需要 view_records 授权"},
+	}, Response: healthRecordsResponse{}},
+	"GET /userview/plan": {Tag: "records", Summary: "查看指定套餐的体检项目及结果", Query: []openapi.Param{
+		{Name: "plan_id", Type: "integer", Required: true},
+		{Name: "customer_id", Type: "integer", Description: "机构用户查看指定客户"},
+		{Name: "user_id", Type: "integer", Description: "查看亲属的记录，需要 view_records 授权"},
+	}},
+	"POST /adduserdata/:customer_id/:plan_id": {Tag: "records", Summary: "机构为用户录入体检数据", Request: []itemValueInput{}, Response: openapi.MessageResponse{}},
+	"POST /imageocr/solve":                    {Tag: "records", Summary: "体检报告图片识别", Upload: true, Request: ocrUpload{}, Response: ocrResponse{}},
+
+	// plans
+	"GET /plans/compare": {Tag: "plans", Summary: "对比多个套餐的项目、价格与评分", Query: []openapi.Param{
+		{Name: "ids", Required: true, Description: "逗号分隔的套餐ID，2 到 5 个"},
+	}, Response: compareResponse{}},
+	"GET /plans/:id/versions":          {Tag: "plans", Summary: "获取套餐的历史版本", Response: planVersionsResponse{}},
+	"GET /plans/:id/versions/:version": {Tag: "plans", Summary: "获取套餐指定版本的内容", Response: models.PlanVersion{}},
+	"GET /plans/:id/items":             {Tag: "plans", Summary: "获取套餐的体检项目", Response: planItemsResponse{}},
+	"GET /plans/:id":                   {Tag: "plans", Summary: "获取套餐详情", Response: models.Plan{}},
+
+	// health items
+	"GET /healthitems": {Tag: "healthitems", Summary: "获取项目目录中的健康检查项目", Query: []openapi.Param{{Name: "keyword", Description: "匹配名称、同义名称或编码"}}, Response: healthItemsResponse{}},
+	"GET /healthitems/all": {Tag: "healthitems", Summary: "分页获取项目目录", Query: pageQuery("id, item_name, code, category, created_at",
+		openapi.Param{Name: "keyword", Description: "匹配名称、同义名称或编码"},
+		openapi.Param{Name: "category", Description: "项目分类，空字符串为未分类"},
+	), Response: healthItemPageResponse{}},
+	"GET /healthitems/byid/:id":                                        {Tag: "healthitems", Summary: "获取用户的个人健康指标"},
+	"GET /healthitems/:id":                                             {Tag: "healthitems", Summary: "获取健康检查项目详情"},
+	"PATCH /healthitems/:id":                                           {Tag: "healthitems", Summary: "管理员更新目录中的健康检查项目（参考范围、危急值界限传 null 清除）", Request: healthItemUpdateRequest{}, Response: openapi.MessageResponse{}},
+	"GET /healthitems/:id/translations":                                {Tag: "healthitems", Summary: "获取健康检查项目的译名"},
+	"PUT /healthitems/:id/translations":                                {Tag: "healthitems", Summary: "设置健康检查项目的译名", Request: translationRequest{}},
+	"PATCH /healthitems/plan-item":                                     {Tag: "healthitems", Summary: "更新套餐中项目的描述", Request: planItemDescriptionRequest{}, Response: openapi.MessageResponse{}},
+	"POST /healthitem-manager/template":                                {Tag: "healthitems", Summary: "创建健康项目模板", Request: templateRequest{}},
+	"POST /healthitem-manager/save-template":                           {Tag: "healthitems", Summary: "保存健康项目模板", Request: healthItemNameRequest{}},
+	"PUT /healthitem-manager/values":                                   {Tag: "healthitems", Summary: "更新健康项目值", Request: templateValuesRequest{}},
+	"GET /healthitem-manager/values/:id":                               {Tag: "healthitems", Summary: "获取健康项目解析后的键值对"},
+	"GET /healthitem-plan/plans/:plan_id/items":                        {Tag: "plans", Summary: "获取套餐关联的健康项目"},
+	"POST /healthitem-plan/plans/:plan_id/items":                       {Tag: "plans", Summary: "重新关联套餐的健康项目", Request: []associateItem{}},
+	"POST /healthitem-plan/plans/:plan_id/item":                        {Tag: "plans", Summary: "添加单个健康项目到套餐", Request: associateItem{}},
+	"GET /healthitem-plan/users/:user_id/plans/:plan_id":               {Tag: "records", Summary: "获取用户特定套餐的健康数据"},
+	"GET /healthitem-plan/users/:user_id/institutions/:institution_id": {Tag: "records", Summary: "按机构获取用户的健康数据"},
+
+	// user packages
+	"POST /user-packages":                           {Tag: "packages", Summary: "用户选择套餐", Request: selectPackageRequest{}},
+	"GET /user-packages/:id":                        {Tag: "packages", Summary: "查看用户已选择的套餐", Response: userPackagesResponse{}},
+	"PATCH /user-packages/appointments/:id":         {Tag: "packages", Summary: "预约、改约或取消预约体检时间（appointment_at 为空表示取消）", Request: appointmentRequest{}, Response: appointmentResponse{}},
+	"PATCH /user-packages/:user_id/:plan_id/status": {Tag: "packages", Summary: "更新用户套餐状态", Request: packageStatusRequest{}, Response: openapi.MessageResponse{}},
+
+	// admin
+	"GET /admin/plans/deleted": {Tag: "admin", Summary: "获取已删除的套餐", Query: pageQuery("id, plan_price, deleted_at", append([]openapi.Param{
+		{Name: "institution_id", Type: "integer"},
+		{Name: "min_price", Type: "number"},
+		{Name: "max_price", Type: "number"},
+	}, dateRangeQuery...)...), Response: deletedPlansResponse{}},
+	"POST /admin/plans/recover/:id": {Tag: "admin", Summary: "恢复已删除的套餐", Response: openapi.MessageResponse{}},
+
+	// search
+	"GET /search": {Tag: "search", Summary: "搜索已审核机构的套餐、机构及健康项目", Query: pageQuery("relevance, price, id",
+		openapi.Param{Name: "q", Required: true, Description: "关键词，支持中文"},
+		openapi.Param{Name: "type", Description: "plan 或 institution，默认全部"},
+		openapi.Param{Name: "institution_id", Type: "integer"},
+		openapi.Param{Name: "min_price", Type: "number"},
+		openapi.Param{Name: "max_price", Type: "number"},
+	), Response: searchResponse{}},
+	"POST /search/reindex": {Tag: "search", Summary: "重建搜索索引（管理员）", Response: reindexResponse{}},
+
+	// recommendations
+	"GET /recommendations": {Tag: "recommendations", Summary: "根据年龄、性别、异常结果与家族史推荐套餐", Query: []openapi.Param{
+		{Name: "limit", Type: "integer", Description: "默认 10，最大 50"},
+	}, Response: recommendationsResponse{}},
+	"GET /recommendations/risk-factors": {Tag: "recommendations", Summary: "获取风险因素列表", Response: riskFactorsResponse{}},
+
+	// audit logs
+	"GET /audit-logs": {Tag: "admin", Summary: "查询审计日志（管理员）", Query: pageQuery("id, created_at", append([]openapi.Param{
+		{Name: "actor_id", Type: "integer"},
+		{Name: "action", Description: "如 user.permission_update、user.deletion_request、user.deletion_cancel、user.delete、institution.review、checkup_results.write、personal_health_item.update"},
+		{Name: "target_type", Description: "user、institution、user_package 或 health_item"},
+		{Name: "target_id", Type: "integer"},
+		{Name: "request_id"},
+	}, dateRangeQuery...)...), Response: auditLogsResponse{}},
+	"GET /audit-logs/verify": {Tag: "admin", Summary: "校验审计日志哈希链（管理员）", Response: utils.AuditChainResult{}},
+
+	// exports
+	"POST /exports":             {Tag: "exports", Summary: "发起个人数据导出（后台生成 zip：data.json、report.html 与上传的报告文件）", Response: exportResponse{}},
+	"GET /exports":              {Tag: "exports", Summary: "获取本人的导出任务", Response: exportsResponse{}},
+	"GET /exports/:id":          {Tag: "exports", Summary: "查询导出任务状态", Response: models.ExportJob{}},
+	"GET /exports/:id/download": {Tag: "exports", Summary: "下载导出归档", Produces: "application/zip"},
+
+	// fhir
+	"GET /fhir/user-packages/:id":     {Tag: "fhir", Summary: "以 FHIR R4 Bundle 导出用户套餐的检查结果", Response: fhir.Bundle{}, Produces: fhir.MediaType},
+	"GET /loinc-mappings":             {Tag: "fhir", Summary: "获取体检项目的 LOINC 映射", Response: loincMappingsResponse{}},
+	"PUT /loinc-mappings/:item_id":    {Tag: "fhir", Summary: "设置体检项目的 LOINC 编码（管理员）", Request: loincMappingRequest{}, Response: loincMappingResponse{}},
+	"DELETE /loinc-mappings/:item_id": {Tag: "fhir", Summary: "删除体检项目的 LOINC 映射（管理员）", Response: openapi.MessageResponse{}},
+
+	// result imports
+	"GET /imports/results/:id": {Tag: "imports", Summary: "查看检验结果导入报告（机构、管理员）", Response: models.ImportReport{}},
+	"GET /imports/results": {Tag: "imports", Summary: "查询检验结果导入报告（机构查看本账号的导入，管理员查看全部）", Query: pageQuery("id, created_at",
+		openapi.Param{Name: "status", Type: "integer", Description: "0: success, 1: partial, 2: failed"},
+		openapi.Param{Name: "format", Description: "hl7v2 或 fhir"},
+		openapi.Param{Name: "message_id"},
+		openapi.Param{Name: "user_id", Type: "integer"},
+		openapi.Param{Name: "user_package_id", Type: "integer"},
+	), Response: importReportsResponse{}},
+	"POST /imports/results": {Tag: "imports", Summary: "导入外部系统的检验结果（HL7 v2 ORU^R01 或 FHIR Bundle），每条消息生成一份导入报告", Query: []openapi.Param{
+		{Name: "format", Description: "hl7v2 或 fhir，默认按 Content-Type 或内容判断"},
+		{Name: "user_package_id", Type: "integer", Description: "结果所属的用户套餐，默认按消息中的标识或该用户唯一的待检测套餐"},
+	}, Consumes: []string{"x-application/hl7-v2+er7", fhir.MediaType}, Response: importResultsResponse{}},
+
+	// batch imports
+	"POST /imports/batches":         {Tag: "imports", Summary: "批量上传套餐体检结果（CSV/XLSX，每行一位客户）；存在行级错误时整批不写入（422）", Upload: true, Request: batchUpload{}, Response: batchUploadResponse{}},
+	"GET /imports/batches/template": {Tag: "imports", Summary: "下载套餐批量上传的 CSV 模板", Query: []openapi.Param{{Name: "plan_id", Type: "integer", Required: true}}, Produces: "text/csv"},
+	"GET /imports/batches/:id":      {Tag: "imports", Summary: "查看已提交的批次", Response: models.BatchImport{}},
+	"GET /imports/batches": {Tag: "imports", Summary: "查询已提交的批量上传（机构查看本账号的批次，管理员查看全部）", Query: pageQuery("id, created_at",
+		openapi.Param{Name: "plan_id", Type: "integer"},
+	), Response: batchImportsResponse{}},
+
+	// reports
+	"GET /reports/user-packages/:id/pdf": {Tag: "reports", Summary: "下载已完成用户套餐的 PDF 体检报告（含验证二维码）", Produces: "application/pdf"},
+	"GET /reports/verify/:code":          {Tag: "reports", Summary: "验证体检报告真伪（报告二维码指向此接口）", Public: true, Response: reportVerifyResponse{}},
+
+	// conclusions
+	"GET /user-packages/:id/conclusion":           {Tag: "conclusions", Summary: "查看体检结论（客户只能看到最新签署的版本，机构还能看到草稿）", Response: conclusionResponse{}},
+	"GET /user-packages/:id/conclusion/revisions": {Tag: "conclusions", Summary: "查看体检结论的全部签署版本", Response: conclusionRevisionsResponse{}},
+	"PUT /user-packages/:id/conclusion":           {Tag: "conclusions", Summary: "保存体检结论草稿及项目点评（已签署的结论修改后需重新签署）", Request: conclusionRequest{}, Response: conclusionSaveResponse{}},
+	"POST /user-packages/:id/conclusion/sign":     {Tag: "conclusions", Summary: "签署体检结论草稿（须提交草稿版本号），生成新的修订版本；修订时须填写原因", Request: conclusionSignRequest{}, Response: conclusionSignResponse{}},
+
+	// reviews
+	"GET /institutions/:id/members":                        {Tag: "reviews", Summary: "查看机构成员及其角色", Response: institutionMembersResponse{}},
+	"POST /institutions/:id/members":                       {Tag: "reviews", Summary: "添加机构成员或修改其角色（录入人 entry / 审核人 reviewer）及值班状态", Request: institutionMemberRequest{}, Response: institutionMemberResponse{}},
+	"DELETE /institutions/:id/members/:user_id":            {Tag: "reviews", Summary: "移除机构成员", Response: openapi.MessageResponse{}},
+	"GET /reviews/pending":                                 {Tag: "reviews", Summary: "待审核发布的用户套餐", Query: pageQuery("id, updated_at"), Response: pendingReviewsResponse{}},
+	"GET /user-packages/:id/review":                        {Tag: "reviews", Summary: "查看结果审核状态、危急值及确认情况", Response: resultReviewResponse{}},
+	"POST /user-packages/:id/review/critical/:item_id/ack": {Tag: "reviews", Summary: "确认危急值（结果修改后需重新确认）", Request: criticalAckRequest{}, Response: criticalAckResponse{}},
+	"POST /user-packages/:id/review/release":               {Tag: "reviews", Summary: "发布结果：须由非录入人的审核人发布，危急值须全部确认", Response: openapi.MessageResponse{}},
+	"GET /critical-alerts": {Tag: "reviews", Summary: "危急值告警及确认记录（含升级级别与响应用时）", Query: pageQuery("id, created_at",
+		openapi.Param{Name: "status", Type: "integer", Description: "0: 未确认, 1: 已确认, 2: 结果已修改"},
+		openapi.Param{Name: "institution_id", Type: "integer"},
+		openapi.Param{Name: "user_package_id", Type: "integer"},
+	), Response: criticalAlertsResponse{}},
+
+	// notifications
+	"GET /notifications": {Tag: "notifications", Summary: "查看我的通知", Query: pageQuery("id, created_at",
+		openapi.Param{Name: "unread", Type: "boolean", Description: "true 时只返回未读通知"},
+	), Response: notificationsResponse{}},
+	"POST /notifications/:id/read": {Tag: "notifications", Summary: "将通知标记为已读", Response: openapi.MessageResponse{}},
+	"POST /notifications/read-all": {Tag: "notifications", Summary: "将全部通知标记为已读", Response: openapi.MessageResponse{}},
+
+	// catalog
+	"GET /catalog/categories":         {Tag: "catalog", Summary: "查看项目分类及其项目数", Response: itemCategoriesResponse{}},
+	"POST /catalog/categories":        {Tag: "catalog", Summary: "新建项目分类", Request: itemCategoryRequest{}, Response: itemCategoryResponse{}},
+	"PUT /catalog/categories/:id":     {Tag: "catalog", Summary: "修改项目分类，改名时项目随之更新", Request: itemCategoryRequest{}, Response: itemCategoryResponse{}},
+	"DELETE /catalog/categories/:id":  {Tag: "catalog", Summary: "删除未被使用的项目分类", Response: openapi.MessageResponse{}},
+	"POST /catalog/items":             {Tag: "catalog", Summary: "向项目目录添加项目", Request: catalogItemRequest{}, Response: catalogItemResponse{}},
+	"PUT /catalog/items/:id/synonyms": {Tag: "catalog", Summary: "设置项目的同义名称", Request: synonymsRequest{}, Response: synonymsResponse{}},
+	"POST /catalog/items/:id/merge":   {Tag: "catalog", Summary: "将重复项目合并到该项目，引用全部迁移", Request: mergeItemsRequest{}, Response: catalogItemResponse{}},
+	"GET /catalog/duplicates":         {Tag: "catalog", Summary: "查看疑似重复的项目", Response: duplicateItemsResponse{}},
+}
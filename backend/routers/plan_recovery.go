@@ -8,9 +8,9 @@ import (
 )
 
 // SetupPlanRecoveryRouter 设置套餐恢复相关路由
-func SetupPlanRecoveryRouter(r *gin.Engine) {
+func SetupPlanRecoveryRouter(r *gin.RouterGroup) {
 	// 设置套餐恢复路由组
-	planRecovery := r.Group("/api/admin/plans")
+	planRecovery := r.Group("/admin/plans")
 	planRecovery.Use(middlewares.AuthMiddleWare())
 	planRecovery.Use(middlewares.AdminRequiredMiddleware())
 	{
@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRecommendationRouter 设置套餐推荐相关路由
+func SetupRecommendationRouter(r *gin.RouterGroup) {
+	recommendations := r.Group("/recommendations")
+	recommendations.Use(middlewares.AuthMiddleWare())
+	{
+		// 为当前用户推荐套餐
+		recommendations.GET("", controllers.GetRecommendations)
+
+		// 风险因素列表
+		recommendations.GET("/risk-factors", controllers.GetRiskFactors)
+	}
+}
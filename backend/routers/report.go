@@ -0,0 +1,17 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupReportRouter PDF 体检报告下载及报告二维码的真伪验证（无需登录）
+func SetupReportRouter(r *gin.RouterGroup) {
+	reports := r.Group("/reports")
+	{
+		reports.GET("/user-packages/:id/pdf", middlewares.AuthMiddleWare(), controllers.GetUserPackageReport)
+		reports.GET("/verify/:code", controllers.VerifyReport)
+	}
+}
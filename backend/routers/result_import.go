@@ -0,0 +1,19 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupResultImportRouter 外部系统（HL7 v2、FHIR）检验结果导入，仅机构与管理员可用
+func SetupResultImportRouter(r *gin.RouterGroup) {
+	imports := r.Group("/imports/results")
+	imports.Use(middlewares.AuthMiddleWare(), middlewares.RequireUserType(3, 2))
+	{
+		imports.POST("", controllers.ImportLabResults)
+		imports.GET("", controllers.GetImportReports)
+		imports.GET("/:id", controllers.GetImportReport)
+	}
+}
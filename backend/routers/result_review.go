@@ -0,0 +1,23 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupResultReviewRouter 结果审核：录入的结果由机构审核人确认危急值并发布后客户才可见
+func SetupResultReviewRouter(r *gin.RouterGroup) {
+	r.GET("/reviews/pending", middlewares.AuthMiddleWare(), middlewares.RequireUserType(3, 2), controllers.GetPendingReviews)
+	// 危急值告警及确认记录
+	r.GET("/critical-alerts", middlewares.AuthMiddleWare(), middlewares.RequireUserType(3, 2), controllers.GetCriticalAlerts)
+
+	review := r.Group("/user-packages/:id/review")
+	review.Use(middlewares.AuthMiddleWare(), middlewares.RequireUserType(3, 2))
+	{
+		review.GET("", controllers.GetResultReview)
+		review.POST("/critical/:item_id/ack", controllers.AcknowledgeCriticalValue)
+		review.POST("/release", controllers.ReleaseResultReview)
+	}
+}
@@ -1,32 +1,87 @@
 package routers
 
 import (
+	"HealthCare/backend/config"
 	"HealthCare/backend/middlewares"
+	"HealthCare/backend/openapi"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// APIPrefix 当前版本接口的路由前缀
+	APIPrefix = "/api/v1"
+	// LegacyPrefix 旧版接口前缀，保留为已弃用的别名
+	LegacyPrefix = "/api"
+)
+
 func SetupRouter() *gin.Engine {
 	routers := gin.Default()
 
 	routers.Use(middlewares.SetupCorsMiddleware())
+	routers.Use(middlewares.RequestIDMiddleware())
+	routers.Use(middlewares.LocaleMiddleware())
 	{
-		SetupAuthRouter(routers)
-		SetupUserRouter(routers)
-		SetupFamilyRouter(routers)
-		SetupInstitutionRouter(routers)
-		SetupCommentaryRouter(routers)
-		SetupUserViewRouter(routers)
-		SetupAddUserDataRouter(routers)
-		SetupImageOcrRouter(routers)
-		SetupUserPlansRouter(routers)
-		SetupHealthItemsRouter(routers)
-		SetupHealthItemManagerRouter(routers)
-		SetupHealthItemPlanRouter(routers)
-		SetupInstitutionUserPackagesRouter(routers)
-		SetupPlanRecoveryRouter(routers)
-		SetupUserPackageStatusRouter(routers)
-		SetupUserPackageRouter(routers)
+		v1 := routers.Group(APIPrefix)
+		setupAPIRouters(v1)
+		v1.GET("/openapi.json", openapi.Handler(routers, APIPrefix, openapi.Info{
+			Title:   config.AppConfig.App.Name + " API",
+			Version: config.AppConfig.App.Version,
+		}, apiDocs, legacyAliases))
+
+		legacy := routers.Group(LegacyPrefix)
+		legacy.Use(middlewares.DeprecatedMiddleware(LegacyPrefix, APIPrefix, legacyAliases))
+		setupSharedRouters(legacy)
+		SetupLegacyRouter(legacy)
 	}
 	return routers
 }
+
+// setupAPIRouters 注册 /api/v1 下的全部业务路由
+func setupAPIRouters(r *gin.RouterGroup) {
+	setupSharedRouters(r)
+	setupV1Routers(r)
+}
+
+// setupSharedRouters 注册版本化之前已有、同时保留在旧版 /api 下的路由
+func setupSharedRouters(r *gin.RouterGroup) {
+	SetupAuthRouter(r)
+	SetupUserRouter(r)
+	SetupFamilyRouter(r)
+	SetupInstitutionRouter(r)
+	SetupCommentaryRouter(r)
+	SetupUserViewRouter(r)
+	SetupAddUserDataRouter(r)
+	SetupImageOcrRouter(r)
+	SetupUserPlansRouter(r)
+	SetupHealthItemsRouter(r)
+	SetupHealthItemManagerRouter(r)
+	SetupHealthItemPlanRouter(r)
+	SetupInstitutionUserPackagesRouter(r)
+	SetupPlanRecoveryRouter(r)
+	SetupUserPackageStatusRouter(r)
+	SetupUserPackageRouter(r)
+}
+
+// setupV1Routers 注册版本化之后新增的路由，只在 /api/v1 下提供，不再注册到旧版 /api
+func setupV1Routers(r *gin.RouterGroup) {
+	SetupCommentaryModerationRouter(r)
+	SetupFamilyAccessRouter(r)
+	SetupInstitutionMemberRouter(r)
+	SetupUserDeletionRouter(r)
+	SetupAppointmentRouter(r)
+	SetupPlanVersionRouter(r)
+	SetupSearchRouter(r)
+	SetupRecommendationRouter(r)
+	SetupDependentRouter(r)
+	SetupAuditLogRouter(r)
+	SetupExportRouter(r)
+	SetupFHIRRouter(r)
+	SetupResultImportRouter(r)
+	SetupBatchImportRouter(r)
+	SetupReportRouter(r)
+	SetupConclusionRouter(r)
+	SetupResultReviewRouter(r)
+	SetupNotificationRouter(r)
+	SetupCatalogRouter(r)
+}
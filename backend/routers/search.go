@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"HealthCare/backend/controllers"
+	"HealthCare/backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSearchRouter 设置全文搜索相关路由
+func SetupSearchRouter(r *gin.RouterGroup) {
+	search := r.Group("/search")
+	search.Use(middlewares.AuthMiddleWare())
+	{
+		// 搜索套餐、机构与健康项目
+		search.GET("", controllers.Search)
+
+		// 重建搜索索引（仅限管理员）
+		search.POST("/reindex", middlewares.AdminRequiredMiddleware(), controllers.RebuildSearchIndex)
+	}
+}
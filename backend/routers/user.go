@@ -7,8 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupUserRouter(routers *gin.Engine) {
-	user := routers.Group("/api/users")
+func SetupUserRouter(routers *gin.RouterGroup) {
+	user := routers.Group("/users")
 	user.Use(middlewares.AuthMiddleWare())
 	{
 		// 查看用户个人信息
@@ -23,16 +23,23 @@ func SetupUserRouter(routers *gin.Engine) {
 		user.PUT("/:id/reset_pwd", controllers.ResetPwd)
 		// 查找用户管理的机构
 		user.GET("/:id/institution", controllers.GetInstitutionByUserId)
+		// 用户设置语言偏好
+		user.PUT("/:id/language", controllers.UpdateUserLanguage)
 		// 用户更新个人信息
 		user.POST("/:id/profile", controllers.UpdateUserProfile)
-		// 删除用户(物理删除，前端二次确认不予后悔)
+		// 申请注销账号(冷静期后匿名化，管理员可立即执行)
 		user.DELETE("/:id", middlewares.RequireUserType(2, 1), controllers.DeleteUser)
 		// 管理员更改用户权限
 		user.PATCH("/:id/permission", middlewares.RequireUserType(2), controllers.UpdateUserPermission)
+	}
+}
 
-		// 用户选择套餐
-		user.POST("/packages", controllers.SelectPackage)
-		// 用户查看已经选择的套餐
-		user.GET("/:id/packages", controllers.GetUserPackages)
+// SetupUserDeletionRouter 查看、撤销注销申请，仅在 /api/v1 下提供
+func SetupUserDeletionRouter(routers *gin.RouterGroup) {
+	user := routers.Group("/users")
+	user.Use(middlewares.AuthMiddleWare(), middlewares.RequireUserType(2, 1))
+	{
+		user.GET("/:id/deletion", controllers.GetUserDeletion)
+		user.DELETE("/:id/deletion", controllers.CancelUserDeletion)
 	}
 }
@@ -8,12 +8,21 @@ import (
 )
 
 // SetupUserPackageRouter 设置用户套餐选择相关路由
-func SetupUserPackageRouter(r *gin.Engine) {
+func SetupUserPackageRouter(r *gin.RouterGroup) {
 	// 用户选择套餐
-	userPackage := r.Group("/api/user-packages")
+	userPackage := r.Group("/user-packages")
 	userPackage.Use(middlewares.AuthMiddleWare())
 	{
 		userPackage.POST("", controllers.SelectPackage)
 		userPackage.GET("/:id", controllers.GetUserPackages)
 	}
 }
+
+// SetupAppointmentRouter 预约、改约或取消预约体检时间，仅在 /api/v1 下提供
+func SetupAppointmentRouter(r *gin.RouterGroup) {
+	userPackage := r.Group("/user-packages")
+	userPackage.Use(middlewares.AuthMiddleWare())
+	{
+		userPackage.PATCH("/appointments/:id", controllers.UpdatePackageAppointment)
+	}
+}
@@ -8,9 +8,9 @@ import (
 )
 
 // SetupUserPackageStatusRouter 设置用户套餐状态更新相关路由
-func SetupUserPackageStatusRouter(r *gin.Engine) {
+func SetupUserPackageStatusRouter(r *gin.RouterGroup) {
 	// 更新用户套餐状态
-	userPackageStatus := r.Group("/api/user-packages/:user_id/:plan_id/status")
+	userPackageStatus := r.Group("/user-packages/:user_id/:plan_id/status")
 	userPackageStatus.Use(middlewares.AuthMiddleWare())
 	{
 		userPackageStatus.PATCH("", controllers.UpdateUserPackageStatus)
@@ -7,19 +7,30 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupUserPlansRouter(r *gin.Engine) {
+func SetupUserPlansRouter(r *gin.RouterGroup) {
 	// 获取用户套餐列表
-	userPlans := r.Group("/api/users/:id/plans")
+	userPlans := r.Group("/users/:id/plans")
 	userPlans.Use(middlewares.AuthMiddleWare())
 	{
 		userPlans.GET("", controllers.GetUserPlans)
 	}
 
 	// 获取套餐项目列表
-	planItems := r.Group("/api/plans")
+	planItems := r.Group("/plans")
 	planItems.Use(middlewares.AuthMiddleWare())
 	{
 		planItems.GET("/:id/items", controllers.GetPlanItems)
 		planItems.GET("/:id", controllers.GetPlanDetails)
 	}
 }
+
+// SetupPlanVersionRouter 套餐对比与版本，仅在 /api/v1 下提供
+func SetupPlanVersionRouter(r *gin.RouterGroup) {
+	plans := r.Group("/plans")
+	plans.Use(middlewares.AuthMiddleWare())
+	{
+		plans.GET("/compare", controllers.ComparePlans)
+		plans.GET("/:id/versions", controllers.GetPlanVersions)
+		plans.GET("/:id/versions/:version", controllers.GetPlanVersion)
+	}
+}
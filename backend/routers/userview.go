@@ -7,8 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupUserViewRouter(r *gin.Engine) {
-	userview := r.Group("api/userview")
+func SetupUserViewRouter(r *gin.RouterGroup) {
+	userview := r.Group("/userview")
 	userview.Use(middlewares.AuthMiddleWare())
 	{
 		// 查看所有体检项目(包含所有体检套餐)
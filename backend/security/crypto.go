@@ -0,0 +1,200 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 信封加密：每个值使用随机生成的数据密钥（DEK）以 AES-256-GCM 加密，DEK 再由配置中的主密钥（KEK）包装，
+// 与密文一起保存为 enc:v1:<主密钥ID>:<包装后的DEK>:<密文>；轮换主密钥时用新的主密钥重新加密即可
+const prefix = "enc:v1:"
+
+var (
+	ErrNotConfigured = errors.New("encryption keys are not configured")
+	ErrUnknownKey    = errors.New("unknown encryption key")
+	ErrMalformed     = errors.New("malformed ciphertext")
+)
+
+type keyring struct {
+	keys     map[string][]byte
+	active   string
+	indexKey []byte
+}
+
+var ring *keyring
+
+// Configure 设置主密钥（base64 编码的 32 字节，键为密钥ID）、新数据使用的主密钥ID以及盲索引密钥
+func Configure(keys map[string]string, active string, indexKey string) error {
+	r := &keyring{keys: make(map[string][]byte), active: active}
+	for id, encoded := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return fmt.Errorf("invalid key id %q", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return fmt.Errorf("key %q must be 32 bytes encoded in base64", id)
+		}
+		r.keys[id] = key
+	}
+	if _, ok := r.keys[active]; !ok {
+		return fmt.Errorf("active key %q: %w", active, ErrUnknownKey)
+	}
+	idx, err := base64.StdEncoding.DecodeString(indexKey)
+	if err != nil || len(idx) < 32 {
+		return errors.New("blind index key must be at least 32 bytes encoded in base64")
+	}
+	r.indexKey = idx
+	ring = r
+	return nil
+}
+
+// ActiveKey 返回新数据使用的主密钥ID
+func ActiveKey() string {
+	if ring == nil {
+		return ""
+	}
+	return ring.active
+}
+
+// IsEncrypted 判断值是否为本包生成的密文
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// KeyID 返回密文使用的主密钥ID，明文返回空字符串
+func KeyID(value string) string {
+	if !IsEncrypted(value) {
+		return ""
+	}
+	id, _, _ := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	return id
+}
+
+// NeedsRotation 判断值是否需要重新加密：非空的明文（加密上线前写入）或使用了非当前主密钥
+func NeedsRotation(value string) bool {
+	return value != "" && KeyID(value) != ActiveKey()
+}
+
+// Encrypt 使用当前主密钥加密，空字符串不加密
+func Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	if ring == nil {
+		return "", ErrNotConfigured
+	}
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	wrapped, err := seal(ring.keys[ring.active], dek)
+	if err != nil {
+		return "", err
+	}
+	data, err := seal(dek, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return prefix + ring.active + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(data), nil
+}
+
+// Decrypt 解密，非密文（加密上线前写入的明文）原样返回
+func Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if ring == nil {
+		return "", ErrNotConfigured
+	}
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", ErrMalformed
+	}
+	kek, ok := ring.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, parts[0])
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrMalformed
+	}
+	data, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrMalformed
+	}
+	dek, err := open(kek, wrapped)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dek, data)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Rotate 用当前主密钥重新加密，明文直接加密
+func Rotate(value string) (string, error) {
+	plaintext, err := Decrypt(value)
+	if err != nil {
+		return "", err
+	}
+	return Encrypt(plaintext)
+}
+
+// BlindIndex 计算字段值的盲索引，field 区分不同字段使相同的值得到不同的索引；空值返回空字符串
+func BlindIndex(field, value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || ring == nil {
+		return ""
+	}
+	mac := hmac.New(sha256.New, ring.indexKey)
+	mac.Write([]byte(field + ":" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// seal AES-256-GCM 加密，输出为 nonce 与密文的拼接
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrMalformed
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,177 @@
+package security
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testKey 测试用的固定密钥，每个字节都为 b
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+func configure(t *testing.T, keys map[string]string, active string) {
+	t.Helper()
+	if err := Configure(keys, active, testKey(0xEE)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ring = nil })
+}
+
+func TestRoundTrip(t *testing.T) {
+	configure(t, map[string]string{"k1": testKey(1)}, "k1")
+	for _, plaintext := range []string{"13800000000", "北京市海淀区", "5.6", strings.Repeat("x", 4096)} {
+		enc, err := Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsEncrypted(enc) || KeyID(enc) != "k1" || strings.Contains(enc, plaintext) {
+			t.Fatalf("Encrypt(%q) = %q", plaintext, enc)
+		}
+		got, err := Decrypt(enc)
+		if err != nil || got != plaintext {
+			t.Fatalf("Decrypt(Encrypt(%q)) = %q, %v", plaintext, got, err)
+		}
+		// 每次加密使用新的数据密钥与 nonce
+		again, _ := Encrypt(plaintext)
+		if again == enc {
+			t.Fatalf("encrypting %q twice gave the same ciphertext", plaintext)
+		}
+	}
+
+	if enc, err := Encrypt(""); err != nil || enc != "" {
+		t.Fatalf("Encrypt(\"\") = %q, %v", enc, err)
+	}
+	// 加密上线前写入的明文原样返回
+	if got, err := Decrypt("13800000000"); err != nil || got != "13800000000" {
+		t.Fatalf("Decrypt(plaintext) = %q, %v", got, err)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	configure(t, map[string]string{"k1": testKey(1)}, "k1")
+	old, err := Encrypt("135")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 追加新密钥并设为 active-key 后，旧密文仍可解密但需要重新加密
+	configure(t, map[string]string{"k1": testKey(1), "k2": testKey(2)}, "k2")
+	if got, err := Decrypt(old); err != nil || got != "135" {
+		t.Fatalf("Decrypt(old) = %q, %v", got, err)
+	}
+	for value, want := range map[string]bool{old: true, "135": true, "": false} {
+		if NeedsRotation(value) != want {
+			t.Errorf("NeedsRotation(%q) = %v, want %v", value, !want, want)
+		}
+	}
+	rotated, err := Rotate(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if KeyID(rotated) != "k2" || NeedsRotation(rotated) {
+		t.Fatalf("Rotate(old) = %q, want a k2 ciphertext", rotated)
+	}
+	fromPlain, err := Rotate("135")
+	if err != nil || KeyID(fromPlain) != "k2" {
+		t.Fatalf("Rotate(plaintext) = %q, %v", fromPlain, err)
+	}
+
+	// 删除旧密钥后只有重新加密过的值可读
+	configure(t, map[string]string{"k2": testKey(2)}, "k2")
+	for _, value := range []string{rotated, fromPlain} {
+		if got, err := Decrypt(value); err != nil || got != "135" {
+			t.Fatalf("Decrypt(rotated) = %q, %v", got, err)
+		}
+	}
+	if _, err := Decrypt(old); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("got error %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	configure(t, map[string]string{"k1": testKey(1), "k2": testKey(2)}, "k1")
+	enc, err := Encrypt("135")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(strings.TrimPrefix(enc, prefix), ":")
+	flip := func(s string) string {
+		b, _ := base64.RawStdEncoding.DecodeString(s)
+		b[len(b)-1] ^= 1
+		return base64.RawStdEncoding.EncodeToString(b)
+	}
+	tests := map[string]string{
+		"flipped ciphertext":   prefix + parts[0] + ":" + parts[1] + ":" + flip(parts[2]),
+		"flipped wrapped key":  prefix + parts[0] + ":" + flip(parts[1]) + ":" + parts[2],
+		"wrong key id":         prefix + "k2:" + parts[1] + ":" + parts[2],
+		"missing part":         prefix + parts[0] + ":" + parts[1],
+		"invalid base64":       prefix + parts[0] + ":" + parts[1] + ":!!",
+		"truncated ciphertext": prefix + parts[0] + ":" + parts[1] + ":AAAA",
+	}
+	for name, value := range tests {
+		if _, err := Decrypt(value); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s: got error %v, want %v", name, err, ErrMalformed)
+		}
+	}
+}
+
+func TestNotConfigured(t *testing.T) {
+	ring = nil
+	if _, err := Encrypt("135"); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("got error %v, want %v", err, ErrNotConfigured)
+	}
+	if _, err := Decrypt(prefix + "k1:a:b"); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("got error %v, want %v", err, ErrNotConfigured)
+	}
+	if got := BlindIndex("phone", "13800000000"); got != "" {
+		t.Fatalf("got blind index %q without keys", got)
+	}
+}
+
+func TestConfigureErrors(t *testing.T) {
+	t.Cleanup(func() { ring = nil })
+	tests := []struct {
+		name     string
+		keys     map[string]string
+		active   string
+		indexKey string
+	}{
+		{"placeholder key", map[string]string{"k1": "CHANGE_ME"}, "k1", testKey(0xEE)},
+		{"short key", map[string]string{"k1": base64.StdEncoding.EncodeToString(make([]byte, 16))}, "k1", testKey(0xEE)},
+		{"id with colon", map[string]string{"k:1": testKey(1)}, "k:1", testKey(0xEE)},
+		{"empty id", map[string]string{"": testKey(1)}, "", testKey(0xEE)},
+		{"unknown active key", map[string]string{"k1": testKey(1)}, "k2", testKey(0xEE)},
+		{"short blind index key", map[string]string{"k1": testKey(1)}, "k1", base64.StdEncoding.EncodeToString(make([]byte, 16))},
+		{"placeholder blind index key", map[string]string{"k1": testKey(1)}, "k1", "CHANGE_ME"},
+	}
+	for _, tt := range tests {
+		ring = nil
+		if err := Configure(tt.keys, tt.active, tt.indexKey); err == nil || ring != nil {
+			t.Errorf("%s: got error %v", tt.name, err)
+		}
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	configure(t, map[string]string{"k1": testKey(1)}, "k1")
+	phone := BlindIndex("phone", "13800000000")
+	if len(phone) != 64 || BlindIndex("phone", " 13800000000 ") != phone {
+		t.Fatalf("got blind index %q", phone)
+	}
+	if BlindIndex("email", "13800000000") == phone || BlindIndex("phone", "13800000001") == phone {
+		t.Fatal("different fields or values give the same blind index")
+	}
+	if BlindIndex("phone", " ") != "" {
+		t.Fatal("blank value has a blind index")
+	}
+
+	// 盲索引只依赖 blind-index-key，轮换主密钥不影响手机号查找
+	configure(t, map[string]string{"k2": testKey(2)}, "k2")
+	if BlindIndex("phone", "13800000000") != phone {
+		t.Fatal("rotating the master key changed the blind index")
+	}
+}
@@ -0,0 +1,266 @@
+// Package sheet 读取 CSV 与 XLSX 表格的第一个工作表，返回按行排列的单元格文本
+package sheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsupported   = errors.New("unsupported file type, expected .csv or .xlsx")
+	ErrNoSheet       = errors.New("workbook has no worksheet")
+	ErrTooManyRows   = errors.New("worksheet has too many rows")
+	ErrTooManyCells  = errors.New("worksheet has too many cells")
+	ErrOutsideHeader = errors.New("cell is outside the header columns")
+)
+
+// maxColumns XLSX 的最大列数（XFD）
+const maxColumns = 16384
+
+// maxCells 读取 XLSX 时按列号补齐后的单元格总数上限，防止稀疏的大列号占用过多内存
+const maxCells = 1 << 20
+
+// Read 按文件扩展名读取 CSV 或 XLSX，去掉行尾的空单元格；
+// 最多读取 maxRows 行（含表头，XLSX 按行号计），超出时返回 ErrTooManyRows
+func Read(fileName string, data []byte, maxRows int) ([][]string, error) {
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".csv":
+		return ReadCSV(data, maxRows)
+	case ".xlsx":
+		return ReadXLSX(data, maxRows)
+	}
+	return nil, ErrUnsupported
+}
+
+// ReadCSV 读取 CSV，兼容 UTF-8 BOM 与各行列数不一致；超过 maxRows 行时返回 ErrTooManyRows
+func ReadCSV(data []byte, maxRows int) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	var rows [][]string
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) >= maxRows {
+			return nil, ErrTooManyRows
+		}
+		rows = append(rows, trimRow(row))
+	}
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxRow struct {
+	R     int `xml:"r,attr"`
+	Cells []struct {
+		R      string    `xml:"r,attr"`
+		T      string    `xml:"t,attr"`
+		V      string    `xml:"v"`
+		Inline *xlsxText `xml:"is"`
+	} `xml:"c"`
+}
+
+// ReadXLSX 读取工作簿中的第一个工作表，支持共享字符串、内联字符串与数值单元格，
+// 日期等带格式的数值按原始值返回。逐行解析工作表：行号超过 maxRows 时返回 ErrTooManyRows，
+// 第一行为表头，其后各行超出表头列数的非空单元格返回 ErrOutsideHeader，补齐后的单元格总数超过上限时返回 ErrTooManyCells
+func ReadXLSX(data []byte, maxRows int) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXML(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+	rc, err := files[sheetPath].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	decoder := xml.NewDecoder(io.LimitReader(rc, 64<<20))
+
+	var rows [][]string
+	width, cellCount, i := maxColumns, 0, 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row xlsxRow
+		if err := decoder.DecodeElement(&row, &start); err != nil {
+			return nil, err
+		}
+		index := row.R - 1
+		if row.R == 0 {
+			index = i
+		}
+		i++
+		if index < 0 || index >= maxRows {
+			return nil, ErrTooManyRows
+		}
+		for len(rows) <= index {
+			rows = append(rows, nil)
+		}
+		var cells []string
+		for j, c := range row.Cells {
+			col := j
+			if c.R != "" && columnIndex(c.R) >= 0 {
+				col = columnIndex(c.R)
+			}
+			var value string
+			switch c.T {
+			case "s":
+				n, err := strconv.Atoi(c.V)
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, errors.New("invalid shared string index in cell " + c.R)
+				}
+				value = shared.Items[n].String()
+			case "inlineStr":
+				if c.Inline != nil {
+					value = c.Inline.String()
+				}
+			default:
+				value = c.V
+			}
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			if col >= width {
+				return nil, ErrOutsideHeader
+			}
+			if col >= len(cells) {
+				cellCount += col + 1 - len(cells)
+				if cellCount > maxCells {
+					return nil, ErrTooManyCells
+				}
+				cells = append(cells, make([]string, col+1-len(cells))...)
+			}
+			cells[col] = value
+		}
+		rows[index] = trimRow(cells)
+		if index == 0 {
+			width = len(rows[0])
+		}
+	}
+}
+
+// firstSheetPath 按 workbook.xml 与其关系文件找到第一个工作表，找不到时使用 sheet1.xml
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var wb xlsxWorkbook
+	var rels xlsxRelationships
+	if f, ok := files["xl/workbook.xml"]; ok && decodeXML(f, &wb) == nil && len(wb.Sheets) > 0 {
+		if f, ok := files["xl/_rels/workbook.xml.rels"]; ok && decodeXML(f, &rels) == nil {
+			for _, rel := range rels.Relationships {
+				if rel.ID != wb.Sheets[0].RID {
+					continue
+				}
+				target := strings.TrimPrefix(rel.Target, "/")
+				if !strings.HasPrefix(target, "xl/") {
+					target = path.Join("xl", target)
+				}
+				if _, ok := files[target]; ok {
+					return target, nil
+				}
+			}
+		}
+	}
+	if _, ok := files["xl/worksheets/sheet1.xml"]; ok {
+		return "xl/worksheets/sheet1.xml", nil
+	}
+	return "", ErrNoSheet
+}
+
+func decodeXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(io.LimitReader(rc, 64<<20)).Decode(v)
+}
+
+// columnIndex 将单元格引用（如 AB12）的列字母转换为从 0 开始的列号
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}
+
+func trimRow(row []string) []string {
+	for i := range row {
+		row[i] = strings.TrimSpace(row[i])
+	}
+	for len(row) > 0 && row[len(row)-1] == "" {
+		row = row[:len(row)-1]
+	}
+	return row
+}
@@ -0,0 +1,129 @@
+package sheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// buildXLSX 生成只包含一个工作表的最小 XLSX，rows 为 sheetData 内的 <row> 元素
+func buildXLSX(t *testing.T, rows string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>姓名</t></si><si><r><t>张</t></r><r><t>三</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData>` + rows + `</sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadXLSX(t *testing.T) {
+	data := buildXLSX(t, `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="inlineStr"><is><t>phone</t></is></c><c r="C1"><v>#12</v></c></row>`+
+		`<row r="3"><c r="A3" t="s"><v>1</v></c><c r="C3"><v>5.6</v></c><c r="D3" s="1"/></row>`)
+	got, err := ReadXLSX(data, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"姓名", "phone", "#12"}, nil, {"张三", "", "5.6"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    string
+		maxRows int
+		want    error
+	}{
+		{"row beyond limit", `<row r="1"><c r="A1"><v>a</v></c></row><row r="4"><c r="A4"><v>b</v></c></row>`, 3, ErrTooManyRows},
+		{"rows without numbers beyond limit", `<row><c><v>a</v></c></row><row><c><v>b</v></c></row>`, 1, ErrTooManyRows},
+		{"cell beyond header", `<row r="1"><c r="A1"><v>a</v></c><c r="B1"><v>b</v></c></row><row r="2"><c r="C2"><v>x</v></c></row>`, 10, ErrOutsideHeader},
+		{"column beyond XFD", `<row r="1"><c r="XFE1"><v>a</v></c></row>`, 10, ErrOutsideHeader},
+		{"empty styled cells beyond header are ignored", `<row r="1"><c r="A1"><v>a</v></c></row><row r="2"><c r="XFD2" s="1"/></row>`, 10, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadXLSX(buildXLSX(t, tt.rows), tt.maxRows)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// 稀疏的大列号不能按列号补齐出大量单元格：表头宽度为 XFD 时由单元格总数上限拦截
+func TestReadXLSXSparseColumns(t *testing.T) {
+	var rows strings.Builder
+	rows.WriteString(`<row r="1"><c r="XFD1"><v>header</v></c></row>`)
+	for i := 2; i <= 2001; i++ {
+		fmt.Fprintf(&rows, `<row r="%d"><c r="XFD%d"><v>1</v></c></row>`, i, i)
+	}
+	data := buildXLSX(t, rows.String())
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := ReadXLSX(data, 2001)
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrTooManyCells) {
+		t.Fatalf("got error %v, want %v", err, ErrTooManyCells)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 256<<20 {
+		t.Fatalf("allocated %d MB reading a %d KB file", allocated>>20, len(data)>>10)
+	}
+
+	// 表头较窄时，数据行中的大列号直接被拒绝
+	rows.Reset()
+	rows.WriteString(`<row r="1"><c r="A1"><v>header</v></c></row>`)
+	for i := 2; i <= 2001; i++ {
+		fmt.Fprintf(&rows, `<row r="%d"><c r="XFD%d"><v>1</v></c></row>`, i, i)
+	}
+	if _, err := ReadXLSX(buildXLSX(t, rows.String()), 2001); !errors.Is(err, ErrOutsideHeader) {
+		t.Fatalf("got error %v, want %v", err, ErrOutsideHeader)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	got, err := ReadCSV([]byte("\xef\xbb\xbfname, phone ,\n张三,138\n"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"name", "phone"}, {"张三", "138"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if _, err := ReadCSV([]byte("a\nb\nc\n"), 2); !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("got error %v, want %v", err, ErrTooManyRows)
+	}
+}
+
+func TestRead(t *testing.T) {
+	if _, err := Read("data.xls", nil, 10); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("got error %v, want %v", err, ErrUnsupported)
+	}
+	if _, err := Read("DATA.CSV", []byte("a,b\n"), 10); err != nil {
+		t.Fatal(err)
+	}
+}